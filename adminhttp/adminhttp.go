@@ -0,0 +1,165 @@
+// Package adminhttp exposes a loaded antconfig.AntConfig over HTTP so
+// operators can inspect and reload a running service:
+//
+//	GET    /config             effective config as JSON, secrets redacted
+//	GET    /config/provenance  layer that set each field, keyed by Go field path
+//	POST   /config/reload      run the reload function and report the outcome
+//	GET    /config/reload      outcome of the latest Reload (antconfig.ReloadStatus)
+//	GET    /config/features    feature flags and their state (antconfig.Feature)
+//	PUT    /config/features/N  toggle feature N at runtime: {"enabled": true}
+//	DELETE /config/features/N  drop the runtime toggle of feature N
+//
+// Mount the handler on an internal-only listener or behind authentication;
+// it does not authenticate requests itself.
+//
+//	mux.Handle("/config", adminhttp.New(ac, reload))
+//	mux.Handle("/config/", adminhttp.New(ac, reload))
+package adminhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/robfordww/antconfig"
+)
+
+// Handler serves the admin endpoints for one AntConfig. Requests are
+// serialized with the reload function, which may rewrite the registered
+// struct in place; reloads by ac.Reload, StartPolling and ReloadOnSignal load
+// into a fresh value published as a whole, so no response observes a
+// half-applied load either way.
+type Handler struct {
+	ac     *antconfig.AntConfig
+	reload func() error
+	mu     sync.RWMutex
+}
+
+// New returns a Handler for ac. reload is called by POST /config/reload,
+// typically re-running WriteConfigValues (for example into a fresh struct
+// published through an antconfig.Snapshot) or ac.Reload, whose failures
+// GET /config/reload then reports; when nil, reload requests are answered
+// with 501 Not Implemented.
+func New(ac *antconfig.AntConfig, reload func() error) *Handler {
+	return &Handler{ac: ac, reload: reload}
+}
+
+// ServeHTTP dispatches on the final path segments, so the handler works
+// whether it is mounted at "/config" or under a prefix such as "/admin/".
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if _, name, ok := strings.Cut(path, "/config/features/"); ok && !strings.Contains(name, "/") {
+		h.serveFeature(w, r, name)
+		return
+	}
+	switch {
+	case strings.HasSuffix(path, "/config/features"):
+		if !allow(w, r, http.MethodGet) {
+			return
+		}
+		writeJSON(w, http.StatusOK, h.ac.Features())
+	case strings.HasSuffix(path, "/config/provenance"):
+		if !allow(w, r, http.MethodGet) {
+			return
+		}
+		h.mu.RLock()
+		prov := h.ac.Provenance()
+		h.mu.RUnlock()
+		writeJSON(w, http.StatusOK, prov)
+	case strings.HasSuffix(path, "/config/reload"):
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			writeJSON(w, http.StatusOK, h.ac.ReloadStatus())
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "GET, POST")
+			writeJSON(w, http.StatusMethodNotAllowed, errorBody{Error: "method " + r.Method + " not allowed"})
+			return
+		}
+		if h.reload == nil {
+			writeJSON(w, http.StatusNotImplemented, errorBody{Error: "reload is not configured"})
+			return
+		}
+		h.mu.Lock()
+		err := h.reload()
+		h.mu.Unlock()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorBody{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "reloaded"})
+	case strings.HasSuffix(path, "/config"):
+		if !allow(w, r, http.MethodGet) {
+			return
+		}
+		h.mu.RLock()
+		doc, err := h.ac.RedactedConfig()
+		h.mu.RUnlock()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, errorBody{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, doc)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveFeature toggles or resets the feature flag name. Toggles only touch
+// atomic state, so they do not wait for a running reload.
+func (h *Handler) serveFeature(w http.ResponseWriter, r *http.Request, name string) {
+	var err error
+	switch r.Method {
+	case http.MethodPut:
+		var body struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Enabled == nil {
+			writeJSON(w, http.StatusBadRequest, errorBody{Error: `expected a body like {"enabled": true}`})
+			return
+		}
+		err = h.ac.SetFeature(name, *body.Enabled)
+	case http.MethodDelete:
+		err = h.ac.ResetFeature(name)
+	default:
+		w.Header().Set("Allow", "PUT, DELETE")
+		writeJSON(w, http.StatusMethodNotAllowed, errorBody{Error: "method " + r.Method + " not allowed"})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, errorBody{Error: err.Error()})
+		return
+	}
+	for _, f := range h.ac.Features() {
+		if f.Name == name {
+			writeJSON(w, http.StatusOK, f)
+			return
+		}
+	}
+}
+
+// errorBody is the JSON body of failed requests.
+type errorBody struct {
+	Error string `json:"error"`
+}
+
+// allow reports whether r uses method, answering 405 otherwise. GET also
+// admits HEAD.
+func allow(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method == method || (method == http.MethodGet && r.Method == http.MethodHead) {
+		return true
+	}
+	w.Header().Set("Allow", method)
+	writeJSON(w, http.StatusMethodNotAllowed, errorBody{Error: "method " + r.Method + " not allowed"})
+	return false
+}
+
+// writeJSON writes v as indented JSON with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
@@ -0,0 +1,169 @@
+package adminhttp
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/robfordww/antconfig"
+)
+
+func TestHandler(t *testing.T) {
+	type Config struct {
+		Host     string `json:"host" default:"localhost"`
+		Password string `json:"password" default:"hunter2" secret:"true"`
+		Port     int    `json:"port" env:"ADMINHTTP_PORT"`
+	}
+	var cfg Config
+	ac := antconfig.New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--none"})
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	reloads := 0
+	var reloadErr error
+	h := New(ac, func() error {
+		reloads++
+		if reloadErr != nil {
+			return reloadErr
+		}
+		return ac.WriteConfigValues()
+	})
+	do := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/config")
+	var doc map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("GET /config: %d %s", rec.Code, rec.Body)
+	}
+	if doc["host"] != "localhost" || doc["password"] != antconfig.Redacted {
+		t.Errorf("unexpected config: %v", doc)
+	}
+
+	rec = do(http.MethodGet, "/admin/config/provenance")
+	var prov map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &prov); err != nil || prov["Host"] != "default" {
+		t.Fatalf("GET provenance: %d %s", rec.Code, rec.Body)
+	}
+
+	t.Setenv("ADMINHTTP_PORT", "9090")
+	if rec = do(http.MethodPost, "/config/reload"); rec.Code != http.StatusOK || reloads != 1 || cfg.Port != 9090 {
+		t.Fatalf("POST reload: %d %s (port %d)", rec.Code, rec.Body, cfg.Port)
+	}
+	reloadErr = errors.New("bad file")
+	if rec = do(http.MethodPost, "/config/reload"); rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "bad file") {
+		t.Fatalf("failed reload: %d %s", rec.Code, rec.Body)
+	}
+
+	if rec = do(http.MethodPost, "/config"); rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != http.MethodGet {
+		t.Errorf("POST /config: %d allow=%q", rec.Code, rec.Header().Get("Allow"))
+	}
+	if rec = do(http.MethodDelete, "/config/reload"); rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, POST" {
+		t.Errorf("DELETE reload: %d allow=%q", rec.Code, rec.Header().Get("Allow"))
+	}
+	if rec = do(http.MethodGet, "/other"); rec.Code != http.StatusNotFound {
+		t.Errorf("GET /other: %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	New(ac, nil).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/config/reload", nil))
+	if rec.Code != http.StatusNotImplemented {
+		t.Errorf("reload without func: %d", rec.Code)
+	}
+}
+
+func TestFeatureEndpoints(t *testing.T) {
+	type Config struct {
+		Checkout bool `feature:"new-checkout"`
+	}
+	var cfg Config
+	ac := antconfig.New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--none"})
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	h := New(ac, nil)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPut, "/admin/config/features/new-checkout", `{"enabled": true}`)
+	var state antconfig.FeatureState
+	if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil || rec.Code != http.StatusOK || !state.Enabled || !state.Overridden {
+		t.Fatalf("PUT feature: %d %s", rec.Code, rec.Body)
+	}
+	if !ac.Feature("new-checkout") || cfg.Checkout {
+		t.Fatal("toggle did not reach Feature, or changed the struct")
+	}
+	rec = do(http.MethodGet, "/config/features", "")
+	var list []antconfig.FeatureState
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list) != 1 || !list[0].Enabled {
+		t.Fatalf("GET features: %d %s", rec.Code, rec.Body)
+	}
+	if rec = do(http.MethodDelete, "/config/features/new-checkout", ""); rec.Code != http.StatusOK || ac.Feature("new-checkout") {
+		t.Fatalf("DELETE feature: %d %s", rec.Code, rec.Body)
+	}
+
+	if rec = do(http.MethodPut, "/config/features/nope", `{"enabled": true}`); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown feature: %d", rec.Code)
+	}
+	if rec = do(http.MethodPut, "/config/features/new-checkout", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing enabled: %d", rec.Code)
+	}
+	if rec = do(http.MethodGet, "/config/features/new-checkout", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET feature: %d", rec.Code)
+	}
+}
+
+func TestReloadStatus(t *testing.T) {
+	type Config struct {
+		Port int `json:"port" env:"ADMINHTTP_STATUS_PORT"`
+	}
+	var cfg Config
+	ac := antconfig.New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	ac.SetPostLoad(func(c any) error {
+		if c.(*Config).Port == 0 {
+			return errors.New("port is required")
+		}
+		return nil
+	})
+	h := New(ac, func() error {
+		_, err := ac.Reload()
+		return err
+	})
+	do := func(method string) (*httptest.ResponseRecorder, antconfig.ReloadStatus) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/config/reload", nil))
+		var status antconfig.ReloadStatus
+		if method == http.MethodGet {
+			if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+				t.Fatalf("GET reload: %d %s", rec.Code, rec.Body)
+			}
+		}
+		return rec, status
+	}
+
+	if rec, _ := do(http.MethodPost); rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "port is required") {
+		t.Fatalf("rejected reload: %d %s", rec.Code, rec.Body)
+	}
+	if _, status := do(http.MethodGet); status.Failures != 1 || !strings.Contains(status.Error, "port is required") || !status.LastSuccess.IsZero() {
+		t.Fatalf("status after failure: %+v", status)
+	}
+	t.Setenv("ADMINHTTP_STATUS_PORT", "8080")
+	if rec, _ := do(http.MethodPost); rec.Code != http.StatusOK {
+		t.Fatalf("POST reload: %d %s", rec.Code, rec.Body)
+	}
+	if _, status := do(http.MethodGet); status.Failures != 0 || status.Error != "" || status.LastSuccess.IsZero() {
+		t.Fatalf("status after success: %+v", status)
+	}
+}
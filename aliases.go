@@ -0,0 +1,104 @@
+package antconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// AliasUse records a renamed setting that was supplied under an old name
+// declared with an `envalias:"OLD_NAME"` or `alias:"old.key"` tag.
+type AliasUse struct {
+	// Path is the dotted Go path of the field, e.g. "Database.Host".
+	Path string
+	// Old is the name that was used: an env var or a dotted config key.
+	Old string
+	// New is the current name: the field's env var or config key.
+	New string
+	// Source is the layer the old name was read from.
+	Source Layer
+}
+
+// OnDeprecatedAlias registers fn to be called after each successful
+// WriteConfigValues that read at least one setting through an alias, listing
+// the old names used so applications can warn about them. A nil fn disables
+// the callback.
+func (a *AntConfig) OnDeprecatedAlias(fn func(used []AliasUse)) {
+	a.onAlias = fn
+}
+
+// aliasNames splits a comma-separated alias tag value.
+func aliasNames(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	var names []string
+	for _, n := range strings.Split(tag, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names
+}
+
+// applyFileAliases moves values found under `alias` keys of the JSON document
+// to their field's current key, so encoding/json decodes them. Alias keys are
+// dotted paths relative to the object holding the field. When both keys are
+// present the current one wins; the alias is still reported as used.
+func applyFileAliases(js []byte, plan *fieldPlan) ([]byte, []AliasUse, error) {
+	fields := plan.withTag("alias")
+	if len(fields) == 0 {
+		return js, nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		// Let the regular decoding report malformed documents
+		return js, nil, nil
+	}
+	var uses []AliasUse
+	for _, f := range fields {
+		if f.jsonPath == nil {
+			continue
+		}
+		parent := f.jsonPath[:len(f.jsonPath)-1]
+		for _, old := range aliasNames(f.tagvalue) {
+			oldPath := append(append([]string(nil), parent...), strings.Split(old, ".")...)
+			val, ok := popJSONValue(doc, oldPath)
+			if !ok {
+				continue
+			}
+			if !jsonHasPath(doc, f.jsonPath) {
+				setJSONValue(doc, f.jsonPath, val)
+			}
+			uses = append(uses, AliasUse{Path: f.path, Old: strings.Join(oldPath, "."), New: strings.Join(f.jsonPath, "."), Source: LayerFile})
+		}
+	}
+	if len(uses) == 0 {
+		return js, nil, nil
+	}
+	rest, err := json.Marshal(doc)
+	return rest, uses, err
+}
+
+// setJSONValue stores val at path in doc, reusing existing objects whose keys
+// match case-insensitively and creating missing ones.
+func setJSONValue(doc map[string]any, path []string, val any) {
+	cur := doc
+	for _, key := range path[:len(path)-1] {
+		for k := range cur {
+			if strings.EqualFold(k, key) {
+				key = k
+				break
+			}
+		}
+		next, ok := cur[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			cur[key] = next
+		}
+		cur = next
+	}
+	cur[path[len(path)-1]] = val
+}
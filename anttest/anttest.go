@@ -0,0 +1,131 @@
+// Package anttest builds hermetic AntConfigs for tests of code that loads
+// configuration with antconfig:
+//
+//	var cfg Config
+//	ac := anttest.Load(t, &cfg,
+//		anttest.WithConfigFile(`{"server": {"port": 8080}}`),
+//		anttest.WithEnv(map[string]string{"APP_DEBUG": "true"}),
+//		anttest.WithArgs("--workers", "4"),
+//	)
+//
+// An AntConfig from this package reads only what its options supply: the
+// process environment, os.Args and any config.json or .env found by
+// discovery are ignored, so tests do not depend on the machine running them
+// and may run in parallel.
+package anttest
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/robfordww/antconfig"
+)
+
+// Option configures the AntConfig built by New or Load.
+type Option func(*fixture) error
+
+// fixture collects the options of one New call.
+type fixture struct {
+	ac   *antconfig.AntConfig
+	env  map[string]string
+	args []string
+	// dir is the temporary directory holding the files, removed by cleanup.
+	dir string
+}
+
+// WithEnv sets the environment the env layer (and .env precedence) reads,
+// instead of none. Repeated options are merged, later values winning.
+func WithEnv(vars map[string]string) Option {
+	return func(f *fixture) error {
+		maps.Copy(f.env, vars)
+		return nil
+	}
+}
+
+// WithConfigFile writes content, JSON or JSONC, to a config.jsonc file in a
+// temporary directory and uses it as the config file. Relative paths in the
+// file, such as "file" $sources, resolve against that directory. A repeated
+// option replaces the content.
+func WithConfigFile(content string) Option {
+	return func(f *fixture) error {
+		if f.dir == "" {
+			dir, err := os.MkdirTemp("", "anttest-")
+			if err != nil {
+				return err
+			}
+			f.dir = dir
+		}
+		path := filepath.Join(f.dir, "config.jsonc")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return err
+		}
+		return f.ac.SetConfigPath(path)
+	}
+}
+
+// WithArgs sets the command-line arguments the flag layer parses, instead of
+// none. Repeated options are appended.
+func WithArgs(args ...string) Option {
+	return func(f *fixture) error {
+		f.args = append(f.args, args...)
+		return nil
+	}
+}
+
+// New returns an AntConfig with cfg registered and the options applied,
+// ready for WriteConfigValues, and a cleanup function that removes the files
+// it wrote; it is safe to call more than once. On error nothing is left
+// behind.
+func New(cfg any, opts ...Option) (ac *antconfig.AntConfig, cleanup func(), err error) {
+	f := &fixture{ac: antconfig.New(), env: map[string]string{}, args: []string{}}
+	cleanup = func() {
+		if f.dir != "" {
+			_ = os.RemoveAll(f.dir)
+		}
+	}
+	if err := f.ac.DisableAutoDiscovery(); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	for _, opt := range opts {
+		if err := opt(f); err != nil {
+			cleanup()
+			return nil, nil, err
+		}
+	}
+	if err := f.ac.SetEnvironment(f.env); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	f.ac.SetFlagArgs(f.args)
+	if err := f.ac.SetConfig(cfg); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return f.ac, cleanup, nil
+}
+
+// TB is the subset of testing.TB used by Load, so the package does not
+// import "testing".
+type TB interface {
+	Helper()
+	Cleanup(func())
+	Fatalf(format string, args ...any)
+}
+
+// Load is New followed by WriteConfigValues for tests: cleanup is
+// registered with t, and any error fails the test.
+func Load(t TB, cfg any, opts ...Option) *antconfig.AntConfig {
+	t.Helper()
+	ac, cleanup, err := New(cfg, opts...)
+	if err != nil {
+		t.Fatalf("anttest: %v", err)
+		return nil
+	}
+	t.Cleanup(cleanup)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("anttest: WriteConfigValues: %v", err)
+	}
+	return ac
+}
@@ -0,0 +1,86 @@
+package anttest
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/robfordww/antconfig"
+)
+
+type config struct {
+	Host    string `json:"host" default:"localhost"`
+	Port    int    `json:"port" env:"ANTTEST_PORT"`
+	Workers int    `json:"workers" flag:"workers"`
+	Debug   bool   `json:"debug" env:"ANTTEST_DEBUG"`
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+	var cfg config
+	ac := Load(t, &cfg,
+		WithConfigFile(`{
+			// JSONC is fine
+			"host": "file-host",
+			"port": 8080
+		}`),
+		WithEnv(map[string]string{"ANTTEST_PORT": "9090"}),
+		WithEnv(map[string]string{"ANTTEST_DEBUG": "true"}),
+		WithArgs("--workers", "4"),
+	)
+	want := config{Host: "file-host", Port: 9090, Workers: 4, Debug: true}
+	if cfg != want {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+	if prov := ac.Provenance(); prov["Port"] != antconfig.LayerEnv || prov["Workers"] != antconfig.LayerFlag {
+		t.Fatalf("unexpected provenance: %v", prov)
+	}
+}
+
+func TestHermetic(t *testing.T) {
+	t.Setenv("ANTTEST_PORT", "1234")
+	var cfg config
+	Load(t, &cfg)
+	if cfg != (config{Host: "localhost"}) {
+		t.Fatalf("fixture read outside its options: %+v", cfg)
+	}
+}
+
+func TestNewCleanup(t *testing.T) {
+	var cfg config
+	ac, cleanup, err := New(&cfg, WithConfigFile(`{"host": "a"}`), WithConfigFile(`{"host": "b"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil || cfg.Host != "b" {
+		t.Fatalf("WriteConfigValues: %v (%+v)", err, cfg)
+	}
+	path := ac.ConfigPath()
+	cleanup()
+	cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("config file left behind: %v", err)
+	}
+
+	if _, _, err := New(config{}); err == nil {
+		t.Fatal("expected error for a non-pointer config")
+	}
+}
+
+type fakeTB struct {
+	testing.TB
+	failed string
+}
+
+func (f *fakeTB) Fatalf(format string, args ...any) {
+	f.failed = strings.TrimSpace(format)
+}
+
+func TestLoadFails(t *testing.T) {
+	var cfg config
+	tb := &fakeTB{TB: t}
+	Load(tb, &cfg, WithArgs("--workers", "many"))
+	if !strings.Contains(tb.failed, "WriteConfigValues") {
+		t.Fatalf("expected the load error to fail the test, got %q", tb.failed)
+	}
+}
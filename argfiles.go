@@ -0,0 +1,64 @@
+package antconfig
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SetArgFiles enables response files in command-line arguments: an argument
+// "@path" is replaced by the lines of that file, one argument per line, so
+// long override sets can be kept out of the command line, e.g. in CI jobs.
+// It applies to the arguments WriteConfigValues parses (SetFlagArgs or
+// os.Args[1:]) and to ParseAndLoad; a FlagSet parsed by the caller can be fed
+// through ExpandArgFiles. It is off by default because values may start with
+// '@'.
+func (a *AntConfig) SetArgFiles(on bool) error {
+	if err := a.checkUnlocked("SetArgFiles"); err != nil {
+		return err
+	}
+	a.argFiles = on
+	return nil
+}
+
+// ExpandArgFiles replaces each argument "@path" before a "--" terminator with
+// the lines of the file at path, relative to the working directory. Lines are
+// taken verbatim, spaces included, except that a trailing "\r" is dropped and
+// blank lines and lines starting with '#' are skipped; "@" arguments inside a
+// file are not expanded again. "@@text" stands for the literal argument
+// "@text".
+func ExpandArgFiles(args []string) ([]string, error) {
+	out := make([]string, 0, len(args))
+	for i, arg := range args {
+		if arg == "--" {
+			return append(out, args[i:]...), nil
+		}
+		switch {
+		case strings.HasPrefix(arg, "@@"):
+			out = append(out, arg[1:])
+		case strings.HasPrefix(arg, "@") && len(arg) > 1:
+			data, err := os.ReadFile(arg[1:])
+			if err != nil {
+				return nil, fmt.Errorf("argument file: %w", err)
+			}
+			for _, line := range strings.Split(string(data), "\n") {
+				line = strings.TrimSuffix(line, "\r")
+				if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+					continue
+				}
+				out = append(out, line)
+			}
+		default:
+			out = append(out, arg)
+		}
+	}
+	return out, nil
+}
+
+// expandArgs applies ExpandArgFiles to args when SetArgFiles is on.
+func (a *AntConfig) expandArgs(args []string) ([]string, error) {
+	if !a.argFiles {
+		return args, nil
+	}
+	return ExpandArgFiles(args)
+}
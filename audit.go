@@ -0,0 +1,174 @@
+package antconfig
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AccessReport tells which config fields the application read through Read
+// or Lookup since EnableAccessAudit (or the last ResetAccessAudit).
+type AccessReport struct {
+	// Reads counts the reads of each dotted Go field path. Reading a nested
+	// struct counts for the struct's path.
+	Reads map[string]int
+	// Unread lists, sorted, the leaf fields that were not read, neither
+	// directly nor through an enclosing struct: candidates for dead config
+	// keys.
+	Unread []string
+}
+
+// accessAudit records the reads of an AntConfig (EnableAccessAudit).
+type accessAudit struct {
+	mu    sync.Mutex
+	reads map[string]int
+	// index maps the address and type of every field of the registered
+	// config to its path. indexed is the load it was built after; a miss
+	// rebuilds it only once a later load may have moved sections.
+	index   map[fieldAddr]string
+	indexed *loadedState
+}
+
+// fieldAddr identifies a field in memory. The type tells a struct apart from
+// its first field, which has the same address.
+type fieldAddr struct {
+	ptr uintptr
+	typ reflect.Type
+}
+
+// EnableAccessAudit starts recording which config fields the application
+// reads, to find settings nobody uses and prune them. Go cannot observe plain
+// field reads, so reads are counted when they go through Read, the
+// instrumented accessor, or Lookup:
+//
+//	ac.EnableAccessAudit()
+//	...
+//	srv.Addr = antconfig.Read(ac, &cfg.HTTP.Addr)
+//	...
+//	for _, path := range ac.AccessReport().Unread {
+//		log.Printf("config field %s is never read", path)
+//	}
+//
+// Calling it again keeps the reads recorded so far.
+func (a *AntConfig) EnableAccessAudit() {
+	if a.audit == nil {
+		a.audit = &accessAudit{reads: map[string]int{}}
+	}
+}
+
+// Read returns *field, a field of the config registered with SetConfig
+// (&cfg.Database.Host) or of the latest LoadInto or Reload value, recording
+// the read when EnableAccessAudit is on. It is safe for concurrent use.
+// Pointers that are not config fields are only dereferenced.
+func Read[T any](a *AntConfig, field *T) T {
+	if au := a.audit; au != nil && field != nil && a.cfgRef != nil {
+		a.checkFrozen()
+		au.mu.Lock()
+		addr := fieldAddr{reflect.ValueOf(field).Pointer(), reflect.TypeFor[T]()}
+		path, ok := au.index[addr]
+		if st := a.loaded.Load(); !ok && (au.index == nil || au.indexed != st) {
+			// Sections allocated, or a fresh value published, by a load
+			// since the index was built
+			au.index, au.indexed = auditIndex(a.cfgRef), st
+			if cur := a.current(); cur != a.cfgRef {
+				for k, v := range auditIndex(cur) {
+					au.index[k] = v
+				}
+			}
+			path, ok = au.index[addr]
+		}
+		if ok {
+			au.reads[path]++
+		}
+		au.mu.Unlock()
+	}
+	return *field
+}
+
+// recordRead counts a read of the field at path for EnableAccessAudit.
+func (a *AntConfig) recordRead(path string) {
+	if au := a.audit; au != nil {
+		au.mu.Lock()
+		au.reads[path]++
+		au.mu.Unlock()
+	}
+}
+
+// auditIndex maps the fields of the config cfg to their paths.
+func auditIndex(cfg any) map[fieldAddr]string {
+	plan, err := newFieldPlan(cfg)
+	if err != nil {
+		return map[fieldAddr]string{}
+	}
+	index := make(map[fieldAddr]string, len(plan.fields))
+	for _, f := range plan.fields {
+		// Fields under a nil section are bound to a detached instance and
+		// cannot be read through cfg
+		if f.fieldValue.CanAddr() && !underNilPointer(f) {
+			index[fieldAddr{f.fieldValue.UnsafeAddr(), f.fieldValue.Type()}] = f.path
+		}
+	}
+	return index
+}
+
+// underNilPointer reports whether a struct pointer on the way to f is nil.
+func underNilPointer(f fieldWithTagValue) bool {
+	v := f.root
+	for _, i := range f.index[:len(f.index)-1] {
+		v = v.Field(i)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return true
+			}
+			v = v.Elem()
+		}
+	}
+	return false
+}
+
+// AccessReport returns the reads recorded since EnableAccessAudit or the
+// last ResetAccessAudit; it is empty when auditing is off.
+func (a *AntConfig) AccessReport() AccessReport {
+	report := AccessReport{Reads: map[string]int{}}
+	au := a.audit
+	if au == nil {
+		return report
+	}
+	au.mu.Lock()
+	for path, n := range au.reads {
+		report.Reads[path] = n
+	}
+	au.mu.Unlock()
+	for _, f := range a.Describe().Fields {
+		if !readBelow(report.Reads, f.Path) {
+			report.Unread = append(report.Unread, f.Path)
+		}
+	}
+	sort.Strings(report.Unread)
+	return report
+}
+
+// readBelow reports whether path or a struct enclosing it was read.
+func readBelow(reads map[string]int, path string) bool {
+	for {
+		if reads[path] > 0 {
+			return true
+		}
+		i := strings.LastIndexByte(path, '.')
+		if i < 0 {
+			return false
+		}
+		path = path[:i]
+	}
+}
+
+// ResetAccessAudit forgets the reads recorded so far, e.g. after startup to
+// audit only what request handling reads.
+func (a *AntConfig) ResetAccessAudit() {
+	if au := a.audit; au != nil {
+		au.mu.Lock()
+		au.reads = map[string]int{}
+		au.mu.Unlock()
+	}
+}
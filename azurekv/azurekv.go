@@ -0,0 +1,147 @@
+// Package azurekv resolves azurekv:// value URIs from Azure Key Vault, so any
+// config layer can reference a secret instead of holding it:
+//
+//	ac.RegisterResolver("azurekv", azurekv.New())
+//	// DB_PASSWORD=azurekv://my-vault/db-password
+//	// or pin a version: azurekv://my-vault/db-password/0123456789abcdef
+//
+// Without a version the current one is read. Requests use the Key Vault REST
+// API with the standard library only; by default the access token comes from
+// the managed identity of the host (App Service and Functions through
+// IDENTITY_ENDPOINT, VMs and AKS through the instance metadata service).
+package azurekv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Scheme is the URI scheme handled by Resolver.
+const Scheme = "azurekv"
+
+const (
+	apiVersion    = "7.4"
+	vaultResource = "https://vault.azure.net"
+	imdsToken     = "http://169.254.169.254/metadata/identity/oauth2/token"
+)
+
+// Resolver reads secrets from Key Vault. It implements antconfig.Resolver.
+type Resolver struct {
+	// Client sends the requests; nil means http.DefaultClient.
+	Client *http.Client
+	// Token returns an access token for https://vault.azure.net; nil uses
+	// the managed identity.
+	Token func(ctx context.Context) (string, error)
+	// VaultURL returns the base URL of the named vault; nil means
+	// https://NAME.vault.azure.net. Set it for sovereign clouds or tests.
+	VaultURL func(vault string) string
+}
+
+// New returns a Resolver using the managed identity's credentials.
+func New() *Resolver { return &Resolver{} }
+
+// Resolve returns the value of the secret uri refers to.
+func (r *Resolver) Resolve(ctx context.Context, uri string) (string, error) {
+	vault, secret, version, err := parseURI(uri)
+	if err != nil {
+		return "", err
+	}
+	token, err := r.token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("azurekv: access token: %w", err)
+	}
+	base := "https://" + vault + ".vault.azure.net"
+	if r.VaultURL != nil {
+		base = r.VaultURL(vault)
+	}
+	u := strings.TrimSuffix(base, "/") + "/secrets/" + url.PathEscape(secret)
+	if version != "" {
+		u += "/" + url.PathEscape(version)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u+"?api-version="+apiVersion, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var body struct {
+		Value *string `json:"value"`
+	}
+	if err := r.getJSON(req, &body); err != nil {
+		return "", fmt.Errorf("azurekv: %s/%s: %w", vault, secret, err)
+	}
+	if body.Value == nil {
+		return "", fmt.Errorf("azurekv: %s/%s: response has no value", vault, secret)
+	}
+	return *body.Value, nil
+}
+
+// parseURI splits azurekv://VAULT/NAME[/VERSION].
+func parseURI(uri string) (vault, secret, version string, err error) {
+	rest, ok := strings.CutPrefix(uri, Scheme+"://")
+	if !ok {
+		return "", "", "", fmt.Errorf("azurekv: %q is not a %s:// URI", uri, Scheme)
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", fmt.Errorf("azurekv: %q is not of the form %s://VAULT/NAME[/VERSION]", uri, Scheme)
+	}
+	if len(parts) == 3 {
+		version = parts[2]
+	}
+	return parts[0], parts[1], version, nil
+}
+
+func (r *Resolver) token(ctx context.Context) (string, error) {
+	if r.Token != nil {
+		return r.Token(ctx)
+	}
+	var req *http.Request
+	var err error
+	if endpoint := os.Getenv("IDENTITY_ENDPOINT"); endpoint != "" {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?api-version=2019-08-01&resource="+url.QueryEscape(vaultResource), nil)
+		if err == nil {
+			req.Header.Set("X-IDENTITY-HEADER", os.Getenv("IDENTITY_HEADER"))
+		}
+	} else {
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, imdsToken+"?api-version=2018-02-01&resource="+url.QueryEscape(vaultResource), nil)
+		if err == nil {
+			req.Header.Set("Metadata", "true")
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	var body struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := r.getJSON(req, &body); err != nil {
+		return "", err
+	}
+	return body.AccessToken, nil
+}
+
+func (r *Resolver) getJSON(req *http.Request, v any) error {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,61 @@
+package azurekv
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/robfordww/antconfig"
+)
+
+func TestResolver(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" || r.URL.Query().Get("api-version") == "" {
+			http.Error(w, "unauthenticated", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/vault-a/secrets/db":
+			w.Write([]byte(`{"value": "hunter2", "id": "x"}`))
+		case "/vault-a/secrets/db/v1":
+			w.Write([]byte(`{"value": "old"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	type Config struct {
+		Password string `json:"password" default:"azurekv://vault-a/db"`
+		Old      string `json:"old" env:"AZUREKV_OLD"`
+	}
+	var cfg Config
+	ac := antconfig.New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--"})
+	r := &Resolver{
+		VaultURL: func(vault string) string { return srv.URL + "/" + vault },
+		Token:    func(context.Context) (string, error) { return "tok", nil },
+	}
+	if err := ac.RegisterResolver(Scheme, r); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AZUREKV_OLD", "azurekv://vault-a/db/v1")
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "hunter2" || cfg.Old != "old" {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+
+	t.Setenv("AZUREKV_OLD", "azurekv://vault-a/missing")
+	err := ac.WriteConfigValues()
+	var me *antconfig.MultiError
+	if !errors.As(err, &me) || me.Errors[0].Path != "Old" || me.Errors[0].Source != antconfig.LayerEnv {
+		t.Fatalf("missing secret: %v", err)
+	}
+	if _, err := r.Resolve(context.Background(), "azurekv://only-vault"); err == nil {
+		t.Fatal("URI without a secret name accepted")
+	}
+}
@@ -0,0 +1,93 @@
+package antconfig
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Names of the bootstrap flags picked out by ParseBootstrapFlags, before any
+// SetFlagPrefix prefix.
+const (
+	ConfigFlag  = "config"
+	EnvFileFlag = "env-file"
+)
+
+// ParseBootstrapFlags is the first phase of a two-phase start, for choosing
+// the files to load from the command line. It removes --config PATH and
+// --env-file PATH (also -config, --config=PATH) from args and applies them
+// with SetConfigPath and SetEnvPath; --env-file may be repeated, the files
+// loading in order as with AddEnvPath. The other arguments are returned for
+// the second phase, fs.Parse after BindConfigFlags, SetFlagArgs or
+// ParseAndLoad, which then applies the remaining layers:
+//
+//	rest, err := ac.ParseBootstrapFlags(os.Args[1:])
+//	if err != nil { ... }
+//	args, err := ac.ParseAndLoad(rest)
+//
+// The flags take the SetFlagPrefix prefix and are only recognized before a
+// "--" terminator. A nil args means os.Args[1:]. The first error of those
+// setters, such as ErrConfigNotFound, is returned once every path is applied.
+func (a *AntConfig) ParseBootstrapFlags(args []string) ([]string, error) {
+	if args == nil && len(os.Args) > 0 {
+		args = os.Args[1:]
+	}
+	var configPath string
+	var envPaths []string
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, value, hasValue := splitFlagArg(arg)
+		if name != a.flagPrefix+ConfigFlag && name != a.flagPrefix+EnvFileFlag {
+			rest = append(rest, arg)
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, fmt.Errorf("flag needs an argument: -%s", name)
+			}
+			i++
+			value = args[i]
+		}
+		if name == a.flagPrefix+ConfigFlag {
+			configPath = value
+		} else {
+			envPaths = append(envPaths, value)
+		}
+	}
+	var first error
+	keep := func(err error) {
+		if first == nil {
+			first = err
+		}
+	}
+	if configPath != "" {
+		keep(a.SetConfigPath(configPath))
+	}
+	for i, p := range envPaths {
+		if i == 0 {
+			keep(a.SetEnvPath(p))
+		} else {
+			keep(a.AddEnvPath(p))
+		}
+	}
+	if first != nil {
+		return nil, first
+	}
+	return rest, nil
+}
+
+// splitFlagArg returns the name of a "-name", "--name" or "--name=value"
+// argument and its inline value; name is empty for non-flag arguments.
+func splitFlagArg(arg string) (name, value string, hasValue bool) {
+	if len(arg) < 2 || arg[0] != '-' || arg == "--" {
+		return "", "", false
+	}
+	name = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+	name, value, hasValue = strings.Cut(name, "=")
+	return name, value, hasValue
+}
@@ -0,0 +1,142 @@
+package antconfig
+
+import (
+	"flag"
+	"io/fs"
+)
+
+// Builder is a panic-free counterpart of the fluent Must* style. Each step
+// records the first error instead of panicking; Build reports it. Every Must*
+// helper on AntConfig has a Builder step or an error-returning method.
+//
+//	loaded, err := antconfig.For(&cfg).WithConfigPath("config.jsonc").WithFlagSet(fs).Build()
+type Builder[T any] struct {
+	a   *AntConfig
+	cfg *T
+	err error
+}
+
+// Loaded is the result of a successful Build: the populated config and the
+// AntConfig that produced it (for Provenance, Hash, EnvHelpString, ...).
+type Loaded[T any] struct {
+	Config    *T
+	AntConfig *AntConfig
+	// Report lists the inputs that contributed to the load.
+	Report LoadReport
+}
+
+// For starts a Builder for cfg, the non-panicking equivalent of
+// New().MustSetConfig(cfg).
+func For[T any](cfg *T) *Builder[T] {
+	b := &Builder[T]{a: New(), cfg: cfg}
+	b.err = b.a.SetConfig(cfg)
+	return b
+}
+
+// step runs fn unless an earlier step already failed.
+func (b *Builder[T]) step(fn func() error) *Builder[T] {
+	if b.err == nil {
+		b.err = fn()
+	}
+	return b
+}
+
+// WithEnvPath is the Builder form of SetEnvPath.
+func (b *Builder[T]) WithEnvPath(path string) *Builder[T] {
+	return b.step(func() error { return b.a.SetEnvPath(path) })
+}
+
+// WithAddedEnvPath is the Builder form of AddEnvPath.
+func (b *Builder[T]) WithAddedEnvPath(path string) *Builder[T] {
+	return b.step(func() error { return b.a.AddEnvPath(path) })
+}
+
+// WithFS is the Builder form of SetFS. Place it before WithConfigPath and
+// WithEnvPath so their existence checks use fsys.
+func (b *Builder[T]) WithFS(fsys fs.FS) *Builder[T] {
+	return b.step(func() error { return b.a.SetFS(fsys) })
+}
+
+// WithEmbeddedConfig is the Builder form of SetEmbeddedConfig.
+func (b *Builder[T]) WithEmbeddedConfig(data []byte, format string) *Builder[T] {
+	return b.step(func() error { return b.a.SetEmbeddedConfig(data, format) })
+}
+
+// WithMode is the Builder form of SetMode.
+func (b *Builder[T]) WithMode(m Mode) *Builder[T] {
+	return b.step(func() error { return b.a.SetMode(m) })
+}
+
+// WithConfigPath is the Builder form of SetConfigPath.
+func (b *Builder[T]) WithConfigPath(path string) *Builder[T] {
+	return b.step(func() error { return b.a.SetConfigPath(path) })
+}
+
+// WithConfigPathOptional is the Builder form of SetConfigPathOptional.
+func (b *Builder[T]) WithConfigPathOptional(path string) *Builder[T] {
+	return b.step(func() error { return b.a.SetConfigPathOptional(path) })
+}
+
+// WithDiscovery is the Builder form of SetDiscovery.
+func (b *Builder[T]) WithDiscovery(locators ...Locator) *Builder[T] {
+	return b.step(func() error { return b.a.SetDiscovery(locators...) })
+}
+
+// WithFlagPrefix is the Builder form of SetFlagPrefix.
+func (b *Builder[T]) WithFlagPrefix(prefix string) *Builder[T] {
+	return b.step(func() error { return b.a.SetFlagPrefix(prefix) })
+}
+
+// WithFlagArgs is the Builder form of SetFlagArgs.
+func (b *Builder[T]) WithFlagArgs(args []string) *Builder[T] {
+	b.a.SetFlagArgs(args)
+	return b
+}
+
+// WithFlagSet is the non-panicking equivalent of MustBindConfigFlags. The
+// FlagSet must still be parsed by the caller before Build.
+func (b *Builder[T]) WithFlagSet(fs *flag.FlagSet) *Builder[T] {
+	return b.step(func() error { return b.a.BindConfigFlags(fs) })
+}
+
+// WithAppVersion is the Builder form of SetAppVersion.
+func (b *Builder[T]) WithAppVersion(version string) *Builder[T] {
+	b.a.SetAppVersion(version)
+	return b
+}
+
+// WithDotEnvExport is the Builder form of SetDotEnvExport.
+func (b *Builder[T]) WithDotEnvExport(export bool) *Builder[T] {
+	b.a.SetDotEnvExport(export)
+	return b
+}
+
+// WithCaseInsensitive is the Builder form of SetCaseInsensitive.
+func (b *Builder[T]) WithCaseInsensitive(on bool) *Builder[T] {
+	return b.step(func() error { return b.a.SetCaseInsensitive(on) })
+}
+
+// WithSecretPrompt is the Builder form of SetSecretPrompt.
+func (b *Builder[T]) WithSecretPrompt(prompt func(label string) (string, error)) *Builder[T] {
+	b.a.SetSecretPrompt(prompt)
+	return b
+}
+
+// AntConfig returns the underlying AntConfig, e.g. to register sources.
+func (b *Builder[T]) AntConfig() *AntConfig { return b.a }
+
+// Err returns the first error recorded by a step, if any.
+func (b *Builder[T]) Err() error { return b.err }
+
+// Build is the terminal step: it returns the first recorded error, or runs
+// WriteConfigValues (via Load) and returns the populated config.
+func (b *Builder[T]) Build() (Loaded[T], error) {
+	if b.err != nil {
+		return Loaded[T]{}, b.err
+	}
+	report, err := b.a.Load()
+	if err != nil {
+		return Loaded[T]{}, err
+	}
+	return Loaded[T]{Config: b.cfg, AntConfig: b.a, Report: report}, nil
+}
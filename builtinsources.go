@@ -0,0 +1,265 @@
+package antconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// builtinProviders are the "$sources" types available without
+// RegisterProvider. Unlike registered factories they get the AntConfig, for
+// its file system, formats and environment, and the directory of the
+// declaring config file.
+var builtinProviders = map[string]func(a *AntConfig, dir string, options map[string]any) (Source, error){
+	"file": newFileSource,
+	"http": newHTTPSource,
+}
+
+// fileSource is the built-in "file" provider: another config file, read and
+// converted like the main one (formats, signature, sops), whose values
+// override the declaring file. It lets a small bootstrap file point to the
+// real config:
+//
+//	{"$sources": [{"type": "file", "path": "/etc/myapp/config.json"}]}
+type fileSource struct {
+	a        *AntConfig
+	path     string
+	optional bool
+}
+
+// newFileSource creates a fileSource from the options "path", relative to
+// dir unless absolute, and "optional", which makes a missing file load no
+// values instead of failing.
+func newFileSource(a *AntConfig, dir string, options map[string]any) (Source, error) {
+	p, err := stringOption(options, "path", true)
+	if err != nil {
+		return nil, err
+	}
+	optional, err := boolOption(options, "optional")
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(dir, p)
+	}
+	return &fileSource{a: a, path: p, optional: optional}, nil
+}
+
+func (s *fileSource) Name() string { return "file:" + s.path }
+
+func (s *fileSource) Load(context.Context) (map[string]any, error) {
+	data, err := s.a.files().ReadFile(s.path)
+	if err != nil {
+		if s.optional && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	js, err := s.a.prepareConfig(s.path, data)
+	if err != nil {
+		return nil, err
+	}
+	return s.a.declaredDocument(js)
+}
+
+// httpSource is the built-in "http" provider: a JSON or JSONC document
+// fetched with GET, for config served by a central endpoint:
+//
+//	{"$sources": [{"type": "http", "url": "https://config.internal/myapp.json",
+//	  "headers": {"Authorization": "Bearer ${CONFIG_TOKEN}"}, "timeout": "5s"}]}
+type httpSource struct {
+	a        *AntConfig
+	url      *url.URL
+	headers  map[string]string
+	timeout  time.Duration
+	optional bool
+}
+
+// newHTTPSource creates an httpSource from the options "url" (http or
+// https), "headers" (an object of strings, with ${VAR} expanded from the
+// environment so tokens stay out of the file), "timeout" (a duration) and
+// "optional", which makes a 404 response load no values instead of failing.
+// With RequireSignature the document must verify against the signature
+// served at the URL with ".sig" appended to its path.
+func newHTTPSource(a *AntConfig, _ string, options map[string]any) (Source, error) {
+	raw, err := stringOption(options, "url", true)
+	if err != nil {
+		return nil, err
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("url %q must be an absolute http or https URL", u.Redacted())
+	}
+	s := &httpSource{a: a, url: u}
+	if h, ok := options["headers"]; ok {
+		fields, ok := h.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("headers must be an object, not %s", jsonKind(h))
+		}
+		s.headers = make(map[string]string, len(fields))
+		for k, v := range fields {
+			str, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("header %s must be a string, not %s", k, jsonKind(v))
+			}
+			s.headers[k] = str
+		}
+	}
+	if t, err := stringOption(options, "timeout", false); err != nil {
+		return nil, err
+	} else if t != "" {
+		if s.timeout, err = time.ParseDuration(t); err != nil {
+			return nil, fmt.Errorf("timeout: %w", err)
+		}
+	}
+	if s.optional, err = boolOption(options, "optional"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Name is the URL without its password, which would otherwise end up in
+// provenance and reports.
+func (s *httpSource) Name() string { return s.url.Redacted() }
+
+func (s *httpSource) Load(ctx context.Context) (map[string]any, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+	data, err := s.get(ctx, s.url)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	if s.a.signingKey != nil {
+		// Like a config file's, the signature is a detached document next to
+		// the source: the same URL with ".sig" appended to the path
+		sigURL := *s.url
+		sigURL.Path += ".sig"
+		sigURL.RawPath = ""
+		raw, err := s.get(ctx, &sigURL)
+		if err == nil && raw == nil {
+			err = fmt.Errorf("GET %s: %s", sigURL.Redacted(), http.StatusText(http.StatusNotFound))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrSignatureInvalid, s.Name(), err)
+		}
+		if err := s.a.checkSignature(s.Name(), data, raw); err != nil {
+			return nil, err
+		}
+	}
+	// The URL path's extension selects a RegisterFormat converter, as for
+	// config files
+	js, err := s.a.configToJSON(path.Base(s.url.Path), data)
+	if err != nil {
+		return nil, err
+	}
+	if js, err = s.a.decryptSOPS(js); err != nil {
+		return nil, err
+	}
+	return s.a.declaredDocument(js)
+}
+
+// maxHTTPSourceSize bounds the documents an httpSource reads, so a
+// misbehaving endpoint cannot exhaust memory.
+const maxHTTPSourceSize = 4 << 20
+
+// get fetches u with the source's headers. A 404 response returns nil data
+// and no error when the source is optional.
+func (s *httpSource) get(ctx context.Context, u *url.URL) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	lookup := s.a.osLookup()
+	for k, v := range s.headers {
+		req.Header.Set(k, os.Expand(v, func(name string) string {
+			val, _ := lookup(name)
+			return val
+		}))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound && s.optional {
+		return nil, nil
+	}
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("GET %s: %s", u.Redacted(), resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPSourceSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxHTTPSourceSize {
+		return nil, fmt.Errorf("GET %s: response exceeds %d bytes", u.Redacted(), maxHTTPSourceSize)
+	}
+	return data, nil
+}
+
+// declaredDocument decodes the JSON document of a built-in source into its
+// values, applying its "$when" blocks. Sources declared there are not
+// followed.
+func (a *AntConfig) declaredDocument(js []byte) (map[string]any, error) {
+	js, err := a.applyConditions(js)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]any
+	if err := json.Unmarshal(js, &values); err != nil {
+		return nil, err
+	}
+	if _, ok := values[sourcesKey]; ok {
+		return nil, fmt.Errorf("%s is only read from the config file", sourcesKey)
+	}
+	return values, nil
+}
+
+// stringOption returns the string option name, which must be present when
+// required.
+func stringOption(options map[string]any, name string, required bool) (string, error) {
+	v, ok := options[name]
+	if !ok {
+		if required {
+			return "", fmt.Errorf("needs %q", name)
+		}
+		return "", nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string, not %s", name, jsonKind(v))
+	}
+	if required && s == "" {
+		return "", fmt.Errorf("needs %q", name)
+	}
+	return s, nil
+}
+
+// boolOption returns the boolean option name, false when absent.
+func boolOption(options map[string]any, name string) (bool, error) {
+	v, ok := options[name]
+	if !ok {
+		return false, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s must be a boolean, not %s", name, jsonKind(v))
+	}
+	return b, nil
+}
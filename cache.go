@@ -0,0 +1,127 @@
+package antconfig
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SetSourceCache makes every successful WriteConfigValues save the values of
+// each registered Source to the JSON file at path, replacing it atomically.
+// With AllowStaleOnError, a later process can then start from these values
+// while a remote source is down. The file may hold secrets and is created
+// with mode 0600. An empty path disables the cache.
+func (a *AntConfig) SetSourceCache(path string) {
+	a.sourceCache = path
+}
+
+// AllowStaleOnError makes a source whose Load fails, after any retries
+// (SetRetryPolicy), use its values from the SetSourceCache file when they
+// were saved less than ttl ago; 0 accepts any age. Such a fallback is
+// reported as a WarningStaleSource instead of failing the load.
+func (a *AntConfig) AllowStaleOnError(ttl time.Duration) {
+	a.allowStale = true
+	a.staleTTL = ttl
+}
+
+// sourceCacheFile is the format of the SetSourceCache file.
+type sourceCacheFile struct {
+	Sources map[string]sourceCacheEntry `json:"sources"`
+}
+
+// sourceCacheEntry holds the values of one source.
+type sourceCacheEntry struct {
+	Saved  time.Time      `json:"saved"`
+	Values map[string]any `json:"values"`
+}
+
+// readSourceCache reads the SetSourceCache file; a missing file is empty.
+func (a *AntConfig) readSourceCache() (sourceCacheFile, error) {
+	var c sourceCacheFile
+	data, err := os.ReadFile(a.sourceCache)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, fmt.Errorf("error parsing source cache %s: %w", a.sourceCache, err)
+	}
+	return c, nil
+}
+
+// cachedSource returns the cached values of the named source when
+// AllowStaleOnError accepts them.
+func (a *AntConfig) cachedSource(name string) (map[string]any, bool) {
+	if !a.allowStale || a.sourceCache == "" {
+		return nil, false
+	}
+	c, err := a.readSourceCache()
+	if err != nil {
+		a.logDebug("source cache unreadable", slog.Any("error", err))
+		return nil, false
+	}
+	e, ok := c.Sources[name]
+	if !ok || e.Values == nil {
+		return nil, false
+	}
+	if a.staleTTL > 0 && time.Since(e.Saved) > a.staleTTL {
+		a.logDebug("source cache stale", slog.String("source", name), slog.Duration("ttl", a.staleTTL))
+		return nil, false
+	}
+	return e.Values, true
+}
+
+// saveSourceCache records fresh, the values loaded from each source by name,
+// in the SetSourceCache file, keeping the entries of other sources.
+func (a *AntConfig) saveSourceCache(fresh map[string]map[string]any) error {
+	if a.sourceCache == "" || len(fresh) == 0 {
+		return nil
+	}
+	c, err := a.readSourceCache()
+	if err != nil || c.Sources == nil {
+		// An unreadable cache is replaced
+		c.Sources = map[string]sourceCacheEntry{}
+	}
+	now := time.Now().UTC()
+	for name, values := range fresh {
+		c.Sources[name] = sourceCacheEntry{Saved: now, Values: values}
+	}
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding source cache: %w", err)
+	}
+	return writeFileAtomic(a.sourceCache, data, 0o600)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it over path, so readers see either the old or the new content.
+func writeFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
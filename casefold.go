@@ -0,0 +1,95 @@
+package antconfig
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// SetCaseInsensitive controls whether `env` names and `flag` names match
+// regardless of case. When enabled, a field tagged `env:"API_KEY"` also reads
+// Api_Key from the OS environment or a .env file, and `flag:"port"` also
+// accepts --PORT. An exact match always wins over a case-folded one.
+//
+// Flags are folded when antconfig parses the arguments itself (SetFlagArgs or
+// os.Args). A FlagSet bound with BindConfigFlags is parsed by the flag
+// package, which keeps its exact-name rules.
+func (a *AntConfig) SetCaseInsensitive(on bool) error {
+	if err := a.checkUnlocked("SetCaseInsensitive"); err != nil {
+		return err
+	}
+	a.caseInsensitive = on
+	return nil
+}
+
+// foldEnvLookup wraps lookup so that a key also matches a variable whose name
+// differs only in case. names lists the variables that exist; candidates are
+// tried in sorted order, so the result does not depend on environment order.
+func foldEnvLookup(lookup func(string) (string, bool), names func() []string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		if v, ok := lookup(key); ok {
+			return v, true
+		}
+		for _, name := range foldMatches(key, names()) {
+			if v, ok := lookup(name); ok {
+				return v, true
+			}
+		}
+		return "", false
+	}
+}
+
+// environNames returns the names of the process environment variables.
+func environNames() []string {
+	env := os.Environ()
+	names := make([]string, 0, len(env))
+	for _, kv := range env {
+		if name, _, ok := strings.Cut(kv, "="); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// mapKeys returns the keys of m.
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// foldMatches returns the names equal to key under case folding, excluding
+// key itself, in sorted order.
+func foldMatches(key string, names []string) []string {
+	var out []string
+	for _, name := range names {
+		if name != key && strings.EqualFold(name, key) {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// foldFlagValues makes the parsed values of flags spelled with a different
+// case available under the names the fields expect. Names that already have
+// an exact value are left alone.
+func foldFlagValues(fields []fieldWithTagValue, values map[string]*string, prefix string) {
+	parsed := mapKeys(values)
+	for _, f := range fields {
+		names := []string{f.tagvalue}
+		if prefix != "" {
+			names = append(names, prefix+f.tagvalue)
+		}
+		for _, name := range names {
+			if _, ok := values[name]; ok {
+				continue
+			}
+			if m := foldMatches(name, parsed); len(m) > 0 {
+				values[name] = values[m[0]]
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package antconfig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// LoadInto runs the full WriteConfigValues pipeline into dst, a pointer of
+// the same type as the config registered via SetConfig, leaving the
+// registered struct untouched. Loading each reload into a new value, such as
+// ac.LoadInto(new(Config)), and publishing it with an atomic swap (see
+// Snapshot) avoids torn reads in services that read the config while it is
+// reloaded. Provenance, RemainingArgs and the warnings describe this load
+// afterwards, as they would for WriteConfigValues, and Lookup, Hash,
+// RedactedConfig and the other readers of the loaded config report dst.
+func (a *AntConfig) LoadInto(dst any) error {
+	return a.LoadIntoContext(context.Background(), dst)
+}
+
+// LoadIntoContext is LoadInto with a context; see WriteConfigValuesContext.
+func (a *AntConfig) LoadIntoContext(ctx context.Context, dst any) error {
+	if a.cfgRef == nil {
+		return fmt.Errorf("LoadInto requires SetConfig to be called first")
+	}
+	if reflect.TypeOf(dst) != reflect.TypeOf(a.cfgRef) {
+		return fmt.Errorf("LoadInto: expected %T, got %T", a.cfgRef, dst)
+	}
+	if reflect.ValueOf(dst).IsNil() {
+		return fmt.Errorf("LoadInto: dst is a nil %T", dst)
+	}
+	_, err := a.loadInto(ctx, dst)
+	return err
+}
+
+// CloneEffective loads the effective configuration into a freshly allocated
+// value of the registered type and returns the pointer to it (a *Config for
+// SetConfig(&cfg) with cfg of type Config). Each call produces an independent
+// value; the registered struct is left untouched.
+func (a *AntConfig) CloneEffective() (any, error) {
+	if a.cfgRef == nil {
+		return nil, fmt.Errorf("CloneEffective requires SetConfig to be called first")
+	}
+	dst := reflect.New(reflect.TypeOf(a.cfgRef).Elem()).Interface()
+	if _, err := a.loadInto(context.Background(), dst); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
@@ -0,0 +1,89 @@
+package antconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrConfigDrift is returned by VerifyCluster when one or more peers report a
+// configuration hash that differs from the local one.
+var ErrConfigDrift = errors.New("configuration drift detected")
+
+// ClusterDrift is the result of comparing the local configuration hash with
+// the hashes reported by peers.
+type ClusterDrift struct {
+	// Hash is the local configuration hash.
+	Hash string
+	// Mismatched maps each drifting peer to the hash it reported.
+	Mismatched map[string]string
+}
+
+// Hash returns a hex-encoded SHA-256 digest of the fields antconfig manages in
+// the loaded config struct (the registered one, or the latest LoadInto or
+// Reload value): each field's path and JSON-encoded value, in struct order.
+// `antconfig:"-"` runtime fields are left out and `json:"-"` fields, such as
+// env-only secrets, are included, so nodes with identical effective config
+// produce the same hash. Requires SetConfig to have been called.
+func (a *AntConfig) Hash() (string, error) {
+	if a.cfgRef == nil {
+		return "", fmt.Errorf("Hash requires SetConfig to be called first")
+	}
+	a.checkFrozen()
+	return configHash(a.current())
+}
+
+// configHash returns the Hash of the config cfg.
+func configHash(cfg any) (string, error) {
+	plan, err := newFieldPlan(cfg)
+	if err != nil {
+		return "", fmt.Errorf("error encoding config for hashing: %w", err)
+	}
+	h := sha256.New()
+	for _, f := range plan.fields {
+		data, err := json.Marshal(f.fieldValue.Interface())
+		if err != nil {
+			return "", fmt.Errorf("error encoding config for hashing: %s: %w", f.path, err)
+		}
+		fmt.Fprintf(h, "%s=%s;", f.path, data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// VerifyCluster computes the local configuration hash, hands it to publish
+// (e.g., to write it to a shared registry; may be nil), and compares it with
+// the hashes reported by peers, keyed by peer name. Call it after
+// WriteConfigValues. When any peer differs, the returned ClusterDrift lists
+// them and the error wraps ErrConfigDrift.
+func (a *AntConfig) VerifyCluster(publish func(hash string) error, peers map[string]string) (ClusterDrift, error) {
+	hash, err := a.Hash()
+	if err != nil {
+		return ClusterDrift{}, err
+	}
+	drift := ClusterDrift{Hash: hash}
+	if publish != nil {
+		if err := publish(hash); err != nil {
+			return drift, fmt.Errorf("error publishing config hash: %w", err)
+		}
+	}
+	var names []string
+	for peer, h := range peers {
+		if h == hash {
+			continue
+		}
+		if drift.Mismatched == nil {
+			drift.Mismatched = map[string]string{}
+		}
+		drift.Mismatched[peer] = h
+		names = append(names, peer)
+	}
+	if len(names) == 0 {
+		return drift, nil
+	}
+	sort.Strings(names)
+	return drift, fmt.Errorf("%w: %d of %d peers differ (%s)", ErrConfigDrift, len(names), len(peers), strings.Join(names, ", "))
+}
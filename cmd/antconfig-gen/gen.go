@@ -0,0 +1,523 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// genField is a leaf field of the config struct.
+type genField struct {
+	path string // dotted Go path, e.g. "DB.Host"
+	kind string // basic kind: string, bool, int8, ..., float64 or duration
+	typ  string // Go type as written in the package, e.g. "int" or "Level"
+	tag  reflect.StructTag
+}
+
+// generator collects the package declarations the config struct refers to.
+type generator struct {
+	pkg     string
+	structs map[string]*ast.StructType
+	// named maps local named types to their basic kind ("type Level int").
+	named map[string]string
+	// timeImports are the names under which files import "time".
+	timeImports map[string]bool
+}
+
+// parsePackage reads the non-test Go files of dir.
+func parsePackage(dir string) (*generator, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{structs: map[string]*ast.StructType{}, named: map[string]string{}, timeImports: map[string]bool{}}
+	fset := token.NewFileSet()
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if g.pkg == "" {
+			g.pkg = f.Name.Name
+		}
+		for _, imp := range f.Imports {
+			if imp.Path.Value == `"time"` {
+				name := "time"
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+				g.timeImports[name] = true
+			}
+		}
+		for _, decl := range f.Decls {
+			gd, ok := decl.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gd.Specs {
+				ts := spec.(*ast.TypeSpec)
+				switch t := ts.Type.(type) {
+				case *ast.StructType:
+					g.structs[ts.Name.Name] = t
+				case *ast.Ident:
+					if basicKinds[t.Name] {
+						g.named[ts.Name.Name] = t.Name
+					}
+				case *ast.SelectorExpr:
+					if id, ok := t.X.(*ast.Ident); ok && t.Sel.Name == "Duration" {
+						g.named[ts.Name.Name] = "time:" + id.Name
+					}
+				}
+			}
+		}
+	}
+	if g.pkg == "" {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	return g, nil
+}
+
+var basicKinds = map[string]bool{
+	"string": true, "bool": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"float32": true, "float64": true,
+}
+
+// fields flattens struct typeName into its leaf fields.
+func (g *generator) fields(typeName, prefix string, seen map[string]bool) ([]genField, error) {
+	st, ok := g.structs[typeName]
+	if !ok {
+		return nil, fmt.Errorf("struct type %s not found in package %s", typeName, g.pkg)
+	}
+	if seen[typeName] {
+		return nil, fmt.Errorf("recursive struct type %s", typeName)
+	}
+	seen[typeName] = true
+	defer delete(seen, typeName)
+
+	var out []genField
+	for _, f := range st.Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(s)
+		}
+		if name, _, _ := strings.Cut(tag.Get("antconfig"), ","); name == "-" {
+			continue
+		}
+		names := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			if n.IsExported() {
+				names = append(names, n.Name)
+			}
+		}
+		embedded := len(f.Names) == 0
+		if embedded {
+			id, ok := f.Type.(*ast.Ident)
+			if !ok {
+				return nil, fmt.Errorf("%s: unsupported embedded field", typeName)
+			}
+			names = append(names, id.Name)
+		}
+		for _, name := range names {
+			path := joinPath(prefix, name)
+			typ, kind, nested, err := g.resolve(f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", path, err)
+			}
+			if nested {
+				// Promoted fields of embedded structs are addressed directly
+				subPrefix := path
+				if embedded {
+					subPrefix = prefix
+				}
+				sub, err := g.fields(typ, subPrefix, seen)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, sub...)
+				continue
+			}
+			out = append(out, genField{path: path, kind: kind, typ: typ, tag: tag})
+		}
+	}
+	return out, nil
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// resolve classifies a field type expression.
+func (g *generator) resolve(expr ast.Expr) (typ, kind string, nested bool, err error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if basicKinds[t.Name] {
+			return t.Name, t.Name, false, nil
+		}
+		if _, ok := g.structs[t.Name]; ok {
+			return t.Name, "", true, nil
+		}
+		if k, ok := g.named[t.Name]; ok {
+			if imp, isTime := strings.CutPrefix(k, "time:"); isTime {
+				if !g.timeImports[imp] {
+					break
+				}
+				return t.Name, "duration", false, nil
+			}
+			return t.Name, k, false, nil
+		}
+	case *ast.SelectorExpr:
+		if id, ok := t.X.(*ast.Ident); ok && g.timeImports[id.Name] && t.Sel.Name == "Duration" {
+			// The generated file imports "time" under its own name
+			return "time.Duration", "duration", false, nil
+		}
+	}
+	var b bytes.Buffer
+	_ = format.Node(&b, token.NewFileSet(), expr)
+	return "", "", false, fmt.Errorf("unsupported type %s (antconfig-gen handles strings, bools, numbers, time.Duration, named basic types and nested structs)", b.String())
+}
+
+// viewMethod is a getter of a generated read-only view.
+type viewMethod struct {
+	name  string
+	path  string // Go path relative to the viewed struct, through embedded structs
+	typ   string
+	view  bool // typ is a struct; the getter returns its view
+	depth int  // embedding depth, to apply Go's promotion rules
+}
+
+// viewMethods lists the getters of typeName's view: one per exported field,
+// with the fields of embedded structs promoted as the Go compiler does.
+func (g *generator) viewMethods(typeName, prefix string, depth int, seen map[string]bool) ([]viewMethod, error) {
+	st, ok := g.structs[typeName]
+	if !ok {
+		return nil, fmt.Errorf("struct type %s not found in package %s", typeName, g.pkg)
+	}
+	if seen[typeName] {
+		return nil, fmt.Errorf("recursive struct type %s", typeName)
+	}
+	seen[typeName] = true
+	defer delete(seen, typeName)
+
+	var out []viewMethod
+	for _, f := range st.Fields.List {
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			if name, _, _ := strings.Cut(reflect.StructTag(s).Get("antconfig"), ","); name == "-" {
+				continue
+			}
+		}
+		if len(f.Names) == 0 {
+			// fields has already rejected embedded types other than local structs
+			name := f.Type.(*ast.Ident).Name
+			sub, err := g.viewMethods(name, joinPath(prefix, name), depth+1, seen)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, sub...)
+			continue
+		}
+		for _, n := range f.Names {
+			if !n.IsExported() {
+				continue
+			}
+			path := joinPath(prefix, n.Name)
+			typ, _, nested, err := g.resolve(f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", path, err)
+			}
+			out = append(out, viewMethod{name: n.Name, path: path, typ: typ, view: nested, depth: depth})
+		}
+	}
+	if depth > 0 {
+		return out, nil
+	}
+	// A name promoted from several structs at the same depth is ambiguous and
+	// left out, like the compiler does; shallower fields shadow deeper ones.
+	minDepth, count := map[string]int{}, map[string]int{}
+	for _, m := range out {
+		if d, ok := minDepth[m.name]; !ok || m.depth < d {
+			minDepth[m.name], count[m.name] = m.depth, 0
+		}
+		if m.depth == minDepth[m.name] {
+			count[m.name]++
+		}
+	}
+	kept := out[:0]
+	for _, m := range out {
+		if m.depth == minDepth[m.name] && count[m.name] == 1 {
+			kept = append(kept, m)
+		}
+	}
+	return kept, nil
+}
+
+// generateViews renders a read-only view interface for typeName and for every
+// struct type reachable from it.
+func (g *generator) generateViews(p func(format string, a ...any), typeName string) error {
+	queue, done := []string{typeName}, map[string]bool{typeName: true}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		methods, err := g.viewMethods(name, "", 0, map[string]bool{})
+		if err != nil {
+			return err
+		}
+		impl := strings.ToLower(name[:1]) + name[1:] + "View"
+		p("\n// %sView gives read-only access to a %s.\n", name, name)
+		p("type %sView interface {\n", name)
+		for _, m := range methods {
+			if m.view {
+				p("\t%s() %sView\n", m.name, m.typ)
+				if !done[m.typ] {
+					done[m.typ] = true
+					queue = append(queue, m.typ)
+				}
+			} else {
+				p("\t%s() %s\n", m.name, m.typ)
+			}
+		}
+		p("}\n\n")
+		p("// New%sView returns a view of the current values of c.\n", name)
+		p("func New%sView(c *%s) %sView { return %s{c} }\n\n", name, name, name, impl)
+		p("type %s struct{ c *%s }\n\n", impl, name)
+		for _, m := range methods {
+			if m.view {
+				p("func (v %s) %s() %sView { return New%sView(&v.c.%s) }\n", impl, m.name, m.typ, m.typ, m.path)
+			} else {
+				p("func (v %s) %s() %s { return v.c.%s }\n", impl, m.name, m.typ, m.path)
+			}
+		}
+	}
+	return nil
+}
+
+// generate renders the Apply/Bind functions for typeName, and its view types
+// when views is set.
+func (g *generator) generate(typeName, flagPrefix string, views bool, args []string) ([]byte, error) {
+	fields, err := g.fields(typeName, "", map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	p := func(format string, a ...any) { fmt.Fprintf(&b, format, a...) }
+
+	// Defaults are validated now and emitted as literals
+	p("// Apply%sDefaults sets the fields of c that have a `default` tag.\n", typeName)
+	p("func Apply%sDefaults(c *%s) {\n", typeName, typeName)
+	for _, f := range fields {
+		def, ok := f.tag.Lookup("default")
+		if !ok || def == "" {
+			continue
+		}
+		if strings.Contains(strings.ReplaceAll(def, "$$", ""), "$(") {
+			return nil, fmt.Errorf("field %s: computed default %q is not supported; set it in code after Apply%sDefaults", f.path, def, typeName)
+		}
+		def = strings.ReplaceAll(def, "$$", "$")
+		lit, err := literal(f, def)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: default value %q: %w", f.path, def, err)
+		}
+		p("\tc.%s = %s\n", f.path, lit)
+	}
+	p("}\n\n")
+
+	p("// Apply%sEnv sets the fields of c that have an `env` tag from the non-empty\n", typeName)
+	p("// variables found by lookup (e.g. os.LookupEnv). It reports every value that\n")
+	p("// fails to parse.\n")
+	p("func Apply%sEnv(c *%s, lookup func(string) (string, bool)) error {\n", typeName, typeName)
+	p("\tvar errs []error\n")
+	for _, f := range fields {
+		name := f.tag.Get("env")
+		if name == "" {
+			continue
+		}
+		p("\tif s, ok := lookup(%q); ok && s != \"\" {\n", name)
+		if f.kind == "string" {
+			p("\t\tc.%s = %s\n", f.path, convert(f, "s"))
+		} else {
+			p("\t\tif v, err := %s; err != nil {\n", parseExpr(f, "s"))
+			p("\t\t\terrs = append(errs, fmt.Errorf(\"%s: env var %s: %%w\", err))\n", f.path, name)
+			p("\t\t} else {\n\t\t\tc.%s = %s\n\t\t}\n", f.path, convert(f, "v"))
+		}
+		p("\t}\n")
+	}
+	p("\treturn errors.Join(errs...)\n}\n\n")
+
+	p("// Bind%sFlags registers the fields of c that have a `flag` tag on fs, using\n", typeName)
+	p("// their current values as flag defaults; apply defaults and env first.\n")
+	p("func Bind%sFlags(fs *flag.FlagSet, c *%s) {\n", typeName, typeName)
+	for _, f := range fields {
+		name := f.tag.Get("flag")
+		if name == "" {
+			continue
+		}
+		cli := strconv.Quote(flagPrefix + name)
+		usage := strconv.Quote(f.tag.Get("desc"))
+		if fn, ok := flagFuncs[f.kind]; ok && f.typ == goType(f.kind) {
+			p("\tfs.%s(&c.%s, %s, c.%s, %s)\n", fn, f.path, cli, f.path, usage)
+			continue
+		}
+		// Named bools stay boolean flags, so --name alone sets them
+		fn := "Func"
+		if f.kind == "bool" {
+			fn = "BoolFunc"
+		}
+		p("\tfs.%s(%s, %s, func(s string) error {\n", fn, cli, usage)
+		if f.kind == "string" {
+			p("\t\tc.%s = %s\n\t\treturn nil\n\t})\n", f.path, convert(f, "s"))
+			continue
+		}
+		p("\t\tv, err := %s\n\t\tif err != nil {\n\t\t\treturn err\n\t\t}\n", parseExpr(f, "s"))
+		p("\t\tc.%s = %s\n\t\treturn nil\n\t})\n", f.path, convert(f, "v"))
+	}
+	p("}\n")
+	if views {
+		if err := g.generateViews(p, typeName); err != nil {
+			return nil, err
+		}
+	}
+
+	body := b.String()
+	var imports []string
+	for _, pkg := range []string{"errors", "flag", "fmt", "strconv", "time"} {
+		if strings.Contains(body, pkg+".") {
+			imports = append(imports, strconv.Quote(pkg))
+		}
+	}
+	sort.Strings(imports)
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by antconfig-gen %s; DO NOT EDIT.\n\n", strings.Join(args, " "))
+	fmt.Fprintf(&src, "package %s\n\nimport (\n\t%s\n)\n\n", g.pkg, strings.Join(imports, "\n\t"))
+	src.WriteString(body)
+	out, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w\n%s", err, src.Bytes())
+	}
+	return out, nil
+}
+
+// flagFuncs are the typed FlagSet methods usable for unnamed field types.
+var flagFuncs = map[string]string{
+	"string":   "StringVar",
+	"bool":     "BoolVar",
+	"int":      "IntVar",
+	"int64":    "Int64Var",
+	"uint":     "UintVar",
+	"uint64":   "Uint64Var",
+	"float64":  "Float64Var",
+	"duration": "DurationVar",
+}
+
+// goType is the unnamed Go type of a kind.
+func goType(kind string) string {
+	if kind == "duration" {
+		return "time.Duration"
+	}
+	return kind
+}
+
+// bits returns the bit size suffix of a numeric kind ("0" for int and uint).
+func bits(kind string) string {
+	n := strings.TrimLeft(kind, "abcdefghijklmnopqrstuvwxyz")
+	if n == "" {
+		return "0"
+	}
+	return n
+}
+
+// parseExpr is the expression parsing string variable s for f's kind.
+func parseExpr(f genField, s string) string {
+	switch {
+	case f.kind == "bool":
+		return "strconv.ParseBool(" + s + ")"
+	case f.kind == "duration":
+		return "time.ParseDuration(" + s + ")"
+	case strings.HasPrefix(f.kind, "uint"):
+		return "strconv.ParseUint(" + s + ", 10, " + bits(f.kind) + ")"
+	case strings.HasPrefix(f.kind, "int"):
+		return "strconv.ParseInt(" + s + ", 10, " + bits(f.kind) + ")"
+	default: // float32, float64
+		return "strconv.ParseFloat(" + s + ", " + bits(f.kind) + ")"
+	}
+}
+
+// convert converts the parsed value v to f's type.
+func convert(f genField, v string) string {
+	switch {
+	case f.typ == "bool" || f.typ == "string" || f.typ == "int64" || f.typ == "uint64" || f.typ == "float64":
+		return v
+	case f.kind == "duration" && strings.HasSuffix(f.typ, ".Duration"):
+		return v
+	}
+	return f.typ + "(" + v + ")"
+}
+
+// literal renders a default value as a Go constant of f's type.
+func literal(f genField, def string) (string, error) {
+	var lit string
+	switch {
+	case f.kind == "string":
+		lit = strconv.Quote(def)
+	case f.kind == "bool":
+		v, err := strconv.ParseBool(def)
+		if err != nil {
+			return "", err
+		}
+		lit = strconv.FormatBool(v)
+	case f.kind == "duration":
+		d, err := time.ParseDuration(def)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s(%d) // %s", f.typ, int64(d), def), nil
+	case strings.HasPrefix(f.kind, "uint"):
+		n, _ := strconv.Atoi(bits(f.kind))
+		v, err := strconv.ParseUint(def, 10, n)
+		if err != nil {
+			return "", err
+		}
+		lit = strconv.FormatUint(v, 10)
+	case strings.HasPrefix(f.kind, "int"):
+		n, _ := strconv.Atoi(bits(f.kind))
+		v, err := strconv.ParseInt(def, 10, n)
+		if err != nil {
+			return "", err
+		}
+		lit = strconv.FormatInt(v, 10)
+	default:
+		n, _ := strconv.Atoi(bits(f.kind))
+		v, err := strconv.ParseFloat(def, n)
+		if err != nil {
+			return "", err
+		}
+		lit = strconv.FormatFloat(v, 'g', -1, n)
+	}
+	if f.typ != f.kind {
+		return f.typ + "(" + lit + ")", nil
+	}
+	return lit, nil
+}
@@ -0,0 +1,37 @@
+// Package example is a config struct used to exercise antconfig-gen; its
+// generated loader is checked in and compared against fresh output by the
+// generator's tests.
+package example
+
+import "time"
+
+//go:generate go run github.com/robfordww/antconfig/cmd/antconfig-gen -type Config -prefix app- -views
+
+// Level is a named basic type.
+type Level int
+
+// Switch is a named bool.
+type Switch bool
+
+// Common is embedded; its fields are promoted.
+type Common struct {
+	Verbose bool `default:"false" env:"EXAMPLE_VERBOSE" flag:"verbose" desc:"verbose output"`
+}
+
+// Database is a nested section.
+type Database struct {
+	Host    string        `json:"host" default:"localhost" env:"EXAMPLE_DB_HOST" flag:"db-host" desc:"database host"`
+	Port    uint16        `json:"port" default:"5432" env:"EXAMPLE_DB_PORT" flag:"db-port"`
+	Timeout time.Duration `json:"timeout" default:"2s" env:"EXAMPLE_DB_TIMEOUT" flag:"db-timeout"`
+}
+
+// Config is the generator input.
+type Config struct {
+	Common
+	Name     string   `json:"name" default:"svc" env:"EXAMPLE_NAME"`
+	Workers  int      `json:"workers" default:"4" env:"EXAMPLE_WORKERS" flag:"workers" desc:"worker count"`
+	Ratio    float32  `json:"ratio" default:"0.5" flag:"ratio"`
+	Level    Level    `json:"level" default:"2" env:"EXAMPLE_LEVEL" flag:"level"`
+	Debug    Switch   `json:"debug" flag:"debug"`
+	Database Database `json:"database"`
+}
@@ -0,0 +1,153 @@
+// Code generated by antconfig-gen -type Config -prefix app- -views; DO NOT EDIT.
+
+package example
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ApplyConfigDefaults sets the fields of c that have a `default` tag.
+func ApplyConfigDefaults(c *Config) {
+	c.Verbose = false
+	c.Name = "svc"
+	c.Workers = 4
+	c.Ratio = 0.5
+	c.Level = Level(2)
+	c.Database.Host = "localhost"
+	c.Database.Port = 5432
+	c.Database.Timeout = time.Duration(2000000000) // 2s
+}
+
+// ApplyConfigEnv sets the fields of c that have an `env` tag from the non-empty
+// variables found by lookup (e.g. os.LookupEnv). It reports every value that
+// fails to parse.
+func ApplyConfigEnv(c *Config, lookup func(string) (string, bool)) error {
+	var errs []error
+	if s, ok := lookup("EXAMPLE_VERBOSE"); ok && s != "" {
+		if v, err := strconv.ParseBool(s); err != nil {
+			errs = append(errs, fmt.Errorf("Verbose: env var EXAMPLE_VERBOSE: %w", err))
+		} else {
+			c.Verbose = v
+		}
+	}
+	if s, ok := lookup("EXAMPLE_NAME"); ok && s != "" {
+		c.Name = s
+	}
+	if s, ok := lookup("EXAMPLE_WORKERS"); ok && s != "" {
+		if v, err := strconv.ParseInt(s, 10, 0); err != nil {
+			errs = append(errs, fmt.Errorf("Workers: env var EXAMPLE_WORKERS: %w", err))
+		} else {
+			c.Workers = int(v)
+		}
+	}
+	if s, ok := lookup("EXAMPLE_LEVEL"); ok && s != "" {
+		if v, err := strconv.ParseInt(s, 10, 0); err != nil {
+			errs = append(errs, fmt.Errorf("Level: env var EXAMPLE_LEVEL: %w", err))
+		} else {
+			c.Level = Level(v)
+		}
+	}
+	if s, ok := lookup("EXAMPLE_DB_HOST"); ok && s != "" {
+		c.Database.Host = s
+	}
+	if s, ok := lookup("EXAMPLE_DB_PORT"); ok && s != "" {
+		if v, err := strconv.ParseUint(s, 10, 16); err != nil {
+			errs = append(errs, fmt.Errorf("Database.Port: env var EXAMPLE_DB_PORT: %w", err))
+		} else {
+			c.Database.Port = uint16(v)
+		}
+	}
+	if s, ok := lookup("EXAMPLE_DB_TIMEOUT"); ok && s != "" {
+		if v, err := time.ParseDuration(s); err != nil {
+			errs = append(errs, fmt.Errorf("Database.Timeout: env var EXAMPLE_DB_TIMEOUT: %w", err))
+		} else {
+			c.Database.Timeout = v
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BindConfigFlags registers the fields of c that have a `flag` tag on fs, using
+// their current values as flag defaults; apply defaults and env first.
+func BindConfigFlags(fs *flag.FlagSet, c *Config) {
+	fs.BoolVar(&c.Verbose, "app-verbose", c.Verbose, "verbose output")
+	fs.IntVar(&c.Workers, "app-workers", c.Workers, "worker count")
+	fs.Func("app-ratio", "", func(s string) error {
+		v, err := strconv.ParseFloat(s, 32)
+		if err != nil {
+			return err
+		}
+		c.Ratio = float32(v)
+		return nil
+	})
+	fs.Func("app-level", "", func(s string) error {
+		v, err := strconv.ParseInt(s, 10, 0)
+		if err != nil {
+			return err
+		}
+		c.Level = Level(v)
+		return nil
+	})
+	fs.BoolFunc("app-debug", "", func(s string) error {
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		c.Debug = Switch(v)
+		return nil
+	})
+	fs.StringVar(&c.Database.Host, "app-db-host", c.Database.Host, "database host")
+	fs.Func("app-db-port", "", func(s string) error {
+		v, err := strconv.ParseUint(s, 10, 16)
+		if err != nil {
+			return err
+		}
+		c.Database.Port = uint16(v)
+		return nil
+	})
+	fs.DurationVar(&c.Database.Timeout, "app-db-timeout", c.Database.Timeout, "")
+}
+
+// ConfigView gives read-only access to a Config.
+type ConfigView interface {
+	Verbose() bool
+	Name() string
+	Workers() int
+	Ratio() float32
+	Level() Level
+	Debug() Switch
+	Database() DatabaseView
+}
+
+// NewConfigView returns a view of the current values of c.
+func NewConfigView(c *Config) ConfigView { return configView{c} }
+
+type configView struct{ c *Config }
+
+func (v configView) Verbose() bool          { return v.c.Common.Verbose }
+func (v configView) Name() string           { return v.c.Name }
+func (v configView) Workers() int           { return v.c.Workers }
+func (v configView) Ratio() float32         { return v.c.Ratio }
+func (v configView) Level() Level           { return v.c.Level }
+func (v configView) Debug() Switch          { return v.c.Debug }
+func (v configView) Database() DatabaseView { return NewDatabaseView(&v.c.Database) }
+
+// DatabaseView gives read-only access to a Database.
+type DatabaseView interface {
+	Host() string
+	Port() uint16
+	Timeout() time.Duration
+}
+
+// NewDatabaseView returns a view of the current values of c.
+func NewDatabaseView(c *Database) DatabaseView { return databaseView{c} }
+
+type databaseView struct{ c *Database }
+
+func (v databaseView) Host() string           { return v.c.Host }
+func (v databaseView) Port() uint16           { return v.c.Port }
+func (v databaseView) Timeout() time.Duration { return v.c.Timeout }
@@ -0,0 +1,61 @@
+package example
+
+import (
+	"flag"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGeneratedLoader(t *testing.T) {
+	env := map[string]string{
+		"EXAMPLE_DB_HOST": "db.internal",
+		"EXAMPLE_LEVEL":   "3",
+		"EXAMPLE_WORKERS": "many",
+		"EXAMPLE_DB_PORT": "70000",
+	}
+	lookup := func(k string) (string, bool) { v, ok := env[k]; return v, ok }
+
+	var c Config
+	ApplyConfigDefaults(&c)
+	err := ApplyConfigEnv(&c, lookup)
+	if err == nil || !strings.Contains(err.Error(), "Workers: env var EXAMPLE_WORKERS") || !strings.Contains(err.Error(), "Database.Port") {
+		t.Fatalf("expected errors for Workers and Database.Port, got %v", err)
+	}
+
+	fs := flag.NewFlagSet("example", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	BindConfigFlags(fs, &c)
+	if err := fs.Parse([]string{"--app-workers", "8", "--app-db-timeout=5s", "--app-ratio", "0.25", "--app-verbose", "--app-debug"}); err != nil {
+		t.Fatal(err)
+	}
+	want := Config{
+		Common:   Common{Verbose: true},
+		Name:     "svc",
+		Workers:  8,
+		Ratio:    0.25,
+		Level:    3,
+		Debug:    true,
+		Database: Database{Host: "db.internal", Port: 5432, Timeout: 5 * time.Second},
+	}
+	if c != want {
+		t.Fatalf("got %+v, want %+v", c, want)
+	}
+	if err := fs.Parse([]string{"--app-level", "high"}); err == nil {
+		t.Fatal("expected flag parse error")
+	}
+}
+
+func TestGeneratedView(t *testing.T) {
+	var c Config
+	ApplyConfigDefaults(&c)
+	view := NewConfigView(&c)
+	if view.Name() != "svc" || view.Database().Port() != 5432 || view.Verbose() {
+		t.Fatalf("unexpected view values: %v %v %v", view.Name(), view.Database().Port(), view.Verbose())
+	}
+	c.Database.Host = "db2"
+	if got := view.Database().Host(); got != "db2" {
+		t.Fatalf("view does not reflect the current config: %q", got)
+	}
+}
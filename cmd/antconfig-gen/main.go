@@ -0,0 +1,75 @@
+// Command antconfig-gen generates reflection-free loaders for an antconfig
+// config struct, for performance-sensitive programs and TinyGo or wasm
+// builds where reflect-heavy code is costly. Add a directive next to the
+// struct and run go generate:
+//
+//	//go:generate go run github.com/robfordww/antconfig/cmd/antconfig-gen -type Config
+//
+// For type Config it writes config_antconfig.go with:
+//
+//	func ApplyConfigDefaults(c *Config)
+//	func ApplyConfigEnv(c *Config, lookup func(string) (string, bool)) error
+//	func BindConfigFlags(fs *flag.FlagSet, c *Config)
+//
+// driven by the same `default`, `env`, `flag` and `desc` tags antconfig
+// reads. Call them in precedence order: defaults, then (optionally)
+// json.Unmarshal of the config file, then env, then bind and parse flags.
+// Malformed defaults are reported at generation time. Supported field types
+// are strings, bools, integers, floats, time.Duration, named types based on
+// them, and nested or embedded structs declared in the same package.
+//
+// With -views it also writes a read-only ConfigView interface, with one
+// getter per field, for Config and each struct nested in it; NewConfigView(c)
+// wraps a *Config so libraries can read it but not modify it.
+//
+// Flags:
+//
+//	-type name    struct type to generate for (required)
+//	-prefix p     prefix for every flag name, like SetFlagPrefix
+//	-views        also generate read-only view interfaces
+//	-output file  output file (default <type>_antconfig.go, lower-cased)
+//	-dir dir      package directory (default ".")
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "antconfig-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("antconfig-gen", flag.ContinueOnError)
+	typeName := fs.String("type", "", "struct type to generate for")
+	prefix := fs.String("prefix", "", "prefix for every flag name")
+	views := fs.Bool("views", false, "also generate read-only view interfaces")
+	output := fs.String("output", "", "output file (default <type>_antconfig.go)")
+	dir := fs.String("dir", ".", "package directory")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *typeName == "" {
+		return fmt.Errorf("-type is required")
+	}
+	g, err := parsePackage(*dir)
+	if err != nil {
+		return err
+	}
+	src, err := g.generate(*typeName, *prefix, *views, args)
+	if err != nil {
+		return err
+	}
+	out := *output
+	if out == "" {
+		out = filepath.Join(*dir, strings.ToLower(*typeName)+"_antconfig.go")
+	}
+	return os.WriteFile(out, src, 0o644)
+}
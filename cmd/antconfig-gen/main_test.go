@@ -0,0 +1,100 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedExampleUpToDate(t *testing.T) {
+	g, err := parsePackage(filepath.Join("internal", "example"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := g.generate("Config", "app-", true, []string{"-type", "Config", "-prefix", "app-", "-views"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile(filepath.Join("internal", "example", "config_antconfig.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("internal/example/config_antconfig.go is stale; run go generate ./...\n%s", got)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	for name, src := range map[string]string{
+		"bad default": "type Config struct {\n\tPort int `default:\"http\"`\n}\n",
+		"unsupported": "type Config struct {\n\tTags []string `env:\"TAGS\"`\n}\n",
+		"missing":     "type Other struct{}\n",
+		"computed":    "type Config struct {\n\tHost string `default:\"$(hostname)\"`\n}\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "cfg.go"), []byte("package cfg\n\n"+src), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := run([]string{"-type", "Config", "-dir", dir})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			want := map[string]string{
+				"bad default": `field Port: default value "http"`,
+				"unsupported": "field Tags: unsupported type []string",
+				"missing":     "struct type Config not found",
+				"computed":    `field Host: computed default "$(hostname)" is not supported`,
+			}[name]
+			if !strings.Contains(err.Error(), want) {
+				t.Fatalf("error %q does not contain %q", err, want)
+			}
+		})
+	}
+}
+
+func TestGenerateSkipsIgnoredFields(t *testing.T) {
+	dir := t.TempDir()
+	src := "package cfg\n\ntype Config struct {\n\tPort  int      `env:\"PORT\"`\n\tCache []string `antconfig:\"-\"`\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "cfg.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"-type", "Config", "-dir", dir}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "config_antconfig.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(out), "c.Port") || strings.Contains(string(out), "Cache") {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+}
+
+func TestGenerateViewsPromotion(t *testing.T) {
+	dir := t.TempDir()
+	src := "package cfg\n\n" +
+		"type A struct {\n\tName string\n\tID   int\n}\n\n" +
+		"type B struct {\n\tName string\n\tTag  string\n}\n\n" +
+		"type Config struct {\n\tA\n\tB\n\tID    string\n\tcache []string\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "cfg.go"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := run([]string{"-type", "Config", "-dir", dir, "-views"}); err != nil {
+		t.Fatal(err)
+	}
+	out, err := os.ReadFile(filepath.Join(dir, "config_antconfig.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Name is ambiguous between A and B; Config.ID shadows A.ID
+	for _, want := range []string{"ID() string", "Tag() string", "return v.c.B.Tag"} {
+		if !strings.Contains(string(out), want) {
+			t.Fatalf("output lacks %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(string(out), "Name()") || strings.Contains(string(out), "ID() int") {
+		t.Fatalf("promotion rules not applied:\n%s", out)
+	}
+}
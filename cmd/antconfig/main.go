@@ -0,0 +1,369 @@
+// Command antconfig checks and transforms antconfig config files, e.g. in CI
+// pipelines. Commands that need the shape of the application's config read a
+// descriptor file produced by (*antconfig.AntConfig).WriteDescriptor, so the
+// application's Go types are not needed.
+//
+// Usage:
+//
+//	antconfig validate -schema config.schema.json [-strict] config.jsonc
+//	antconfig effective -schema config.schema.json [-env .env.prod] [-sources] config.jsonc
+//	antconfig diff old.jsonc new.jsonc
+//	antconfig convert [-to json|jsonc] [-o out.json] config.jsonc
+//
+// validate loads the file the way the application would (defaults, file,
+// the -env files, environment; nothing is auto-discovered) and reports every bad value, missing required setting
+// and, with -strict, every warning such as unknown keys. effective prints the
+// resolved config as JSON with secrets redacted. diff compares two files key
+// by key. convert strips comments and trailing commas and re-indents; its
+// jsonc output is the same indented JSON, since strict JSON is valid JSONC.
+// Exit status is 0 on success, 1 when validation fails or files differ, and 2
+// on usage errors.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/robfordww/antconfig"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// errFailed signals a failed check; details have already been printed.
+var errFailed = errors.New("failed")
+
+// usageError is a command line mistake, reported with exit status 2.
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "usage: antconfig <validate|effective|diff|convert> [flags] files...")
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "validate":
+		err = cmdValidate(args[1:], stdout, stderr)
+	case "effective":
+		err = cmdEffective(args[1:], stdout, stderr)
+	case "diff":
+		err = cmdDiff(args[1:], stdout, stderr)
+	case "convert":
+		err = cmdConvert(args[1:], stdout, stderr)
+	default:
+		err = usageError{fmt.Sprintf("unknown command %q", args[0])}
+	}
+	var ue usageError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &ue):
+		fmt.Fprintln(stderr, "antconfig:", err)
+		return 2
+	case errors.Is(err, errFailed):
+		return 1
+	default:
+		fmt.Fprintln(stderr, "antconfig:", err)
+		return 1
+	}
+}
+
+// newFlagSet returns a FlagSet for a subcommand that reports through stderr.
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet("antconfig "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+// parseArgs parses a subcommand's flags and requires exactly n operands.
+func parseArgs(fs *flag.FlagSet, args []string, n int, operands string) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil, err
+		}
+		return nil, usageError{err.Error()}
+	}
+	if fs.NArg() != n {
+		return nil, usageError{fmt.Sprintf("%s expects %s", fs.Name(), operands)}
+	}
+	return fs.Args(), nil
+}
+
+// loadResult is a config loaded through a descriptor.
+type loadResult struct {
+	ac       *antconfig.AntConfig
+	cfg      reflect.Value
+	desc     antconfig.Descriptor
+	warnings []antconfig.Warning
+}
+
+// loadWithSchema loads file (and envFiles) into a struct rebuilt from the
+// descriptor at schema, as the application would.
+func loadWithSchema(schema, file string, envFiles []string) (*loadResult, error) {
+	if schema == "" {
+		return nil, usageError{"-schema is required"}
+	}
+	desc, err := loadDescriptor(schema)
+	if err != nil {
+		return nil, err
+	}
+	t, err := structType(desc)
+	if err != nil {
+		return nil, fmt.Errorf("descriptor %s: %w", schema, err)
+	}
+	res := &loadResult{cfg: reflect.New(t), desc: desc}
+	ac := antconfig.New()
+	res.ac = ac
+	ac.SetDotEnvExport(false)
+	// Only the files named on the command line are read, never a config or
+	// .env discovered around the working directory
+	if err := ac.DisableAutoDiscovery(); err != nil {
+		return res, err
+	}
+	if len(envFiles) == 0 {
+		if err := ac.DisableDotEnv(); err != nil {
+			return res, err
+		}
+	}
+	ac.OnWarning(func(w antconfig.Warning) { res.warnings = append(res.warnings, w) })
+	if err := ac.SetConfig(res.cfg.Interface()); err != nil {
+		return res, err
+	}
+	if err := ac.SetConfigPath(file); err != nil {
+		return res, err
+	}
+	for _, p := range envFiles {
+		if err := ac.AddEnvPath(p); err != nil {
+			return res, err
+		}
+	}
+	// Bind an empty FlagSet so our own arguments are not read as overrides
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	if err := ac.BindConfigFlags(fs); err != nil {
+		return res, err
+	}
+	if err := fs.Parse(nil); err != nil {
+		return res, err
+	}
+	return res, ac.WriteConfigValues()
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(s string) error { *l = append(*l, s); return nil }
+
+func cmdValidate(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("validate", stderr)
+	schema := fs.String("schema", "", "descriptor `file` written by WriteDescriptor")
+	strict := fs.Bool("strict", false, "treat warnings (unknown keys, deprecated names) as failures")
+	var envFiles stringList
+	fs.Var(&envFiles, "env", "`.env` file to load (repeatable)")
+	operands, err := parseArgs(fs, args, 1, "one config file")
+	if err != nil {
+		return err
+	}
+	res, err := loadWithSchema(*schema, operands[0], envFiles)
+	var ue usageError
+	if res == nil || errors.As(err, &ue) {
+		return err
+	}
+	failed := false
+	if err != nil {
+		var me *antconfig.MultiError
+		if errors.As(err, &me) {
+			for _, fe := range me.Errors {
+				if fe.Source != "" {
+					fmt.Fprintf(stdout, "error: %s (from %s): %v\n", fe.Path, fe.Source, fe.Err)
+				} else {
+					fmt.Fprintf(stdout, "error: %s: %v\n", fe.Path, fe.Err)
+				}
+			}
+		} else {
+			fmt.Fprintf(stdout, "error: %v\n", err)
+		}
+		failed = true
+	}
+	for _, w := range res.warnings {
+		fmt.Fprintf(stdout, "warning: %s\n", w)
+	}
+	if failed || (*strict && len(res.warnings) > 0) {
+		return errFailed
+	}
+	fmt.Fprintf(stdout, "%s: ok\n", operands[0])
+	return nil
+}
+
+func cmdEffective(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("effective", stderr)
+	schema := fs.String("schema", "", "descriptor `file` written by WriteDescriptor")
+	sources := fs.Bool("sources", false, "print the layer that set each field instead of the config")
+	var envFiles stringList
+	fs.Var(&envFiles, "env", "`.env` file to load (repeatable)")
+	operands, err := parseArgs(fs, args, 1, "one config file")
+	if err != nil {
+		return err
+	}
+	res, err := loadWithSchema(*schema, operands[0], envFiles)
+	if err != nil {
+		return err
+	}
+	if *sources {
+		prov := res.ac.Provenance()
+		paths := make([]string, 0, len(prov))
+		for p := range prov {
+			paths = append(paths, p)
+		}
+		sort.Strings(paths)
+		for _, p := range paths {
+			fmt.Fprintf(stdout, "%s\t%s\n", p, prov[p])
+		}
+		return nil
+	}
+	doc, err := res.ac.RedactedConfig()
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout, "%s\n", out)
+	return err
+}
+
+func cmdDiff(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("diff", stderr)
+	operands, err := parseArgs(fs, args, 2, "two config files")
+	if err != nil {
+		return err
+	}
+	var flat [2]map[string]string
+	for i, p := range operands {
+		doc, err := readDoc(p)
+		if err != nil {
+			return err
+		}
+		flat[i] = map[string]string{}
+		flatten(doc, "", flat[i])
+	}
+	keys := map[string]bool{}
+	for _, m := range flat {
+		for k := range m {
+			keys[k] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	differ := false
+	for _, k := range sorted {
+		a, inA := flat[0][k]
+		b, inB := flat[1][k]
+		switch {
+		case !inB:
+			fmt.Fprintf(stdout, "- %s: %s\n", k, a)
+		case !inA:
+			fmt.Fprintf(stdout, "+ %s: %s\n", k, b)
+		case a != b:
+			fmt.Fprintf(stdout, "~ %s: %s -> %s\n", k, a, b)
+		default:
+			continue
+		}
+		differ = true
+	}
+	if differ {
+		return errFailed
+	}
+	return nil
+}
+
+// readDoc reads a JSON or JSONC file into a generic value.
+func readDoc(path string) (any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	dec := json.NewDecoder(bytes.NewReader(antconfig.ToJSON(data)))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// flatten records every leaf of v under its dotted key; arrays are compared
+// as a whole.
+func flatten(v any, prefix string, out map[string]string) {
+	if m, ok := v.(map[string]any); ok && len(m) > 0 {
+		for k, child := range m {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			flatten(child, key, out)
+		}
+		return
+	}
+	data, _ := json.Marshal(v)
+	out[prefix] = string(data)
+}
+
+func cmdConvert(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("convert", stderr)
+	to := fs.String("to", "", "output `format`, json or jsonc (default: the other one of the input); both are indented JSON")
+	output := fs.String("o", "", "write to `file` instead of stdout")
+	operands, err := parseArgs(fs, args, 1, "one input file")
+	if err != nil {
+		return err
+	}
+	in := operands[0]
+	format := *to
+	if format == "" {
+		format = "jsonc"
+		if strings.EqualFold(filepath.Ext(in), ".jsonc") {
+			format = "json"
+		}
+	}
+	if format != "json" && format != "jsonc" {
+		return usageError{fmt.Sprintf("unsupported format %q (want json or jsonc)", format)}
+	}
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return err
+	}
+	// Both targets are indented JSON; strict JSON is also valid JSONC
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, antconfig.ToJSON(data)); err != nil {
+		return fmt.Errorf("error parsing %s: %w", in, err)
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	if *output != "" {
+		return os.WriteFile(*output, out.Bytes(), 0o644)
+	}
+	_, err = stdout.Write(out.Bytes())
+	return err
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robfordww/antconfig"
+)
+
+type testDB struct {
+	Host     string `json:"host" default:"localhost" env:"ANTCFG_DB_HOST"`
+	Port     int    `json:"port" default:"5432"`
+	Password string `json:"password" env:"ANTCFG_DB_PASSWORD" secret:"true"`
+}
+
+type testConfig struct {
+	DB      testDB        `json:"db"`
+	Timeout time.Duration `json:"timeout" default:"5s"`
+	Token   string        `json:"token" required:"true"`
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func runCmd(args ...string) (int, string, string) {
+	var stdout, stderr strings.Builder
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestSchemaCommands(t *testing.T) {
+	dir := t.TempDir()
+	var cfg testConfig
+	var b strings.Builder
+	if err := antconfig.New().MustSetConfig(&cfg).WriteDescriptor(&b); err != nil {
+		t.Fatal(err)
+	}
+	schema := writeFile(t, dir, "schema.json", b.String())
+	good := writeFile(t, dir, "good.jsonc", `{
+		// production
+		"db": {"host": "db.prod", "password": "hunter2"},
+		"token": "abc",
+	}`)
+	bad := writeFile(t, dir, "bad.json", `{"db": {"port": "x"}, "extra": 1}`)
+
+	if code, out, errOut := runCmd("validate", "-schema", schema, good); code != 0 || !strings.Contains(out, "ok") {
+		t.Fatalf("validate good: code %d, out %q, err %q", code, out, errOut)
+	}
+	code, out, _ := runCmd("validate", "-schema", schema, bad)
+	if code != 1 || !strings.Contains(out, "error parsing config file") {
+		t.Fatalf("validate bad: code %d, out %q", code, out)
+	}
+
+	missing := writeFile(t, dir, "missing.json", `{"extra": 1}`)
+	code, out, _ = runCmd("validate", "-schema", schema, "-strict", missing)
+	if code != 1 || !strings.Contains(out, "error: Token: required setting is missing") || !strings.Contains(out, "warning: config key extra") {
+		t.Fatalf("validate missing: code %d, out %q", code, out)
+	}
+
+	code, out, _ = runCmd("effective", "-schema", schema, good)
+	if code != 0 || !strings.Contains(out, `"host": "db.prod"`) || !strings.Contains(out, `"password": "REDACTED"`) || !strings.Contains(out, `"port": 5432`) {
+		t.Fatalf("effective: code %d, out %q", code, out)
+	}
+	// A .env in the working directory is not discovered; -env files are read
+	t.Chdir(dir)
+	dotenv := writeFile(t, dir, ".env", "ANTCFG_DB_HOST=db.dotenv\n")
+	code, out, _ = runCmd("effective", "-schema", schema, good)
+	if code != 0 || !strings.Contains(out, `"host": "db.prod"`) {
+		t.Fatalf("effective picked up a discovered .env: code %d, out %q", code, out)
+	}
+	code, out, _ = runCmd("effective", "-schema", schema, "-env", dotenv, good)
+	if code != 0 || !strings.Contains(out, `"host": "db.dotenv"`) {
+		t.Fatalf("effective -env: code %d, out %q", code, out)
+	}
+	t.Setenv("ANTCFG_DB_HOST", "db.env")
+	code, out, _ = runCmd("effective", "-schema", schema, "-sources", good)
+	if code != 0 || !strings.Contains(out, "DB.Host\tenv\n") || !strings.Contains(out, "DB.Port\tdefault\n") {
+		t.Fatalf("effective -sources: code %d, out %q", code, out)
+	}
+
+	if code, _, errOut := runCmd("validate", good); code != 2 || !strings.Contains(errOut, "-schema is required") {
+		t.Fatalf("validate without schema: code %d, err %q", code, errOut)
+	}
+}
+
+func TestDiffAndConvert(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.jsonc", `{"db": {"host": "a", "port": 1}, "old": true, /* c */ "tags": [1, 2]}`)
+	b := writeFile(t, dir, "b.json", `{"db": {"host": "b", "port": 1}, "new": "x", "tags": [1, 2]}`)
+
+	code, out, _ := runCmd("diff", a, b)
+	want := "~ db.host: \"a\" -> \"b\"\n+ new: \"x\"\n- old: true\n"
+	if code != 1 || out != want {
+		t.Fatalf("diff: code %d, out %q, want %q", code, out, want)
+	}
+	if code, out, _ := runCmd("diff", a, a); code != 0 || out != "" {
+		t.Fatalf("diff identical: code %d, out %q", code, out)
+	}
+
+	code, out, _ = runCmd("convert", a)
+	if code != 0 || !strings.HasPrefix(out, "{\n  \"db\": {\n    \"host\": \"a\",") || strings.Contains(out, "/*") {
+		t.Fatalf("convert: code %d, out %q", code, out)
+	}
+	dst := filepath.Join(dir, "out.jsonc")
+	if code, _, errOut := runCmd("convert", "-to", "jsonc", "-o", dst, b); code != 0 {
+		t.Fatalf("convert -o: code %d, err %q", code, errOut)
+	}
+	if _, err := os.Stat(dst); err != nil {
+		t.Fatal(err)
+	}
+	if code, _, _ := runCmd("convert", "-to", "yaml", b); code != 2 {
+		t.Fatalf("convert -to yaml: expected usage error, got %d", code)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/robfordww/antconfig"
+)
+
+// namedTypes are the non-builtin types a descriptor may name directly.
+var namedTypes = map[string]reflect.Type{
+	"time.Duration":   reflect.TypeOf(time.Duration(0)),
+	"time.Time":       reflect.TypeOf(time.Time{}),
+	"url.URL":         reflect.TypeOf(url.URL{}),
+	"netip.Addr":      reflect.TypeOf(netip.Addr{}),
+	"netip.AddrPort":  reflect.TypeOf(netip.AddrPort{}),
+	"regexp.Regexp":   reflect.TypeOf(regexp.Regexp{}),
+	"big.Int":         reflect.TypeOf(big.Int{}),
+	"big.Rat":         reflect.TypeOf(big.Rat{}),
+	"json.RawMessage": reflect.TypeOf(json.RawMessage{}),
+	"interface {}":    anyType,
+}
+
+var anyType = reflect.TypeOf((*any)(nil)).Elem()
+
+// kindTypes stand in for application types the tool cannot name, by kind.
+var kindTypes = map[string]reflect.Type{
+	"bool":       reflect.TypeOf(false),
+	"string":     reflect.TypeOf(""),
+	"int":        reflect.TypeOf(int(0)),
+	"int8":       reflect.TypeOf(int8(0)),
+	"int16":      reflect.TypeOf(int16(0)),
+	"int32":      reflect.TypeOf(int32(0)),
+	"int64":      reflect.TypeOf(int64(0)),
+	"uint":       reflect.TypeOf(uint(0)),
+	"uint8":      reflect.TypeOf(uint8(0)),
+	"uint16":     reflect.TypeOf(uint16(0)),
+	"uint32":     reflect.TypeOf(uint32(0)),
+	"uint64":     reflect.TypeOf(uint64(0)),
+	"uintptr":    reflect.TypeOf(uintptr(0)),
+	"float32":    reflect.TypeOf(float32(0)),
+	"float64":    reflect.TypeOf(float64(0)),
+	"complex64":  reflect.TypeOf(complex64(0)),
+	"complex128": reflect.TypeOf(complex128(0)),
+}
+
+// loadDescriptor reads a descriptor file written by WriteDescriptor.
+func loadDescriptor(path string) (antconfig.Descriptor, error) {
+	var d antconfig.Descriptor
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return d, err
+	}
+	if err := json.Unmarshal(antconfig.ToJSON(data), &d); err != nil {
+		return d, fmt.Errorf("error parsing descriptor %s: %w", path, err)
+	}
+	return d, nil
+}
+
+// schemaNode is a struct under construction: leaf fields and nested structs
+// in declaration order.
+type schemaNode struct {
+	name     string
+	key      string
+	leaf     *antconfig.FieldDescriptor
+	children []*schemaNode
+}
+
+// structType rebuilds an equivalent struct type from a descriptor, keeping
+// every field's tags so antconfig loads it like the original.
+func structType(d antconfig.Descriptor) (reflect.Type, error) {
+	root := &schemaNode{}
+	for i := range d.Fields {
+		f := &d.Fields[i]
+		segs := strings.Split(f.Path, ".")
+		var keys []string
+		if f.Key != "" {
+			keys = strings.Split(f.Key, ".")
+		}
+		n := root
+		for j, seg := range segs[:len(segs)-1] {
+			key := "-"
+			if len(keys) == len(segs) {
+				key = keys[j]
+			}
+			n = n.child(seg, key)
+		}
+		n.children = append(n.children, &schemaNode{name: segs[len(segs)-1], leaf: f})
+	}
+	return root.build()
+}
+
+func (n *schemaNode) child(name, key string) *schemaNode {
+	for _, c := range n.children {
+		if c.leaf == nil && c.name == name {
+			return c
+		}
+	}
+	c := &schemaNode{name: name, key: key}
+	n.children = append(n.children, c)
+	return c
+}
+
+func (n *schemaNode) build() (reflect.Type, error) {
+	fields := make([]reflect.StructField, 0, len(n.children))
+	for _, c := range n.children {
+		if c.leaf != nil {
+			t, err := parseType(c.leaf.Type, c.leaf.Kind)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", c.leaf.Path, err)
+			}
+			fields = append(fields, reflect.StructField{Name: c.name, Type: t, Tag: reflect.StructTag(c.leaf.Tag)})
+			continue
+		}
+		t, err := c.build()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, reflect.StructField{Name: c.name, Type: t, Tag: reflect.StructTag(fmt.Sprintf("json:%q", c.key))})
+	}
+	return reflect.StructOf(fields), nil
+}
+
+// parseType maps a reflect type string back to a type; application types
+// are replaced by a type of the same kind.
+func parseType(name, kind string) (reflect.Type, error) {
+	switch {
+	case strings.HasPrefix(name, "*"):
+		elem, err := parseType(name[1:], "")
+		if err != nil {
+			return nil, err
+		}
+		return reflect.PointerTo(elem), nil
+	case strings.HasPrefix(name, "[]"):
+		elem, err := parseType(name[2:], "")
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(elem), nil
+	case strings.HasPrefix(name, "map[string]"):
+		elem, err := parseType(name[len("map[string]"):], "")
+		if err != nil {
+			return nil, err
+		}
+		return reflect.MapOf(reflect.TypeOf(""), elem), nil
+	}
+	if t, ok := namedTypes[name]; ok {
+		return t, nil
+	}
+	if t, ok := kindTypes[name]; ok {
+		return t, nil
+	}
+	switch kind {
+	case "":
+		// Unknown element type of a composite: accept any JSON value
+		return anyType, nil
+	case "struct":
+		// Opaque application structs are usually text-encoded
+		return reflect.TypeOf(""), nil
+	case "slice", "map", "interface":
+		return anyType, nil
+	}
+	if t, ok := kindTypes[kind]; ok {
+		return t, nil
+	}
+	return nil, fmt.Errorf("unsupported type %s (kind %s)", name, kind)
+}
@@ -0,0 +1,43 @@
+package antconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNameCollision is returned by BindConfigFlags when two fields map to the
+// same flag or environment variable name, e.g. a struct type reused for two
+// nested fields without distinct `flag` tags.
+var ErrNameCollision = errors.New("configuration name collision")
+
+// checkNameCollisions reports every flag name (with the configured prefix)
+// and env var name, including `envalias` names, claimed by more than one
+// field. Names are compared case-insensitively under SetCaseInsensitive.
+func (a *AntConfig) checkNameCollisions(fields []fieldWithTagValue) error {
+	var errs []error
+	seen := map[string]string{}
+	claim := func(kind, name, path string) {
+		key := kind + "\x00" + name
+		if a.caseInsensitive {
+			key = strings.ToLower(key)
+		}
+		if prev, ok := seen[key]; ok && prev != path {
+			errs = append(errs, fmt.Errorf("%w: %s %s is used by both %s and %s", ErrNameCollision, kind, name, prev, path))
+			return
+		}
+		seen[key] = path
+	}
+	for _, f := range fields {
+		if name := f.tags["flag"]; name != "" {
+			claim("flag", "--"+a.flagPrefix+name, f.path)
+		}
+		if name := f.tags["env"]; name != "" {
+			claim("env var", name, f.path)
+		}
+		for _, old := range aliasNames(f.tags["envalias"]) {
+			claim("env var", old, f.path)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,25 @@
+package antconfig
+
+// This file holds compatibility shims for names used by earlier releases.
+// They are kept for the lifetime of v1 and forward to the canonical API.
+
+// SetValues applies configuration values to the registered config.
+//
+// Deprecated: use WriteConfigValues.
+func (a *AntConfig) SetValues() error {
+	return a.WriteConfigValues()
+}
+
+// LocateFromExe searches upward from the executable's directory for filename.
+//
+// Deprecated: use LocateFromExeUp.
+func LocateFromExe(filename string) (string, error) {
+	return LocateFromExeUp(filename)
+}
+
+// LocateFromWorkingDir searches upward from the working directory for filename.
+//
+// Deprecated: use LocateFromWorkingDirUp.
+func LocateFromWorkingDir(filename string) (string, error) {
+	return LocateFromWorkingDirUp(filename)
+}
@@ -0,0 +1,125 @@
+package antconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// completionFlag is a config flag as seen by the completion generators.
+type completionFlag struct {
+	cli     string
+	desc    string
+	boolean bool
+}
+
+// completionFlags lists the flags derived from the registered config,
+// including the flag prefix.
+func (a *AntConfig) completionFlags() []completionFlag {
+	if a.cfgRef == nil {
+		return nil
+	}
+	fields, err := findFieldsWithTag("flag", reflect.New(reflect.TypeOf(a.cfgRef).Elem()).Interface())
+	if err != nil {
+		return nil
+	}
+	out := make([]completionFlag, 0, len(fields))
+	for _, f := range fields {
+		_, desc := unquoteUsage(f.tags["desc"], f.fieldValue.Type())
+		out = append(out, completionFlag{
+			cli:     a.flagPrefix + f.tagvalue,
+			desc:    strings.Join(strings.Fields(desc), " "),
+			boolean: isBoolField(f.fieldValue.Type()),
+		})
+	}
+	return out
+}
+
+// completionProgram returns program, defaulting to the executable's base name.
+func completionProgram(program string) string {
+	if program == "" {
+		program = filepath.Base(os.Args[0])
+	}
+	return program
+}
+
+// BashCompletion returns a bash completion script offering the config flags
+// (with the flag prefix) for program; "" means the running executable's name.
+// Source it, e.g. from ~/.bash_completion.d, to tab-complete config overrides.
+func (a *AntConfig) BashCompletion(program string) string {
+	program = completionProgram(program)
+	fn := "_" + strings.Map(func(r rune) rune {
+		if r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, program) + "_antconfig"
+	var words []string
+	for _, f := range a.completionFlags() {
+		words = append(words, "--"+f.cli)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# bash completion for %s\n", program)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    if [[ \"$cur\" == -* ]]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=( $(compgen -W %s -- \"$cur\") )\n", shellQuote(strings.Join(words, " ")))
+	b.WriteString("    fi\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(&b, "complete -o default -F %s %s\n", fn, shellQuote(program))
+	return b.String()
+}
+
+// ZshCompletion returns a zsh completion script (for a file named
+// _program on $fpath) offering the config flags with their descriptions.
+func (a *AntConfig) ZshCompletion(program string) string {
+	program = completionProgram(program)
+	var b strings.Builder
+	fmt.Fprintf(&b, "#compdef %s\n\n_arguments \\\n", program)
+	for _, f := range a.completionFlags() {
+		desc := strings.NewReplacer(`[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(f.desc)
+		spec := "--" + f.cli
+		if !f.boolean {
+			spec += "="
+		}
+		if desc != "" {
+			spec += "[" + desc + "]"
+		}
+		if !f.boolean {
+			spec += ":value:"
+		}
+		fmt.Fprintf(&b, "  %s \\\n", shellQuote(spec))
+	}
+	b.WriteString("  '*::arg:_default'\n")
+	return b.String()
+}
+
+// FishCompletion returns fish completion commands offering the config flags
+// with their descriptions; save it as completions/program.fish.
+func (a *AntConfig) FishCompletion(program string) string {
+	program = completionProgram(program)
+	var b strings.Builder
+	for _, f := range a.completionFlags() {
+		fmt.Fprintf(&b, "complete -c %s -l %s", shellQuote(program), shellQuote(f.cli))
+		if !f.boolean {
+			b.WriteString(" -r")
+		}
+		if f.desc != "" {
+			fmt.Fprintf(&b, " -d %s", shellQuote(f.desc))
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// shellQuote single-quotes s for POSIX shells, zsh and fish.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r == '-' || r == '_' || r == '.' || r == '/' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,60 @@
+package antconfig
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// SetConcurrentSources makes each load call the Load method of every
+// registered Source in parallel before any layer is applied, so several
+// remote sources (HTTP, Vault, SSM, ...) cost the latency of the slowest one
+// rather than their sum. The values are still merged in priority order. The
+// first failure cancels the context of the remaining loads and fails the
+// load. Sources, and a Tracer if one is set, must then be safe for
+// concurrent use, and sources must not depend on values exported from .env
+// files during the load.
+func (a *AntConfig) SetConcurrentSources(on bool) {
+	a.concurrentSources = on
+}
+
+// SetSourceTimeout bounds the time all Source.Load calls of a load may take
+// together: their context is canceled d after the load starts, whether the
+// sources are loaded one by one or concurrently. 0 disables the deadline.
+func (a *AntConfig) SetSourceTimeout(d time.Duration) {
+	a.sourceTimeout = d
+}
+
+// loadSources loads every source concurrently and returns their values in
+// the order of sources. The first error, in time, is returned after all
+// loads have finished; it cancels the context of the others.
+func (a *AntConfig) loadSources(ctx context.Context, sources []prioritizedSource) ([]sourceValues, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make([]sourceValues, len(sources))
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	for i, ps := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			values, err := a.loadSource(ctx, ps)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			results[i] = values
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
@@ -0,0 +1,205 @@
+package antconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// whenKey marks a conditional block in a config document.
+const whenKey = "$when"
+
+// SetCondition sets the value of a condition that "$when" blocks in config
+// files can test, adding a new condition or replacing a built-in one: "os"
+// (runtime.GOOS), "arch" (runtime.GOARCH) and "hostname" (os.Hostname).
+//
+// Any object in a config document, including a whole document of a
+// multi-document file, may carry "$when": an object mapping condition names
+// to a value or a list of values. The object is kept, without "$when", when
+// every named condition equals one of its values, and dropped otherwise.
+// Values are path.Match patterns ("web-*") and a leading "!" negates one.
+// Together with multi-document files this gives per-platform overrides:
+//
+//	{"cache_dir": "/var/cache/myapp"}
+//	---
+//	{"$when": {"os": "windows"}, "cache_dir": "C:\\ProgramData\\myapp"}
+//	---
+//	{"$when": {"os": ["darwin", "freebsd"], "arch": "!386"}, "cache_dir": "/Library/Caches/myapp"}
+//
+// An unknown condition name in a file is an error.
+func (a *AntConfig) SetCondition(name, value string) error {
+	if err := a.checkUnlocked("SetCondition"); err != nil {
+		return err
+	}
+	if a.conditions == nil {
+		a.conditions = map[string]string{}
+	}
+	a.conditions[name] = value
+	return nil
+}
+
+// condition returns the value of the condition name.
+func (a *AntConfig) condition(name string) (string, bool) {
+	if v, ok := a.conditions[name]; ok {
+		return v, true
+	}
+	switch name {
+	case "os":
+		return runtime.GOOS, true
+	case "arch":
+		return runtime.GOARCH, true
+	case "hostname":
+		h, _ := os.Hostname()
+		return h, true
+	}
+	return "", false
+}
+
+// conditionNames lists the known condition names, sorted.
+func (a *AntConfig) conditionNames() []string {
+	names := []string{"arch", "hostname", "os"}
+	for name := range a.conditions {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// keepDocument reports whether the "$when" of doc, if any, holds, and removes
+// it from doc.
+func (a *AntConfig) keepDocument(doc map[string]any) (bool, error) {
+	when, ok := doc[whenKey]
+	if !ok {
+		return true, nil
+	}
+	delete(doc, whenKey)
+	return a.holds(when)
+}
+
+// holds evaluates the value of a "$when" key.
+func (a *AntConfig) holds(when any) (bool, error) {
+	conds, ok := when.(map[string]any)
+	if !ok {
+		return false, fmt.Errorf("%s must be an object, not %s", whenKey, jsonKind(when))
+	}
+	// Sorted, so that the reported error does not vary
+	names := make([]string, 0, len(conds))
+	for name := range conds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		actual, known := a.condition(name)
+		if !known {
+			return false, fmt.Errorf("%s: unknown condition %q (known: %s)", whenKey, name, strings.Join(a.conditionNames(), ", "))
+		}
+		var patterns []any
+		switch v := conds[name].(type) {
+		case string:
+			patterns = []any{v}
+		case []any:
+			patterns = v
+		default:
+			return false, fmt.Errorf("%s: condition %s must be a string or a list of strings, not %s", whenKey, name, jsonKind(v))
+		}
+		matched := false
+		for _, p := range patterns {
+			s, ok := p.(string)
+			if !ok {
+				return false, fmt.Errorf("%s: condition %s must be a string or a list of strings, not %s", whenKey, name, jsonKind(p))
+			}
+			ok, err := matchCondition(s, actual)
+			if err != nil {
+				return false, fmt.Errorf("%s: condition %s: %w", whenKey, name, err)
+			}
+			if ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchCondition matches actual against a "$when" pattern.
+func matchCondition(pattern, actual string) (bool, error) {
+	negate := strings.HasPrefix(pattern, "!")
+	if negate {
+		pattern = pattern[1:]
+	}
+	ok, err := path.Match(pattern, actual)
+	if err != nil {
+		return false, fmt.Errorf("bad pattern %q: %w", pattern, err)
+	}
+	return ok != negate, nil
+}
+
+// applyConditions drops the "$when" blocks of the config document js whose
+// conditions do not hold, and the "$when" keys of the others. A document
+// without "$when" is returned unchanged.
+func (a *AntConfig) applyConditions(js []byte) ([]byte, error) {
+	if !bytes.Contains(js, []byte(`"`+whenKey+`"`)) {
+		return js, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		// Let decoding report a malformed config as usual
+		return js, nil
+	}
+	doc, keep, err := a.prune(doc)
+	if err != nil {
+		return nil, err
+	}
+	if !keep {
+		doc = map[string]any{}
+	}
+	return json.Marshal(doc)
+}
+
+// prune applies the "$when" blocks within v; keep is false when v itself is
+// a block whose conditions do not hold.
+func (a *AntConfig) prune(v any) (_ any, keep bool, err error) {
+	switch x := v.(type) {
+	case map[string]any:
+		if keep, err := a.keepDocument(x); err != nil || !keep {
+			return nil, false, err
+		}
+		for k, sub := range x {
+			sub, keep, err := a.prune(sub)
+			if err != nil {
+				return nil, false, fmt.Errorf("%s: %w", k, err)
+			}
+			if keep {
+				x[k] = sub
+			} else {
+				delete(x, k)
+			}
+		}
+	case []any:
+		out := x[:0]
+		for i, sub := range x {
+			sub, keep, err := a.prune(sub)
+			if err != nil {
+				return nil, false, fmt.Errorf("[%d]: %w", i, err)
+			}
+			if keep {
+				out = append(out, sub)
+			}
+		}
+		return out, true, nil
+	}
+	return v, true, nil
+}
@@ -1,21 +1,34 @@
 package antconfig
 
 import (
+	"context"
+	"crypto/ed25519"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"math"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Errors
 var ErrConfigNotFound = errors.New("config file not found")
 var ErrEnvFileNotFound = errors.New("environment file not found")
 
+// ErrConfigRemoved is returned by WriteConfigValues when the file set with
+// SetConfigPath existed at that time but is gone at load time, as opposed to
+// ErrConfigNotFound for a path that never existed.
+var ErrConfigRemoved = errors.New("config file was removed")
+
 // AntConfig is a small, zero-dependency configuration helper that applies
 // values to a tagged struct from (in order): defaults, config file (JSON/JSONC),
 // .env file, OS environment variables, and command-line flags.
@@ -24,19 +37,136 @@ var ErrEnvFileNotFound = errors.New("environment file not found")
 // pointer, optionally BindConfigFlags to register flags on a flag.FlagSet,
 // then call WriteConfigValues() to apply.
 type AntConfig struct {
-	envPath    string
+	// envPaths lists .env files loaded in order; later files override earlier ones.
+	envPaths   []string
 	configPath string
+	// configExisted records whether configPath existed when it was set, and
+	// configOptional whether a missing file is skipped (SetConfigPathOptional).
+	configExisted  bool
+	configOptional bool
+	// persistMu serializes rewrites of the config file (Persist).
+	persistMu sync.Mutex
 	// flagArgs optionally holds CLI args to parse (e.g., os.Args[1:]).
 	// When empty, WriteConfigValues will fall back to os.Args[1:].
 	flagArgs []string
+	// argFiles expands "@path" arguments to the file's lines (SetArgFiles).
+	argFiles bool
+	// flagDefaults are the flag defaults by field path shown by
+	// WriteFlagHelp (SeedFlagDefaults).
+	flagDefaults map[string]string
+	// standard holds the flags of EnableStandardFlags; nil when not enabled.
+	standard *standardFlags
 	// flagPrefix, if set, is prepended to all CLI flags defined via `flag:"name"`.
 	// For example, with flagPrefix="config-" and tag `flag:"secret"`, accepted flag
 	// forms include: --config-secret=value, --config-secret value, or --config-secret (bool true).
 	flagPrefix string
 	// flagSet, if provided, will be populated via BindConfigFlags and consulted for parsed values.
 	flagSet *flag.FlagSet
+	// flagRaw records the raw input of flag.Func flags registered by BindConfigFlags.
+	flagRaw map[string]*string
 	// cfgRef holds the config pointer used for reflection when binding flags.
 	cfgRef any
+	// dotEnvPrivate keeps .env values in memory for the load instead of
+	// exporting them to the process environment (see SetDotEnvExport).
+	dotEnvPrivate bool
+	// exported holds the .env values the latest load exported to the
+	// process environment, guarded by exportMu, so that later loads do not
+	// take them for explicit env.
+	exportMu sync.Mutex
+	exported map[string]string
+	// loaded is what the most recent successful load produced, published
+	// as a whole so that readers never see a mix of two loads.
+	loaded atomic.Pointer[loadedState]
+	// sources are additional value sources interleaved by priority (AddSource).
+	sources []prioritizedSource
+	// parsers holds per-instance string parsers by field type (RegisterParser).
+	parsers typeParsers
+	// formats maps config file extensions to converters (RegisterFormat).
+	formats map[string]func([]byte) ([]byte, error)
+	// templating runs config files through text/template (EnableTemplating).
+	templating bool
+	// conditions holds the "$when" conditions set by SetCondition.
+	conditions map[string]string
+	// resolvers dereference value URIs by scheme (RegisterResolver).
+	resolvers map[string]Resolver
+	// resolveDepth bounds chained value URIs (SetResolveDepth).
+	resolveDepth int
+	// signingKey, if set, must have signed every config file (RequireSignature).
+	signingKey ed25519.PublicKey
+	// sopsKeys supplies the data key for sops-encrypted config files.
+	sopsKeys SOPSKeyProvider
+	// migrations upgrade config files by schema version (RegisterMigration).
+	migrations map[int]migration
+	// locators are the config file discovery strategies (SetDiscovery).
+	locators []Locator
+	// onWarning receives soft issues found while loading (OnWarning).
+	onWarning func(Warning)
+	// postLoad checks each loaded config (SetPostLoad).
+	postLoad func(cfg any) error
+	// onAlias is notified of settings read through an alias (OnDeprecatedAlias).
+	onAlias func([]AliasUse)
+	// reload tracks the effective config for Reload and OnChange.
+	reload reloadState
+	// features are the `feature` fields of the registered struct (Feature).
+	features map[string]*featureFlag
+	// audit records the fields read through Read and Lookup
+	// (EnableAccessAudit).
+	audit *accessAudit
+	// slog, if set, receives debug diagnostics of loads (SetSlog).
+	slog *slog.Logger
+	// tracer, if set, receives spans around loads and sources (SetTracer).
+	tracer Tracer
+	// sourcesLocked rejects changes to files and sources (LockSources)
+	// unless unlocks UnlockForReload calls are pending. They are atomic so a
+	// watcher may unlock while readers check the lock. lockedProviders is
+	// the RegisterProvider sequence number at the time of locking.
+	sourcesLocked   atomic.Bool
+	unlocks         atomic.Int32
+	lockedProviders atomic.Uint64
+	// usageWidth wraps usage descriptions at this many columns; 0 disables wrapping.
+	usageWidth int
+	// lookupEnv and envNames replace the process environment when set
+	// (SetEnvironment, SetLookupEnv).
+	lookupEnv func(string) (string, bool)
+	envNames  func() []string
+	// fsys, if set, replaces the operating system for reading config and
+	// .env files (SetFS).
+	fsys fs.FS
+	// embedded and embeddedFormat hold the baseline config layered right
+	// after the defaults (SetEmbeddedConfig).
+	embedded       []byte
+	embeddedFormat string
+	// mode selects the built-in layers that are read (SetMode).
+	mode Mode
+	// noDiscovery, noConfigFile and noDotEnv disable auto-discovery and the
+	// file layers (DisableAutoDiscovery, DisableConfigFile, DisableDotEnv).
+	noDiscovery  bool
+	noConfigFile bool
+	noDotEnv     bool
+	// concurrentSources loads all sources in parallel (SetConcurrentSources);
+	// sourceTimeout bounds their loads (SetSourceTimeout).
+	concurrentSources bool
+	sourceTimeout     time.Duration
+	// retry is the policy for failing source loads (SetRetryPolicy).
+	retry RetryPolicy
+	// sourceCache is the file holding the last loaded source values
+	// (SetSourceCache); allowStale and staleTTL enable falling back to it
+	// (AllowStaleOnError).
+	sourceCache string
+	allowStale  bool
+	staleTTL    time.Duration
+	// secretPrompt reads required secrets left empty (SetSecretPrompt).
+	secretPrompt func(label string) (string, error)
+	// caseInsensitive matches env and flag names regardless of case
+	// (SetCaseInsensitive).
+	caseInsensitive bool
+	// frozen holds the checksum of the config taken by Freeze.
+	frozen *frozenState
+	// tagLint reports config tags on unexported fields (SetTagLint).
+	tagLint LintLevel
+	// appVersion is the running application version used to enforce
+	// `removed_in:"…"` tags. Empty disables the check.
+	appVersion string
 }
 
 // New constructs a new AntConfig with default settings.
@@ -46,17 +176,69 @@ func New() *AntConfig {
 
 // SetFlagArgs sets the CLI arguments that should be used for flag overrides.
 // If not provided, WriteConfigValues falls back to os.Args[1:].
-func (c *AntConfig) SetFlagArgs(args []string) {
-	c.flagArgs = args
+func (a *AntConfig) SetFlagArgs(args []string) {
+	a.flagArgs = args
+}
+
+// RemainingArgs returns the command-line arguments that are not config
+// flags: positional arguments and everything after a "--" terminator. When a
+// FlagSet is bound (BindConfigFlags) it returns fs.Args(); otherwise it
+// reports the arguments of the most recent successful WriteConfigValues
+// (SetFlagArgs or os.Args[1:]).
+func (a *AntConfig) RemainingArgs() []string {
+	if a.flagSet != nil {
+		return a.flagSet.Args()
+	}
+	return append([]string(nil), a.state().remainingArgs...)
 }
 
 // SetFlagPrefix sets an optional CLI flag prefix (e.g., "config-").
-func (c *AntConfig) SetFlagPrefix(prefix string) {
-	c.flagPrefix = prefix
+func (a *AntConfig) SetFlagPrefix(prefix string) error {
+	if err := a.checkUnlocked("SetFlagPrefix"); err != nil {
+		return err
+	}
+	a.flagPrefix = prefix
+	return nil
+}
+
+// SetDotEnvExport controls whether values loaded from .env files are exported
+// to the process environment via os.Setenv (the default). When disabled, .env
+// values are kept in an internal map consulted only while applying `env`
+// tags, so they do not leak to child processes or other readers of os.Environ.
+// Exported values still belong to the .env layer on later loads: a reload
+// picks up an edited .env file, and unsets exported keys the file dropped.
+func (a *AntConfig) SetDotEnvExport(export bool) {
+	a.dotEnvPrivate = !export
+}
+
+// SetAppVersion sets the running application version (e.g., "v2.4.1"). When
+// set, WriteConfigValues fails for any field tagged `removed_in:"vX"` whose
+// version is <= the application version and that is still supplied by the
+// config file, environment, or flags.
+func (a *AntConfig) SetAppVersion(version string) {
+	a.appVersion = version
+}
+
+// AppVersion returns the application version set via SetAppVersion, if any.
+func (a *AntConfig) AppVersion() string { return a.appVersion }
+
+// EnvPath returns the first configured .env path, if any.
+func (a *AntConfig) EnvPath() string {
+	if len(a.envPaths) == 0 {
+		return ""
+	}
+	return a.envPaths[0]
 }
 
-// EnvPath returns the configured .env path, if any.
-func (a *AntConfig) EnvPath() string { return a.envPath }
+// EnvPaths returns a copy of all configured .env paths in load order.
+func (a *AntConfig) EnvPaths() []string {
+	if a.envPaths == nil {
+		return nil
+	}
+	dup := make([]string, len(a.envPaths))
+	copy(dup, a.envPaths)
+	return dup
+}
 
 // ConfigPath returns the configured config file path, if any.
 func (a *AntConfig) ConfigPath() string { return a.configPath }
@@ -75,7 +257,10 @@ func (a *AntConfig) FlagArgs() []string {
 }
 
 // SetConfig stores a reference to the config pointer for later operations
-// like BindConfigFlags. cfg must be a non-nil pointer to a struct.
+// like BindConfigFlags. cfg must be a non-nil pointer to a struct. All
+// `default` tags are validated against their field types up front; malformed
+// defaults are returned together as a *MultiError and cfg is not registered.
+// Malformed `validate` tags, such as ones naming unknown fields, are rejected too.
 func (a *AntConfig) SetConfig(cfg any) error {
 	if cfg == nil {
 		return fmt.Errorf("expected a non-nil pointer to a struct, got <nil>")
@@ -84,7 +269,73 @@ func (a *AntConfig) SetConfig(cfg any) error {
 	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
 		return fmt.Errorf("expected a non-nil pointer to a struct, got %s", v.Kind())
 	}
+	if err := validateDefaults(v.Elem().Type(), a.parsers); err != nil {
+		return err
+	}
+	if err := validateConstraints(v.Elem().Type()); err != nil {
+		return err
+	}
+	if err := validateFromTags(v.Elem().Type()); err != nil {
+		return err
+	}
+	if err := validatePathTags(v.Elem().Type()); err != nil {
+		return err
+	}
+	if err := validateEncodingTags(v.Elem().Type()); err != nil {
+		return err
+	}
+	features, err := featureFlags(v.Elem().Type())
+	if err != nil {
+		return err
+	}
 	a.cfgRef = cfg
+	a.loaded.Store(nil)
+	a.features = features
+	return nil
+}
+
+// loadedState is the outcome of a successful load.
+type loadedState struct {
+	// config is the struct that was loaded: the registered one, or the
+	// LoadInto or Reload value.
+	config any
+	// provenance records which layer last set each field, keyed by dotted
+	// Go field path.
+	provenance map[string]Layer
+	// remainingArgs are the positional arguments left over when the load
+	// parsed the arguments itself (RemainingArgs).
+	remainingArgs []string
+	// configFile is the config file read (Persist).
+	configFile string
+}
+
+// state returns the outcome of the most recent successful load; before the
+// first it holds just the registered struct.
+func (a *AntConfig) state() *loadedState {
+	if st := a.loaded.Load(); st != nil {
+		return st
+	}
+	return &loadedState{config: a.cfgRef}
+}
+
+// current returns the most recently loaded config, which readers such as
+// Lookup, Hash and RedactedConfig report: the registered struct until a
+// LoadInto or Reload loads a fresh value.
+func (a *AntConfig) current() any {
+	return a.state().config
+}
+
+// validateDefaults parses every `default` tag of struct type t against its
+// field type, on a scratch value so the caller's struct is left untouched.
+// All malformed defaults are reported together as a *MultiError.
+func validateDefaults(t reflect.Type, parsers typeParsers) error {
+	fields, err := findFieldsWithTag("default", reflect.New(t).Interface())
+	if err != nil {
+		return fmt.Errorf("error finding fields with 'default' tag: %v", err)
+	}
+	if errs := setDefaultValues(fields, parsers, map[string]Layer{}); len(errs) > 0 {
+		return &MultiError{Errors: errs}
+	}
 	return nil
 }
 
@@ -101,32 +352,67 @@ func (a *AntConfig) MustSetConfig(cfg any) *AntConfig {
 // It respects the configured prefix (via SetFlagPrefix) for the CLI names. This method does not parse
 // or apply flags; call fs.Parse(...) yourself, then WriteConfigValues to apply. It also binds the
 // FlagSet to AntConfig so WriteConfigValues reads values from it. Requires SetConfig to be called first.
+// Fields are registered with native flag types so fs.Parse validates them: flag.Func for types with a
+// registered parser, flag.Var for flag.Value types, flag.TextVar for encoding.TextUnmarshaler types, and
+// flag.Duration for time.Duration. Boolean fields also get a --no-name flag that sets --name=false.
+// Flags already defined on fs under the same name are reused as-is. Two fields mapping to the same
+// flag or env var name (e.g. a struct reused for two nested fields) fail with ErrNameCollision.
 func (a *AntConfig) BindConfigFlags(fs *flag.FlagSet) error {
 	if a.cfgRef == nil {
 		return fmt.Errorf("BindConfigFlags requires SetConfig to be called first")
 	}
 	// Collect flag fields (and related metadata like optional descriptions)
-	fields, err := findFieldsWithTag("flag", a.cfgRef)
+	plan, err := newFieldPlan(a.cfgRef)
 	if err != nil {
 		return err
 	}
+	if err := a.checkNameCollisions(plan.fields); err != nil {
+		return err
+	}
+	fields := plan.withTag("flag")
+	var negatable []string
 	for _, f := range fields {
 		name := f.tagvalue
 		cli := name
 		if a.flagPrefix != "" {
 			cli = a.flagPrefix + name
 		}
+		// Leave flags the caller already defined (e.g. via fs.Var) untouched
+		if fs.Lookup(cli) != nil {
+			continue
+		}
 		usage := ""
 		if f.tags != nil {
 			usage = f.tags["desc"]
 		}
-		switch f.fieldValue.Kind() {
-		case reflect.Bool:
-			fs.Bool(cli, false, usage)
-		default:
-			fs.String(cli, "", usage)
+		var raw *string
+		if layout := f.tags["layout"]; layout != "" && isTimeField(f.fieldValue.Type()) {
+			raw = funcFlag(fs, cli, usage, func(s string) error {
+				_, err := time.Parse(layout, s)
+				return err
+			})
+		} else if enc := f.tag.Get("encoding"); enc != "" {
+			raw = funcFlag(fs, cli, usage, func(s string) error {
+				_, err := decodeString(enc, s)
+				return err
+			})
+		} else {
+			raw = registerFlag(fs, f.fieldValue.Type(), cli, usage, a.parsers)
+			if isBoolField(f.fieldValue.Type()) {
+				negatable = append(negatable, cli)
+			}
+		}
+		if raw != nil {
+			if a.flagRaw == nil {
+				a.flagRaw = map[string]*string{}
+			}
+			a.flagRaw[cli] = raw
 		}
 	}
+	// Negated forms go last so a field really named "no-…" keeps its flag
+	for _, cli := range negatable {
+		registerNegatedFlag(fs, cli)
+	}
 	a.flagSet = fs
 	return nil
 }
@@ -175,62 +461,41 @@ func (a *AntConfig) ListFlags(c any) ([]FlagSpec, error) {
 
 // EnvHelpString builds a help section for environment variables that can
 // configure fields of the registered config struct. It returns a string
-// formatted to append after flag usage output, using the same two-space
-// indentation convention as flag.PrintDefaults.
+// formatted to append after flag usage output, following the layout of
+// flag.PrintDefaults (see WriteEnvHelp).
 // Requires SetConfig to have been called; otherwise returns an empty string.
 func (a *AntConfig) EnvHelpString() string {
-	if a.cfgRef == nil {
-		return ""
-	}
-	fields, err := findFieldsWithTag("env", a.cfgRef)
-	if err != nil || len(fields) == 0 {
-		return ""
-	}
-	// Build two columns: col1 = ENV (default "...") if default exists; col2 = description
-	type row struct{ col1, col2 string }
-	rows := make([]row, 0, len(fields))
-	max := 0
-	for _, f := range fields {
-		envName := f.tagvalue
-		def := ""
-		if f.tags != nil && f.tags["default"] != "" {
-			def = fmt.Sprintf(" (default %q)", f.tags["default"])
-		}
-		col1 := envName + def
-		if len(col1) > max {
-			max = len(col1)
-		}
-		desc := ""
-		if f.tags != nil {
-			desc = f.tags["desc"]
-		}
-		rows = append(rows, row{col1: col1, col2: desc})
-	}
 	var b strings.Builder
-	b.WriteString("Environment variables:\n")
-	for _, r := range rows {
-		b.WriteString(r.col1)
-		// At least one space between columns; align by max col1 width
-		pad := max - len(r.col1) + 1
-		if pad < 1 {
-			pad = 1
-		}
-		b.WriteString(strings.Repeat(" ", pad))
-		if r.col2 != "" {
-			b.WriteString("- " + r.col2)
-		}
-		b.WriteString("\n")
-	}
+	_ = a.WriteEnvHelp(&b)
 	return b.String()
 }
 
 //
 
-// SetEnvPath sets the path to a .env file and validates it exists. When not set,
-// WriteConfigValues will auto-discover a .env in the current working directory.
-func (c *AntConfig) SetEnvPath(path string) error {
-	c.envPath = path
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+// SetEnvPath sets the path to a .env file, replacing any paths added before,
+// and validates it exists. When no path is set, WriteConfigValues will
+// auto-discover a .env in the current working directory.
+func (a *AntConfig) SetEnvPath(path string) error {
+	if err := a.checkUnlocked("SetEnvPath"); err != nil {
+		return err
+	}
+	a.envPaths = []string{path}
+	if _, err := a.files().Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrEnvFileNotFound, path)
+	}
+	return nil
+}
+
+// AddEnvPath appends a .env file to load after the ones already configured and
+// validates it exists. Files are loaded in order with later files overriding
+// earlier ones, e.g. .env, then .env.local, then .env.<profile>. Values from
+// explicit OS environment variables still take precedence over all of them.
+func (a *AntConfig) AddEnvPath(path string) error {
+	if err := a.checkUnlocked("AddEnvPath"); err != nil {
+		return err
+	}
+	a.envPaths = append(a.envPaths, path)
+	if _, err := a.files().Stat(path); errors.Is(err, fs.ErrNotExist) {
 		return fmt.Errorf("%w: %s", ErrEnvFileNotFound, path)
 	}
 	return nil
@@ -238,125 +503,430 @@ func (c *AntConfig) SetEnvPath(path string) error {
 
 // SetConfigPath sets the path to a JSON/JSONC config file and validates it exists.
 // When not set, WriteConfigValues will auto-discover config.jsonc or config.json
-// by walking upward from the current working directory.
-func (c *AntConfig) SetConfigPath(path string) error {
-	c.configPath = path
-	if _, err := os.Stat(path); os.IsNotExist(err) {
+// by walking upward from the current working directory (see SetDiscovery).
+func (a *AntConfig) SetConfigPath(path string) error {
+	if err := a.checkUnlocked("SetConfigPath"); err != nil {
+		return err
+	}
+	a.setConfigPath(path, false)
+	if !a.configExisted {
 		return fmt.Errorf("%w: %s", ErrConfigNotFound, path)
 	}
 	return nil
 }
 
+// SetConfigPathOptional is like SetConfigPath for a file that may be absent:
+// a missing file is not an error, here or in WriteConfigValues, which then
+// loads without a config file. If the file existed when it was set and has
+// disappeared by load time, the load reports a WarningMissingFile.
+func (a *AntConfig) SetConfigPathOptional(path string) error {
+	if err := a.checkUnlocked("SetConfigPathOptional"); err != nil {
+		return err
+	}
+	a.setConfigPath(path, true)
+	return nil
+}
+
+func (a *AntConfig) setConfigPath(path string, optional bool) {
+	a.configPath = path
+	a.configOptional = optional
+	_, err := a.files().Stat(path)
+	a.configExisted = !errors.Is(err, fs.ErrNotExist)
+}
+
 // WriteConfigValues applies configuration values to the struct registered via
 // SetConfig/MustSetConfig, in this precedence order:
-//  1. default values from `default:"…"` tags
+//  1. default values from `default:"…"` tags, then the SetEmbeddedConfig baseline
 //  2. config file (JSON/JSONC) from SetConfigPath or auto-discovery
-//  3. .env file from SetEnvPath or auto-discovery (does not override existing OS env)
+//  3. .env files from SetEnvPath/AddEnvPath or auto-discovery (does not override existing OS env;
+//     exported to the process environment unless SetDotEnvExport(false))
 //  4. OS environment variables from `env:"NAME"` tags (non-empty values override)
 //  5. command-line flags from a bound FlagSet (BindConfigFlags) or from SetFlagArgs/os.Args
+//  6. answers to SetSecretPrompt for required secrets that are still empty
 //
-// Returns an error on invalid inputs, I/O, or parsing failures.
+// Returns an error on invalid inputs, I/O, or parsing failures. Values that
+// fail to convert in any layer do not stop the load; they are all reported
+// together as a *MultiError of *FieldError.
 func (a *AntConfig) WriteConfigValues() error {
+	return a.WriteConfigValuesContext(context.Background())
+}
+
+// WriteConfigValuesContext is WriteConfigValues with a context, which is
+// passed to every Source.Load and parents the spans started by SetTracer.
+func (a *AntConfig) WriteConfigValuesContext(ctx context.Context) error {
+	_, err := a.writeConfigValues(ctx)
+	return err
+}
+
+// writeConfigValues runs a load into the registered config and returns the
+// run, or nil when no config is registered.
+func (a *AntConfig) writeConfigValues(ctx context.Context) (*loadRun, error) {
 	if a.cfgRef == nil {
-		return fmt.Errorf("WriteConfigValues requires SetConfig to be called first")
+		return nil, fmt.Errorf("WriteConfigValues requires SetConfig to be called first")
+	}
+	if err := a.applyStandardFlags(); err != nil {
+		return nil, err
 	}
-	c := a.cfgRef
+	if a.validateFlagSet() {
+		return nil, a.validateStandard(ctx)
+	}
+	run, err := a.loadInto(ctx, a.cfgRef)
+	if err != nil {
+		return run, err
+	}
+	return run, a.finishStandardFlags()
+}
+
+// loadInto runs a load into target, a pointer to a value of the registered
+// config type, and records its provenance and leftovers on a.
+func (a *AntConfig) loadInto(ctx context.Context, target any) (*loadRun, error) {
+	registered := target == a.cfgRef
+	if registered {
+		a.checkFrozen()
+	}
+	run := &loadRun{
+		ctx:          ctx,
+		target:       target,
+		lookupOS:     a.osLookup(),
+		exportDotEnv: !a.dotEnvPrivate && a.lookupEnv == nil,
+		prompt:       a.secretPrompt,
+	}
+	err := a.load(run)
+	if a.onWarning != nil {
+		for _, w := range run.warnings {
+			a.onWarning(w)
+		}
+	}
+	if err != nil {
+		return run, err
+	}
+	a.loaded.Store(&loadedState{
+		config:        target,
+		provenance:    run.provenance,
+		remainingArgs: run.remainingArgs,
+		configFile:    run.report.ConfigFile,
+	})
+	a.updateFeatures(target)
+	if a.frozen != nil && registered {
+		a.frozen = a.freezeState()
+	}
+	if err := a.saveSourceCache(run.fresh); err != nil {
+		a.logDebug("source cache not saved", slog.Any("error", err))
+	}
+	if a.onAlias != nil && len(run.aliasUses) > 0 {
+		a.onAlias(run.aliasUses)
+	}
+	return run, nil
+}
+
+// load runs the layered pipeline described on WriteConfigValues against
+// run.target, recording per-field provenance into run.
+func (a *AntConfig) load(run *loadRun) (err error) {
+	defer a.startSpan(run, "antconfig.load")(&err)
+	c := run.target
+	run.provenance = map[string]Layer{}
+	run.parsers = a.parsers
 	// Make sure c is a pointer to a struct
 	if reflect.TypeOf(c).Kind() != reflect.Ptr || reflect.TypeOf(c).Elem().Kind() != reflect.Struct {
 		return fmt.Errorf("expected a pointer to a struct, got %s", reflect.TypeOf(c).Kind())
 	}
 
-	// Set default values based on struct tags
-	fields, err := findFieldsWithTag("default", c)
+	// Collect the tag metadata of all fields once; each layer below selects
+	// the fields it needs from the plan
+	plan, err := newFieldPlan(c)
 	if err != nil {
-		return fmt.Errorf("error finding fields with 'default' tag: %v", err)
+		return fmt.Errorf("error collecting config fields: %v", err)
 	}
-	if err := setDefaultValues(fields); err != nil {
-		return fmt.Errorf("error setting default values: %v", err)
+	run.plan = plan
+	if err := a.lintTags(run, reflect.TypeOf(c).Elem()); err != nil {
+		return err
 	}
+	if run.from, err = fromRules(plan); err != nil {
+		return err
+	}
+	if a.caseInsensitive {
+		run.lookupOS = foldEnvLookup(run.lookupOS, a.osEnvNames)
+	}
+	// Conversion failures are collected across layers and reported together
+	var fieldErrs []*FieldError
+	// noteOverrides logs the fields each layer took over (SetSlog)
+	noteOverrides := a.overrideLogger(run)
 
-	// Merge configuration file (JSON/JSONC) over defaults, if provided
-	if a.configPath != "" {
-		data, err := os.ReadFile(a.configPath)
+	// Registered sources are interleaved with the built-in layers by priority:
+	// applySources(p) applies every pending source whose priority is below p.
+	pending := a.sortedSources()
+	if a.sourceTimeout > 0 {
+		ctx, cancel := context.WithTimeout(run.context(), a.sourceTimeout)
+		defer cancel()
+		run.ctx = ctx
+	}
+	// With SetConcurrentSources, every source is loaded up front and only
+	// applied below
+	var prefetched []sourceValues
+	if a.concurrentSources && len(pending) > 1 {
+		if prefetched, err = a.loadSources(run.context(), pending); err != nil {
+			return err
+		}
+	}
+	// declared are the sources of the config file's "$sources", applied
+	// after registered ones of equal priority
+	var declared []prioritizedSource
+	declaredNext := func(below Priority) bool {
+		return len(declared) > 0 && declared[0].priority < below && (len(pending) == 0 || declared[0].priority < pending[0].priority)
+	}
+	applySources := func(below Priority) error {
+		for declaredNext(below) || len(pending) > 0 && pending[0].priority < below {
+			if declaredNext(below) {
+				errs, err := a.applySource(run, declared[0])
+				if err != nil {
+					return err
+				}
+				fieldErrs = append(fieldErrs, errs...)
+				a.logSource(declared[0])
+				noteOverrides()
+				declared = declared[1:]
+				continue
+			}
+			if prefetched != nil {
+				fieldErrs = append(fieldErrs, a.applySourceValues(run, pending[0], prefetched[0])...)
+				prefetched = prefetched[1:]
+			} else {
+				errs, err := a.applySource(run, pending[0])
+				if err != nil {
+					return err
+				}
+				fieldErrs = append(fieldErrs, errs...)
+			}
+			a.logSource(pending[0])
+			noteOverrides()
+			pending = pending[1:]
+		}
+		return nil
+	}
+	if err := applySources(PriorityDefault); err != nil {
+		return err
+	}
+	fieldErrs = append(fieldErrs, setDefaultValues(plan.withTag("default"), run.parsers, run.provenance)...)
+	noteOverrides()
+	if a.embedded != nil {
+		errs, err := a.applyEmbeddedConfig(run)
 		if err != nil {
-			return fmt.Errorf("error reading config file %s: %w", a.configPath, err)
+			return err
 		}
-		js := ToJSON(data)
-		if err := json.Unmarshal(js, c); err != nil {
-			return fmt.Errorf("error parsing config file %s: %w", a.configPath, err)
+		fieldErrs = append(fieldErrs, errs...)
+		run.report.EmbeddedConfig = true
+		noteOverrides()
+	}
+	if err := applySources(PriorityFile); err != nil {
+		return err
+	}
+
+	if err := a.checkMode(); err != nil {
+		return err
+	}
+	// Merge configuration file (JSON/JSONC) over defaults, if provided.
+	// doc keeps the generic form of the file for key-usage checks.
+	var doc map[string]any
+	if a.configPath != "" && !a.noConfigFile {
+		data, err := a.files().ReadFile(a.configPath)
+		switch {
+		case errors.Is(err, fs.ErrNotExist) && a.configOptional:
+			if a.configExisted {
+				run.warn(WarningMissingFile, a.configPath, LayerFile, "config file "+a.configPath+" was removed and is skipped")
+			}
+		case errors.Is(err, fs.ErrNotExist) && a.configExisted:
+			return fmt.Errorf("error reading config file: %w after it was set: %s", ErrConfigRemoved, a.configPath)
+		case errors.Is(err, fs.ErrNotExist):
+			return fmt.Errorf("error reading config file: %w: %s", ErrConfigNotFound, a.configPath)
+		case err != nil:
+			return fmt.Errorf("error reading config file %s: %w", a.configPath, err)
+		default:
+			var errs []*FieldError
+			if doc, errs, err = a.applyConfigFile(run, a.configPath, data, "config file"); err != nil {
+				return err
+			}
+			fieldErrs = append(fieldErrs, errs...)
+			run.report.ConfigFile = a.configPath
 		}
 	} else {
-		// Auto-discover config file from working directory upwards
-		// Try common names in order
-		candidates := []string{"config.jsonc", "config.json"}
-		for _, name := range candidates {
-			if path, err := LocateFromWorkingDirUp(name); err == nil && path != "" {
-				if data, rerr := os.ReadFile(path); rerr == nil {
-					js := ToJSON(data)
-					if uerr := json.Unmarshal(js, c); uerr != nil {
-						return fmt.Errorf("error parsing discovered config %s: %w", path, uerr)
-					}
+		// Auto-discover config file (working directory upwards by default)
+		// Try common names in order, then registered formats
+		if path := a.discover(); path != "" {
+			if data, rerr := a.files().ReadFile(path); rerr == nil {
+				var errs []*FieldError
+				var err error
+				if doc, errs, err = a.applyConfigFile(run, path, data, "discovered config"); err != nil {
+					return err
 				}
-				break
+				fieldErrs = append(fieldErrs, errs...)
+				run.report.ConfigFile, run.report.ConfigDiscovered = path, true
 			}
 		}
 	}
 
+	if run.report.ConfigFile != "" {
+		a.logDebug("config file", slog.String("path", run.report.ConfigFile), slog.Bool("discovered", run.report.ConfigDiscovered))
+		noteOverrides()
+	}
+	if doc != nil {
+		if declared, err = a.fileSources(doc, reflect.TypeOf(c).Elem(), run.report.ConfigFile); err != nil {
+			return fmt.Errorf("error in config file %s: %w", run.report.ConfigFile, err)
+		}
+	}
+
 	// Process environment variables based on .env file
 
-	// Load .env file into process environment if configured, otherwise auto-discover in CWD.
-	// .env is lower priority than explicit env variables.
-	if a.envPath != "" {
-		if err := loadDotEnv(a.envPath); err != nil {
-			return fmt.Errorf("error loading .env file: %w", err)
-		}
-	} else {
-		if wd, err := os.Getwd(); err == nil {
-			candidate := filepath.Join(wd, ".env")
-			if _, statErr := os.Stat(candidate); statErr == nil {
-				if err := loadDotEnv(candidate); err != nil {
-					return fmt.Errorf("error loading discovered .env file: %w", err)
-				}
+	// Load .env files if configured, otherwise auto-discover in CWD. Values are
+	// collected into dotenv and, unless disabled via SetDotEnvExport(false),
+	// exported to the process environment. .env is lower priority than
+	// explicit env variables.
+	dotenv := map[string]string{}
+	if len(a.envPaths) > 0 && !a.noDotEnv {
+		for _, p := range a.envPaths {
+			if err := loadDotEnv(a.files(), p, dotenv, run.lookupOS); err != nil {
+				return fmt.Errorf("error loading .env file: %w", err)
 			}
+			run.report.EnvFiles = append(run.report.EnvFiles, p)
+		}
+	} else if candidate, ok := a.discoverDotEnv(); ok {
+		if err := loadDotEnv(a.files(), candidate, dotenv, run.lookupOS); err != nil {
+			return fmt.Errorf("error loading discovered .env file: %w", err)
 		}
+		run.report.EnvFiles, run.report.EnvFileDiscovered = []string{candidate}, true
+	}
+	for _, p := range run.report.EnvFiles {
+		a.logDebug(".env file", slog.String("path", p), slog.Bool("discovered", run.report.EnvFileDiscovered))
+	}
+	if run.exportDotEnv {
+		a.exportDotEnv(dotenv)
+	}
+	// .env keys only exist in dotenv when the OS environment lacked them, so
+	// checking dotenv first attributes exported values to the .env layer.
+	dotenvLookup := func(key string) (string, bool) {
+		v, ok := dotenv[key]
+		return v, ok
+	}
+	if a.caseInsensitive {
+		dotenvLookup = foldEnvLookup(dotenvLookup, func() []string { return mapKeys(dotenv) })
+	}
+	lookupEnv := func(key string) (string, Layer, bool) {
+		if v, ok := dotenvLookup(key); ok {
+			return v, LayerDotEnv, true
+		}
+		v, ok := run.lookupOS(key)
+		return v, LayerEnv, ok
+	}
+	lookupDotEnvOnly := func(key string) (string, Layer, bool) {
+		v, ok := dotenvLookup(key)
+		return v, LayerDotEnv, ok
+	}
+	lookupOSOnly := func(key string) (string, Layer, bool) {
+		if _, ok := dotenvLookup(key); ok {
+			return "", LayerEnv, false
+		}
+		v, ok := run.lookupOS(key)
+		return v, LayerEnv, ok
 	}
 
-	// Process environment variables based on system environment
-	fields, err = findFieldsWithTag("env", c)
-	if err != nil {
-		return fmt.Errorf("error finding fields with 'env' tag: %v", err)
+	// Process environment variables: .env values first, then the OS environment
+	fields := plan.withTag("env")
+	if err := applySources(PriorityDotEnv); err != nil {
+		return err
 	}
 	if len(fields) > 0 {
-		if err := processEnvironment(fields); err != nil {
-			return fmt.Errorf("error processing environment variables: %v", err)
-		}
+		fieldErrs = append(fieldErrs, processEnvironment(fields, lookupDotEnvOnly, run)...)
+		noteOverrides()
+	}
+	if err := applySources(PriorityEnv); err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		fieldErrs = append(fieldErrs, processEnvironment(fields, lookupOSOnly, run)...)
+		noteOverrides()
+	}
+	if err := applySources(PriorityFlag); err != nil {
+		return err
 	}
 
 	// Process command-line flag overrides (highest precedence)
-	flagFields, err := findFieldsWithTag("flag", c)
-	if err != nil {
-		return fmt.Errorf("error finding fields with 'flag' tag: %v", err)
-	}
-	if len(flagFields) > 0 {
-		var values map[string]*string
-		if a.flagSet != nil {
-			values = map[string]*string{}
-			a.flagSet.Visit(func(f *flag.Flag) {
-				v := f.Value.String()
+	flagFields := plan.withTag("flag")
+	var values map[string]*string
+	var native map[string]flag.Value
+	if run.skipFlags {
+		values = map[string]*string{}
+	} else if a.flagSet != nil {
+		values = map[string]*string{}
+		native = map[string]flag.Value{}
+		a.flagSet.Visit(func(f *flag.Flag) {
+			if raw, ok := a.flagRaw[f.Name]; ok {
+				// flag.Func values have no string form; use the recorded input
+				v := *raw
 				values[f.Name] = &v
-			})
-		} else {
-			args := a.flagArgs
-			if len(args) == 0 && len(os.Args) > 1 {
-				args = os.Args[1:]
+				return
+			}
+			v := f.Value.String()
+			values[f.Name] = &v
+			native[f.Name] = f.Value
+		})
+	} else {
+		args := a.flagArgs
+		if len(args) == 0 && len(os.Args) > 1 {
+			args = os.Args[1:]
+		}
+		args, err := a.expandArgs(args)
+		if err != nil {
+			return err
+		}
+		boolFlags := map[string]bool{}
+		for _, f := range flagFields {
+			if isBoolField(f.fieldValue.Type()) {
+				boolFlags[a.flagPrefix+f.tagvalue] = true
 			}
-			values = parseArgsToFlagMap(args, a.flagPrefix)
 		}
-		if err := assignFlagsFromMap(flagFields, values, a.flagPrefix); err != nil {
-			return fmt.Errorf("error processing flags: %v", err)
+		values, run.remainingArgs = parseArgsToFlagMap(args, a.flagPrefix, boolFlags)
+	}
+	// Hypothetical flag values (Preview) win over parsed ones
+	for name, v := range run.flagOverrides {
+		v := v
+		values[name] = &v
+		delete(native, name)
+		if k, ok := strings.CutPrefix(name, a.flagPrefix); ok && a.flagPrefix != "" {
+			values[k] = &v
+			delete(native, k)
 		}
 	}
+	if a.caseInsensitive && a.flagSet == nil {
+		foldFlagValues(flagFields, values, a.flagPrefix)
+	}
+	fieldErrs = append(fieldErrs, assignFlagsFromMap(run, flagFields, values, native, a.flagPrefix)...)
+	noteOverrides()
+	if err := applySources(math.MaxInt); err != nil {
+		return err
+	}
+	// Optional sections only default-filled by now are dropped again
+	fieldErrs = append(fieldErrs, resolveSections(run, doc, lookupEnv)...)
+	fieldErrs = append(fieldErrs, a.resolveValues(run)...)
+	fieldErrs = append(fieldErrs, a.resolvePaths(run)...)
+	fieldErrs = append(fieldErrs, a.loadFiles(run)...)
+	if run.prompt != nil {
+		fieldErrs = append(fieldErrs, a.promptSecrets(run)...)
+	}
+	fieldErrs = append(fieldErrs, a.checkRequired(withoutSections(plan.withTag("required"), run.cleared))...)
+	fieldErrs = append(fieldErrs, a.checkConstraints(run)...)
+	if len(fieldErrs) > 0 {
+		return &MultiError{Errors: fieldErrs}
+	}
 
+	// Enforce removed_in deprecation deadlines against the keys actually used
+	if err := a.checkRemovedKeys(run, doc, values, lookupEnv); err != nil {
+		return err
+	}
+
+	if a.postLoad != nil {
+		if err := a.postLoad(run.target); err != nil {
+			return fmt.Errorf("post-load hook: %w", err)
+		}
+	}
 	return nil
 }
 
@@ -366,8 +936,7 @@ func (a *AntConfig) WriteConfigValues() error {
 func LocateFromExeUp(filename string) (string, error) {
 	exePath, err := os.Executable()
 	if err != nil {
-		fmt.Printf("Error getting executable path: %v\n", err)
-		return "", err
+		return "", fmt.Errorf("locating %s: error getting executable path: %w", filename, err)
 	}
 	return searchUpwards(filepath.Dir(exePath), filename)
 }
@@ -378,12 +947,41 @@ func LocateFromExeUp(filename string) (string, error) {
 func LocateFromWorkingDirUp(filename string) (string, error) {
 	wd, err := os.Getwd()
 	if err != nil {
-		fmt.Printf("Error getting working directory: %v\n", err)
-		return "", err
+		return "", fmt.Errorf("locating %s: error getting working directory: %w", filename, err)
 	}
 	return searchUpwards(wd, filename)
 }
 
+// LocateFromUserConfig searches for filename in the conventional per-user and
+// system configuration directories for appName, in this order:
+// $XDG_CONFIG_HOME/appName, ~/.config/appName, %APPDATA%\appName, and
+// /etc/appName. Directories whose base is unset are skipped. Returns the first
+// match or ErrConfigNotFound.
+func LocateFromUserConfig(appName, filename string) (string, error) {
+	for _, dir := range userConfigDirs(appName) {
+		candidate := filepath.Join(dir, filename)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %s", ErrConfigNotFound, filename)
+}
+
+// userConfigDirs lists the candidate directories used by LocateFromUserConfig.
+func userConfigDirs(appName string) []string {
+	var dirs []string
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		dirs = append(dirs, filepath.Join(xdg, appName))
+	}
+	if home, err := os.UserHomeDir(); err == nil && home != "" {
+		dirs = append(dirs, filepath.Join(home, ".config", appName))
+	}
+	if appData := os.Getenv("APPDATA"); appData != "" {
+		dirs = append(dirs, filepath.Join(appData, appName))
+	}
+	return append(dirs, filepath.Join("/etc", appName))
+}
+
 func searchUpwards(path, configFile string) (string, error) {
 	maxLevels := 10
 	for i := 0; i < maxLevels; i++ {
@@ -406,15 +1004,67 @@ type fieldWithTagValue struct {
 	tagvalue   string
 	// tags holds commonly used tag values for this field (e.g., "default",
 	// "env", "flag", "desc"). The requested tag's value is also
-	// accessible via tagvalue for convenience.
+	// accessible via tagvalue for convenience. "group" is inherited from
+	// enclosing struct fields when the field has no group tag of its own.
 	tags map[string]string
+	// path is the dotted Go field path from the config root (e.g., "Database.Host").
+	path string
+	// jsonPath holds the config file keys leading to this field; nil when the
+	// field cannot be set from a config file (json:"-").
+	jsonPath []string
+	// tag is the field's complete struct tag.
+	tag reflect.StructTag
+	// root is the config struct and index the field indices leading from it
+	// to this field; together they locate the field for writing (see target).
+	root  reflect.Value
+	index []int
+}
+
+// target returns the settable field, first allocating any nil struct
+// pointers on the way from the config root. Call it only when a value is
+// actually written, so unconfigured optional sub-structs stay nil.
+func (f fieldWithTagValue) target() reflect.Value {
+	if !f.root.IsValid() {
+		return f.fieldValue
+	}
+	v := f.root
+	for _, i := range f.index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// current returns the field's present value without allocating, or the zero
+// value of its type when it lies under a nil struct pointer.
+func (f fieldWithTagValue) current() reflect.Value {
+	if !f.root.IsValid() {
+		return f.fieldValue
+	}
+	v := f.root
+	for _, i := range f.index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Zero(f.fieldValue.Type())
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
 }
 
-// findFieldsWithTag returns a slice of fieldWithTagValue containing settable
-// reflect.Value instances for fields with the specified tag. It correctly
-// traverses nested structs, including those that are nil pointers.
+// findFieldsWithTag returns a slice of fieldWithTagValue describing fields
+// with the specified tag, or every field when tagname is "". It traverses
+// nested structs, including nil pointers to structs, without allocating
+// them: fields under a nil pointer report the type and zero value of a
+// detached instance, and target allocates the path when a value is written.
 func findFieldsWithTag(tagname string, s any) ([]fieldWithTagValue, error) {
-	var fields []fieldWithTagValue
 	v := reflect.ValueOf(s)
 
 	// If s is not a pointer to a struct, it's an error because we can't set fields.
@@ -427,209 +1077,197 @@ func findFieldsWithTag(tagname string, s any) ([]fieldWithTagValue, error) {
 	if v.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("expected a pointer to a struct, but it points to %s", v.Kind())
 	}
+	return findFieldsWithTagAt(tagname, v, v, nil, "", []string{}, ""), nil
+}
 
+// findFieldsWithTagAt is findFieldsWithTag for the struct v nested under root
+// at the given field indices, Go field path, and config file key path. group
+// is the `group` tag inherited from the enclosing struct fields.
+func findFieldsWithTagAt(tagname string, v, root reflect.Value, index []int, prefix string, jsonPrefix []string, group string) []fieldWithTagValue {
+	var fields []fieldWithTagValue
 	t := v.Type()
 	for i := 0; i < t.NumField(); i++ {
 		fieldValue := v.Field(i)
 		fieldType := t.Field(i)
 
 		// We can only process settable (i.e., exported) fields.
-		if !fieldValue.CanSet() {
+		if !fieldValue.CanSet() || isIgnored(fieldType) {
 			continue
 		}
+		path, jsonPath := childPaths(fieldType, prefix, jsonPrefix)
+		fieldIndex := append(append(make([]int, 0, len(index)+1), index...), i)
+		fieldGroup := group
+		if g := fieldType.Tag.Get("group"); g != "" {
+			fieldGroup = g
+		}
 
 		// --- Recursion Logic ---
 		// Recurse into nested structs (passed by value).
-		// We pass the address to ensure fields within it remain settable.
-		if fieldValue.Kind() == reflect.Struct && fieldValue.CanAddr() {
-			nestedFields, err := findFieldsWithTag(tagname, fieldValue.Addr().Interface())
-			if err != nil {
-				return nil, err
-			}
-			fields = append(fields, nestedFields...)
+		if fieldValue.Kind() == reflect.Struct && !isOpaqueStruct(fieldValue.Type()) {
+			fields = append(fields, findFieldsWithTagAt(tagname, fieldValue, root, fieldIndex, path, jsonPath, fieldGroup)...)
 		}
 
-		// Recurse into nested pointers to structs.
-		if fieldValue.Kind() == reflect.Ptr && fieldValue.Type().Elem().Kind() == reflect.Struct {
-			// If the pointer is nil, create a new struct instance for it.
-			if fieldValue.IsNil() {
-				fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+		// Recurse into nested pointers to structs. A nil pointer is walked
+		// through a detached instance and left nil.
+		if fieldValue.Kind() == reflect.Ptr && fieldValue.Type().Elem().Kind() == reflect.Struct && !isOpaqueStruct(fieldValue.Type().Elem()) {
+			elem := reflect.New(fieldValue.Type().Elem()).Elem()
+			if !fieldValue.IsNil() {
+				elem = fieldValue.Elem()
 			}
-			nestedFields, err := findFieldsWithTag(tagname, fieldValue.Interface())
-			if err != nil {
-				return nil, err
-			}
-			fields = append(fields, nestedFields...)
+			fields = append(fields, findFieldsWithTagAt(tagname, elem, root, fieldIndex, path, jsonPath, fieldGroup)...)
 		}
 
 		// --- Tag Processing ---
 		// After recursion, process the tag on the current field.
-		if tagValue := fieldType.Tag.Get(tagname); tagValue != "" {
+		if tagValue := fieldType.Tag.Get(tagname); tagValue != "" || tagname == "" {
 			tags := map[string]string{
-				"default": fieldType.Tag.Get("default"),
-				"env":     fieldType.Tag.Get("env"),
-				"flag":    fieldType.Tag.Get("flag"),
-				"desc":    fieldType.Tag.Get("desc"),
+				"default":    fieldType.Tag.Get("default"),
+				"env":        fieldType.Tag.Get("env"),
+				"flag":       fieldType.Tag.Get("flag"),
+				"desc":       fieldType.Tag.Get("desc"),
+				"removed_in": fieldType.Tag.Get("removed_in"),
+				"layout":     fieldType.Tag.Get("layout"),
+				"envalias":   fieldType.Tag.Get("envalias"),
+				"required":   fieldType.Tag.Get("required"),
+				"secret":     fieldType.Tag.Get("secret"),
+				"group":      fieldGroup,
 			}
 			fields = append(fields, fieldWithTagValue{
 				fieldValue: fieldValue,
 				tagvalue:   tagValue,
 				tags:       tags,
+				path:       path,
+				jsonPath:   jsonPath,
+				tag:        fieldType.Tag,
+				root:       root,
+				index:      fieldIndex,
 			})
 		}
 	}
 
-	return fields, nil
+	return fields
 }
 
-// processEnvironment retrieves the environment variable using the tag value, converts
-// it to the correct type, and sets the struct field.
-func processEnvironment(fieldList []fieldWithTagValue) error {
-	for _, row := range fieldList {
-		envValStr := os.Getenv(row.tagvalue)
-		if envValStr == "" {
-			continue
-		}
-
-		fieldVal := row.fieldValue
-		if !fieldVal.CanSet() {
-			continue
-		}
-		parseCtx := fmt.Sprintf("env var '%s' ('%s')", row.tagvalue, envValStr)
-		unsupportedCtx := fmt.Sprintf("env var '%s'", row.tagvalue)
-		if err := setFieldFromString(fieldVal, envValStr, parseCtx, unsupportedCtx, true); err != nil {
-			return err
+// childPaths returns the Go field path and config file key path for a field
+// nested under prefix/jsonPrefix. Embedded structs without a json name are
+// flattened, mirroring encoding/json. A nil jsonPrefix propagates (json:"-").
+func childPaths(f reflect.StructField, prefix string, jsonPrefix []string) (string, []string) {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	path := prefix
+	if !(f.Anonymous && name == "") {
+		if path != "" {
+			path += "."
 		}
+		path += f.Name
 	}
-	return nil
+	if jsonPrefix == nil || name == "-" {
+		return path, nil
+	}
+	if f.Anonymous && name == "" {
+		return path, jsonPrefix
+	}
+	if name == "" {
+		name = f.Name
+	}
+	jsonPath := make([]string, len(jsonPrefix), len(jsonPrefix)+1)
+	copy(jsonPath, jsonPrefix)
+	return path, append(jsonPath, name)
 }
 
-// process defaultValues sets default values for fields that have a 'default' tag.
-func setDefaultValues(fieldList []fieldWithTagValue) error {
+// processEnvironment retrieves the environment variable using the tag value via
+// lookup, converts it to the correct type, and sets the struct field. Values
+// that fail to convert are reported as FieldErrors; processing continues.
+func processEnvironment(fieldList []fieldWithTagValue, lookup func(string) (string, Layer, bool), run *loadRun) []*FieldError {
+	var errs []*FieldError
 	for _, row := range fieldList {
-		if row.tagvalue == "" {
-			continue
+		name := row.tagvalue
+		envValStr, layer, _ := lookup(name)
+		if envValStr == "" {
+			// Fall back to the old names of a renamed variable
+			for _, old := range aliasNames(row.tags["envalias"]) {
+				if v, l, _ := lookup(old); v != "" {
+					run.aliasUses = append(run.aliasUses, AliasUse{Path: row.path, Old: old, New: name, Source: l})
+					run.warn(WarningDeprecated, row.path, l, fmt.Sprintf("env var %s is deprecated; use %s", old, name))
+					name, envValStr, layer = old, v, l
+					break
+				}
+			}
 		}
-		fieldVal := row.fieldValue
-		if !fieldVal.CanSet() {
+		if envValStr == "" {
 			continue
 		}
-		ctx := fmt.Sprintf("default value '%s'", row.tagvalue)
-		if err := setFieldFromString(fieldVal, row.tagvalue, ctx, ctx, true); err != nil {
-			return err
+		if fe := run.refuse(row.path, layer); fe != nil {
+			errs = append(errs, fe)
+			continue
 		}
-	}
-	return nil
-}
-
-// (moved) ListFlags and FlagSpec are defined above the writer for clarity.
 
-// loadDotEnv parses a .env-like file and sets process environment variables
-// for keys that are not already explicitly present in the environment.
-// This ensures precedence: defaults < .env < OS env < flags.
-func loadDotEnv(path string) error {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		// Only return error if the path was set but unreadable; caller controls existence.
-		return err
-	}
-	lines := strings.Split(string(data), "\n")
-	for _, raw := range lines {
-		line := strings.TrimSpace(raw)
-		if line == "" || strings.HasPrefix(line, "#") {
+		parseCtx := fmt.Sprintf("env var '%s' ('%s')", name, envValStr)
+		unsupportedCtx := fmt.Sprintf("env var '%s'", name)
+		if err := setRowFromString(row, envValStr, parseCtx, unsupportedCtx, true, run.parsers); err != nil {
+			if errors.Is(err, errValueIgnored) {
+				run.warn(WarningIgnoredValue, row.path, layer, fmt.Sprintf("env var %s ignored: %s fields cannot be set from env", name, row.fieldValue.Type()))
+				continue
+			}
+			errs = append(errs, &FieldError{Path: row.path, Source: layer, Raw: envValStr, Err: err})
 			continue
 		}
-		// Optional "export " prefix
-		if strings.HasPrefix(line, "export ") {
-			line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		run.provenance[row.path] = layer
+		if layer == LayerDotEnv {
+			run.report.DotEnvVars++
+		} else {
+			run.report.EnvVars++
 		}
-		// Split at first '='
-		eq := strings.IndexByte(line, '=')
-		if eq <= 0 { // no '=', or empty key
+	}
+	return errs
+}
+
+// setDefaultValues sets default values for fields that have a 'default' tag,
+// expanding $(name) function references (RegisterDefaultFunc) first, and
+// reporting malformed defaults as FieldErrors.
+func setDefaultValues(fieldList []fieldWithTagValue, parsers typeParsers, prov map[string]Layer) []*FieldError {
+	var errs []*FieldError
+	for _, row := range fieldList {
+		// `default:"nil"` marks an optional section (isOptionalSection)
+		if row.tagvalue == "" || row.tagvalue == "nil" && isOptionalSection(row) {
 			continue
 		}
-		key := strings.TrimSpace(line[:eq])
-		val := strings.TrimSpace(line[eq+1:])
-		if key == "" {
+		def, err := expandDefault(row.tagvalue)
+		if err != nil {
+			errs = append(errs, &FieldError{Path: row.path, Source: LayerDefault, Raw: row.tagvalue, Err: err})
 			continue
 		}
-		// Handle quoted values; for double quotes, unescape common sequences
-		if len(val) >= 2 && ((val[0] == '"' && val[len(val)-1] == '"') || (val[0] == '\'' && val[len(val)-1] == '\'')) {
-			quote := val[0]
-			inner := val[1 : len(val)-1]
-			if quote == '"' {
-				inner = unescapeDoubleQuoted(inner)
-			}
-			val = inner
-		} else {
-			// For unquoted values, strip trailing inline comment if preceded by whitespace
-			if hash := strings.IndexByte(val, '#'); hash >= 0 {
-				// Keep portion before '#', but only if there's whitespace before '#'
-				trimmed := strings.TrimRightFunc(val[:hash], func(r rune) bool { return r == ' ' || r == '\t' })
-				if len(trimmed) < len(val[:hash]) {
-					val = strings.TrimSpace(trimmed)
-				}
-			}
-		}
-		if _, exists := os.LookupEnv(key); exists {
-			// Do not override explicit env
-			continue
+		ctx := fmt.Sprintf("default value '%s'", row.tagvalue)
+		if def != row.tagvalue {
+			ctx = fmt.Sprintf("default value '%s' ('%s')", row.tagvalue, def)
 		}
-		_ = os.Setenv(key, val)
-	}
-	return nil
-}
-
-// unescapeDoubleQuoted handles a minimal set of escape sequences within a double-quoted .env value.
-func unescapeDoubleQuoted(s string) string {
-	// Replace common escapes: \\ \n \r \t \" and \$
-	// We avoid strconv.Unquote to keep control and avoid treating backticks, etc.
-	var b strings.Builder
-	b.Grow(len(s))
-	for i := 0; i < len(s); i++ {
-		c := s[i]
-		if c == '\\' && i+1 < len(s) {
-			i++
-			switch s[i] {
-			case 'n':
-				b.WriteByte('\n')
-				continue
-			case 'r':
-				b.WriteByte('\r')
-				continue
-			case 't':
-				b.WriteByte('\t')
-				continue
-			case '"':
-				b.WriteByte('"')
-				continue
-			case '\\':
-				b.WriteByte('\\')
-				continue
-			case '$':
-				b.WriteByte('$')
-				continue
-			default:
-				// Unknown escape, keep literally the escaped char
-				b.WriteByte(s[i])
+		if err := setRowFromString(row, def, ctx, ctx, true, parsers); err != nil {
+			if errors.Is(err, errValueIgnored) {
 				continue
 			}
+			errs = append(errs, &FieldError{Path: row.path, Source: LayerDefault, Raw: row.tagvalue, Err: err})
+			continue
 		}
-		b.WriteByte(c)
+		prov[row.path] = LayerDefault
 	}
-	return b.String()
+	return errs
 }
 
-// assignFlagsFromMap applies parsed flag values to the struct fields.
-func assignFlagsFromMap(fieldList []fieldWithTagValue, values map[string]*string, prefix string) error {
+// assignFlagsFromMap applies parsed flag values to the struct fields,
+// reporting values that fail to convert as FieldErrors. native optionally
+// holds the flag.Value of each parsed flag of a bound FlagSet; those are
+// honored before falling back to string conversion. Provenance is recorded
+// into run.
+func assignFlagsFromMap(run *loadRun, fieldList []fieldWithTagValue, values map[string]*string, native map[string]flag.Value, prefix string) []*FieldError {
+	var errs []*FieldError
 	for _, row := range fieldList {
 		name := row.tagvalue
 		// Prefer exact match by logical name; if not found, check prefixed form
+		key := name
 		valPtr, ok := values[name]
 		if !ok || valPtr == nil {
 			if prefix != "" {
 				if v2, ok2 := values[prefix+name]; ok2 && v2 != nil {
-					valPtr = v2
+					key, valPtr = prefix+name, v2
 				} else {
 					continue
 				}
@@ -638,36 +1276,59 @@ func assignFlagsFromMap(fieldList []fieldWithTagValue, values map[string]*string
 			}
 		}
 		val := *valPtr
-
-		fieldVal := row.fieldValue
-		if !fieldVal.CanSet() {
+		if fe := run.refuse(row.path, LayerFlag); fe != nil {
+			errs = append(errs, fe)
 			continue
 		}
 
+		if fv, ok := native[key]; ok {
+			if handled, err := assignFlagValue(row.target(), fv); handled {
+				if err != nil {
+					errs = append(errs, &FieldError{Path: row.path, Source: LayerFlag, Raw: val,
+						Err: fmt.Errorf("could not apply flag --%s=%q: %w", name, val, err)})
+					continue
+				}
+				run.provenance[row.path] = LayerFlag
+				run.report.Flags++
+				continue
+			}
+		}
+
 		// For flags, do not ignore unsupported slice types
 		parseCtx := fmt.Sprintf("flag --%s=%q", name, val)
 		unsupportedCtx := fmt.Sprintf("flag --%s", name)
-		if err := setFieldFromString(fieldVal, val, parseCtx, unsupportedCtx, false); err != nil {
-			return err
+		if err := setRowFromString(row, val, parseCtx, unsupportedCtx, false, run.parsers); err != nil {
+			errs = append(errs, &FieldError{Path: row.path, Source: LayerFlag, Raw: val, Err: err})
+			continue
 		}
+		run.provenance[row.path] = LayerFlag
+		run.report.Flags++
 	}
-	return nil
+	return errs
 }
 
 // parseArgsToFlagMap builds a map of flag name -> value string pointer by parsing
-// args. It supports --name=value, --name value, and presence-only booleans.
-// If a prefix is configured, de-prefixed keys are also included.
-func parseArgsToFlagMap(args []string, prefix string) map[string]*string {
+// args, and returns the positional arguments it did not consume. It supports
+// --name=value, --name value, and presence-only booleans; a single dash works
+// like a double one (-name, as with the flag package; no POSIX grouping of
+// single-letter flags). A lone "-" and negative numbers such as "-5" are not
+// flags, and "--" ends flag parsing: everything after it is positional.
+// If a prefix is configured, de-prefixed keys are also included. boolFlags
+// holds the (prefixed) names of boolean flags, which also accept the negated
+// form --no-name (or --no-name=true) as --name=false; later arguments win.
+// A boolean flag only takes the following argument as its value when that is
+// "true" or "false", so "--verbose input.txt" leaves input.txt positional.
+func parseArgsToFlagMap(args []string, prefix string, boolFlags map[string]bool) (map[string]*string, []string) {
 	values := map[string]*string{}
-	if len(args) == 0 {
-		return values
-	}
+	var rest []string
 	for i := 0; i < len(args); i++ {
 		a := args[i]
-		if len(a) == 0 {
-			continue
+		if a == "--" {
+			rest = append(rest, args[i+1:]...)
+			break
 		}
-		if !(len(a) >= 2 && a[0] == '-') {
+		if !isFlagArg(a) {
+			rest = append(rest, a)
 			continue
 		}
 		// strip leading dashes
@@ -676,19 +1337,22 @@ func parseArgsToFlagMap(args []string, prefix string) map[string]*string {
 			j++
 		}
 		keyAndMaybe := a[j:]
-		if keyAndMaybe == "" {
-			continue
-		}
 		key := keyAndMaybe
 		var valStr *string
-		if eq := strings.IndexByte(keyAndMaybe, '='); eq >= 0 {
+		if name, v, ok := negatedBoolFlag(keyAndMaybe, boolFlags); ok {
+			key, valStr = name, &v
+		} else if eq := strings.IndexByte(keyAndMaybe, '='); eq >= 0 {
 			key = keyAndMaybe[:eq]
 			v := keyAndMaybe[eq+1:]
 			valStr = &v
 		} else {
-			if i+1 < len(args) && !(len(args[i+1]) > 0 && args[i+1][0] == '-') {
-				v := args[i+1]
-				valStr = &v
+			next := ""
+			hasNext := i+1 < len(args) && args[i+1] != "--" && !isFlagArg(args[i+1])
+			if hasNext {
+				next = args[i+1]
+			}
+			if hasNext && (!boolFlags[key] || next == "true" || next == "false") {
+				valStr = &next
 				i++
 			} else {
 				t := "true"
@@ -703,15 +1367,35 @@ func parseArgsToFlagMap(args []string, prefix string) map[string]*string {
 			}
 		}
 	}
-	return values
+	return values, rest
+}
+
+// isFlagArg reports whether a command-line argument is a flag: it starts
+// with a dash but is not "-" alone, a run of dashes, or a negative number.
+func isFlagArg(a string) bool {
+	if len(a) < 2 || a[0] != '-' || strings.Trim(a, "-") == "" {
+		return false
+	}
+	if _, err := strconv.ParseFloat(a, 64); err == nil {
+		return false
+	}
+	return true
 }
 
 // setFieldFromString converts the provided string to the type of fieldVal and sets it.
 // parseCtx is used in parse error messages (e.g., "flag --name=\"val\"").
 // unsupportedCtx is used for unsupported type errors (e.g., "flag --name").
 // If ignoreNonIntSlice is true, slices whose element type is not int are ignored
-// (used for defaults/env). When false, an error is returned (used for flags).
-func setFieldFromString(fieldVal reflect.Value, s string, parseCtx, unsupportedCtx string, ignoreNonIntSlice bool) error {
+// with an error wrapping errValueIgnored (used for defaults/env). When false, a
+// plain error is returned (used for flags).
+// parsers are per-instance parsers consulted before all other conversions.
+func setFieldFromString(fieldVal reflect.Value, s string, parseCtx, unsupportedCtx string, ignoreNonIntSlice bool, parsers typeParsers) error {
+	if handled, err := setFromTextParser(fieldVal, s, parsers); handled {
+		if err != nil {
+			return fmt.Errorf("could not parse %s to %s: %w", parseCtx, fieldVal.Type(), err)
+		}
+		return nil
+	}
 	switch fieldVal.Kind() {
 	case reflect.String:
 		fieldVal.SetString(s)
@@ -723,7 +1407,7 @@ func setFieldFromString(fieldVal reflect.Value, s string, parseCtx, unsupportedC
 		}
 		fieldVal.SetInt(iv)
 		return nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
 		uv, err := strconv.ParseUint(s, 10, fieldVal.Type().Bits())
 		if err != nil {
 			return fmt.Errorf("could not parse %s to uint: %w", parseCtx, err)
@@ -744,17 +1428,33 @@ func setFieldFromString(fieldVal reflect.Value, s string, parseCtx, unsupportedC
 		}
 		fieldVal.SetFloat(fv)
 		return nil
+	case reflect.Ptr:
+		// Pointer fields such as *big.Int are allocated and parsed as their element
+		elem := reflect.New(fieldVal.Type().Elem())
+		if err := setFieldFromString(elem.Elem(), s, parseCtx, unsupportedCtx, ignoreNonIntSlice, parsers); err != nil {
+			return err
+		}
+		fieldVal.Set(elem)
+		return nil
+	case reflect.Complex64, reflect.Complex128:
+		cv, err := strconv.ParseComplex(s, fieldVal.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("could not parse %s to complex: %w", parseCtx, err)
+		}
+		fieldVal.SetComplex(cv)
+		return nil
 	case reflect.Slice:
 		if fieldVal.Type().Elem().Kind() == reflect.Int {
-			var intSlice []int
-			if err := json.Unmarshal([]byte(s), &intSlice); err != nil {
+			// Decode into the field's own type so named slices ([]Port, Ports) work
+			intSlice := reflect.New(fieldVal.Type())
+			if err := json.Unmarshal([]byte(s), intSlice.Interface()); err != nil {
 				return fmt.Errorf("could not parse %s to []int: %w", parseCtx, err)
 			}
-			fieldVal.Set(reflect.ValueOf(intSlice))
+			fieldVal.Set(intSlice.Elem())
 			return nil
 		}
 		if ignoreNonIntSlice {
-			return nil
+			return fmt.Errorf("%w: unsupported slice type for %s: %s", errValueIgnored, unsupportedCtx, fieldVal.Type().String())
 		}
 		return fmt.Errorf("unsupported slice type for %s: %s", unsupportedCtx, fieldVal.Type().String())
 	default:
@@ -0,0 +1,71 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAliases(t *testing.T) {
+	type DB struct {
+		Host string `json:"host" alias:"hostname,server"`
+		Port int    `json:"port" env:"DB_PORT" envalias:"DATABASE_PORT"`
+	}
+	type Cfg struct {
+		DB      DB     `json:"db"`
+		Timeout int    `json:"timeout" alias:"legacy.timeout_secs"`
+		Region  string `json:"region" env:"APP_REGION" envalias:"REGION,AWS_REGION"`
+		Name    string `json:"name" alias:"title"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	doc := `{"db": {"hostname": "db.internal"}, "legacy": {"timeout_secs": 30}, "name": "new", "title": "old"}`
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DATABASE_PORT", "5433")
+	t.Setenv("AWS_REGION", "eu-west-1")
+
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	var used []AliasUse
+	ant.OnDeprecatedAlias(func(u []AliasUse) { used = u })
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	want := Cfg{DB: DB{Host: "db.internal", Port: 5433}, Timeout: 30, Region: "eu-west-1", Name: "new"}
+	if cfg != want {
+		t.Fatalf("got %+v, want %+v", cfg, want)
+	}
+	if p := ant.Provenance(); p["DB.Host"] != LayerFile || p["DB.Port"] != LayerEnv || p["Timeout"] != LayerFile {
+		t.Errorf("unexpected provenance: %v", p)
+	}
+	wantUsed := []AliasUse{
+		{Path: "DB.Host", Old: "db.hostname", New: "db.host", Source: LayerFile},
+		{Path: "Timeout", Old: "legacy.timeout_secs", New: "timeout", Source: LayerFile},
+		{Path: "Name", Old: "title", New: "name", Source: LayerFile},
+		{Path: "DB.Port", Old: "DATABASE_PORT", New: "DB_PORT", Source: LayerEnv},
+		{Path: "Region", Old: "AWS_REGION", New: "APP_REGION", Source: LayerEnv},
+	}
+	if !reflect.DeepEqual(used, wantUsed) {
+		t.Errorf("alias uses:\n got %+v\nwant %+v", used, wantUsed)
+	}
+
+	// The current name wins over its alias
+	t.Setenv("DB_PORT", "6000")
+	used = nil
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DB.Port != 6000 {
+		t.Errorf("DB_PORT should win over DATABASE_PORT, got %d", cfg.DB.Port)
+	}
+	for _, u := range used {
+		if u.Old == "DATABASE_PORT" {
+			t.Errorf("unused alias reported: %+v", u)
+		}
+	}
+}
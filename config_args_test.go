@@ -0,0 +1,105 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseArgsTerminatorAndPositionals(t *testing.T) {
+	bools := map[string]bool{"verbose": true}
+	values, rest := parseArgsToFlagMap([]string{
+		"in.txt", "-verbose", "out.txt", "--offset", "-5", "-", "--name", "x", "--", "--port=1", "-v",
+	}, "", bools)
+	want := map[string]string{"verbose": "true", "offset": "-5", "name": "x"}
+	if len(values) != len(want) {
+		t.Fatalf("unexpected flags: %v", values)
+	}
+	for k, v := range want {
+		if values[k] == nil || *values[k] != v {
+			t.Errorf("%s: got %v, want %q", k, values[k], v)
+		}
+	}
+	if wantRest := []string{"in.txt", "out.txt", "-", "--port=1", "-v"}; !reflect.DeepEqual(rest, wantRest) {
+		t.Errorf("rest = %q, want %q", rest, wantRest)
+	}
+
+	values, rest = parseArgsToFlagMap([]string{"--verbose", "false", "file"}, "", bools)
+	if *values["verbose"] != "false" || !reflect.DeepEqual(rest, []string{"file"}) {
+		t.Errorf("explicit bool value: %v %q", *values["verbose"], rest)
+	}
+}
+
+func TestRemainingArgs(t *testing.T) {
+	type Cfg struct {
+		Port  int  `flag:"port"`
+		Debug bool `flag:"debug"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	ant.SetFlagArgs([]string{"serve", "--port", "8080", "--debug", "./site", "--", "--port=1"})
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 8080 || !cfg.Debug {
+		t.Fatalf("unexpected values: %+v", cfg)
+	}
+	if got, want := ant.RemainingArgs(), []string{"serve", "./site", "--port=1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("RemainingArgs = %q, want %q", got, want)
+	}
+}
+
+func TestArgFiles(t *testing.T) {
+	type Cfg struct {
+		Name string `flag:"name"`
+		Port int    `flag:"port"`
+		Tag  string `flag:"tag"`
+	}
+	path := filepath.Join(t.TempDir(), "args.txt")
+	if err := os.WriteFile(path, []byte("# CI overrides\r\n--name=hello world\r\n\r\n--port\r\n8080\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"@" + path, "--tag", "@@literal", "in.txt", "--", "@" + path}
+
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs(args)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "" || cfg.Tag != "@@literal" {
+		t.Fatalf("argument files must be opt-in: %+v", cfg)
+	}
+
+	if err := ac.SetArgFiles(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "hello world" || cfg.Port != 8080 || cfg.Tag != "@literal" {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+	if rest := ac.RemainingArgs(); !reflect.DeepEqual(rest, []string{"in.txt", "@" + path}) {
+		t.Fatalf("RemainingArgs = %q", rest)
+	}
+
+	ac.SetFlagArgs([]string{"@" + path + ".missing"})
+	if err := ac.WriteConfigValues(); err == nil || !strings.Contains(err.Error(), "argument file") {
+		t.Fatalf("err = %v", err)
+	}
+
+	var pcfg Cfg
+	ac = New().MustSetConfig(&pcfg)
+	if err := ac.SetArgFiles(true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ac.ParseAndLoad([]string{"@" + path}); err != nil {
+		t.Fatal(err)
+	}
+	if pcfg.Port != 8080 {
+		t.Fatalf("ParseAndLoad: %+v", pcfg)
+	}
+}
@@ -0,0 +1,81 @@
+package antconfig
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestAccessAudit(t *testing.T) {
+	type Cfg struct {
+		Host string `default:"localhost"`
+		Port int    `default:"80"`
+		DB   struct {
+			Name string `default:"app"`
+			User string
+		}
+		TLS *struct {
+			Cert string
+		} `default:"nil" enablekey:"AUDIT_TLS"`
+		Legacy string
+	}
+	var cfg Cfg
+	ac := New()
+	if err := ac.DisableAutoDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetEnvironment(map[string]string{"AUDIT_TLS": "true"}); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+
+	if host := Read(ac, &cfg.Host); host != "" {
+		t.Fatalf("Read before load: %q", host)
+	}
+	if r := ac.AccessReport(); len(r.Reads) != 0 || r.Unread != nil {
+		t.Fatalf("reads recorded without EnableAccessAudit: %+v", r)
+	}
+
+	ac.EnableAccessAudit()
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if Read(ac, &cfg.Host) != "localhost" {
+				t.Error("Read returned the wrong value")
+			}
+		}()
+	}
+	wg.Wait()
+	_ = Read(ac, &cfg.DB)
+	_ = Read(ac, &cfg.TLS.Cert) // allocated by the load
+	if _, ok := ac.Lookup("port"); !ok {
+		t.Fatal("Lookup failed")
+	}
+	other := "not a config field"
+	_ = Read(ac, &other)
+	// Misses rebuild the index only after a new load
+	index := reflect.ValueOf(ac.audit.index).UnsafePointer()
+	_ = Read(ac, &other)
+	if reflect.ValueOf(ac.audit.index).UnsafePointer() != index {
+		t.Fatal("a repeated miss rebuilt the audit index")
+	}
+
+	r := ac.AccessReport()
+	want := map[string]int{"Host": 4, "DB": 1, "TLS.Cert": 1, "Port": 1}
+	if !reflect.DeepEqual(r.Reads, want) {
+		t.Fatalf("Reads = %v, want %v", r.Reads, want)
+	}
+	if !reflect.DeepEqual(r.Unread, []string{"Legacy"}) {
+		t.Fatalf("Unread = %v, want [Legacy]", r.Unread)
+	}
+
+	ac.ResetAccessAudit()
+	if r := ac.AccessReport(); len(r.Reads) != 0 || len(r.Unread) != 6 {
+		t.Fatalf("after reset: %+v", r)
+	}
+}
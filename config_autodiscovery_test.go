@@ -1,6 +1,10 @@
 package antconfig
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"testing"
@@ -46,3 +50,143 @@ func TestConfigAutoDiscovery_Upwards(t *testing.T) {
 		t.Fatalf("expected auto-discovered config applied, got %+v", cfg)
 	}
 }
+
+func TestLocateFromUserConfig(t *testing.T) {
+	xdg := t.TempDir()
+	home := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv("HOME", home)
+	t.Setenv("APPDATA", "")
+
+	// Only ~/.config/app has the file: XDG is consulted first but misses.
+	homeDir := filepath.Join(home, ".config", "antapp")
+	if err := os.MkdirAll(homeDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	homeFile := filepath.Join(homeDir, "config.jsonc")
+	if err := os.WriteFile(homeFile, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := LocateFromUserConfig("antapp", "config.jsonc")
+	if err != nil {
+		t.Fatalf("LocateFromUserConfig: %v", err)
+	}
+	if got != homeFile {
+		t.Fatalf("expected %s, got %s", homeFile, got)
+	}
+
+	// XDG_CONFIG_HOME wins once it has the file.
+	xdgDir := filepath.Join(xdg, "antapp")
+	if err := os.MkdirAll(xdgDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	xdgFile := filepath.Join(xdgDir, "config.jsonc")
+	if err := os.WriteFile(xdgFile, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = LocateFromUserConfig("antapp", "config.jsonc")
+	if err != nil {
+		t.Fatalf("LocateFromUserConfig: %v", err)
+	}
+	if got != xdgFile {
+		t.Fatalf("expected XDG file %s, got %s", xdgFile, got)
+	}
+
+	if _, err := LocateFromUserConfig("antapp-missing-xyz", "config.jsonc"); !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("expected ErrConfigNotFound, got %v", err)
+	}
+}
+
+func TestLocateFromModuleRoot(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "pkg", "sub")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range map[string]string{
+		filepath.Join(root, "go.mod"):             "module example.com/app\n",
+		filepath.Join(root, "config.json"):        `{"A": "root"}`,
+		filepath.Join(root, "pkg", "config.json"): `{"A": "pkg"}`,
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(sub)
+
+	got, err := LocateFromModuleRoot("config.json")
+	if err != nil {
+		t.Fatalf("LocateFromModuleRoot: %v", err)
+	}
+	if want := filepath.Join(root, "config.json"); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+	if _, err := LocateFromModuleRoot("missing.json"); !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("expected ErrConfigNotFound, got %v", err)
+	}
+
+	type Cfg struct {
+		A string `default:"defA"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.A != "pkg" {
+		t.Fatalf("default discovery should find the nearest config, got %q", cfg.A)
+	}
+	if err := ant.SetDiscovery(LocateFromModuleRoot, LocateFromWorkingDirUp); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.A != "root" {
+		t.Fatalf("module root discovery should find the root config, got %q", cfg.A)
+	}
+	if err := ant.SetDiscovery(nil); err == nil {
+		t.Fatal("expected error for nil locator")
+	}
+}
+
+type recordingLogger struct{ lines []string }
+
+func (l *recordingLogger) Printf(format string, args ...any) {
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+}
+
+func TestSetDebugLogger(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "config.json"), []byte(`{}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(root)
+	log := &recordingLogger{}
+	SetDebugLogger(log)
+	t.Cleanup(func() { SetDebugLogger(nil) })
+
+	var cfg struct{ A string }
+	if err := New().MustSetConfig(&cfg).WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	want := "antconfig: config file path=" + filepath.Join(root, "config.json") + " discovered=true"
+	found := false
+	for _, l := range log.lines {
+		found = found || l == want
+	}
+	if !found {
+		t.Fatalf("expected %q in debug log, got %q", want, log.lines)
+	}
+
+	// An instance logger takes over; nothing is written twice
+	log.lines = nil
+	ac := New().MustSetConfig(&cfg)
+	ac.SetSlog(slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if len(log.lines) != 0 {
+		t.Fatalf("expected no package debug output with SetSlog, got %q", log.lines)
+	}
+}
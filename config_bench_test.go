@@ -0,0 +1,121 @@
+package antconfig
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type benchConfig struct {
+	Server struct {
+		Host    string        `json:"host" env:"BENCH_HOST" flag:"host" default:"localhost"`
+		Port    int           `json:"port" env:"BENCH_PORT" flag:"port" default:"8080"`
+		Timeout time.Duration `json:"timeout" env:"BENCH_TIMEOUT" default:"5s"`
+	} `json:"server"`
+	Database struct {
+		URL      string `json:"url" env:"BENCH_DB_URL" required:"true"`
+		Password string `json:"password" env:"BENCH_DB_PASSWORD" secret:"true"`
+		Pool     int    `json:"pool" env:"BENCH_DB_POOL" default:"10"`
+	} `json:"database"`
+	Debug bool     `json:"debug" env:"BENCH_DEBUG" flag:"debug"`
+	Tags  []string `json:"tags"`
+}
+
+var benchEnv = map[string]string{
+	"BENCH_HOST":        "0.0.0.0",
+	"BENCH_DB_URL":      "postgres://db/app",
+	"BENCH_DB_PASSWORD": "hunter2",
+	"BENCH_DEBUG":       "true",
+}
+
+func benchmarkLoad(b *testing.B, setup func(*AntConfig)) {
+	var cfg benchConfig
+	ac := New().MustSetConfig(&cfg)
+	if err := ac.SetEnvironment(benchEnv); err != nil {
+		b.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{"--port", "9000"})
+	setup(ac)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := ac.WriteConfigValues(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkLoadDefault includes config file and .env discovery, which stats
+// candidate files from the working directory upward on every load.
+func BenchmarkLoadDefault(b *testing.B) {
+	benchmarkLoad(b, func(*AntConfig) {})
+}
+
+// BenchmarkLoadEnvOnly is the fast path: defaults, env and flags only.
+func BenchmarkLoadEnvOnly(b *testing.B) {
+	benchmarkLoad(b, func(ac *AntConfig) {
+		if err := ac.SetMode(StrictEnvOnly); err != nil {
+			b.Fatal(err)
+		}
+	})
+}
+
+// countingFS counts every file system access, which all go through Open.
+type countingFS struct {
+	fs.FS
+	opens int
+}
+
+func (c *countingFS) Open(name string) (fs.File, error) {
+	c.opens++
+	return c.FS.Open(name)
+}
+
+func TestEnvOnlyTouchesNoFiles(t *testing.T) {
+	for name, setup := range map[string]func(*AntConfig){
+		"StrictEnvOnly": func(ac *AntConfig) {
+			if err := ac.SetMode(StrictEnvOnly); err != nil {
+				t.Fatal(err)
+			}
+		},
+		"Disable": func(ac *AntConfig) {
+			if err := ac.DisableConfigFile(); err != nil {
+				t.Fatal(err)
+			}
+			if err := ac.DisableDotEnv(); err != nil {
+				t.Fatal(err)
+			}
+		},
+		"default": func(*AntConfig) {},
+	} {
+		t.Run(name, func(t *testing.T) {
+			files := &countingFS{FS: fstest.MapFS{}}
+			var cfg benchConfig
+			ac := New().MustSetConfig(&cfg)
+			if err := ac.SetEnvironment(benchEnv); err != nil {
+				t.Fatal(err)
+			}
+			ac.SetFlagArgs([]string{"--"})
+			if err := ac.SetFS(files); err != nil {
+				t.Fatal(err)
+			}
+			setup(ac)
+			if err := ac.WriteConfigValues(); err != nil {
+				t.Fatal(err)
+			}
+			if name == "default" {
+				if files.opens == 0 {
+					t.Fatal("expected discovery to look for files")
+				}
+				return
+			}
+			if files.opens != 0 {
+				t.Fatalf("%d file system accesses with the file layers off", files.opens)
+			}
+			if cfg.Server.Host != "0.0.0.0" || cfg.Server.Port != 8080 || cfg.Database.Pool != 10 {
+				t.Fatalf("cfg = %+v", cfg)
+			}
+		})
+	}
+}
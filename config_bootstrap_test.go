@@ -0,0 +1,66 @@
+package antconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseBootstrapFlags(t *testing.T) {
+	type Cfg struct {
+		Host  string `json:"host" flag:"host"`
+		Port  int    `json:"port" env:"BOOT_PORT" flag:"port"`
+		Debug bool   `json:"debug" env:"BOOT_DEBUG"`
+	}
+	dir := t.TempDir()
+	config := filepath.Join(dir, "app.json")
+	env := filepath.Join(dir, "app.env")
+	local := filepath.Join(dir, "local.env")
+	for name, content := range map[string]string{
+		config: `{"host": "from-file", "port": 1}`,
+		env:    "BOOT_PORT=2\nBOOT_DEBUG=true\n",
+		local:  "BOOT_PORT=3\n",
+	} {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetDotEnvExport(false)
+	rest, err := ac.ParseBootstrapFlags([]string{"serve", "--config", config, "-env-file=" + env, "--env-file", local, "--", "--config", "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"serve", "--", "--config", "x"}; !reflect.DeepEqual(rest, want) {
+		t.Fatalf("rest = %q, want %q", rest, want)
+	}
+	if ac.ConfigPath() != config || !reflect.DeepEqual(ac.EnvPaths(), []string{env, local}) {
+		t.Fatalf("paths: %q %q", ac.ConfigPath(), ac.EnvPaths())
+	}
+	args, err := ac.ParseAndLoad(append([]string{"--host", "from-flag"}, rest...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "from-flag" || cfg.Port != 3 || !cfg.Debug {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+	if want := []string{"serve", "--", "--config", "x"}; !reflect.DeepEqual(args, want) {
+		t.Fatalf("args = %q", args)
+	}
+
+	// The prefix applies, and errors are reported
+	ac = New().MustSetConfig(&cfg)
+	if err := ac.SetFlagPrefix("app-"); err != nil {
+		t.Fatal(err)
+	}
+	if rest, err := ac.ParseBootstrapFlags([]string{"--config", "kept", "--app-config=" + filepath.Join(dir, "missing.json")}); !errors.Is(err, ErrConfigNotFound) || rest != nil {
+		t.Fatalf("rest = %q, err = %v", rest, err)
+	}
+	if _, err := ac.ParseBootstrapFlags([]string{"--app-env-file"}); err == nil {
+		t.Fatal("expected an error for a missing value")
+	}
+}
@@ -0,0 +1,56 @@
+package antconfig
+
+import (
+	"errors"
+	"flag"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuilderBuild(t *testing.T) {
+	type Cfg struct {
+		Host string `default:"localhost" flag:"host"`
+		Port int    `default:"8080"`
+	}
+	var cfg Cfg
+	fs := flag.NewFlagSet("builder", flag.ContinueOnError)
+	b := For(&cfg).WithFlagPrefix("app-").WithFlagSet(fs)
+	if err := fs.Parse([]string{"--app-host=example.org"}); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := b.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if loaded.Config != &cfg || cfg.Host != "example.org" || cfg.Port != 8080 {
+		t.Fatalf("unexpected result: %+v", cfg)
+	}
+	if loaded.AntConfig.Provenance()["Host"] != LayerFlag {
+		t.Fatalf("expected provenance from the loading AntConfig")
+	}
+}
+
+func TestBuilderDefersErrors(t *testing.T) {
+	type Cfg struct {
+		Port int `default:"x"`
+	}
+	var cfg Cfg
+	// A failing SetConfig must not panic and must win over later errors
+	_, err := For(&cfg).WithConfigPath(filepath.Join(t.TempDir(), "missing.json")).Build()
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("expected default validation error, got %v", err)
+	}
+
+	type Ok struct{ Port int }
+	var ok Ok
+	_, err = For(&ok).WithConfigPath(filepath.Join(t.TempDir(), "missing.json")).Build()
+	if !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("expected ErrConfigNotFound, got %v", err)
+	}
+
+	var nilCfg *Ok
+	if _, err := For(nilCfg).Build(); err == nil {
+		t.Fatal("expected error for nil config pointer")
+	}
+}
@@ -0,0 +1,90 @@
+package antconfig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSourceCacheAllowStaleOnError(t *testing.T) {
+	type Cfg struct {
+		Host string
+		Port int
+	}
+	cache := filepath.Join(t.TempDir(), "sources.json")
+
+	// First process: the source is up and its values are cached
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	ac.SetSourceCache(cache)
+	if err := ac.AddSource(&flakySource{values: map[string]any{"Host": "remote", "Port": 8080}}, PriorityEnv); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.AddValues(map[string]any{"Port": 1}, PriorityDefault); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm() != 0o600 {
+		t.Fatalf("cache mode = %v, want 0600", fi.Mode().Perm())
+	}
+	data, _ := os.ReadFile(cache)
+	var saved sourceCacheFile
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saved.Sources["memory"]; ok || len(saved.Sources) != 1 {
+		t.Fatalf("unexpected cache entries: %s", data)
+	}
+
+	// Second process: the source is down
+	restart := func(ttl time.Duration) (*AntConfig, *Cfg, *[]Warning) {
+		cfg := &Cfg{}
+		ac := New().MustSetConfig(cfg)
+		ac.SetFlagArgs([]string{})
+		ac.SetSourceCache(cache)
+		ac.AllowStaleOnError(ttl)
+		var warnings []Warning
+		ac.OnWarning(func(w Warning) { warnings = append(warnings, w) })
+		if err := ac.AddSource(&flakySource{fail: 100}, PriorityEnv); err != nil {
+			t.Fatal(err)
+		}
+		return ac, cfg, &warnings
+	}
+	ac, got, warnings := restart(time.Hour)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if got.Host != "remote" || got.Port != 8080 {
+		t.Fatalf("cfg=%+v", *got)
+	}
+	if len(*warnings) != 1 || (*warnings)[0].Kind != WarningStaleSource {
+		t.Fatalf("unexpected warnings: %+v", *warnings)
+	}
+	if src := ac.Provenance()["Host"]; src != "flaky" {
+		t.Fatalf("provenance = %q", src)
+	}
+
+	// Entries older than the TTL are not used
+	saved.Sources["flaky"] = sourceCacheEntry{Saved: time.Now().Add(-2 * time.Hour), Values: saved.Sources["flaky"].Values}
+	data, _ = json.Marshal(saved)
+	if err := os.WriteFile(cache, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	ac, _, _ = restart(time.Hour)
+	if err := ac.WriteConfigValues(); err == nil {
+		t.Fatal("expected error for expired cache entry")
+	}
+	ac, got, _ = restart(0)
+	if err := ac.WriteConfigValues(); err != nil || got.Host != "remote" {
+		t.Fatalf("err=%v cfg=%+v", err, *got)
+	}
+}
@@ -0,0 +1,60 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCaseInsensitiveEnvAndFlags(t *testing.T) {
+	type Cfg struct {
+		Key   string `env:"CI_API_KEY"`
+		Token string `env:"CI_TOKEN"`
+		Port  int    `flag:"port"`
+		Host  string `flag:"host"`
+	}
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envPath, []byte("ci_token=from-dotenv\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("Ci_Api_Key", "folded")
+	args := []string{"--PORT=8080", "--Host", "h", "--host=exact"}
+
+	var strict Cfg
+	ant := New().MustSetConfig(&strict)
+	ant.SetDotEnvExport(false)
+	if err := ant.SetEnvPath(envPath); err != nil {
+		t.Fatal(err)
+	}
+	ant.SetFlagArgs(args)
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if strict.Key != "" || strict.Token != "" || strict.Port != 0 || strict.Host != "exact" {
+		t.Fatalf("case-sensitive load matched folded names: %+v", strict)
+	}
+
+	var cfg Cfg
+	ant = New().MustSetConfig(&cfg)
+	ant.SetDotEnvExport(false)
+	if err := ant.SetCaseInsensitive(true); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.SetEnvPath(envPath); err != nil {
+		t.Fatal(err)
+	}
+	ant.SetFlagArgs(args)
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Key != "folded" || cfg.Token != "from-dotenv" || cfg.Port != 8080 {
+		t.Fatalf("unexpected values: %+v", cfg)
+	}
+	if cfg.Host != "exact" {
+		t.Errorf("exact flag name should win, got Host=%q", cfg.Host)
+	}
+	if p := ant.Provenance(); p["Token"] != LayerDotEnv || p["Key"] != LayerEnv {
+		t.Errorf("unexpected provenance: %v", p)
+	}
+}
@@ -0,0 +1,42 @@
+package antconfig
+
+import "testing"
+
+func TestLoadInto(t *testing.T) {
+	type Cfg struct {
+		Host  string   `json:"host" default:"localhost" env:"CLONE_HOST"`
+		Peers []string `json:"peers"`
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--"})
+	t.Setenv("CLONE_HOST", "a")
+
+	first := new(Cfg)
+	if err := ac.LoadInto(first); err != nil {
+		t.Fatal(err)
+	}
+	if first.Host != "a" || cfg.Host != "" {
+		t.Fatalf("first = %+v, registered = %+v", first, cfg)
+	}
+	if ac.Provenance()["Host"] != LayerEnv {
+		t.Fatalf("provenance = %v", ac.Provenance())
+	}
+
+	t.Setenv("CLONE_HOST", "b")
+	v, err := ac.CloneEffective()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := v.(*Cfg)
+	if second == first || second.Host != "b" || first.Host != "a" || cfg.Host != "" {
+		t.Fatalf("second = %+v, first = %+v", second, first)
+	}
+
+	if err := ac.LoadInto(&struct{ Host string }{}); err == nil {
+		t.Fatal("LoadInto accepted a different type")
+	}
+	if err := ac.LoadInto((*Cfg)(nil)); err == nil {
+		t.Fatal("LoadInto accepted a nil pointer")
+	}
+}
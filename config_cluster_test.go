@@ -0,0 +1,89 @@
+package antconfig
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVerifyCluster(t *testing.T) {
+	type Cfg struct {
+		Host string `default:"localhost"`
+		Port int    `default:"8080"`
+	}
+	load := func(t *testing.T, port int) *AntConfig {
+		t.Helper()
+		var cfg Cfg
+		ant := New()
+		if err := ant.SetConfig(&cfg); err != nil {
+			t.Fatal(err)
+		}
+		if err := ant.WriteConfigValues(); err != nil {
+			t.Fatal(err)
+		}
+		if port != 0 {
+			cfg.Port = port // simulate a node with a different override
+		}
+		return ant
+	}
+
+	local := load(t, 0)
+	same := load(t, 0)
+	other := load(t, 9090)
+	localHash, _ := local.Hash()
+	sameHash, _ := same.Hash()
+	otherHash, _ := other.Hash()
+	if localHash != sameHash {
+		t.Fatalf("identical configs should hash equally: %s vs %s", localHash, sameHash)
+	}
+
+	var published string
+	drift, err := local.VerifyCluster(func(h string) error { published = h; return nil },
+		map[string]string{"node-b": sameHash, "node-c": otherHash})
+	if !errors.Is(err, ErrConfigDrift) {
+		t.Fatalf("expected ErrConfigDrift, got %v", err)
+	}
+	if published != localHash || drift.Hash != localHash {
+		t.Fatalf("expected local hash to be published and reported")
+	}
+	if len(drift.Mismatched) != 1 || drift.Mismatched["node-c"] != otherHash {
+		t.Fatalf("expected only node-c to drift, got %v", drift.Mismatched)
+	}
+
+	if _, err := local.VerifyCluster(nil, map[string]string{"node-b": sameHash}); err != nil {
+		t.Fatalf("expected no drift, got %v", err)
+	}
+}
+
+func TestHashManagedFields(t *testing.T) {
+	type Cfg struct {
+		Host  string `json:"host" default:"localhost"`
+		Token string `json:"-" env:"HASH_TOKEN"`
+		Hook  func() `antconfig:"-"`
+	}
+	hash := func(t *testing.T, token string) string {
+		t.Helper()
+		cfg := Cfg{Hook: func() {}}
+		ant := New()
+		if err := ant.SetEnvironment(map[string]string{"HASH_TOKEN": token}); err != nil {
+			t.Fatal(err)
+		}
+		ant.SetFlagArgs([]string{})
+		if err := ant.SetConfig(&cfg); err != nil {
+			t.Fatal(err)
+		}
+		if err := ant.WriteConfigValues(); err != nil {
+			t.Fatal(err)
+		}
+		h, err := ant.Hash()
+		if err != nil {
+			t.Fatalf("Hash with an antconfig:\"-\" func field: %v", err)
+		}
+		return h
+	}
+	if a, b := hash(t, "one"), hash(t, "one"); a != b {
+		t.Fatalf("identical configs should hash equally: %s vs %s", a, b)
+	}
+	if hash(t, "one") == hash(t, "two") {
+		t.Fatal("a json:\"-\" env field must be part of the hash")
+	}
+}
@@ -0,0 +1,62 @@
+package antconfig
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestBindConfigFlagsNameCollisions(t *testing.T) {
+	type DB struct {
+		Host string `flag:"db-host" env:"DB_HOST"`
+	}
+	type Cfg struct {
+		Primary DB
+		Replica DB
+		Debug   bool   `flag:"debug" envalias:"VERBOSE"`
+		Verbose bool   `env:"VERBOSE"`
+		NoDebug string `flag:"no-debug"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	err := ant.BindConfigFlags(fs)
+	if !errors.Is(err, ErrNameCollision) {
+		t.Fatalf("expected ErrNameCollision, got %v", err)
+	}
+	for _, want := range []string{
+		"flag --db-host is used by both Primary.Host and Replica.Host",
+		"env var DB_HOST is used by both Primary.Host and Replica.Host",
+		"env var VERBOSE is used by both Debug and Verbose",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in:\n%v", want, err)
+		}
+	}
+	if fs.Lookup("db-host") != nil {
+		t.Error("no flags should be registered when names collide")
+	}
+
+	type Ok struct {
+		Debug   bool   `flag:"debug"`
+		NoDebug string `flag:"no-debug"`
+	}
+	var ok Ok
+	ant = New().MustSetConfig(&ok)
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := ant.BindConfigFlags(fs); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Parse([]string{"-debug", "-no-debug=x"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if !ok.Debug || ok.NoDebug != "x" {
+		t.Fatalf("a field named no-debug must keep its own flag: %+v", ok)
+	}
+}
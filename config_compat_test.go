@@ -0,0 +1,28 @@
+package antconfig
+
+import "testing"
+
+func TestCompatShims(t *testing.T) {
+	type Cfg struct {
+		A string `default:"a"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetValues(); err != nil {
+		t.Fatalf("SetValues: %v", err)
+	}
+	if cfg.A != "a" {
+		t.Fatalf("expected SetValues to apply defaults, got %q", cfg.A)
+	}
+
+	want, wantErr := LocateFromWorkingDirUp("config_test.jsonc")
+	got, err := LocateFromWorkingDir("config_test.jsonc")
+	if got != want || (err == nil) != (wantErr == nil) {
+		t.Fatalf("LocateFromWorkingDir should match LocateFromWorkingDirUp: %q/%v vs %q/%v", got, err, want, wantErr)
+	}
+	want, wantErr = LocateFromExeUp("config_test.jsonc")
+	got, err = LocateFromExe("config_test.jsonc")
+	if got != want || (err == nil) != (wantErr == nil) {
+		t.Fatalf("LocateFromExe should match LocateFromExeUp: %q/%v vs %q/%v", got, err, want, wantErr)
+	}
+}
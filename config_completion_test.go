@@ -0,0 +1,47 @@
+package antconfig
+
+import "testing"
+
+func TestShellCompletion(t *testing.T) {
+	type Cfg struct {
+		Host    string `flag:"host" desc:"server \x60addr\x60: it's [required]"`
+		Verbose bool   `flag:"verbose"`
+		Port    int    `env:"PORT"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetFlagPrefix("app-"); err != nil {
+		t.Fatal(err)
+	}
+
+	bash := `# bash completion for my-tool
+_my_tool_antconfig() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    if [[ "$cur" == -* ]]; then
+        COMPREPLY=( $(compgen -W '--app-host --app-verbose' -- "$cur") )
+    fi
+}
+complete -o default -F _my_tool_antconfig my-tool
+`
+	if got := ant.BashCompletion("my-tool"); got != bash {
+		t.Errorf("bash:\n%s\nwant:\n%s", got, bash)
+	}
+
+	zsh := `#compdef my-tool
+
+_arguments \
+  '--app-host=[server addr\: it'\''s \[required\]]:value:' \
+  --app-verbose \
+  '*::arg:_default'
+`
+	if got := ant.ZshCompletion("my-tool"); got != zsh {
+		t.Errorf("zsh:\n%s\nwant:\n%s", got, zsh)
+	}
+
+	fish := `complete -c my-tool -l app-host -r -d 'server addr: it'\''s [required]'
+complete -c my-tool -l app-verbose
+`
+	if got := ant.FishCompletion("my-tool"); got != fish {
+		t.Errorf("fish:\n%s\nwant:\n%s", got, fish)
+	}
+}
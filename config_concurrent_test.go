@@ -0,0 +1,105 @@
+package antconfig
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// barrierSource blocks in Load until every source sharing its WaitGroup has
+// started, so sequential loading can only finish by timing out.
+type barrierSource struct {
+	name   string
+	wg     *sync.WaitGroup
+	values map[string]any
+	err    error
+}
+
+func (b barrierSource) Name() string { return b.name }
+
+func (b barrierSource) Load(ctx context.Context) (map[string]any, error) {
+	b.wg.Done()
+	if b.err != nil {
+		return nil, b.err
+	}
+	done := make(chan struct{})
+	go func() { b.wg.Wait(); close(done) }()
+	select {
+	case <-done:
+		return b.values, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestConcurrentSources(t *testing.T) {
+	type Cfg struct {
+		Host string
+		Port int
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	ac.SetConcurrentSources(true)
+	ac.SetSourceTimeout(5 * time.Second)
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	if err := ac.AddSource(barrierSource{name: "vault", wg: wg, values: map[string]any{"Host": "vault", "Port": 1}}, PriorityEnv); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.AddSource(barrierSource{name: "ssm", wg: wg, values: map[string]any{"Host": "ssm"}}, PriorityFile); err != nil {
+		t.Fatal(err)
+	}
+	report, err := ac.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Merged in priority order: vault (PriorityEnv) wins over ssm (PriorityFile)
+	if cfg.Host != "vault" || cfg.Port != 1 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if len(report.Sources) != 2 || report.Sources[0] != "ssm" || report.Sources[1] != "vault" {
+		t.Fatalf("unexpected sources: %v", report.Sources)
+	}
+}
+
+func TestConcurrentSourcesFirstErrorCancels(t *testing.T) {
+	type Cfg struct{ Host string }
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	ac.SetConcurrentSources(true)
+	boom := errors.New("boom")
+	wg := &sync.WaitGroup{}
+	wg.Add(3) // never reached: the waiting source must be canceled
+	if err := ac.AddSource(barrierSource{name: "slow", wg: wg}, PriorityDefault); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.AddSource(barrierSource{name: "broken", wg: wg, err: boom}, PriorityEnv); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); !errors.Is(err, boom) {
+		t.Fatalf("expected the failing source's error, got %v", err)
+	}
+}
+
+func TestSourceTimeout(t *testing.T) {
+	type Cfg struct{ Host string }
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	ac.SetSourceTimeout(20 * time.Millisecond)
+	wg := &sync.WaitGroup{}
+	wg.Add(2)
+	if err := ac.AddSource(barrierSource{name: "a", wg: wg}, PriorityEnv); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.AddSource(barrierSource{name: "b", wg: wg}, PriorityEnv); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected sequential loading to hit the deadline, got %v", err)
+	}
+}
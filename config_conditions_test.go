@@ -0,0 +1,160 @@
+package antconfig
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestConditionalDocuments(t *testing.T) {
+	type Cfg struct {
+		CacheDir string `json:"cache_dir"`
+		Workers  int    `json:"workers"`
+	}
+	file := `{"cache_dir": "/var/cache/app", "workers": 2}
+---
+{"$when": {"os": "windows"}, "cache_dir": "C:\\ProgramData\\app"}
+---
+{"$when": {"os": ["darwin", "freebsd"], "arch": "!386"}, "cache_dir": "/Library/Caches/app"}
+---
+{"$when": {"hostname": "web-*"}, "workers": 16}
+`
+	cases := []struct {
+		os, arch, host string
+		want           Cfg
+	}{
+		{"linux", "amd64", "db-1", Cfg{"/var/cache/app", 2}},
+		{"windows", "amd64", "web-3", Cfg{`C:\ProgramData\app`, 16}},
+		{"darwin", "arm64", "laptop", Cfg{"/Library/Caches/app", 2}},
+		{"darwin", "386", "laptop", Cfg{"/var/cache/app", 2}},
+	}
+	for _, tc := range cases {
+		var cfg Cfg
+		var warnings []Warning
+		ac := New()
+		ac.OnWarning(func(w Warning) { warnings = append(warnings, w) })
+		if err := ac.SetFS(fstest.MapFS{"config.jsonc": {Data: []byte(file)}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.SetCondition("os", tc.os); err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.SetCondition("arch", tc.arch); err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.SetCondition("hostname", tc.host); err != nil {
+			t.Fatal(err)
+		}
+		ac.MustSetConfig(&cfg)
+		if err := ac.WriteConfigValues(); err != nil {
+			t.Fatalf("%s/%s/%s: WriteConfigValues: %v", tc.os, tc.arch, tc.host, err)
+		}
+		if cfg != tc.want {
+			t.Errorf("%s/%s/%s: got %+v, want %+v", tc.os, tc.arch, tc.host, cfg, tc.want)
+		}
+		if len(warnings) != 0 {
+			t.Errorf("unexpected warnings: %v", warnings)
+		}
+	}
+}
+
+func TestConditionalNestedBlocks(t *testing.T) {
+	type Plugin struct {
+		Name string `json:"name"`
+	}
+	type Cfg struct {
+		Plugins []Plugin `json:"plugins"`
+		Debug   *struct {
+			Addr string `json:"addr"`
+		} `json:"debug"`
+	}
+	file := `{
+		"plugins": [
+			{"name": "core"},
+			{"$when": {"os": "linux"}, "name": "inotify"},
+			{"$when": {"os": "!linux"}, "name": "poll"}
+		],
+		"debug": {"$when": {"stage": "dev"}, "addr": ":6060"}
+	}`
+	load := func(stage string) Cfg {
+		var cfg Cfg
+		ac := New()
+		if err := ac.SetFS(fstest.MapFS{"config.jsonc": {Data: []byte(file)}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.SetCondition("os", "linux"); err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.SetCondition("stage", stage); err != nil {
+			t.Fatal(err)
+		}
+		ac.MustSetConfig(&cfg)
+		if err := ac.WriteConfigValues(); err != nil {
+			t.Fatalf("WriteConfigValues: %v", err)
+		}
+		return cfg
+	}
+	cfg := load("dev")
+	if len(cfg.Plugins) != 2 || cfg.Plugins[1].Name != "inotify" {
+		t.Fatalf("unexpected plugins: %+v", cfg.Plugins)
+	}
+	if cfg.Debug == nil || cfg.Debug.Addr != ":6060" {
+		t.Fatalf("expected debug block for dev, got %+v", cfg.Debug)
+	}
+	if cfg := load("prod"); cfg.Debug != nil {
+		t.Fatalf("debug block should be dropped for prod, got %+v", cfg.Debug)
+	}
+}
+
+func TestConditionalBuiltins(t *testing.T) {
+	type Cfg struct {
+		Native bool `json:"native"`
+	}
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetEmbeddedConfig([]byte(`{"native": false}
+---
+{"$when": {"os": "`+runtime.GOOS+`", "arch": "`+runtime.GOARCH+`", "hostname": "*"}, "native": true}`), "jsonc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.DisableAutoDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if !cfg.Native {
+		t.Fatalf("block for the running platform was not applied")
+	}
+}
+
+func TestConditionalErrors(t *testing.T) {
+	type Cfg struct {
+		Host string `json:"host"`
+	}
+	cases := map[string]struct {
+		file string
+		want string
+	}{
+		"unknown condition": {`{"$when": {"region": "eu"}, "host": "x"}`, `unknown condition "region" (known: arch, hostname, os)`},
+		"not an object":     {"{\"host\": \"x\"}\n---\n{\"$when\": \"linux\"}", "document 2: $when must be an object, not a string"},
+		"bad value":         {`{"host": {"$when": {"os": 1}}}`, "condition os must be a string or a list of strings, not a number"},
+		"bad pattern":       {`{"$when": {"os": "[linux"}}`, "bad pattern"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var cfg Cfg
+			ac := New()
+			if err := ac.SetFS(fstest.MapFS{"config.jsonc": {Data: []byte(tc.file)}}); err != nil {
+				t.Fatal(err)
+			}
+			ac.MustSetConfig(&cfg)
+			err := ac.WriteConfigValues()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package antconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateConstraints(t *testing.T) {
+	type TLS struct {
+		Cert string `flag:"tls-cert" validate:"requires=Key"`
+		Key  string `env:"CONSTRAINT_TLS_KEY" flag:"tls-key"`
+	}
+	type Cfg struct {
+		Insecure bool `flag:"insecure" validate:"conflicts=TLS.Cert"`
+		TLS      TLS
+	}
+	load := func(args ...string) (Cfg, error) {
+		var cfg Cfg
+		ant := New().MustSetConfig(&cfg)
+		ant.SetFlagArgs(args)
+		return cfg, ant.WriteConfigValues()
+	}
+
+	if _, err := load("--tls-cert=c.pem", "--tls-key=k.pem"); err != nil {
+		t.Fatalf("valid combination rejected: %v", err)
+	}
+	if _, err := load("--insecure"); err != nil {
+		t.Fatalf("insecure alone rejected: %v", err)
+	}
+
+	_, err := load("--insecure", "--tls-cert=c.pem")
+	var me *MultiError
+	if !errors.As(err, &me) || !errors.Is(err, ErrConstraint) {
+		t.Fatalf("expected constraint errors, got %v", err)
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		"Insecure: configuration constraint violated: --insecure cannot be combined with --tls-cert",
+		"TLS.Cert: configuration constraint violated: --tls-cert requires TLS.Key to be set (via config key TLS.Key, env var CONSTRAINT_TLS_KEY, flag --tls-key)",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("missing %q in:\n%s", want, msg)
+		}
+	}
+	if len(me.Errors) != 2 || me.Errors[0].Source != LayerFlag {
+		t.Errorf("unexpected field errors: %+v", me.Errors)
+	}
+
+	type Bad struct {
+		A string `validate:"requires=Missing"`
+		B string `validate:"excludes=A"`
+	}
+	err = New().SetConfig(&Bad{})
+	if err == nil || !strings.Contains(err.Error(), "unknown field Missing") || !strings.Contains(err.Error(), `invalid validate clause "excludes=A"`) {
+		t.Fatalf("expected malformed validate tags to be rejected, got %v", err)
+	}
+}
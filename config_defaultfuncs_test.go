@@ -0,0 +1,54 @@
+package antconfig
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestComputedDefaults(t *testing.T) {
+	RegisterDefaultFunc("test-region", func() (string, error) { return "eu-west", nil })
+	RegisterDefaultFunc("test-broken", func() (string, error) { return "", errors.New("no metadata") })
+	type Cfg struct {
+		Host    string `default:"$(hostname)"`
+		Workers int    `default:"$(numcpu)"`
+		Cache   string `default:"$(tempdir)/cache"`
+		Region  string `default:"$(test-region)"`
+		Price   string `default:"$$(test-region) costs $$5"`
+		Plain   string `default:"$HOME"`
+		Secret  string `default:"pa$$word"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	host, _ := os.Hostname()
+	if cfg.Host != host || cfg.Workers != runtime.NumCPU() || cfg.Cache != os.TempDir()+"/cache" {
+		t.Errorf("unexpected computed defaults: %+v", cfg)
+	}
+	if cfg.Region != "eu-west" || cfg.Price != "$(test-region) costs $5" || cfg.Plain != "$HOME" || cfg.Secret != "pa$$word" {
+		t.Errorf("unexpected defaults: %+v", cfg)
+	}
+	if p := ant.Provenance(); p["Host"] != LayerDefault {
+		t.Errorf("Host provenance = %v, want default", p["Host"])
+	}
+
+	type Bad struct {
+		A string `default:"$(nope)"`
+		B string `default:"$(test-broken)"`
+		C int    `default:"$(test-region)"`
+	}
+	err := New().SetConfig(&Bad{})
+	var me *MultiError
+	if !errors.As(err, &me) || len(me.Errors) != 3 {
+		t.Fatalf("expected three default errors, got %v", err)
+	}
+	for _, want := range []string{`unknown default function "nope"`, "no metadata", "('eu-west')"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("missing %q in %v", want, err)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package antconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"v3", "v3.0.0", 0},
+		{"v3.1.0", "v3", 1},
+		{"2.9.9", "v3", -1},
+		{"v3.0.0-rc1", "v3", -1},
+		{"v3.0.0-rc2", "v3.0.0-rc1", 1},
+		{"v10", "v9", 1},
+	}
+	for _, c := range cases {
+		if got := compareVersions(c.a, c.b); got != c.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestRemovedIn(t *testing.T) {
+	type Cfg struct {
+		Old  string `env:"RM_OLD" flag:"old" removed_in:"v3"`
+		Keep string `env:"RM_KEEP" default:"k"`
+		DB   struct {
+			Legacy int `json:"legacy_port" removed_in:"v3"`
+		}
+	}
+
+	t.Run("env used after removal fails", func(t *testing.T) {
+		t.Setenv("RM_OLD", "x")
+		var cfg Cfg
+		ant := New()
+		ant.SetAppVersion("v3.1.0")
+		if err := ant.SetConfig(&cfg); err != nil {
+			t.Fatal(err)
+		}
+		err := ant.WriteConfigValues()
+		if !errors.Is(err, ErrKeyRemoved) {
+			t.Fatalf("expected ErrKeyRemoved, got %v", err)
+		}
+		if !strings.Contains(err.Error(), "env var RM_OLD") || !strings.Contains(err.Error(), "Old was removed in v3") {
+			t.Fatalf("unexpected message: %v", err)
+		}
+	})
+
+	t.Run("before removal version is allowed", func(t *testing.T) {
+		t.Setenv("RM_OLD", "x")
+		var cfg Cfg
+		ant := New()
+		ant.SetAppVersion("v2.9.0")
+		if err := ant.SetConfig(&cfg); err != nil {
+			t.Fatal(err)
+		}
+		if err := ant.WriteConfigValues(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if cfg.Old != "x" {
+			t.Fatalf("expected Old from env, got %q", cfg.Old)
+		}
+	})
+
+	t.Run("flag and config key are detected", func(t *testing.T) {
+		dir := t.TempDir()
+		p := filepath.Join(dir, "config.json")
+		if err := os.WriteFile(p, []byte(`{"DB": {"legacy_port": 1}}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		var cfg Cfg
+		ant := New()
+		ant.SetAppVersion("v3")
+		ant.SetFlagArgs([]string{"--old=y"})
+		if err := ant.SetConfigPath(p); err != nil {
+			t.Fatal(err)
+		}
+		if err := ant.SetConfig(&cfg); err != nil {
+			t.Fatal(err)
+		}
+		err := ant.WriteConfigValues()
+		if !errors.Is(err, ErrKeyRemoved) {
+			t.Fatalf("expected ErrKeyRemoved, got %v", err)
+		}
+		for _, want := range []string{"flag --old", "config key DB.legacy_port"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Fatalf("expected %q in error, got %v", want, err)
+			}
+		}
+	})
+
+	t.Run("unused removed key is fine", func(t *testing.T) {
+		var cfg Cfg
+		ant := New()
+		ant.SetAppVersion("v4")
+		if err := ant.SetConfig(&cfg); err != nil {
+			t.Fatal(err)
+		}
+		if err := ant.WriteConfigValues(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package antconfig
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	type DB struct {
+		Host string `json:"host" env:"DB_HOST" required:"true"`
+	}
+	type Cfg struct {
+		DB     *DB    `json:"db"`
+		Token  string `json:"-" env:"TOKEN" secret:"true" desc:"API token"`
+		Limits []int  `default:"[1]"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	want := Descriptor{Fields: []FieldDescriptor{
+		{Path: "DB.Host", Key: "db.host", Type: "string", Kind: "string", Tag: `json:"host" env:"DB_HOST" required:"true"`, Env: "DB_HOST", Required: true},
+		{Path: "Token", Type: "string", Kind: "string", Tag: `json:"-" env:"TOKEN" secret:"true" desc:"API token"`, Env: "TOKEN", Desc: "API token", Secret: true},
+		{Path: "Limits", Key: "Limits", Type: "[]int", Kind: "slice", Tag: `default:"[1]"`, Default: "[1]"},
+	}}
+	if got := ant.Describe(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Describe:\n got %+v\nwant %+v", got, want)
+	}
+	if cfg.DB != nil {
+		t.Error("Describe must not touch the registered config")
+	}
+
+	var b strings.Builder
+	if err := ant.WriteDescriptor(&b); err != nil {
+		t.Fatal(err)
+	}
+	var back Descriptor
+	if err := json.Unmarshal([]byte(b.String()), &back); err != nil || !reflect.DeepEqual(back, want) {
+		t.Fatalf("descriptor round trip: %v\n%s", err, b.String())
+	}
+}
@@ -0,0 +1,110 @@
+package antconfig
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmbeddedConfigLayering(t *testing.T) {
+	type Cfg struct {
+		Host  string `default:"localhost"`
+		Port  int    `default:"80"`
+		Debug bool   `env:"EMB_TEST_DEBUG"`
+		Level string `default:"info"`
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"Port": 9000}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	if err := ac.SetEnvironment(map[string]string{"EMB_TEST_DEBUG": "false"}); err != nil {
+		t.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{})
+	embedded := []byte(`{
+		// baked into the binary
+		"Host": "prod.example.com",
+		"Port": 8080,
+		"Debug": true,
+	}`)
+	if err := ac.SetEmbeddedConfig(embedded, ""); err != nil {
+		t.Fatal(err)
+	}
+	embedded[0] = 'x' // the data is copied
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "prod.example.com" || cfg.Port != 9000 || cfg.Debug || cfg.Level != "info" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	prov := ac.Provenance()
+	want := map[string]Layer{"Host": LayerEmbedded, "Port": LayerFile, "Debug": LayerEnv, "Level": LayerDefault}
+	for k, v := range want {
+		if prov[k] != v {
+			t.Errorf("provenance[%s] = %v, want %v", k, prov[k], v)
+		}
+	}
+}
+
+func TestEmbeddedConfigRegisteredFormat(t *testing.T) {
+	type Cfg struct{ Name string }
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	ac.SetDiscovery(func(string) (string, error) { return "", ErrConfigNotFound })
+	if err := ac.SetEmbeddedConfig([]byte("Name=baked"), "KV"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err == nil || !strings.Contains(err.Error(), "no format registered for .kv") {
+		t.Fatalf("expected unregistered format error, got %v", err)
+	}
+	kv := func(data []byte) ([]byte, error) {
+		k, v, _ := bytes.Cut(bytes.TrimSpace(data), []byte("="))
+		return []byte(`{"` + string(k) + `": "` + string(v) + `"}`), nil
+	}
+	if err := ac.RegisterFormat(".kv", kv); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "baked" {
+		t.Fatalf("Name = %q, want baked", cfg.Name)
+	}
+}
+
+func TestEmbeddedConfigUnknownKeyWarning(t *testing.T) {
+	type Cfg struct{ Name string }
+	var cfg Cfg
+	var warnings []Warning
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	ac.SetDiscovery(func(string) (string, error) { return "", ErrConfigNotFound })
+	ac.OnWarning(func(w Warning) { warnings = append(warnings, w) })
+	if err := ac.SetEmbeddedConfig([]byte(`{"Nmae": "typo"}`), "json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Kind != WarningUnknownKey || warnings[0].Source != LayerEmbedded {
+		t.Fatalf("unexpected warnings: %+v", warnings)
+	}
+}
+
+func TestEmbeddedConfigLocked(t *testing.T) {
+	ac := New()
+	ac.LockSources()
+	if err := ac.SetEmbeddedConfig([]byte(`{}`), "json"); !errors.Is(err, ErrSourcesLocked) {
+		t.Fatalf("expected ErrSourcesLocked, got %v", err)
+	}
+}
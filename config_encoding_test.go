@@ -0,0 +1,96 @@
+package antconfig
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncodingTag(t *testing.T) {
+	type Cfg struct {
+		Key    []byte `json:"key" encoding:"base64" env:"ENC_KEY"`
+		Salt   string `json:"salt" encoding:"hex" flag:"salt"`
+		Token  []byte `json:"token" encoding:"base64url" default:"aGk"`
+		Secret []byte `json:"secret" encoding:"hex"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"secret": "cafe"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ENC_KEY", "AAEC/w==\n")
+
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--salt", "6869"})
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if string(cfg.Key) != "\x00\x01\x02\xff" || cfg.Salt != "hi" || string(cfg.Token) != "hi" || string(cfg.Secret) != "\xca\xfe" {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+
+	t.Setenv("ENC_KEY", "not base64!")
+	err := ac.WriteConfigValues()
+	var me *MultiError
+	if !errors.As(err, &me) || len(me.Errors) != 1 || me.Errors[0].Path != "Key" || me.Errors[0].Source != LayerEnv {
+		t.Fatalf("bad env value: %v", err)
+	}
+
+	// Bound flags are decoded while parsing
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := New().MustSetConfig(&Cfg{}).BindConfigFlags(fs); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Parse([]string{"--salt", "zz"}); err == nil {
+		t.Fatal("invalid hex flag accepted")
+	}
+
+	err = New().SetConfig(&struct {
+		N int    `encoding:"base64"`
+		S string `encoding:"rot13"`
+	}{})
+	if err == nil || !strings.Contains(err.Error(), "field N") || !strings.Contains(err.Error(), "rot13") {
+		t.Fatalf("bad encoding tags: %v", err)
+	}
+}
+
+func TestEncodingTagFromSources(t *testing.T) {
+	type Cfg struct {
+		Key  []byte `json:"key" encoding:"base64"`
+		Salt string `json:"salt" encoding:"hex"`
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	if err := ac.DisableAutoDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.AddSource(optionSource{name: "vault", values: map[string]any{"key": "AAEC/w=="}}, PriorityFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.AddValues(map[string]any{"salt": "6869"}, PriorityFlag); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if string(cfg.Key) != "\x00\x01\x02\xff" || cfg.Salt != "hi" {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+
+	if err := ac.AddValues(map[string]any{"key": "not base64!"}, PriorityFlag); err != nil {
+		t.Fatal(err)
+	}
+	err := ac.WriteConfigValues()
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "Key" || fe.Source != Layer("memory") || !strings.Contains(err.Error(), "as base64") {
+		t.Fatalf("expected a decode error for Key, got %v", err)
+	}
+}
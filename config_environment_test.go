@@ -0,0 +1,84 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetEnvironment(t *testing.T) {
+	type Cfg struct {
+		Host string `env:"ISO_HOST"`
+		Port int    `env:"ISO_PORT"`
+		User string `env:"ISO_USER"`
+	}
+	t.Setenv("ISO_HOST", "from-process")
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envPath, []byte("ISO_PORT=1\nISO_USER=dotenv\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 4; i++ {
+		port := string(rune('5' + i))
+		t.Run(port, func(t *testing.T) {
+			t.Parallel()
+			var cfg Cfg
+			ant := New().MustSetConfig(&cfg)
+			if err := ant.SetEnvPath(envPath); err != nil {
+				t.Fatal(err)
+			}
+			if err := ant.SetEnvironment(map[string]string{"ISO_PORT": port}); err != nil {
+				t.Fatal(err)
+			}
+			if err := ant.WriteConfigValues(); err != nil {
+				t.Fatal(err)
+			}
+			// The real ISO_HOST is invisible; the .env value yields to the
+			// injected ISO_PORT and fills ISO_USER
+			if cfg.Host != "" || cfg.Port != int(port[0]-'0') || cfg.User != "dotenv" {
+				t.Errorf("unexpected config: %+v", cfg)
+			}
+		})
+	}
+	t.Cleanup(func() {
+		if _, ok := os.LookupEnv("ISO_USER"); ok {
+			t.Error(".env values must not be exported with an injected environment")
+		}
+	})
+}
+
+func TestSetLookupEnv(t *testing.T) {
+	type Cfg struct {
+		Host string `env:"LOOKUP_HOST"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetLookupEnv(func(key string) (string, bool) {
+		if key == "LOOKUP_HOST" {
+			return "injected", true
+		}
+		return "", false
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "injected" || ant.Provenance()["Host"] != LayerEnv {
+		t.Fatalf("unexpected result: %+v %v", cfg, ant.Provenance())
+	}
+
+	d, err := ant.Preview(map[string]string{})
+	if err != nil || len(d.Changes) != 0 {
+		t.Fatalf("Preview should see the injected environment: %+v %v", d, err)
+	}
+
+	if err := ant.SetLookupEnv(nil); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOOKUP_HOST", "process")
+	if err := ant.WriteConfigValues(); err != nil || cfg.Host != "process" {
+		t.Fatalf("nil lookup should restore the process environment: %q %v", cfg.Host, err)
+	}
+}
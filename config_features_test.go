@@ -0,0 +1,105 @@
+package antconfig
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestFeatures(t *testing.T) {
+	type Cfg struct {
+		Checkout bool `feature:"new-checkout" env:"FEAT_CHECKOUT"`
+		Beta     *struct {
+			Search bool `feature:"beta-search" default:"true"`
+		} `default:"nil" enablekey:"FEAT_BETA"`
+	}
+	env := map[string]string{"FEAT_CHECKOUT": "true"}
+	var cfg Cfg
+	ac := New()
+	if err := ac.DisableAutoDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetEnvironment(env); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if ac.Feature("new-checkout") {
+		t.Fatal("feature on before loading")
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if !ac.Feature("new-checkout") || ac.Feature("beta-search") || ac.Feature("nope") {
+		t.Fatalf("unexpected features: %+v", ac.Features())
+	}
+
+	// Runtime toggles survive reloads until reset
+	if err := ac.SetFeature("new-checkout", false); err != nil {
+		t.Fatal(err)
+	}
+	env["FEAT_BETA"] = "true"
+	if err := ac.SetEnvironment(env); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if ac.Feature("new-checkout") || !ac.Feature("beta-search") || !cfg.Checkout {
+		t.Fatalf("unexpected features after reload: %+v", ac.Features())
+	}
+	want := []FeatureState{
+		{Name: "beta-search", Path: "Beta.Search", Enabled: true, Configured: true},
+		{Name: "new-checkout", Path: "Checkout", Enabled: false, Configured: true, Overridden: true},
+	}
+	if got := ac.Features(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Features() = %+v, want %+v", got, want)
+	}
+	if err := ac.ResetFeature("new-checkout"); err != nil {
+		t.Fatal(err)
+	}
+	if !ac.Feature("new-checkout") {
+		t.Fatal("ResetFeature did not restore the loaded value")
+	}
+	if err := ac.SetFeature("nope", true); err == nil {
+		t.Fatal("expected error for unknown feature")
+	}
+
+	// Reads race with toggles and reloads without locks
+	var wg sync.WaitGroup
+	for i := range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				_ = ac.Feature("new-checkout")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			_ = ac.SetFeature("beta-search", i%2 == 0)
+			_ = ac.LoadInto(new(Cfg))
+		}()
+	}
+	wg.Wait()
+}
+
+func TestFeatureTags(t *testing.T) {
+	cases := map[string]struct {
+		cfg  any
+		want string
+	}{
+		"not a bool": {&struct {
+			Rate int `feature:"rate"`
+		}{}, "needs a bool field"},
+		"duplicate": {&struct {
+			A bool `feature:"x"`
+			B bool `feature:"x"`
+		}{}, `feature "x" is already used by A`},
+	}
+	for name, tc := range cases {
+		if err := New().SetConfig(tc.cfg); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected error containing %q, got %v", name, tc.want, err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package antconfig
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSeedFlagDefaults(t *testing.T) {
+	type Cfg struct {
+		Host    string        `json:"host" flag:"host" default:"localhost" desc:"server host"`
+		Port    int           `json:"port" flag:"port" env:"SEED_PORT" default:"80"`
+		Timeout time.Duration `json:"timeout" flag:"timeout" default:"5s"`
+		Debug   *bool         `json:"debug" flag:"debug"`
+		Token   string        `json:"token" flag:"token" required:"true"`
+		Name    string        `json:"name" flag:"name"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"host": "db.internal", "timeout": 90000000000, "debug": true}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	if err := ac.SetEnvironment(map[string]string{"SEED_PORT": "8080"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	ac.MustBindConfigFlags(fs)
+	if err := ac.SeedFlagDefaults(fs); err != nil {
+		t.Fatalf("a missing required value must not fail seeding: %v", err)
+	}
+	if cfg.Host != "" {
+		t.Fatalf("the registered struct must be left untouched: %+v", cfg)
+	}
+	for name, want := range map[string]string{"host": "db.internal", "port": "8080", "timeout": "1m30s", "debug": "true", "name": ""} {
+		if got := fs.Lookup(name).DefValue; got != want {
+			t.Errorf("%s: DefValue = %q, want %q", name, got, want)
+		}
+	}
+
+	var out bytes.Buffer
+	fs.SetOutput(&out)
+	fs.PrintDefaults()
+	if !strings.Contains(out.String(), `(default "db.internal")`) {
+		t.Errorf("PrintDefaults:\n%s", out.String())
+	}
+	help := ac.FlagHelpString()
+	if !strings.Contains(help, `server host (default "db.internal")`) || !strings.Contains(help, "(default 8080)") {
+		t.Errorf("WriteFlagHelp:\n%s", help)
+	}
+
+	// Flags still win once parsed
+	if err := fs.Parse([]string{"--port", "9000", "--token", "t"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 9000 || cfg.Host != "db.internal" || cfg.Timeout != 90*time.Second || cfg.Debug == nil || !*cfg.Debug {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+
+	if err := New().SeedFlagDefaults(fs); err == nil {
+		t.Fatal("expected an error without SetConfig")
+	}
+}
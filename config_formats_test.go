@@ -0,0 +1,68 @@
+package antconfig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// attrsToJSON is a toy stand-in for an HCL decoder: it converts flat
+// `name = "value"` attribute lines into a JSON object.
+func attrsToJSON(data []byte) ([]byte, error) {
+	obj := map[string]any{}
+	for _, line := range strings.Split(string(data), "\n") {
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		var val any
+		if err := json.Unmarshal([]byte(strings.TrimSpace(v)), &val); err != nil {
+			return nil, err
+		}
+		obj[strings.TrimSpace(k)] = val
+	}
+	return json.Marshal(obj)
+}
+
+func TestRegisterFormat(t *testing.T) {
+	type Cfg struct {
+		Region string `json:"region"`
+		Size   int    `json:"size" env:"FORMAT_SIZE"`
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "infra.hcl")
+	if err := os.WriteFile(path, []byte("region = \"eu-west-1\"\nsize = 3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("FORMAT_SIZE", "5")
+
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.RegisterFormat("hcl", attrsToJSON); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Region != "eu-west-1" || cfg.Size != 5 {
+		t.Fatalf("expected file values with env on top, got %+v", cfg)
+	}
+	if p := ant.Provenance(); p["Region"] != LayerFile || p["Size"] != LayerEnv {
+		t.Fatalf("unexpected provenance: %v", p)
+	}
+
+	if err := os.WriteFile(path, []byte("region = nope\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err == nil || !strings.Contains(err.Error(), "error decoding config file") {
+		t.Fatalf("expected decode error, got %v", err)
+	}
+	if err := ant.RegisterFormat(".json", attrsToJSON); err == nil {
+		t.Fatal("expected built-in formats to be protected")
+	}
+}
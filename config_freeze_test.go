@@ -0,0 +1,66 @@
+package antconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFreeze(t *testing.T) {
+	type DB struct {
+		Host string            `json:"host" env:"FREEZE_HOST" default:"localhost"`
+		Tags map[string]string `json:"tags"`
+	}
+	type Cfg struct {
+		DB    *DB `json:"db"`
+		Conns int `json:"-" antconfig:"-"`
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--"})
+	if err := ac.VerifyFrozen(); err != nil || ac.Frozen() {
+		t.Fatalf("unfrozen config: %v", err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.Freeze(); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Conns++
+	if err := ac.VerifyFrozen(); err != nil {
+		t.Fatalf("ignored field counted as a mutation: %v", err)
+	}
+
+	cfg.DB.Tags = map[string]string{"a": "1"}
+	err := ac.VerifyFrozen()
+	if !errors.Is(err, ErrConfigMutated) {
+		t.Fatalf("map mutation not detected: %v", err)
+	}
+	if debugBuild && !strings.Contains(err.Error(), "DB.Tags") {
+		t.Fatalf("debug builds should name the field: %v", err)
+	}
+	if debugBuild {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Lookup on a mutated frozen config did not panic")
+				}
+			}()
+			ac.Lookup("db.host")
+		}()
+	}
+	cfg.DB.Tags = nil
+	if err := ac.VerifyFrozen(); err != nil {
+		t.Fatalf("restored config: %v", err)
+	}
+
+	// A reload is a legitimate change and renews the checksum
+	t.Setenv("FREEZE_HOST", "db.internal")
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.VerifyFrozen(); err != nil || cfg.DB.Host != "db.internal" {
+		t.Fatalf("after reload: %v, host %q", err, cfg.DB.Host)
+	}
+}
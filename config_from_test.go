@@ -0,0 +1,140 @@
+package antconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// vaultSource is a named Source for `from` tests.
+type vaultSource map[string]any
+
+func (v vaultSource) Name() string { return "vault" }
+
+func (v vaultSource) Load(context.Context) (map[string]any, error) { return v, nil }
+
+func TestFromTagErrors(t *testing.T) {
+	type Cfg struct {
+		User     string `env:"FROM_TEST_USER"`
+		Password string `env:"FROM_TEST_PASSWORD" flag:"password" from:"env,flag"`
+		Token    string `json:"token" from:"nofile" default:"none"`
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"User": "bob", "password": "hunter2", "token": "t0"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	if err := ac.SetEnvironment(map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{})
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	err := ac.WriteConfigValues()
+	if !errors.Is(err, ErrSourceNotAllowed) {
+		t.Fatalf("expected ErrSourceNotAllowed, got %v", err)
+	}
+	var me *MultiError
+	if !errors.As(err, &me) || len(me.Errors) != 2 {
+		t.Fatalf("expected 2 field errors, got %v", err)
+	}
+	for _, fe := range me.Errors {
+		if fe.Source != LayerFile || fe.Raw != "" {
+			t.Errorf("unexpected field error %+v", fe)
+		}
+	}
+	if strings.Contains(err.Error(), "hunter2") {
+		t.Fatalf("error leaks the refused value: %v", err)
+	}
+	if want := `Password: value from a disallowed source: file (from:"env,flag")`; me.Errors[0].Error() != want {
+		t.Fatalf("error = %q, want %q", me.Errors[0].Error(), want)
+	}
+	if cfg.Password != "" || cfg.Token != "none" || cfg.User != "bob" {
+		t.Fatalf("refused values were applied: %+v", cfg)
+	}
+}
+
+func TestFromTagAllowsListedLayers(t *testing.T) {
+	type Cfg struct {
+		Password string `env:"FROM_TEST_PASSWORD" flag:"password" from:"env,flag"`
+		Region   string `env:"FROM_TEST_REGION" from:"nodotenv"`
+		Vault    string `from:"vault"`
+	}
+	dir := t.TempDir()
+	envPath := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envPath, []byte("FROM_TEST_REGION=eu\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	if err := ac.SetEnvironment(map[string]string{"FROM_TEST_PASSWORD": "s3cret"}); err != nil {
+		t.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{})
+	ac.SetDiscovery(func(string) (string, error) { return "", ErrConfigNotFound })
+	if err := ac.SetEnvPath(envPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.AddSource(vaultSource{"Vault": "v"}, PriorityEnv); err != nil {
+		t.Fatal(err)
+	}
+	err := ac.WriteConfigValues()
+	var me *MultiError
+	if !errors.As(err, &me) || len(me.Errors) != 1 || me.Errors[0].Path != "Region" || me.Errors[0].Source != LayerDotEnv {
+		t.Fatalf("expected only the .env Region value to be refused, got %v", err)
+	}
+	if cfg.Password != "s3cret" || cfg.Vault != "v" || cfg.Region != "" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestFromTagRefusesSources(t *testing.T) {
+	type Cfg struct {
+		Password string `from:"env"`
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	ac.SetDiscovery(func(string) (string, error) { return "", ErrConfigNotFound })
+	if err := ac.AddValues(map[string]any{"Password": "x"}, PriorityFlag); err != nil {
+		t.Fatal(err)
+	}
+	err := ac.WriteConfigValues()
+	var me *MultiError
+	if !errors.As(err, &me) || len(me.Errors) != 1 || me.Errors[0].Source != LayerMemory {
+		t.Fatalf("expected memory value to be refused, got %v", err)
+	}
+}
+
+func TestFromTagValidation(t *testing.T) {
+	cases := map[string]any{
+		"mixed": &struct {
+			A string `from:"env,nofile"`
+		}{},
+		"empty": &struct {
+			A string `from:"env,"`
+		}{},
+		"env tag": &struct {
+			A string `env:"A" from:"noenv,nodotenv"`
+		}{},
+		"flag": &struct {
+			A string `flag:"a" from:"file"`
+		}{},
+	}
+	for name, cfg := range cases {
+		if err := New().SetConfig(cfg); err == nil {
+			t.Errorf("%s: expected SetConfig to fail", name)
+		}
+	}
+	if err := New().SetConfig(&struct {
+		A string `from:"notary"`
+	}{}); err != nil {
+		t.Errorf("source name starting with no: %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package antconfig
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSetFSReadsConfigAndDotEnv(t *testing.T) {
+	type Cfg struct {
+		Host  string `default:"localhost"`
+		Port  int    `default:"80"`
+		Token string `env:"FS_TEST_TOKEN"`
+		Extra string `env:"FS_TEST_EXTRA"`
+	}
+	fsys := fstest.MapFS{
+		"conf/app.jsonc": {Data: []byte(`{"Host": "example.com" /* bundled */}`)},
+		"conf/.envrc":    {Data: []byte("export FS_TEST_TOKEN=abc\ndotenv extra.env\n")},
+		"conf/extra.env": {Data: []byte("FS_TEST_EXTRA=${FS_TEST_TOKEN}-x\n")},
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	if err := ac.SetEnvironment(map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{})
+	if err := ac.SetFS(fsys); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetConfigPath("./conf/app.jsonc"); err != nil {
+		t.Fatalf("SetConfigPath: %v", err)
+	}
+	if err := ac.SetEnvPath("/conf/.envrc"); err != nil {
+		t.Fatalf("SetEnvPath: %v", err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "example.com" || cfg.Port != 80 || cfg.Token != "abc" || cfg.Extra != "abc-x" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestSetFSMissingFiles(t *testing.T) {
+	ac := New()
+	if err := ac.SetFS(fstest.MapFS{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetConfigPath("config.json"); !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("expected ErrConfigNotFound, got %v", err)
+	}
+	if err := ac.SetEnvPath(".env"); !errors.Is(err, ErrEnvFileNotFound) {
+		t.Fatalf("expected ErrEnvFileNotFound, got %v", err)
+	}
+}
+
+func TestSetFSDiscoversAtRoot(t *testing.T) {
+	type Cfg struct {
+		Name string `default:"none"`
+		Mode string `env:"FS_TEST_MODE"`
+	}
+	fsys := fstest.MapFS{
+		"config.json": {Data: []byte(`{"Name": "embedded"}`)},
+		".env":        {Data: []byte("FS_TEST_MODE=prod\n")},
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	if err := ac.SetEnvironment(map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{})
+	if err := ac.SetFS(fsys); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "embedded" || cfg.Mode != "prod" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if got := ac.Provenance()["Name"]; got != LayerFile {
+		t.Fatalf("Name provenance = %v, want file", got)
+	}
+}
+
+func TestSetFSSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte(`{"Name": "signed"}`)
+	type Cfg struct{ Name string }
+	load := func(sig []byte) error {
+		var cfg Cfg
+		ac := New().MustSetConfig(&cfg)
+		ac.SetFlagArgs([]string{})
+		if err := ac.RequireSignature(pub); err != nil {
+			t.Fatal(err)
+		}
+		fsys := fstest.MapFS{"config.json": {Data: data}}
+		if sig != nil {
+			fsys["config.json.sig"] = &fstest.MapFile{Data: sig}
+		}
+		if err := ac.SetFS(fsys); err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.SetConfigPath("config.json"); err != nil {
+			t.Fatal(err)
+		}
+		return ac.WriteConfigValues()
+	}
+	if err := load(ed25519.Sign(priv, data)); err != nil {
+		t.Fatalf("signed load: %v", err)
+	}
+	if err := load(nil); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("expected ErrSignatureInvalid, got %v", err)
+	}
+}
+
+func TestSetFSLocked(t *testing.T) {
+	ac := New()
+	ac.LockSources()
+	if err := ac.SetFS(fstest.MapFS{}); !errors.Is(err, ErrSourcesLocked) {
+		t.Fatalf("expected ErrSourcesLocked, got %v", err)
+	}
+}
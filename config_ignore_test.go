@@ -0,0 +1,67 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIgnoreTag(t *testing.T) {
+	type Runtime struct {
+		Conn string `default:"dial" env:"IGNORE_CONN" flag:"conn"`
+	}
+	type Cfg struct {
+		Host    string            `json:"host" default:"localhost" env:"IGNORE_HOST"`
+		Token   string            `json:"token" default:"t" env:"IGNORE_TOKEN" flag:"token" antconfig:"-"`
+		Runtime Runtime           `json:"runtime" antconfig:"-"`
+		Cache   map[string]string `json:"cache" antconfig:"-"`
+	}
+	t.Setenv("IGNORE_HOST", "env-host")
+	t.Setenv("IGNORE_TOKEN", "env-token")
+	t.Setenv("IGNORE_CONN", "env-conn")
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"token": "file", "runtime": {"Conn": "file"}, "cache": {"k": "v"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Cfg{Cache: map[string]string{"live": "1"}}
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--token", "flag"})
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	var warnings []Warning
+	ac.OnWarning(func(w Warning) { warnings = append(warnings, w) })
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "env-host" || cfg.Token != "" || cfg.Runtime.Conn != "" || len(cfg.Cache) != 1 || cfg.Cache["live"] != "1" {
+		t.Fatalf("ignored fields were touched: %+v", cfg)
+	}
+	if len(warnings) != 3 {
+		t.Fatalf("expected unknown-key warnings for the ignored keys, got %+v", warnings)
+	}
+
+	flags, err := ac.ListFlags(&cfg)
+	if err != nil || len(flags) != 0 {
+		t.Fatalf("ListFlags = %+v, %v", flags, err)
+	}
+	if help := ac.EnvHelpString(); strings.Contains(help, "IGNORE_TOKEN") || strings.Contains(help, "IGNORE_CONN") {
+		t.Fatalf("ignored fields in env help:\n%s", help)
+	}
+	if d := ac.Describe(); len(d.Fields) != 1 || d.Fields[0].Path != "Host" {
+		t.Fatalf("Describe = %+v", d)
+	}
+	sample, err := ac.GenerateSample("jsonc")
+	if err != nil || strings.Contains(string(sample), "token") || strings.Contains(string(sample), "runtime") {
+		t.Fatalf("sample: %v\n%s", err, sample)
+	}
+	doc, err := ac.RedactedConfig()
+	if err != nil || len(doc) != 1 {
+		t.Fatalf("RedactedConfig = %v, %v", doc, err)
+	}
+	if err := ac.AddValues(map[string]any{"Token": "x"}, PriorityEnv); err == nil {
+		t.Fatal("AddValues accepted an ignored field")
+	}
+}
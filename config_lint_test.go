@@ -0,0 +1,55 @@
+package antconfig
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTagLint(t *testing.T) {
+	type db struct {
+		Host string `env:"LINT_DB_HOST"`
+	}
+	type Cfg struct {
+		Port     int    `env:"LINT_PORT" default:"80"`
+		host     string `env:"LINT_HOST"`
+		database db
+		notes    string
+	}
+	var cfg Cfg
+
+	ac := New().MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("LintOff: %v", err)
+	}
+
+	if err := ac.SetTagLint(LintWarn); err != nil {
+		t.Fatal(err)
+	}
+	var warnings []Warning
+	ac.OnWarning(func(w Warning) { warnings = append(warnings, w) })
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("LintWarn: %v", err)
+	}
+	if len(warnings) != 2 || warnings[0].Kind != WarningUnexportedTag || warnings[0].Path != "host" || warnings[1].Path != "database.Host" {
+		t.Fatalf("warnings = %+v", warnings)
+	}
+	if want := `field database.Host: tag env:"LINT_DB_HOST" is ignored because database is unexported`; warnings[1].Message != want {
+		t.Fatalf("message = %q, want %q", warnings[1].Message, want)
+	}
+
+	if err := ac.SetTagLint(LintError); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Port = 0
+	err := ac.WriteConfigValues()
+	var me *MultiError
+	if !errors.As(err, &me) || len(me.Errors) != 2 || !errors.Is(err, ErrUnexportedTag) {
+		t.Fatalf("LintError: %v", err)
+	}
+	if cfg.Port != 0 {
+		t.Fatal("LintError load applied values")
+	}
+	if err := ac.SetTagLint(LintLevel(7)); err == nil {
+		t.Fatal("unknown level accepted")
+	}
+}
@@ -0,0 +1,128 @@
+package antconfig
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLockSources(t *testing.T) {
+	type Cfg struct {
+		Port int `json:"port" default:"80"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"port": 81}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	ant.LockSources()
+	for name, err := range map[string]error{
+		"SetConfigPath":  ant.SetConfigPath(path),
+		"SetEnvPath":     ant.SetEnvPath(path),
+		"AddEnvPath":     ant.AddEnvPath(path),
+		"AddValues":      ant.AddValues(map[string]any{"Port": 1}, PriorityFlag),
+		"RegisterFormat": ant.RegisterFormat(".x", func(b []byte) ([]byte, error) { return b, nil }),
+		"SetEnvironment": ant.SetEnvironment(map[string]string{"PORT": "1"}),
+		"SetLookupEnv":   ant.SetLookupEnv(func(string) (string, bool) { return "", false }),
+		"SetSOPSKey":     ant.SetSOPSKeyProvider(SOPSDataKeyFromEnv("KEY")),
+		"SetCondition":   ant.SetCondition("os", "plan9"),
+		"DisableConfig":  ant.DisableConfigFile(),
+		"DisableDotEnv":  ant.DisableDotEnv(),
+		"DisableAuto":    ant.DisableAutoDiscovery(),
+		"RequireSig":     ant.RequireSignature(make(ed25519.PublicKey, ed25519.PublicKeySize)),
+		"Templating":     ant.EnableTemplating(),
+		"SetArgFiles":    ant.SetArgFiles(true),
+		"SetFlagPrefix":  ant.SetFlagPrefix("x-"),
+		"CaseFold":       ant.SetCaseInsensitive(true),
+		"RegisterParser": ant.RegisterParser(reflect.TypeFor[int](), func(s string) (any, error) { return 1, nil }),
+	} {
+		if !errors.Is(err, ErrSourcesLocked) {
+			t.Errorf("%s: expected ErrSourcesLocked, got %v", name, err)
+		}
+	}
+	if ant.ConfigPath() != "" || len(ant.EnvPaths()) != 0 || ant.lookupEnv != nil || ant.conditions != nil || ant.noConfigFile ||
+		ant.signingKey != nil || ant.templating || ant.flagPrefix != "" || ant.parsers != nil {
+		t.Fatal("locked setters must not change state")
+	}
+	if err := ant.WriteConfigValues(); err != nil || cfg.Port != 80 {
+		t.Fatalf("loading must keep working while locked: %v (%+v)", err, cfg)
+	}
+
+	// A watcher may unlock while other goroutines check the lock
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = ant.SourcesLocked()
+		}
+	}()
+	relock := ant.UnlockForReload()
+	<-done
+	if err := ant.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	relock()
+	if !ant.SourcesLocked() {
+		t.Fatal("expected relock")
+	}
+	if err := ant.WriteConfigValues(); err != nil || cfg.Port != 81 {
+		t.Fatalf("expected reload from the new path: %v (%+v)", err, cfg)
+	}
+}
+
+func TestUnlockForReloadNests(t *testing.T) {
+	ant := New()
+	relockUnlocked := ant.UnlockForReload()
+	relockUnlocked()
+	if ant.SourcesLocked() {
+		t.Fatal("relocking an AntConfig that was never locked must not lock it")
+	}
+
+	ant.LockSources()
+	outer := ant.UnlockForReload()
+	inner := ant.UnlockForReload()
+	inner()
+	inner()
+	if ant.SourcesLocked() {
+		t.Fatal("an inner relock must not lock while the outer change is in progress")
+	}
+	if err := ant.SetFlagPrefix("x-"); err != nil {
+		t.Fatal(err)
+	}
+	outer()
+	if !ant.SourcesLocked() {
+		t.Fatal("expected the sources locked after the last relock")
+	}
+}
+
+func TestLockSourcesRefusesLaterProviders(t *testing.T) {
+	type Cfg struct {
+		Port int `json:"port"`
+	}
+	path := writeProviderConfig(t, `{"$sources": [{"type": "test-late", "values": {"port": 9}}]}`)
+	var cfg Cfg
+	ant := New()
+	if err := ant.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	ant.MustSetConfig(&cfg)
+	ant.LockSources()
+	RegisterProvider("test-late", func(options map[string]any) (Source, error) {
+		return optionSource{name: "late", values: options["values"].(map[string]any)}, nil
+	})
+	if err := ant.WriteConfigValues(); !errors.Is(err, ErrSourcesLocked) {
+		t.Fatalf("expected ErrSourcesLocked for a provider registered after the lock, got %v", err)
+	}
+	relock := ant.UnlockForReload()
+	relock()
+	if err := ant.WriteConfigValues(); err != nil || cfg.Port != 9 {
+		t.Fatalf("expected the provider accepted after a deliberate unlock: %v (%+v)", err, cfg)
+	}
+}
@@ -0,0 +1,146 @@
+package antconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestLogEffective(t *testing.T) {
+	type Cfg struct {
+		Host     string        `default:"localhost"`
+		Port     int           `env:"LOG_PORT"`
+		Timeout  time.Duration `default:"5s"`
+		Password string        `env:"LOG_PASSWORD" secret:"true"`
+		Token    string        `secret:"true"`
+		Debug    *struct {
+			Addr string
+		} `default:"nil"`
+	}
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetEnvironment(map[string]string{"LOG_PORT": "8080", "LOG_PASSWORD": "hunter2"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.DisableAutoDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	if err := ac.LogEffective(logger); err != nil {
+		t.Fatal(err)
+	}
+	type record struct {
+		Level, Msg, Path, Source string
+		Value                    any
+	}
+	got := map[string]record{}
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r record
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		if r.Level != "INFO" || r.Msg != "config" {
+			t.Fatalf("unexpected record: %+v", r)
+		}
+		got[r.Path] = r
+	}
+	want := map[string]record{
+		"Host":     {Value: "localhost", Source: "default"},
+		"Port":     {Value: float64(8080), Source: "env"},
+		"Timeout":  {Value: float64(5 * time.Second), Source: "default"},
+		"Password": {Value: Redacted, Source: "env"},
+		"Token":    {Value: "", Source: "unset"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d records, want %d: %v", len(got), len(want), got)
+	}
+	for path, w := range want {
+		if g := got[path]; g.Value != w.Value || g.Source != w.Source {
+			t.Errorf("%s: got value=%v source=%s, want value=%v source=%s", path, g.Value, g.Source, w.Value, w.Source)
+		}
+	}
+}
+
+func TestSetSlog(t *testing.T) {
+	type Cfg struct {
+		Host string `json:"host" default:"localhost" env:"SLOG_HOST"`
+		Port int    `json:"port" env:"SLOG_PORT"`
+		Key  string `json:"key" env:"SLOG_KEY" secret:"true"`
+	}
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetFS(fstest.MapFS{
+		"config.json": {Data: []byte(`{"host": "file-host", "port": 80, "key": "file-secret"}`)},
+		".env":        {Data: []byte("SLOG_PORT=81\n")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetEnvironment(map[string]string{"SLOG_HOST": "env-host", "SLOG_KEY": "env-secret"}); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	ac.SetSlog(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	ac.MustSetConfig(&cfg)
+	if err := ac.AddValues(map[string]any{"port": 90}, PriorityEnv); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+
+	var lines []string
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var r map[string]any
+		if err := dec.Decode(&r); err != nil {
+			t.Fatal(err)
+		}
+		if r["level"] != "DEBUG" {
+			t.Fatalf("unexpected level: %v", r)
+		}
+		line := fmt.Sprint(r["msg"])
+		for _, k := range []string{"name", "found", "path", "discovered", "priority", "layer", "previous"} {
+			if v, ok := r[k]; ok {
+				line += fmt.Sprintf(" %s=%v", k, v)
+			}
+		}
+		lines = append(lines, line)
+	}
+	want := []string{
+		"config candidate name=config.jsonc",
+		"config candidate name=config.json found=config.json",
+		"config file path=config.json discovered=true",
+		"value overridden path=Host layer=file previous=default",
+		".env file path=.env discovered=true",
+		"value overridden path=Port layer=dotenv previous=file",
+		"value overridden path=Host layer=env previous=file",
+		"value overridden path=Key layer=env previous=file",
+		"source name=memory priority=300",
+		"value overridden path=Port layer=memory previous=dotenv",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected records:\n%s\nwant:\n%s", strings.Join(lines, "\n"), strings.Join(want, "\n"))
+	}
+	if strings.Contains(buf.String(), "secret") {
+		t.Fatalf("values leaked into the log")
+	}
+}
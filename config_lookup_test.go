@@ -0,0 +1,38 @@
+package antconfig
+
+import "testing"
+
+func TestWasSet(t *testing.T) {
+	type DB struct {
+		Host string `json:"host" default:"localhost"`
+		Port int    `json:"port" default:"5432" env:"WASSET_PORT"`
+	}
+	type Cfg struct {
+		Database DB     `json:"database"`
+		Name     string `json:"name" default:"svc"`
+		Mode     string `json:"mode" flag:"mode"`
+	}
+	t.Setenv("WASSET_PORT", "5432")
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--"})
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]bool{
+		"database.port": true, // same value as the default, but given explicitly
+		"Database.Port": true,
+		"database":      true,
+		"database.host": false,
+		"name":          false,
+		"mode":          false,
+		"missing":       false,
+	} {
+		if got := ac.WasSet(key); got != want {
+			t.Errorf("WasSet(%q) = %v, want %v", key, got, want)
+		}
+	}
+	if !ac.IsSet("name") {
+		t.Error("IsSet should count defaults")
+	}
+}
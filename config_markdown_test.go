@@ -0,0 +1,65 @@
+package antconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkdownDoc(t *testing.T) {
+	type DB struct {
+		Host string `json:"host" env:"DB_HOST" envalias:"DATABASE_HOST" flag:"db-host" desc:"database host" required:"true"`
+		Port int    `json:"port" default:"5432" desc:"port | number"`
+	}
+	type Cfg struct {
+		DB      DB            `json:"db"`
+		Timeout time.Duration `json:"timeout" default:"5s" flag:"timeout" desc:"request \x60timeout\x60"`
+		Mode    string        `json:"mode" removed_in:"v3"`
+		Secret  string        `json:"-" env:"SECRET"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetFlagPrefix("app-"); err != nil {
+		t.Fatal(err)
+	}
+	got := ant.MarkdownDoc()
+	want := strings.Join([]string{
+		"| Key | Type | Default | Env | Flag | Description | Required |",
+		"|-----|------|---------|-----|------|-------------|----------|",
+		"| `db.host` | `string` |  | `DB_HOST` (was `DATABASE_HOST`) | `--app-db-host` | database host | yes |",
+		"| `db.port` | `int` | `5432` |  |  | port \\| number |  |",
+		"| `timeout` | `time.Duration` | `5s` |  | `--app-timeout` | request timeout |  |",
+		"| `mode` | `string` |  |  |  | Deprecated; removed in v3. |  |",
+		"|  | `string` |  | `SECRET` |  |  |  |",
+	}, "\n") + "\n"
+	if got != want {
+		t.Fatalf("MarkdownDoc mismatch:\n got:\n%s\nwant:\n%s", got, want)
+	}
+	if New().MarkdownDoc() != "" {
+		t.Error("expected empty doc without SetConfig")
+	}
+}
+
+func TestRequiredFields(t *testing.T) {
+	type Cfg struct {
+		Token string `json:"token" env:"REQ_TOKEN" flag:"token" required:"true"`
+		Port  int    `json:"port" default:"80" required:"true"`
+		Note  string `json:"note" required:"false"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	ant.SetFlagArgs([]string{"--unrelated"})
+	err := ant.WriteConfigValues()
+	var me *MultiError
+	if !errors.As(err, &me) || len(me.Errors) != 1 || me.Errors[0].Path != "Token" {
+		t.Fatalf("expected one missing-field error for Token, got %v", err)
+	}
+	if !errors.Is(err, ErrRequired) || !strings.Contains(err.Error(), "env var REQ_TOKEN, flag --token") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	t.Setenv("REQ_TOKEN", "t0k3n")
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateEnvMatrix(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(cfgPath, []byte(`{"Region": "eu"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	type Cfg struct {
+		Region  string `default:"us"`
+		Workers int    `default:"1" env:"MX_WORKERS"`
+		Mode    string `env:"MX_MODE" default:"fast"`
+	}
+	var cfg Cfg
+	ant := New()
+	if err := ant.SetConfigPath(cfgPath); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.SetConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	results, err := ant.GenerateEnvMatrix([]map[string]string{
+		{"MX_WORKERS": "4"},
+		{"MX_WORKERS": "8", "MX_MODE": "safe"},
+	})
+	if err != nil {
+		t.Fatalf("GenerateEnvMatrix: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	first := results[0].Config.(*Cfg)
+	second := results[1].Config.(*Cfg)
+	if first.Workers != 4 || first.Mode != "fast" || first.Region != "eu" {
+		t.Fatalf("unexpected first config: %+v", first)
+	}
+	if second.Workers != 8 || second.Mode != "safe" {
+		t.Fatalf("unexpected second config: %+v", second)
+	}
+	if p := results[0].Provenance; p["Workers"] != LayerEnv || p["Mode"] != LayerDefault || p["Region"] != LayerFile {
+		t.Fatalf("unexpected provenance: %v", p)
+	}
+	if cfg != (Cfg{}) {
+		t.Fatalf("registered config must be untouched, got %+v", cfg)
+	}
+	if _, ok := os.LookupEnv("MX_WORKERS"); ok {
+		t.Fatal("matrix overrides must not leak into the process environment")
+	}
+}
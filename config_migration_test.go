@@ -0,0 +1,72 @@
+package antconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestConfigMigrations(t *testing.T) {
+	type Cfg struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"version": 1, "server": "db.local:5432"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	// 1 → 2 splits "server" into host and port; 2 → 3 bumps the port
+	if err := ant.RegisterMigration(1, 2, func(doc map[string]any) error {
+		host, port, _ := strings.Cut(doc["server"].(string), ":")
+		delete(doc, "server")
+		doc["host"], doc["port"] = host, port
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.RegisterMigration(2, 3, func(doc map[string]any) error {
+		if doc["port"] == "5432" {
+			doc["port"] = 6432
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var warnings []Warning
+	ant.OnWarning(func(w Warning) { warnings = append(warnings, w) })
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "db.local" || cfg.Port != 6432 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings: %v", warnings)
+	}
+
+	if err := ant.RegisterMigration(1, 5, func(map[string]any) error { return nil }); err == nil {
+		t.Error("expected duplicate migration to be rejected")
+	}
+	if err := ant.RegisterMigration(3, 3, func(map[string]any) error { return nil }); err == nil {
+		t.Error("expected non-increasing migration to be rejected")
+	}
+
+	failing := errors.New("boom")
+	if err := ant.RegisterMigration(3, 4, func(map[string]any) error { return failing }); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"version": 3, "host": "h"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := ant.WriteConfigValues()
+	if !errors.Is(err, failing) || !strings.Contains(err.Error(), "from version 3 to 4") {
+		t.Fatalf("expected migration failure, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package antconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStrictEnvOnlySkipsDiscovery(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"Host": "from-file"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("MODE_TEST_PORT=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	type Cfg struct {
+		Host  string `default:"localhost"`
+		Port  int    `env:"MODE_TEST_PORT" default:"80"`
+		Level string `env:"MODE_TEST_LEVEL"`
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	if err := ac.SetEnvironment(map[string]string{"MODE_TEST_LEVEL": "debug"}); err != nil {
+		t.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{})
+	if err := ac.SetMode(StrictEnvOnly); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "localhost" || cfg.Port != 80 || cfg.Level != "debug" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	// The same directory is picked up in the default mode
+	if err := ac.SetMode(ModeDefault); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "from-file" || cfg.Port != 1 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestStrictEnvOnlyRejectsPaths(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	type Cfg struct{ Host string }
+	for name, set := range map[string]func(*AntConfig) error{
+		"config": func(ac *AntConfig) error { return ac.SetConfigPath(path) },
+		"env":    func(ac *AntConfig) error { return ac.AddEnvPath(path) },
+	} {
+		var cfg Cfg
+		ac := New().MustSetConfig(&cfg)
+		ac.SetFlagArgs([]string{})
+		if err := set(ac); err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.SetMode(StrictEnvOnly); err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.WriteConfigValues(); !errors.Is(err, ErrStrictMode) {
+			t.Errorf("%s: expected ErrStrictMode, got %v", name, err)
+		}
+	}
+	if err := New().SetMode(Mode(7)); err == nil {
+		t.Error("expected unknown mode to be rejected")
+	}
+}
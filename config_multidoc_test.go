@@ -0,0 +1,70 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMultiDocumentConfig(t *testing.T) {
+	type Cfg struct {
+		Name   string `json:"name"`
+		Server struct {
+			Host string `json:"host"`
+			Port int    `json:"port"`
+		} `json:"server"`
+		Tags []string `json:"tags"`
+	}
+	for _, tc := range []struct {
+		name, src string
+	}{
+		{"separator", `// base
+{"name": "app", "server": {"host": "localhost", "port": 80}, "tags": ["a", "b"]}
+---
+// production overrides
+{
+  "server": {"port": 443, },
+  "tags": ["prod"],
+}
+`},
+		{"ndjson", `{"name": "app", "server": {"host": "localhost", "port": 80}, "tags": ["a", "b"]}
+{"server": {"port": 443}, "tags": ["prod"]}
+`},
+	} {
+		path := filepath.Join(t.TempDir(), "config.jsonc")
+		if err := os.WriteFile(path, []byte(tc.src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var cfg Cfg
+		ac := New().MustSetConfig(&cfg)
+		ac.SetFlagArgs([]string{})
+		if err := ac.SetConfigPath(path); err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.WriteConfigValues(); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		// Nested objects merge key by key; other values are replaced
+		if cfg.Name != "app" || cfg.Server.Host != "localhost" || cfg.Server.Port != 443 || len(cfg.Tags) != 1 || cfg.Tags[0] != "prod" {
+			t.Fatalf("%s: cfg=%+v", tc.name, cfg)
+		}
+	}
+}
+
+func TestMultiDocumentConfigErrors(t *testing.T) {
+	for _, tc := range []struct {
+		src, want string
+	}{
+		{"{\"a\": 1}\n---\n[1]\n", "document 2 is not an object"},
+		{"{\"a\": 1}\n---\n{\"b\": }\n", "(line 3, column 7)"},
+	} {
+		if _, err := jsoncToJSON([]byte(tc.src), nil); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("jsoncToJSON(%q): err=%v, want %q", tc.src, err, tc.want)
+		}
+	}
+	// A single document is passed through unchanged
+	if js, err := jsoncToJSON([]byte(`{"a": 1,}`), nil); err != nil || string(js) != `{"a": 1 }` {
+		t.Errorf("single document: %q, %v", js, err)
+	}
+}
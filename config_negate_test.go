@@ -0,0 +1,61 @@
+package antconfig
+
+import (
+	"flag"
+	"io"
+	"testing"
+)
+
+func TestNegatedBoolFlags(t *testing.T) {
+	type Cfg struct {
+		Encrypt bool   `default:"true" flag:"encrypt"`
+		Verbose bool   `default:"true" flag:"verbose"`
+		NoCache bool   `flag:"no-cache"`
+		Name    string `flag:"name" default:"n"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	ant.SetFlagArgs([]string{"--no-encrypt", "pos", "--no-verbose=false", "--no-cache", "--no-name=x"})
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Encrypt || !cfg.Verbose || !cfg.NoCache || cfg.Name != "n" {
+		t.Fatalf("unexpected values: %+v", cfg)
+	}
+
+	cfg = Cfg{}
+	ant = New().MustSetConfig(&cfg)
+	if err := ant.SetFlagPrefix("app-"); err != nil {
+		t.Fatal(err)
+	}
+	ant.SetFlagArgs([]string{"--app-encrypt", "--no-app-encrypt"})
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Encrypt {
+		t.Fatal("expected the later --no-app-encrypt to win")
+	}
+
+	cfg = Cfg{}
+	ant = New().MustSetConfig(&cfg)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if err := ant.BindConfigFlags(fs); err != nil {
+		t.Fatal(err)
+	}
+	if fs.Lookup("no-name") != nil {
+		t.Fatal("non-boolean flags must not get a negated form")
+	}
+	if err := fs.Parse([]string{"-no-encrypt", "--verbose"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Encrypt || !cfg.Verbose {
+		t.Fatalf("unexpected values from FlagSet: %+v", cfg)
+	}
+	if p := ant.Provenance(); p["Encrypt"] != LayerFlag {
+		t.Errorf("Encrypt provenance = %v, want flag", p["Encrypt"])
+	}
+}
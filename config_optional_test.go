@@ -0,0 +1,84 @@
+package antconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigPathMissingAtLoad(t *testing.T) {
+	type Cfg struct {
+		Host string `default:"localhost"`
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"Host": "file"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); !errors.Is(err, ErrConfigRemoved) {
+		t.Fatalf("expected ErrConfigRemoved, got %v", err)
+	}
+
+	missing := filepath.Join(dir, "never.json")
+	if err := ac.SetConfigPath(missing); !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("expected ErrConfigNotFound from SetConfigPath, got %v", err)
+	}
+	err := ac.WriteConfigValues()
+	if !errors.Is(err, ErrConfigNotFound) || errors.Is(err, ErrConfigRemoved) {
+		t.Fatalf("expected ErrConfigNotFound at load, got %v", err)
+	}
+}
+
+func TestSetConfigPathOptional(t *testing.T) {
+	type Cfg struct {
+		Host string `default:"localhost"`
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	var cfg Cfg
+	var warnings []Warning
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	ac.OnWarning(func(w Warning) { warnings = append(warnings, w) })
+
+	// Never existed: skipped silently
+	if err := ac.SetConfigPathOptional(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil || cfg.Host != "localhost" || len(warnings) != 0 {
+		t.Fatalf("err=%v cfg=%+v warnings=%v", err, cfg, warnings)
+	}
+
+	// Present: loaded
+	if err := os.WriteFile(path, []byte(`{"Host": "file"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetConfigPathOptional(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil || cfg.Host != "file" {
+		t.Fatalf("err=%v cfg=%+v", err, cfg)
+	}
+
+	// Removed after it was set: skipped with a warning
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	cfg = Cfg{}
+	if err := ac.WriteConfigValues(); err != nil || cfg.Host != "localhost" {
+		t.Fatalf("err=%v cfg=%+v", err, cfg)
+	}
+	if len(warnings) != 1 || warnings[0].Kind != WarningMissingFile || warnings[0].Path != path {
+		t.Fatalf("unexpected warnings: %+v", warnings)
+	}
+}
@@ -0,0 +1,45 @@
+package antconfig
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestParseAndLoad(t *testing.T) {
+	type Cfg struct {
+		Port  int    `flag:"port" default:"80" desc:"listen port"`
+		Debug bool   `flag:"debug"`
+		Name  string `env:"PARSE_NAME"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	rest, err := ant.ParseAndLoad([]string{"-port", "8080", "--no-debug", "serve", "--", "-x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != 8080 || cfg.Debug {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if want := []string{"serve", "--", "-x"}; !reflect.DeepEqual(rest, want) {
+		t.Fatalf("rest = %q, want %q", rest, want)
+	}
+
+	// Help output goes to stderr; silence it for the test
+	stderr := os.Stderr
+	devnull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer devnull.Close()
+	os.Stderr = devnull
+	defer func() { os.Stderr = stderr }()
+	if _, err := New().MustSetConfig(&cfg).ParseAndLoad([]string{"--help"}); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("expected flag.ErrHelp, got %v", err)
+	}
+	if _, err := New().MustSetConfig(&cfg).ParseAndLoad([]string{"-port=http"}); err == nil {
+		t.Fatal("expected a parse error")
+	}
+}
@@ -0,0 +1,200 @@
+package antconfig
+
+import (
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testPort int
+
+type testPorts []int
+
+type testColor int
+
+func TestNamedAndExtendedKinds(t *testing.T) {
+	type Cfg struct {
+		Port    testPort      `default:"8080" env:"PARSE_PORT"`
+		Ports   testPorts     `default:"[1,2]"`
+		Addr    uintptr       `default:"4096"`
+		Phase   complex128    `default:"1+2i"`
+		Timeout time.Duration `default:"250ms"`
+		Start   time.Time     `default:"2024-01-02T03:04:05Z"`
+		Level   testLevel     `env:"PARSE_LEVEL"`
+	}
+	t.Setenv("PARSE_PORT", "9090")
+	t.Setenv("PARSE_LEVEL", "warn")
+	var cfg Cfg
+	if err := New().MustSetConfig(&cfg).WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Port != 9090 || len(cfg.Ports) != 2 || cfg.Addr != 4096 || cfg.Phase != 1+2i {
+		t.Fatalf("unexpected values: %+v", cfg)
+	}
+	if cfg.Timeout != 250*time.Millisecond || cfg.Start.Year() != 2024 || cfg.Level != 2 {
+		t.Fatalf("unexpected values: %+v", cfg)
+	}
+}
+
+func TestRegisterParser(t *testing.T) {
+	RegisterParser(func(s string) (testColor, error) {
+		switch strings.ToLower(s) {
+		case "red":
+			return 1, nil
+		case "green":
+			return 2, nil
+		}
+		return 0, fmt.Errorf("unknown color %q", s)
+	})
+	type Cfg struct {
+		Color testColor `default:"green" flag:"color"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	ant.SetFlagArgs([]string{"--color=RED"})
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Color != 1 {
+		t.Fatalf("expected registered parser to apply, got %d", cfg.Color)
+	}
+
+	type Bad struct {
+		Color testColor `default:"blue"`
+	}
+	var bad Bad
+	err := New().SetConfig(&bad)
+	if err == nil || !strings.Contains(err.Error(), `unknown color "blue"`) {
+		t.Fatalf("expected parser error from default validation, got %v", err)
+	}
+}
+
+type testByteSize int64
+
+func parseTestByteSize(s string) (any, error) {
+	units := map[string]int64{"KB": 1 << 10, "MB": 1 << 20, "GB": 1 << 30}
+	for suffix, mult := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			var v int64
+			if _, err := fmt.Sscan(n, &v); err != nil {
+				return nil, err
+			}
+			return testByteSize(v * mult), nil
+		}
+	}
+	return nil, fmt.Errorf("missing unit in %q", s)
+}
+
+func TestInstanceRegisterParser(t *testing.T) {
+	type Cfg struct {
+		Cache  testByteSize `default:"512MB"`
+		Buffer testByteSize `default:"1KB" env:"PARSE_BUFFER"`
+		Upload testByteSize `default:"1KB" env:"PARSE_UPLOAD"`
+		Max    testByteSize `default:"1KB" flag:"max"`
+	}
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFile, []byte("PARSE_UPLOAD=2MB\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PARSE_BUFFER", "64KB")
+
+	var cfg Cfg
+	ant := New()
+	if err := ant.RegisterParser(reflect.TypeOf(testByteSize(0)), parseTestByteSize); err != nil {
+		t.Fatal(err)
+	}
+	ant.SetDotEnvExport(false)
+	ant.SetFlagArgs([]string{"--max=3GB"})
+	if err := ant.SetEnvPath(envFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.SetConfig(&cfg); err != nil {
+		t.Fatalf("SetConfig: %v", err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Cache != 512<<20 || cfg.Buffer != 64<<10 || cfg.Upload != 2<<20 || cfg.Max != 3<<30 {
+		t.Fatalf("unexpected sizes: %+v", cfg)
+	}
+
+	// Parsers are per instance; a fresh AntConfig rejects the suffixed default
+	var other Cfg
+	if err := New().SetConfig(&other); err == nil {
+		t.Fatal("expected default validation error without the parser")
+	}
+	// Parser results must be assignable to the registered type
+	bad := New()
+	if err := bad.RegisterParser(reflect.TypeOf(testByteSize(0)), func(string) (any, error) { return "oops", nil }); err != nil {
+		t.Fatal(err)
+	}
+	if err := bad.SetConfig(&other); err == nil || !strings.Contains(err.Error(), "returned string") {
+		t.Fatalf("expected type mismatch error, got %v", err)
+	}
+}
+
+// testDecimal is a minimal fixed-point decimal standing in for a third-party
+// decimal type; it parses through encoding.TextUnmarshaler.
+type testDecimal struct {
+	units int64 // value * 10^4
+}
+
+func (d *testDecimal) UnmarshalText(b []byte) error {
+	whole, frac, _ := strings.Cut(string(b), ".")
+	if len(frac) > 4 {
+		return fmt.Errorf("too many decimal places in %q", b)
+	}
+	frac += strings.Repeat("0", 4-len(frac))
+	var w, f int64
+	if _, err := fmt.Sscan(whole+" "+frac, &w, &f); err != nil {
+		return err
+	}
+	d.units = w*10000 + f
+	return nil
+}
+
+func TestBigAndDecimalFields(t *testing.T) {
+	type Cfg struct {
+		Limit  big.Int     `default:"123456789012345678901234567890"`
+		Cap    *big.Int    `env:"BIG_CAP"`
+		Rate   *big.Rat    `default:"0.0025"`
+		Fee    testDecimal `default:"0.0001" flag:"fee"`
+		Scaled *big.Float  `default:"1.5"`
+	}
+	t.Setenv("BIG_CAP", "-98765432109876543210")
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	ant.SetFlagArgs([]string{"--fee=12.3456"})
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Limit.String() != "123456789012345678901234567890" {
+		t.Fatalf("unexpected big.Int: %s", cfg.Limit.String())
+	}
+	if cfg.Cap == nil || cfg.Cap.String() != "-98765432109876543210" {
+		t.Fatalf("unexpected *big.Int: %v", cfg.Cap)
+	}
+	if cfg.Rate.Cmp(big.NewRat(1, 400)) != 0 {
+		t.Fatalf("expected exact rate 1/400, got %s", cfg.Rate)
+	}
+	if cfg.Fee.units != 123456 {
+		t.Fatalf("unexpected decimal units: %d", cfg.Fee.units)
+	}
+	if cfg.Scaled.String() != "1.5" {
+		t.Fatalf("unexpected big.Float: %s", cfg.Scaled)
+	}
+
+	type Bad struct {
+		Fee testDecimal `default:"0.00001"`
+	}
+	var bad Bad
+	if err := New().SetConfig(&bad); err == nil {
+		t.Fatal("expected decimal default validation error")
+	}
+}
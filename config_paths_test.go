@@ -0,0 +1,123 @@
+package antconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestResolvePath(t *testing.T) {
+	type Cfg struct {
+		Cert  string   `json:"cert" resolve:"path"`
+		Key   *string  `json:"key" resolve:"path" default:"certs/key.pem"`
+		CAs   []string `json:"cas" resolve:"path"`
+		Token string   `json:"token" resolve:"path" env:"RESOLVE_TOKEN"`
+		Abs   string   `json:"abs" resolve:"path"`
+		Plain string   `json:"plain"`
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"cert": "tls/cert.pem", "cas": ["a.pem", "../b.pem"], "abs": "/etc/x.pem", "plain": "rel"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RESOLVE_TOKEN", "token.txt")
+	wd, _ := os.Getwd()
+
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--"})
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Cert != filepath.Join(dir, "tls/cert.pem") || *cfg.Key != filepath.Join(dir, "certs/key.pem") || cfg.Abs != "/etc/x.pem" || cfg.Plain != "rel" {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+	if len(cfg.CAs) != 2 || cfg.CAs[0] != filepath.Join(dir, "a.pem") || cfg.CAs[1] != filepath.Join(filepath.Dir(dir), "b.pem") {
+		t.Fatalf("CAs = %v", cfg.CAs)
+	}
+	if cfg.Token != filepath.Join(wd, "token.txt") {
+		t.Fatalf("env path should resolve against the working directory: %q", cfg.Token)
+	}
+
+	// With SetFS, paths stay relative names in the file system
+	var fsCfg Cfg
+	ac = New().MustSetConfig(&fsCfg)
+	ac.SetFlagArgs([]string{"--"})
+	if err := ac.SetFS(fstest.MapFS{"conf/config.json": {Data: []byte(`{"cert": "cert.pem"}`)}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetConfigPath("conf/config.json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if fsCfg.Cert != "conf/cert.pem" || fsCfg.Token != "token.txt" {
+		t.Fatalf("fs cfg = %+v", fsCfg)
+	}
+
+	type Bad struct {
+		N int    `resolve:"path"`
+		S string `resolve:"dir"`
+	}
+	err := New().SetConfig(&Bad{})
+	if err == nil || !strings.Contains(err.Error(), "field N") || !strings.Contains(err.Error(), "field S") {
+		t.Fatalf("bad resolve tags: %v", err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	type Cfg struct {
+		Cert  string  `json:"cert" loadfile:"true"`
+		Token *string `json:"token" loadfile:"trim" env:"LOADFILE_TOKEN"`
+		Key   string  `json:"key" loadfile:"true" env:"LOADFILE_KEY"`
+	}
+	dir := t.TempDir()
+	for name, data := range map[string]string{"cert.pem": "CERT\n", "token": "  s3cret\n"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"cert": "cert.pem"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOADFILE_TOKEN", filepath.Join(dir, "token"))
+
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--"})
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Cert != "CERT\n" || cfg.Token == nil || *cfg.Token != "s3cret" || cfg.Key != "" {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+
+	// A reload reads the files again rather than treating contents as paths
+	if err := ac.WriteConfigValues(); err != nil || cfg.Cert != "CERT\n" {
+		t.Fatalf("reload: %v, cert %q", err, cfg.Cert)
+	}
+
+	t.Setenv("LOADFILE_KEY", filepath.Join(dir, "missing.pem"))
+	err := ac.WriteConfigValues()
+	var me *MultiError
+	if !errors.As(err, &me) || me.Errors[0].Path != "Key" || me.Errors[0].Source != LayerEnv {
+		t.Fatalf("missing file: %v", err)
+	}
+
+	if err := New().SetConfig(&struct {
+		N int `loadfile:"true"`
+	}{}); err == nil {
+		t.Fatal("loadfile accepted an int field")
+	}
+}
@@ -0,0 +1,126 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPersist(t *testing.T) {
+	type Cfg struct {
+		Server struct {
+			Host    string        `json:"host"`
+			Port    int           `json:"port"`
+			Timeout time.Duration `json:"timeout"`
+		} `json:"server"`
+		Debug bool   `json:"debug"`
+		Token string `json:"-"`
+	}
+	path := filepath.Join(t.TempDir(), "config.jsonc")
+	src := `{
+  // Server settings
+  "server": {
+    "host": "localhost", // where to listen
+    "port": 8080,
+  },
+  /* leave debug off in production */
+  "debug": false
+}
+`
+	if err := os.WriteFile(path, []byte(src), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.Persist("Server.Port", 9090); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.Persist("server.host", "0.0.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.Persist("Debug", "true"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.Persist("Server.Timeout", 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  // Server settings
+  "server": {
+    "host": "0.0.0.0", // where to listen
+    "port": 9090,
+    "timeout": 5000000000,
+  },
+  /* leave debug off in production */
+  "debug": true
+}
+`
+	if string(data) != want {
+		t.Fatalf("unexpected file:\n%s", data)
+	}
+	if fi, err := os.Stat(path); err != nil || fi.Mode().Perm() != 0o640 {
+		t.Fatalf("mode not kept: %v %v", fi.Mode(), err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Host != "0.0.0.0" || cfg.Server.Port != 9090 || cfg.Server.Timeout != 5*time.Second || !cfg.Debug {
+		t.Fatalf("reloaded cfg=%+v", cfg)
+	}
+
+	for _, tc := range []struct {
+		field string
+		value any
+		want  string
+	}{
+		{"Missing", 1, "no config field"},
+		{"Token", "x", "cannot be set from a config file"},
+		{"Server.Port", "not-a-number", "Server.Port"},
+	} {
+		if err := ac.Persist(tc.field, tc.value); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Persist(%s): err=%v, want %q", tc.field, err, tc.want)
+		}
+	}
+}
+
+func TestSetJSONCValue(t *testing.T) {
+	for _, tc := range []struct {
+		name, src string
+		keys      []string
+		want      string
+	}{
+		{"empty file", ``, []string{"a", "b"}, "{\n  \"a\": {\"b\": 1}\n}\n"},
+		{"inline object", `{"a": 0}`, []string{"b"}, `{"a": 0, "b": 1}`},
+		{"empty object", "{\n  \"a\": {}\n}", []string{"a", "b"}, "{\n  \"a\": {\n    \"b\": 1\n  }\n}"},
+		{"trailing comma", "{\n  \"a\": 0,\n}", []string{"b"}, "{\n  \"a\": 0,\n  \"b\": 1,\n}"},
+		{"line comment", "{\n  \"a\": 0 // zero\n}", []string{"b"}, "{\n  \"a\": 0, // zero\n  \"b\": 1\n}"},
+		{"replace object", `{"a": {"x": [1, "}"]}}`, []string{"a"}, `{"a": 1}`},
+		{"case-insensitive", `{"Port": 0}`, []string{"port"}, `{"Port": 1}`},
+		{"scalar to object", `{"a": null}`, []string{"a", "b"}, `{"a": {"b": 1}}`},
+	} {
+		got, err := setJSONCValue([]byte(tc.src), tc.keys, []byte("1"))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+	if _, err := setJSONCValue([]byte(`{"sops": {"version": "3"}}`), []string{"a"}, []byte("1")); err == nil {
+		t.Error("expected error editing a SOPS file")
+	}
+	if _, err := setJSONCValue([]byte(`{"a": }`), []string{"a"}, []byte("1")); err == nil {
+		t.Error("expected error for malformed input")
+	}
+}
@@ -0,0 +1,42 @@
+package antconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFieldPlanMatchesTagScan(t *testing.T) {
+	type Inner struct {
+		Host string `default:"localhost" env:"PLAN_HOST"`
+		Port int    `flag:"port" required:"true"`
+	}
+	type Cfg struct {
+		Name  string `default:"app" flag:"name"`
+		Inner Inner
+		Ptr   *Inner
+		Skip  string
+	}
+	var cfg Cfg
+	plan, err := newFieldPlan(&cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"default", "env", "flag", "required", "removed_in"} {
+		want, err := findFieldsWithTag(tag, &cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := plan.withTag(tag)
+		if len(got) != len(want) {
+			t.Fatalf("%s: got %d fields, want %d", tag, len(got), len(want))
+		}
+		for i := range want {
+			if got[i].path != want[i].path || got[i].tagvalue != want[i].tagvalue {
+				t.Errorf("%s[%d]: got %s=%q, want %s=%q", tag, i, got[i].path, got[i].tagvalue, want[i].path, want[i].tagvalue)
+			}
+			if !reflect.DeepEqual(got[i].jsonPath, want[i].jsonPath) {
+				t.Errorf("%s[%d]: jsonPath %v, want %v", tag, i, got[i].jsonPath, want[i].jsonPath)
+			}
+		}
+	}
+}
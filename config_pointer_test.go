@@ -0,0 +1,58 @@
+package antconfig
+
+import (
+	"flag"
+	"testing"
+)
+
+func TestPointerScalarFields(t *testing.T) {
+	type Cfg struct {
+		Port    *int     `json:"port" env:"PTR_PORT" flag:"port"`
+		Name    *string  `json:"name" default:"svc" flag:"name"`
+		Verbose *bool    `json:"verbose" flag:"verbose"`
+		Ratio   *float64 `json:"ratio" flag:"ratio" required:"true"`
+		Unset   *int     `json:"unset" env:"PTR_UNSET" flag:"unset"`
+	}
+	t.Setenv("PTR_PORT", "0")
+
+	// Parsed args: a zero value given explicitly allocates the pointer
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--verbose", "serve", "--ratio", "0"})
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port == nil || *cfg.Port != 0 || cfg.Name == nil || *cfg.Name != "svc" || cfg.Verbose == nil || !*cfg.Verbose || cfg.Ratio == nil || *cfg.Ratio != 0 || cfg.Unset != nil {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+	if args := ac.RemainingArgs(); len(args) != 1 || args[0] != "serve" {
+		t.Fatalf("*bool flag consumed a positional: %v", args)
+	}
+	prov := ac.Provenance()
+	if prov["Port"] != LayerEnv || prov["Verbose"] != LayerFlag || prov["Name"] != LayerDefault {
+		t.Fatalf("provenance = %v", prov)
+	}
+	if _, ok := prov["Unset"]; ok {
+		t.Fatal("nil pointer has provenance")
+	}
+
+	// A bound FlagSet registers the element types, including negatable bools
+	var bound Cfg
+	ac = New().MustSetConfig(&bound)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	if err := ac.BindConfigFlags(fs); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Parse([]string{"--no-verbose", "--port", "8080", "--ratio=0.5", "extra"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if bound.Verbose == nil || *bound.Verbose || bound.Port == nil || *bound.Port != 8080 || bound.Ratio == nil || *bound.Ratio != 0.5 || bound.Unset != nil {
+		t.Fatalf("bound = %+v", bound)
+	}
+	if fs.Lookup("port").Value.String() != "8080" {
+		t.Fatal("pointer flag not registered as int")
+	}
+}
@@ -67,6 +67,10 @@ func TestPrecedence_ConfigEnvDotenvFlags(t *testing.T) {
 	if cfg.A != "OS" {
 		t.Fatalf("expected A from OS env over .env, got %q", cfg.A)
 	}
+	prov := ant.Provenance()
+	if prov["A"] != LayerEnv || prov["B"] != LayerDotEnv || prov["I"] != LayerDotEnv {
+		t.Fatalf("unexpected provenance after first pass: %v", prov)
+	}
 
 	// Second pass: with flags, verify flags override everything
 	cfg = Cfg{}
@@ -80,4 +84,7 @@ func TestPrecedence_ConfigEnvDotenvFlags(t *testing.T) {
 	if cfg.A != "FLAG" || cfg.I != 5 || cfg.B != "dotenvB" {
 		t.Fatalf("expected flags to win (A, I) and .env to remain for B, got %+v", cfg)
 	}
+	if prov := ant.Provenance(); prov["A"] != LayerFlag || prov["I"] != LayerFlag {
+		t.Fatalf("expected flag provenance for A and I, got %v", prov)
+	}
 }
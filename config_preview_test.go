@@ -0,0 +1,54 @@
+package antconfig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPreview(t *testing.T) {
+	type DB struct {
+		Host string `default:"localhost" env:"PREVIEW_DB_HOST"`
+		Port int    `default:"5432" flag:"db-port"`
+	}
+	type Cfg struct {
+		Name string `default:"svc" env:"PREVIEW_NAME"`
+		DB   *DB
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetFlagPrefix("app-"); err != nil {
+		t.Fatal(err)
+	}
+	ant.SetFlagArgs([]string{"--app-db-port=6000"})
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := ant.Preview(map[string]string{
+		"PREVIEW_DB_HOST": "db.internal",
+		"PREVIEW_NAME":    "svc", // same as current: no change
+		"--app-db-port":   "7000",
+	})
+	if err != nil {
+		t.Fatalf("Preview: %v", err)
+	}
+	if len(d.Changes) != 2 {
+		t.Fatalf("expected 2 changes, got %+v", d.Changes)
+	}
+	host, port := d.Changes[0], d.Changes[1]
+	if host.Path != "DB.Host" || host.Old != "localhost" || host.New != "db.internal" || host.Source != LayerEnv {
+		t.Fatalf("unexpected host change: %+v", host)
+	}
+	if port.Path != "DB.Port" || port.Old != 6000 || port.New != 7000 || port.Source != LayerFlag {
+		t.Fatalf("unexpected port change: %+v", port)
+	}
+	if cfg.DB.Host != "localhost" || cfg.DB.Port != 6000 {
+		t.Fatalf("Preview must not apply overrides: %+v", cfg.DB)
+	}
+	if _, set := os.LookupEnv("PREVIEW_DB_HOST"); set {
+		t.Fatal("Preview must not touch the process environment")
+	}
+	if d, err := ant.Preview(nil); err != nil || !d.Empty() {
+		t.Fatalf("expected empty diff without overrides, got %+v, %v", d, err)
+	}
+}
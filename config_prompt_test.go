@@ -0,0 +1,83 @@
+package antconfig
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSecretPromptFillsMissingSecrets(t *testing.T) {
+	type Cfg struct {
+		Token   string `env:"PROMPT_TEST_TOKEN" required:"true" secret:"true" desc:"API token"`
+		Pin     int    `required:"true" secret:"true"`
+		Name    string `required:"true" default:"svc"`
+		Present string `env:"PROMPT_TEST_PRESENT" required:"true" secret:"true"`
+	}
+	var cfg Cfg
+	var labels []string
+	ac := New().MustSetConfig(&cfg)
+	if err := ac.SetEnvironment(map[string]string{"PROMPT_TEST_PRESENT": "from-env"}); err != nil {
+		t.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{})
+	ac.SetDiscovery(func(string) (string, error) { return "", ErrConfigNotFound })
+	answers := map[string]string{"Token (API token)": "t0k3n", "Pin": "1234"}
+	ac.SetSecretPrompt(func(label string) (string, error) {
+		labels = append(labels, label)
+		return answers[label], nil
+	})
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Token != "t0k3n" || cfg.Pin != 1234 || cfg.Present != "from-env" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if strings.Join(labels, "|") != "Token (API token)|Pin" {
+		t.Fatalf("prompted for %q", labels)
+	}
+	if p := ac.Provenance(); p["Token"] != LayerPrompt || p["Present"] != LayerEnv {
+		t.Fatalf("unexpected provenance: %v", p)
+	}
+
+	// Preview never prompts
+	labels = nil
+	if _, err := ac.Preview(map[string]string{"PROMPT_TEST_PRESENT": "other"}); err == nil {
+		t.Fatal("expected Preview to report the missing secrets")
+	}
+	if len(labels) != 0 {
+		t.Fatalf("Preview prompted for %q", labels)
+	}
+}
+
+func TestSecretPromptWithoutTerminal(t *testing.T) {
+	type Cfg struct {
+		Token string `required:"true" secret:"true"`
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	ac.SetDiscovery(func(string) (string, error) { return "", ErrConfigNotFound })
+	ac.SetSecretPrompt(func(string) (string, error) { return "", ErrNoTerminal })
+	if err := ac.WriteConfigValues(); !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected ErrRequired, got %v", err)
+	}
+}
+
+func TestSecretPromptBadValue(t *testing.T) {
+	type Cfg struct {
+		Pin int `required:"true" secret:"true"`
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	ac.SetDiscovery(func(string) (string, error) { return "", ErrConfigNotFound })
+	ac.SetSecretPrompt(func(string) (string, error) { return "not-a-number", nil })
+	err := ac.WriteConfigValues()
+	var me *MultiError
+	if !errors.As(err, &me) || me.Errors[0].Source != LayerPrompt || me.Errors[0].Raw != "" {
+		t.Fatalf("expected a prompt FieldError without the raw value, got %v", err)
+	}
+	if strings.Contains(err.Error(), "not-a-number") {
+		t.Fatalf("error leaks the entered value: %v", err)
+	}
+}
@@ -0,0 +1,339 @@
+package antconfig
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// optionSource serves the "values" option of its "$sources" entry.
+type optionSource struct {
+	name   string
+	values map[string]any
+}
+
+func (s optionSource) Name() string { return s.name }
+func (s optionSource) Load(context.Context) (map[string]any, error) {
+	return s.values, nil
+}
+
+func init() {
+	RegisterProvider("test-values", func(options map[string]any) (Source, error) {
+		values, ok := options["values"].(map[string]any)
+		if !ok {
+			return nil, errors.New("needs \"values\"")
+		}
+		name, _ := options["name"].(string)
+		if name == "" {
+			name = "test-values"
+		}
+		return optionSource{name: name, values: values}, nil
+	})
+}
+
+func writeProviderConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProviderSourcesFromConfigFile(t *testing.T) {
+	type Cfg struct {
+		Host  string `json:"host"`
+		Port  int    `json:"port" env:"PROV_PORT"`
+		Token string `json:"token"`
+	}
+	path := writeProviderConfig(t, `{
+		"$sources": [
+			{"type": "test-values", "name": "remote", "values": {"host": "remote-host", "port": 7000}},
+			{"type": "test-values", "name": "late", "priority": 500, "values": {"token": "late-token"}}
+		],
+		"host": "file-host",
+		"port": 8080,
+		"token": "file-token"
+	}`)
+	t.Setenv("PROV_PORT", "9090")
+
+	var cfg Cfg
+	var warnings []Warning
+	ac := New()
+	ac.OnWarning(func(w Warning) { warnings = append(warnings, w) })
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	// The source overrides the file, env overrides the source, and the
+	// priority 500 source overrides everything
+	if cfg.Host != "remote-host" || cfg.Port != 9090 || cfg.Token != "late-token" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	prov := ac.Provenance()
+	if prov["Host"] != "remote" || prov["Port"] != LayerEnv || prov["Token"] != "late" {
+		t.Fatalf("unexpected provenance: %v", prov)
+	}
+	if len(warnings) != 0 {
+		t.Fatalf("unexpected warnings: %v", warnings)
+	}
+}
+
+func TestProviderSourcesTieWithRegistered(t *testing.T) {
+	type Cfg struct {
+		Host string `json:"host"`
+	}
+	path := writeProviderConfig(t, `{"$sources": [{"type": "test-values", "values": {"host": "declared"}}]}`)
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.AddValues(map[string]any{"host": "registered"}, PriorityFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Host != "declared" {
+		t.Fatalf("declared source should apply after a registered one of equal priority, got %q", cfg.Host)
+	}
+}
+
+func TestProviderSourcesErrors(t *testing.T) {
+	type Cfg struct {
+		Host string `json:"host"`
+	}
+	cases := map[string]struct {
+		doc  string
+		want string
+	}{
+		"not an array":  {`{"$sources": {"type": "test-values"}}`, "must be an array of objects, not an object"},
+		"not an object": {`{"$sources": ["test-values"]}`, "$sources[0] must be an object, not a string"},
+		"missing type":  {`{"$sources": [{"values": {}}]}`, `$sources[0] needs a "type"`},
+		"unknown type":  {`{"$sources": [{"type": "nope"}]}`, `unknown source type "nope"`},
+		"bad priority":  {`{"$sources": [{"type": "test-values", "priority": "high", "values": {}}]}`, "priority must be an integer"},
+		"factory error": {`{"$sources": [{"type": "test-values"}]}`, `$sources[0] (test-values): needs "values"`},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var cfg Cfg
+			ac := New()
+			if err := ac.SetConfigPath(writeProviderConfig(t, tc.doc)); err != nil {
+				t.Fatal(err)
+			}
+			ac.MustSetConfig(&cfg)
+			err := ac.WriteConfigValues()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestProviderSourcesKeyAsField(t *testing.T) {
+	type Cfg struct {
+		Sources []string `json:"$sources"`
+	}
+	path := writeProviderConfig(t, `{"$sources": ["a", "b"]}`)
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if len(cfg.Sources) != 2 {
+		t.Fatalf("unexpected sources field: %v", cfg.Sources)
+	}
+}
+
+func TestFileProviderBootstrap(t *testing.T) {
+	type Cfg struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "real.jsonc"), []byte(`{
+		// the real config
+		"host": "real-host"
+	}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	bootstrap := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(bootstrap, []byte(`{"$sources": [
+		{"type": "file", "path": "real.jsonc"},
+		{"type": "file", "path": "missing.json", "optional": true}
+	], "host": "bootstrap", "port": 8080}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetConfigPath(bootstrap); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Host != "real-host" || cfg.Port != 8080 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if got := ac.Provenance()["Host"]; got != Layer("file:"+filepath.Join(dir, "real.jsonc")) {
+		t.Fatalf("unexpected provenance: %v", got)
+	}
+
+	if err := os.WriteFile(bootstrap, []byte(`{"$sources": [{"type": "file", "path": "missing.json"}]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err == nil || !strings.Contains(err.Error(), "missing.json") {
+		t.Fatalf("expected error for missing file, got %v", err)
+	}
+}
+
+func TestHTTPProvider(t *testing.T) {
+	type Cfg struct {
+		Host string `json:"host"`
+		Port int    `json:"port" env:"HTTP_PROV_PORT"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/app.json" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer s3cret" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"host": "remote-host", "port": 7000}`))
+	}))
+	defer srv.Close()
+
+	load := func(sources string) (*Cfg, *AntConfig, error) {
+		cfg := &Cfg{}
+		ac := New()
+		if err := ac.SetEnvironment(map[string]string{"CONFIG_TOKEN": "s3cret", "HTTP_PROV_PORT": "9090"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.SetConfigPath(writeProviderConfig(t, `{"$sources": `+sources+`, "host": "file-host"}`)); err != nil {
+			t.Fatal(err)
+		}
+		ac.MustSetConfig(cfg)
+		return cfg, ac, ac.WriteConfigValues()
+	}
+
+	cfg, ac, err := load(`[{"type": "http", "url": "` + srv.URL + `/app.json",
+		"headers": {"Authorization": "Bearer ${CONFIG_TOKEN}"}, "timeout": "5s"}]`)
+	if err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Host != "remote-host" || cfg.Port != 9090 {
+		t.Fatalf("unexpected config: %+v", *cfg)
+	}
+	if prov := ac.Provenance()["Host"]; prov != Layer(srv.URL+"/app.json") {
+		t.Fatalf("unexpected provenance: %v", prov)
+	}
+
+	if _, _, err := load(`[{"type": "http", "url": "` + srv.URL + `/app.json"}]`); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected 403 error, got %v", err)
+	}
+	if cfg, _, err := load(`[{"type": "http", "url": "` + srv.URL + `/gone.json", "optional": true}]`); err != nil {
+		t.Fatalf("optional 404 should load nothing, got %v", err)
+	} else if cfg.Host != "file-host" {
+		t.Fatalf("unexpected config: %+v", *cfg)
+	}
+	if _, _, err := load(`[{"type": "http", "url": "ftp://example.com/x"}]`); err == nil || !strings.Contains(err.Error(), "http or https") {
+		t.Fatalf("expected URL scheme error, got %v", err)
+	}
+}
+
+func TestHTTPProviderSignature(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := []byte(`{"host": "remote-host"}`)
+	docs := map[string][]byte{
+		"/signed.json":     doc,
+		"/signed.json.sig": []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, doc))),
+		"/forged.json":     []byte(`{"host": "evil-host"}`),
+		"/forged.json.sig": ed25519.Sign(priv, doc),
+		"/unsigned.json":   doc,
+		"/huge.json":       []byte(`{"host": "` + strings.Repeat("x", maxHTTPSourceSize) + `"}`),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := docs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer srv.Close()
+
+	type Cfg struct {
+		Host string `json:"host"`
+	}
+	load := func(name string, signed bool) (*Cfg, error) {
+		root := []byte(`{"$sources": [{"type": "http", "url": "` + srv.URL + name + `"}]}`)
+		path := writeProviderConfig(t, string(root))
+		if err := os.WriteFile(path+".sig", ed25519.Sign(priv, root), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg := &Cfg{}
+		ac := New()
+		if err := ac.SetEnvironment(map[string]string{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := ac.SetConfigPath(path); err != nil {
+			t.Fatal(err)
+		}
+		if signed {
+			if err := ac.RequireSignature(pub); err != nil {
+				t.Fatal(err)
+			}
+		}
+		ac.MustSetConfig(cfg)
+		return cfg, ac.WriteConfigValues()
+	}
+
+	if cfg, err := load("/signed.json", true); err != nil {
+		t.Fatalf("signed source: %v", err)
+	} else if cfg.Host != "remote-host" {
+		t.Fatalf("unexpected config: %+v", *cfg)
+	}
+	for _, name := range []string{"/forged.json", "/unsigned.json"} {
+		if _, err := load(name, true); !errors.Is(err, ErrSignatureInvalid) {
+			t.Fatalf("%s: expected ErrSignatureInvalid, got %v", name, err)
+		}
+	}
+	if _, err := load("/unsigned.json", false); err != nil {
+		t.Fatalf("unsigned source without RequireSignature: %v", err)
+	}
+	if _, err := load("/huge.json", false); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("expected size limit error, got %v", err)
+	}
+}
+
+func TestHTTPProviderRedactsPassword(t *testing.T) {
+	src, err := newHTTPSource(New(), "", map[string]any{"url": "https://user:pw@config.example/app.json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name := src.Name(); strings.Contains(name, "pw") {
+		t.Fatalf("password in source name %q", name)
+	}
+}
@@ -0,0 +1,55 @@
+package antconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRegexpFields(t *testing.T) {
+	type Cfg struct {
+		Allow  *regexp.Regexp `json:"allow" default:"^api\\."`
+		Deny   *regexp.Regexp `json:"deny"`
+		Ignore *regexp.Regexp `json:"ignore" env:"RE_IGNORE"`
+		Unset  *regexp.Regexp `json:"unset"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"deny": "(?i)admin"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("RE_IGNORE", `\.tmp$`)
+
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Allow == nil || !cfg.Allow.MatchString("api.example.com") || cfg.Allow.MatchString("www.api.com") {
+		t.Errorf("Allow: %v", cfg.Allow)
+	}
+	if cfg.Deny == nil || !cfg.Deny.MatchString("/ADMIN/users") {
+		t.Errorf("Deny: %v", cfg.Deny)
+	}
+	if cfg.Ignore == nil || !cfg.Ignore.MatchString("x.tmp") {
+		t.Errorf("Ignore: %v", cfg.Ignore)
+	}
+	if cfg.Unset != nil {
+		t.Errorf("Unset should stay nil, got %v", cfg.Unset)
+	}
+
+	t.Setenv("RE_IGNORE", "(unclosed")
+	err := ant.WriteConfigValues()
+	var me *MultiError
+	if !errors.As(err, &me) || len(me.Errors) != 1 {
+		t.Fatalf("expected one field error, got %v", err)
+	}
+	if fe := me.Errors[0]; fe.Path != "Ignore" || fe.Source != LayerEnv || !strings.Contains(fe.Error(), "missing closing )") {
+		t.Errorf("unexpected error: %v", fe)
+	}
+}
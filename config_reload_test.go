@@ -0,0 +1,371 @@
+package antconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReloadNotifiesOnChange(t *testing.T) {
+	type Cfg struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	path := writeProviderConfig(t, `{"host": "a", "port": 1}`)
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	var got []*Cfg
+	cancel := ac.OnChange(func(c any) { got = append(got, c.(*Cfg)) })
+
+	if changed, err := ac.Reload(); err != nil || changed {
+		t.Fatalf("unchanged reload: changed=%v err=%v", changed, err)
+	}
+	if err := os.WriteFile(path, []byte(`{"host": "b", "port": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := ac.Reload(); err != nil || !changed {
+		t.Fatalf("changed reload: changed=%v err=%v", changed, err)
+	}
+	if len(got) != 1 || got[0].Host != "b" {
+		t.Fatalf("unexpected notifications: %+v", got)
+	}
+	if cfg.Host != "a" {
+		t.Fatalf("registered struct should not change, got %+v", cfg)
+	}
+	if changed, _ := ac.Reload(); changed {
+		t.Fatal("second reload of the same file reported a change")
+	}
+
+	// A failed reload notifies no one and keeps the previous hash
+	if err := os.WriteFile(path, []byte(`{"host": `), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ac.Reload(); err == nil {
+		t.Fatal("expected error for malformed config")
+	}
+	if err := os.WriteFile(path, []byte(`{"host": "b", "port": 1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := ac.Reload(); err != nil || changed {
+		t.Fatalf("reload after failure: changed=%v err=%v", changed, err)
+	}
+
+	cancel()
+	if err := os.WriteFile(path, []byte(`{"host": "c"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, _ := ac.Reload(); !changed || len(got) != 1 {
+		t.Fatalf("cancelled subscriber was notified: changed=%v %+v", changed, got)
+	}
+}
+
+func TestStartPolling(t *testing.T) {
+	type Cfg struct {
+		Host string `json:"host"`
+	}
+	path := writeProviderConfig(t, `{"host": "a"}`)
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	changes := make(chan *Cfg, 10)
+	ac.OnChange(func(c any) { changes <- c.(*Cfg) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := ac.StartPolling(ctx, 5*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case c := <-changes:
+		t.Fatalf("unexpected change before the file changed: %+v", c)
+	case <-time.After(30 * time.Millisecond):
+	}
+	if err := os.WriteFile(path, []byte(`{"host": "b"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case c := <-changes:
+		if c.Host != "b" {
+			t.Fatalf("unexpected config: %+v", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change notification from polling")
+	}
+
+	if err := ac.StartPolling(ctx, 0); err == nil || !strings.Contains(err.Error(), "interval must be positive") {
+		t.Fatalf("expected interval error, got %v", err)
+	}
+	if err := New().StartPolling(ctx, time.Second); err == nil {
+		t.Fatal("expected error without SetConfig")
+	}
+}
+
+func TestReloadRollback(t *testing.T) {
+	type Cfg struct {
+		Workers int `json:"workers" required:"true"`
+	}
+	path := writeProviderConfig(t, `{"workers": 4}`)
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	errTooMany := errors.New("too many workers")
+	ac.SetPostLoad(func(c any) error {
+		if c.(*Cfg).Workers > 64 {
+			return errTooMany
+		}
+		return nil
+	})
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	current := &cfg
+	var failures []error
+	ac.OnChange(func(c any) { current = c.(*Cfg) })
+	ac.OnReloadError(func(err error) { failures = append(failures, err) })
+
+	for i, content := range []string{`{"workers": 100}`, `{"workers": 0}`} {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if changed, err := ac.Reload(); err == nil || changed {
+			t.Fatalf("%s: expected a rejected reload, got changed=%v err=%v", content, changed, err)
+		}
+		if current.Workers != 4 || len(failures) != i+1 {
+			t.Fatalf("%s: broken config swapped in (%+v) or not reported (%v)", content, *current, failures)
+		}
+		if status := ac.ReloadStatus(); status.Failures != i+1 || status.Error != failures[i].Error() {
+			t.Fatalf("%s: unexpected status %+v", content, status)
+		}
+	}
+	if !errors.Is(failures[0], errTooMany) || !strings.Contains(failures[0].Error(), "post-load hook") {
+		t.Fatalf("unexpected hook error: %v", failures[0])
+	}
+
+	if err := os.WriteFile(path, []byte(`{"workers": 8}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := ac.Reload(); err != nil || !changed || current.Workers != 8 {
+		t.Fatalf("recovery reload: changed=%v err=%v cfg=%+v", changed, err, *current)
+	}
+	if status := ac.ReloadStatus(); status.Failures != 0 || status.Error != "" || status.LastSuccess.IsZero() {
+		t.Fatalf("unexpected status after recovery: %+v", status)
+	}
+}
+
+func TestReloadPublishesToReaders(t *testing.T) {
+	type Cfg struct {
+		Host     string `json:"host"`
+		Password string `json:"password" secret:"true"`
+	}
+	path := writeProviderConfig(t, `{"host": "a", "password": "one"}`)
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	before, _ := ac.Hash()
+	if err := os.WriteFile(path, []byte(`{"host": "b", "password": "two"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := ac.Reload(); err != nil || !changed {
+		t.Fatalf("Reload: changed=%v err=%v", changed, err)
+	}
+	doc, err := ac.RedactedConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc["host"] != "b" || doc["password"] != Redacted {
+		t.Fatalf("RedactedConfig after reload: %v", doc)
+	}
+	if v, _ := ac.Lookup("host"); v != "b" {
+		t.Fatalf("Lookup after reload: %v", v)
+	}
+	if after, _ := ac.Hash(); after == before {
+		t.Fatal("Hash did not follow the reload")
+	}
+	if cfg.Host != "a" {
+		t.Fatalf("registered struct changed: %+v", cfg)
+	}
+
+	// The next load into the registered struct is what readers see again
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := ac.Lookup("host"); v != "b" || cfg.Host != "b" {
+		t.Fatalf("Lookup after WriteConfigValues: %v (%+v)", v, cfg)
+	}
+}
+
+func TestPollingRacesWithReaders(t *testing.T) {
+	type Cfg struct {
+		Host string `json:"host"`
+		Port int    `json:"port" flag:"port"`
+	}
+	path := writeProviderConfig(t, `{"host": "a"}`)
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetEnvironment(map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{"--port", "80", "rest"})
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	changes := make(chan struct{}, 100)
+	ac.OnChange(func(any) { changes <- struct{}{} })
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := ac.StartPolling(ctx, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+			}
+			_ = os.WriteFile(path, []byte(`{"host": "h`+strings.Repeat("x", i%3)+`"}`), 0o644)
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	deadline := time.After(5 * time.Second)
+	for seen := 0; seen < 3; {
+		_ = ac.Provenance()
+		_ = ac.WasSet("port")
+		_ = ac.IsSet("host")
+		_ = ac.RemainingArgs()
+		_, _ = ac.Lookup("host")
+		_, _ = ac.Hash()
+		_, _ = ac.RedactedConfig()
+		select {
+		case <-changes:
+			seen++
+		case <-deadline:
+			t.Fatal("polling reported no changes")
+		default:
+		}
+	}
+	cancel()
+	<-done
+}
+
+func TestReloadPicksUpDotEnvEdits(t *testing.T) {
+	type Cfg struct {
+		X string `env:"RELOAD_DOTENV_X"`
+		Y string `env:"RELOAD_DOTENV_Y"`
+	}
+	t.Cleanup(func() {
+		os.Unsetenv("RELOAD_DOTENV_X")
+		os.Unsetenv("RELOAD_DOTENV_Y")
+	})
+	envPath := writeProviderConfig(t, "RELOAD_DOTENV_X=one\nRELOAD_DOTENV_Y=kept\n")
+	var cfg Cfg
+	ac := New()
+	if err := ac.DisableAutoDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetEnvPath(envPath); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if os.Getenv("RELOAD_DOTENV_X") != "one" {
+		t.Fatal(".env value was not exported")
+	}
+	var current *Cfg
+	ac.OnChange(func(c any) { current = c.(*Cfg) })
+
+	if err := os.WriteFile(envPath, []byte("RELOAD_DOTENV_X=two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := ac.Reload(); err != nil || !changed {
+		t.Fatalf("Reload: changed=%v err=%v", changed, err)
+	}
+	if current.X != "two" || current.Y != "" {
+		t.Fatalf("edited .env not applied: %+v", *current)
+	}
+	if prov := ac.Provenance()["X"]; prov != LayerDotEnv {
+		t.Fatalf("provenance = %v, want %v", prov, LayerDotEnv)
+	}
+	if got := os.Getenv("RELOAD_DOTENV_X"); got != "two" {
+		t.Fatalf("exported X = %q", got)
+	}
+	if _, ok := os.LookupEnv("RELOAD_DOTENV_Y"); ok {
+		t.Fatal("key removed from .env is still exported")
+	}
+
+	// An explicit change to the process environment still wins over .env
+	os.Setenv("RELOAD_DOTENV_X", "explicit")
+	if _, err := ac.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if current.X != "explicit" || ac.Provenance()["X"] != LayerEnv {
+		t.Fatalf("explicit env lost: %+v (%v)", *current, ac.Provenance()["X"])
+	}
+}
+
+func TestReloadDetectsEnvOnlyChanges(t *testing.T) {
+	type Cfg struct {
+		Host  string `json:"host" default:"localhost"`
+		Token string `json:"-" env:"RV_TOKEN"`
+		Hook  func() `antconfig:"-"`
+	}
+	env := map[string]string{"RV_TOKEN": "one"}
+	cfg := Cfg{Hook: func() {}}
+	ac := New()
+	if err := ac.SetLookupEnv(func(k string) (string, bool) { v, ok := env[k]; return v, ok }); err != nil {
+		t.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{})
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	var got []*Cfg
+	ac.OnChange(func(c any) { got = append(got, c.(*Cfg)) })
+
+	// The antconfig:"-" func field must not break hashing
+	if changed, err := ac.Reload(); err != nil || changed {
+		t.Fatalf("expected unchanged reload, got changed=%v err=%v", changed, err)
+	}
+	// A rotated env-only secret is a change
+	env["RV_TOKEN"] = "two"
+	if changed, err := ac.Reload(); err != nil || !changed {
+		t.Fatalf("expected a change, got changed=%v err=%v", changed, err)
+	}
+	if len(got) != 1 || got[0].Token != "two" {
+		t.Fatalf("OnChange not notified of the rotated token: %+v", got)
+	}
+}
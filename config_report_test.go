@@ -0,0 +1,79 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadReport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"Host": "file"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("REPORT_TEST_PORT=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	type Cfg struct {
+		Host  string `default:"localhost"`
+		Port  int    `env:"REPORT_TEST_PORT"`
+		Level string `env:"REPORT_TEST_LEVEL"`
+		User  string `env:"REPORT_TEST_USER" flag:"user"`
+		Debug bool   `flag:"debug"`
+		Name  string
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	if err := ac.SetEnvironment(map[string]string{"REPORT_TEST_LEVEL": "debug", "REPORT_TEST_USER": "env"}); err != nil {
+		t.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{"--user", "flag", "--debug"})
+	if err := ac.AddValues(map[string]any{"Name": "mem"}, PriorityDefault); err != nil {
+		t.Fatal(err)
+	}
+	report, err := ac.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cwd, _ := os.Getwd()
+	want := LoadReport{
+		ConfigFile:        filepath.Join(cwd, "config.json"),
+		ConfigDiscovered:  true,
+		EnvFiles:          []string{filepath.Join(cwd, ".env")},
+		EnvFileDiscovered: true,
+		DotEnvVars:        1,
+		EnvVars:           2,
+		Flags:             2,
+		Sources:           []string{"memory"},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Fatalf("report = %+v\nwant     %+v", report, want)
+	}
+
+	if err := ac.DisableAutoDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	if report, err = ac.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if report.ConfigFile != "" || report.EnvFiles != nil || report.DotEnvVars != 0 {
+		t.Fatalf("unexpected report without discovery: %+v", report)
+	}
+}
+
+func TestBuilderReport(t *testing.T) {
+	type Cfg struct {
+		Host string `flag:"host"`
+	}
+	var cfg Cfg
+	loaded, err := For(&cfg).WithFlagArgs([]string{"--host", "h"}).WithEmbeddedConfig([]byte(`{}`), "json").Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Report.Flags != 1 || !loaded.Report.EmbeddedConfig {
+		t.Fatalf("unexpected report: %+v", loaded.Report)
+	}
+}
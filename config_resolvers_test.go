@@ -0,0 +1,136 @@
+package antconfig
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinResolvers(t *testing.T) {
+	type Cfg struct {
+		Password string   `json:"password" env:"RES_PASSWORD"`
+		Token    *string  `json:"token" env:"RES_TOKEN"`
+		Hosts    []string `json:"hosts"`
+		Plain    string   `json:"plain" default:"https://example.com"`
+	}
+	secret := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secret, []byte("hunter2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"hosts": ["env://HOST_A", "b.internal"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--"})
+	if err := ac.SetEnvironment(map[string]string{
+		"RES_PASSWORD": "file://" + secret,
+		"RES_TOKEN":    "env://TOKEN_ALIAS",
+		"TOKEN_ALIAS":  "env://REAL_TOKEN",
+		"REAL_TOKEN":   "abc123",
+		"HOST_A":       "a.internal",
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.EnableBuiltinResolvers(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "hunter2" || cfg.Token == nil || *cfg.Token != "abc123" || cfg.Plain != "https://example.com" {
+		t.Fatalf("cfg = %+v", cfg)
+	}
+	if len(cfg.Hosts) != 2 || cfg.Hosts[0] != "a.internal" || cfg.Hosts[1] != "b.internal" {
+		t.Fatalf("Hosts = %v", cfg.Hosts)
+	}
+
+	if err := New().EnableBuiltinResolvers("ftp"); err == nil {
+		t.Fatal("expected an error for an unknown scheme")
+	}
+}
+
+func TestResolverErrors(t *testing.T) {
+	type Cfg struct {
+		Password string `json:"password" env:"RES_PASSWORD"`
+	}
+	load := func(env map[string]string, depth int) error {
+		var cfg Cfg
+		ac := New().MustSetConfig(&cfg)
+		ac.SetFlagArgs([]string{"--"})
+		if err := ac.SetEnvironment(env); err != nil {
+			t.Fatal(err)
+		}
+		ac.SetResolveDepth(depth)
+		if err := ac.EnableBuiltinResolvers(); err != nil {
+			t.Fatal(err)
+		}
+		return ac.WriteConfigValues()
+	}
+
+	err := load(map[string]string{"RES_PASSWORD": "env://MISSING"}, 0)
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Path != "Password" || fe.Source != LayerEnv || !strings.Contains(err.Error(), "MISSING is not set") {
+		t.Fatalf("err = %v", err)
+	}
+
+	// A cycle stops at the depth limit
+	err = load(map[string]string{"RES_PASSWORD": "env://A", "A": "env://B", "B": "env://A"}, 0)
+	if err == nil || !strings.Contains(err.Error(), "after 8 resolutions") {
+		t.Fatalf("err = %v", err)
+	}
+	err = load(map[string]string{"RES_PASSWORD": "env://A", "A": "env://B", "B": "x"}, 1)
+	if err == nil || !strings.Contains(err.Error(), "after 1 resolutions") {
+		t.Fatalf("err = %v", err)
+	}
+	if err := load(map[string]string{"RES_PASSWORD": "env://A", "A": "env://B", "B": "x"}, 2); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExecResolver(t *testing.T) {
+	if _, err := exec.LookPath("echo"); err != nil {
+		t.Skip("echo not available")
+	}
+	type Cfg struct {
+		Password string `json:"password" env:"RES_PASSWORD"`
+	}
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--"})
+	if err := ac.SetEnvironment(map[string]string{"RES_PASSWORD": "exec://echo s3cret"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.EnableBuiltinResolvers(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "exec://echo s3cret" {
+		t.Fatalf("exec must not be enabled by default: %q", cfg.Password)
+	}
+
+	ac = New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--"})
+	if err := ac.SetEnvironment(map[string]string{"RES_PASSWORD": "exec://echo s3cret"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.EnableBuiltinResolvers("exec"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "s3cret" {
+		t.Fatalf("Password = %q", cfg.Password)
+	}
+}
@@ -0,0 +1,120 @@
+package antconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakySource fails the first fail calls to Load, then returns values.
+type flakySource struct {
+	fail   int
+	calls  int
+	values map[string]any
+}
+
+func (f *flakySource) Name() string { return "flaky" }
+
+func (f *flakySource) Load(context.Context) (map[string]any, error) {
+	f.calls++
+	if f.calls <= f.fail {
+		return nil, errors.New("connection refused")
+	}
+	return f.values, nil
+}
+
+func TestRetryPolicy(t *testing.T) {
+	type Cfg struct{ Host string }
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	src := &flakySource{fail: 2, values: map[string]any{"Host": "remote"}}
+	if err := ac.AddSource(src, PriorityEnv); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetRetryPolicy(RetryPolicy{Attempts: 3, Backoff: time.Millisecond, Jitter: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "remote" || src.calls != 3 {
+		t.Fatalf("cfg=%+v calls=%d", cfg, src.calls)
+	}
+
+	// Exhausted attempts fail the load
+	src.calls, src.fail = 0, 5
+	if err := ac.WriteConfigValues(); err == nil || src.calls != 3 {
+		t.Fatalf("expected error after 3 attempts, got err=%v calls=%d", err, src.calls)
+	}
+
+	if err := ac.SetRetryPolicy(RetryPolicy{Jitter: 2}); err == nil {
+		t.Fatal("expected error for jitter outside [0, 1]")
+	}
+}
+
+func TestRetryPolicyFallbackToCache(t *testing.T) {
+	type Cfg struct{ Host string }
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	var warnings []Warning
+	ac.OnWarning(func(w Warning) { warnings = append(warnings, w) })
+	src := &flakySource{values: map[string]any{"Host": "remote"}}
+	if err := ac.AddSource(src, PriorityEnv); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetRetryPolicy(RetryPolicy{Attempts: 2, FallbackToCache: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err != nil || cfg.Host != "remote" {
+		t.Fatalf("err=%v cfg=%+v", err, cfg)
+	}
+
+	// The source goes down: its last good values are reused with a warning
+	src.fail = 100
+	cfg = Cfg{}
+	if err := ac.WriteConfigValues(); err != nil || cfg.Host != "remote" {
+		t.Fatalf("err=%v cfg=%+v", err, cfg)
+	}
+	if len(warnings) != 1 || warnings[0].Kind != WarningStaleSource || warnings[0].Path != "flaky" {
+		t.Fatalf("unexpected warnings: %+v", warnings)
+	}
+}
+
+func TestRetryPolicyFallbackWithoutCache(t *testing.T) {
+	type Cfg struct{ Host string }
+	var cfg Cfg
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{})
+	if err := ac.AddSource(&flakySource{fail: 100}, PriorityEnv); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetRetryPolicy(RetryPolicy{FallbackToCache: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.WriteConfigValues(); err == nil {
+		t.Fatal("expected error without an earlier successful load")
+	}
+}
+
+func TestRetryPolicyDelayDoesNotOverflow(t *testing.T) {
+	for _, p := range []RetryPolicy{
+		{Backoff: time.Second},
+		{Backoff: time.Second, Jitter: 1},
+		{Backoff: time.Second, MaxBackoff: time.Hour},
+	} {
+		prev := time.Duration(0)
+		for n := 2; n <= 100; n++ {
+			d := p.delay(n)
+			if d <= 0 || (p.Jitter == 0 && d < prev) {
+				t.Fatalf("%+v: delay(%d) = %v after %v", p, n, d, prev)
+			}
+			prev = d
+		}
+		if p.MaxBackoff > 0 && prev != p.MaxBackoff {
+			t.Fatalf("%+v: delay = %v, want the cap", p, prev)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package antconfig
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGenerateSample(t *testing.T) {
+	type DB struct {
+		Host     string `json:"host" default:"localhost" env:"DB_HOST" desc:"database host"`
+		Password string `json:"password" default:"changeme" env:"DB_PASSWORD" secret:"true"`
+	}
+	type Cfg struct {
+		DB      DB            `json:"db"`
+		Timeout time.Duration `json:"timeout" default:"5s" flag:"timeout" desc:"request timeout"`
+		Day     time.Time     `json:"day" layout:"2006-01-02" default:"2024-03-01"`
+		Ports   []int         `json:"ports" default:"[80,443]"`
+		Tags    []string      `json:"tags"`
+		Skip    string        `json:"-"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	got, err := ant.GenerateSample("jsonc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "db": {
+    // database host
+    // env DB_HOST
+    "host": "localhost",
+
+    // env DB_PASSWORD; secret, prefer env
+    "password": ""
+  },
+
+  // request timeout
+  // flag --timeout
+  "timeout": 5000000000,
+
+  // layout 2006-01-02
+  "day": "2024-03-01",
+  "ports": [80,443],
+  "tags": []
+}
+`
+	if string(got) != want {
+		t.Fatalf("sample mismatch:\n got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// The sample loads back into the defaults, minus secrets
+	path := filepath.Join(t.TempDir(), "config.jsonc")
+	if err := os.WriteFile(path, got, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var loaded Cfg
+	var fromSample Cfg
+	if err := New().MustSetConfig(&loaded).WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	la := New().MustSetConfig(&fromSample)
+	if err := la.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := la.WriteConfigValues(); err != nil {
+		t.Fatalf("loading sample: %v", err)
+	}
+	loaded.Tags = []string{}
+	loaded.DB.Password = ""
+	if !reflect.DeepEqual(fromSample, loaded) {
+		t.Errorf("sample round trip:\n got %+v\nwant %+v", fromSample, loaded)
+	}
+
+	plain, err := ant.GenerateSample("json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(plain) {
+		t.Errorf("json sample is not valid JSON:\n%s", plain)
+	}
+	if _, err := ant.GenerateSample("yaml"); err == nil {
+		t.Error("expected error for unsupported format")
+	}
+}
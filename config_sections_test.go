@@ -0,0 +1,73 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOptionalSections(t *testing.T) {
+	type TLS struct {
+		Cert string `json:"cert" default:"server.pem" env:"SECTION_TLS_CERT"`
+		Key  string `json:"key" required:"true"`
+	}
+	type Metrics struct {
+		Port int `json:"port" default:"9090"`
+	}
+	type Cfg struct {
+		TLS     *TLS     `json:"tls" default:"nil"`
+		Metrics *Metrics `json:"metrics" enablekey:"SECTION_METRICS"`
+		Always  *Metrics `json:"always"`
+	}
+	load := func(file string) (Cfg, error) {
+		t.Helper()
+		var cfg Cfg
+		ac := New().MustSetConfig(&cfg)
+		ac.SetFlagArgs([]string{"--"})
+		if file != "" {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(file), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := ac.SetConfigPath(path); err != nil {
+				t.Fatal(err)
+			}
+		}
+		err := ac.WriteConfigValues()
+		return cfg, err
+	}
+
+	// Untouched sections stay nil and skip their required fields
+	cfg, err := load("")
+	if err != nil || cfg.TLS != nil || cfg.Metrics != nil || cfg.Always == nil || cfg.Always.Port != 9090 {
+		t.Fatalf("cfg = %+v, err = %v", cfg, err)
+	}
+
+	// A file value enables the section, which then gets its defaults
+	cfg, err = load(`{"tls": {"key": "k.pem"}, "metrics": {}}`)
+	if err != nil || cfg.TLS == nil || cfg.TLS.Cert != "server.pem" || cfg.TLS.Key != "k.pem" || cfg.Metrics == nil || cfg.Metrics.Port != 9090 {
+		t.Fatalf("cfg = %+v, err = %v", cfg, err)
+	}
+
+	// An env var with a section enabled makes its required fields count
+	t.Setenv("SECTION_TLS_CERT", "env.pem")
+	if _, err := load(""); err == nil {
+		t.Fatal("expected TLS.Key to be required once the section is enabled")
+	}
+
+	// enablekey switches a section on or off regardless of the other layers
+	t.Setenv("SECTION_METRICS", "false")
+	cfg, _ = load(`{"tls": {"key": "k"}, "metrics": {"port": 1}}`)
+	if cfg.Metrics != nil {
+		t.Fatalf("disabled section set: %+v", cfg.Metrics)
+	}
+	t.Setenv("SECTION_METRICS", "true")
+	cfg, _ = load(`{"tls": {"key": "k"}}`)
+	if cfg.Metrics == nil || cfg.Metrics.Port != 9090 {
+		t.Fatalf("enabled section = %+v", cfg.Metrics)
+	}
+	t.Setenv("SECTION_METRICS", "maybe")
+	if _, err := load(`{"tls": {"key": "k"}}`); err == nil {
+		t.Fatal("invalid enablekey value accepted")
+	}
+}
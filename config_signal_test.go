@@ -0,0 +1,54 @@
+//go:build unix
+
+package antconfig
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReloadOnSignal(t *testing.T) {
+	type Cfg struct {
+		Host string `json:"host"`
+	}
+	path := writeProviderConfig(t, `{"host": "a"}`)
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	changes := make(chan *Cfg, 1)
+	ac.OnChange(func(c any) { changes <- c.(*Cfg) })
+	stop, err := ac.ReloadOnSignal(syscall.SIGHUP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+
+	if err := os.WriteFile(path, []byte(`{"host": "b"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case c := <-changes:
+		if c.Host != "b" {
+			t.Fatalf("unexpected config: %+v", c)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload after SIGHUP")
+	}
+	stop()
+	stop()
+
+	if _, err := ac.ReloadOnSignal(); err == nil {
+		t.Fatal("expected error without signals")
+	}
+}
@@ -0,0 +1,56 @@
+package antconfig
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRequireSignature(t *testing.T) {
+	type Cfg struct {
+		Port int `json:"port"`
+	}
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "config.jsonc")
+	content := []byte(`{"port": 8080} // signed`)
+	if err := os.WriteFile(path, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.RequireSignature(pub[:8]); err == nil {
+		t.Fatal("expected error for short public key")
+	}
+	if err := ant.RequireSignature(pub); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("expected missing signature to fail, got %v", err)
+	}
+
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, content))
+	if err := os.WriteFile(path+".sig", []byte(sig+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil || cfg.Port != 8080 {
+		t.Fatalf("expected signed file to load, got %v (%+v)", err, cfg)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"port": 9090}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Port = 0
+	if err := ant.WriteConfigValues(); !errors.Is(err, ErrSignatureInvalid) || cfg.Port != 0 {
+		t.Fatalf("expected tampered file to be rejected before applying, got %v (%+v)", err, cfg)
+	}
+}
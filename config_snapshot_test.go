@@ -0,0 +1,59 @@
+package antconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type fakeTB struct {
+	failed string
+}
+
+func (f *fakeTB) Helper()                      {}
+func (f *fakeTB) Fatalf(s string, args ...any) { f.failed = fmt.Sprintf(s, args...) }
+
+func TestSnapshotScopedOverride(t *testing.T) {
+	type DB struct {
+		Host string `json:"host"`
+		Port int
+	}
+	type Cfg struct {
+		Name string
+		DB   *DB
+	}
+	cfg := Cfg{Name: "svc", DB: &DB{Host: "db", Port: 5432}}
+	snap := NewSnapshot(&cfg)
+	before := snap.Load()
+
+	// Parallel tests override the same path with their own values
+	t.Run("group", func(t *testing.T) {
+		for _, host := range []string{"a", "b", "c"} {
+			t.Run(host, func(t *testing.T) {
+				t.Parallel()
+				mine := snap.ScopedOverride(t, "DB.host", host).ScopedOverride(t, "DB.Port", "6543")
+				for i := 0; i < 100; i++ {
+					if got := mine.Load(); got.DB.Host != host || got.DB.Port != 6543 || got.Name != "svc" {
+						t.Fatalf("unexpected overridden snapshot: %+v", got.DB)
+					}
+				}
+			})
+		}
+	})
+	if got := snap.Load(); got != before || got.DB.Host != "db" || cfg.DB.Host != "db" {
+		t.Fatalf("override must not change the shared snapshot or the source struct: %+v", got.DB)
+	}
+
+	// Store keeps the overrides of a derived snapshot; a later override of
+	// the same path wins
+	mine := snap.ScopedOverride(t, "Name", "first").ScopedOverride(t, "name", "second")
+	mine.Store(&Cfg{Name: "reloaded", DB: &DB{Host: "db2"}})
+	if got := mine.Load(); got.Name != "second" || got.DB.Host != "db2" {
+		t.Fatalf("unexpected snapshot after Store: %+v", got)
+	}
+
+	ft := &fakeTB{}
+	if snap.ScopedOverride(ft, "Missing", 1) != nil || !strings.Contains(ft.failed, "ScopedOverride") {
+		t.Fatalf("expected unknown path to fail, got %q", ft.failed)
+	}
+}
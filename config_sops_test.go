@@ -0,0 +1,143 @@
+package antconfig
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sopsEncrypt produces a value in the sops ENC[...] format for tests.
+func sopsEncrypt(t *testing.T, key []byte, plain, typ, aad string) string {
+	t.Helper()
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := make([]byte, 32)
+	for i := range iv {
+		iv[i] = byte(i)
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed := gcm.Seal(nil, iv, []byte(plain), []byte(aad))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+	enc := base64.StdEncoding.EncodeToString
+	return fmt.Sprintf("ENC[AES256_GCM,data:%s,iv:%s,tag:%s,type:%s]", enc(data), enc(iv), enc(tag), typ)
+}
+
+func TestSOPSEncryptedConfig(t *testing.T) {
+	type DB struct {
+		Password string `json:"password"`
+		Port     int    `json:"port"`
+	}
+	type Cfg struct {
+		Name  string   `json:"name"`
+		Debug bool     `json:"debug"`
+		DB    DB       `json:"db"`
+		Keys  []string `json:"keys"`
+	}
+	key := make([]byte, 32)
+	for i := range key {
+		key[i] = byte(255 - i)
+	}
+	const modified = "2024-01-01T00:00:00Z"
+	// sopsDoc renders the document with the given plaintext name and key list;
+	// mac is the plaintext MAC, or "" for none
+	values := []string{"plain", "True", "s3cret", "5432", "k1"}
+	sopsDoc := func(name string, keys []string, mac string) string {
+		encKeys := make([]string, len(keys))
+		for i, k := range keys {
+			encKeys[i] = fmt.Sprintf("%q", sopsEncrypt(t, key, k, "str", "keys:"))
+		}
+		meta := fmt.Sprintf(`{"kms": [], "lastmodified": %q, "version": "3.8.1"}`, modified)
+		if mac != "" {
+			meta = fmt.Sprintf(`{"kms": [], "lastmodified": %q, "mac": %q, "version": "3.8.1"}`,
+				modified, sopsEncrypt(t, key, mac, "str", modified))
+		}
+		return fmt.Sprintf(`{
+			"name": %q,
+			"debug": %q,
+			"db": {"password": %q, "port": %q},
+			"keys": [%s],
+			"sops": %s
+		}`,
+			name,
+			sopsEncrypt(t, key, "True", "bool", "debug:"),
+			sopsEncrypt(t, key, "s3cret", "str", "db:password:"),
+			sopsEncrypt(t, key, "5432", "int", "db:port:"),
+			strings.Join(encKeys, ", "), meta)
+	}
+	mac := sopsMAC(values...)
+	doc := sopsDoc("plain", []string{"k1"}, mac)
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); !errors.Is(err, ErrSOPSNoKey) {
+		t.Fatalf("expected ErrSOPSNoKey without a provider, got %v", err)
+	}
+
+	t.Setenv("TEST_SOPS_DATA_KEY", hex.EncodeToString(key))
+	if err := ant.SetSOPSKeyProvider(SOPSDataKeyFromEnv("TEST_SOPS_DATA_KEY")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Name != "plain" || !cfg.Debug || cfg.DB.Password != "s3cret" || cfg.DB.Port != 5432 ||
+		len(cfg.Keys) != 1 || cfg.Keys[0] != "k1" {
+		t.Fatalf("unexpected decrypted config: %+v", cfg)
+	}
+
+	// A value encrypted for another key path fails authentication
+	tampered := strings.Replace(doc, sopsEncrypt(t, key, "s3cret", "str", "db:password:"), sopsEncrypt(t, key, "s3cret", "str", "other:"), 1)
+	if err := os.WriteFile(path, []byte(tampered), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Fatalf("expected authentication failure, got %v", err)
+	}
+
+	// Each value authenticates on its own; the MAC catches edited plaintext,
+	// dropped or added values and a missing or malformed mac
+	for name, doc := range map[string]string{
+		"edited plaintext": sopsDoc("evil", []string{"k1"}, mac),
+		"dropped value":    sopsDoc("plain", nil, mac),
+		"added value":      sopsDoc("plain", []string{"k1", "k1"}, mac),
+		"no mac":           sopsDoc("plain", []string{"k1"}, ""),
+		"malformed mac":    strings.Replace(sopsDoc("plain", []string{"k1"}, ""), `"version"`, `"mac": "]", "version"`, 1),
+		"truncated mac":    strings.Replace(sopsDoc("plain", []string{"k1"}, ""), `"version"`, `"mac": "ENC[", "version"`, 1),
+	} {
+		if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := ant.WriteConfigValues(); !errors.Is(err, ErrSOPSMACMismatch) {
+			t.Fatalf("%s: expected ErrSOPSMACMismatch, got %v", name, err)
+		}
+	}
+}
+
+// sopsMAC is the MAC sops records for the given leaf values in document order.
+func sopsMAC(values ...string) string {
+	h := sha512.New()
+	for _, v := range values {
+		h.Write([]byte(v))
+	}
+	return fmt.Sprintf("%X", h.Sum(nil))
+}
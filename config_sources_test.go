@@ -0,0 +1,100 @@
+package antconfig
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAddValuesPriority(t *testing.T) {
+	type Cfg struct {
+		Host string `default:"localhost" env:"SRC_HOST" flag:"host"`
+		Port int    `default:"80" env:"SRC_PORT"`
+		DB   struct {
+			Name  string `json:"db_name"`
+			Ports []int
+		}
+	}
+	t.Setenv("SRC_HOST", "env-host")
+	t.Setenv("SRC_PORT", "81")
+
+	var cfg Cfg
+	ant := New()
+	ant.SetFlagArgs([]string{"--host=flag-host"})
+	if err := ant.SetConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	// Between env and flags: overrides env, loses to flags
+	if err := ant.AddValues(map[string]any{"host": "mem-host", "Port": 9000}, PriorityEnv); err != nil {
+		t.Fatal(err)
+	}
+	// Right after defaults: overridden by env
+	if err := ant.AddValues(map[string]any{
+		"DB": map[string]any{"db_name": "discovered", "Ports": []int{1, 2}},
+	}, PriorityDefault); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Host != "flag-host" || cfg.Port != 9000 {
+		t.Fatalf("unexpected host/port: %+v", cfg)
+	}
+	if cfg.DB.Name != "discovered" || len(cfg.DB.Ports) != 2 {
+		t.Fatalf("unexpected nested values: %+v", cfg.DB)
+	}
+	prov := ant.Provenance()
+	if prov["Port"] != LayerMemory || prov["Host"] != LayerFlag || prov["DB.Name"] != LayerMemory {
+		t.Fatalf("unexpected provenance: %v", prov)
+	}
+}
+
+func TestAddValuesUnknownKey(t *testing.T) {
+	type Cfg struct {
+		Host string
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.AddValues(map[string]any{"Hots": "x"}, PriorityEnv); err == nil {
+		t.Fatal("expected error for unknown key")
+	}
+}
+
+type failingSource struct{}
+
+func (failingSource) Name() string { return "failing" }
+func (failingSource) Load(context.Context) (map[string]any, error) {
+	return nil, errors.New("unreachable")
+}
+
+type badValueSource struct{}
+
+func (badValueSource) Name() string { return "discovery" }
+func (badValueSource) Load(context.Context) (map[string]any, error) {
+	return map[string]any{"Port": "not-a-port"}, nil
+}
+
+func TestAddSourceErrors(t *testing.T) {
+	type Cfg struct {
+		Port int
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.AddSource(failingSource{}, PriorityFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err == nil || !strings.Contains(err.Error(), "failing") {
+		t.Fatalf("expected source load error, got %v", err)
+	}
+
+	ant = New().MustSetConfig(&cfg)
+	if err := ant.AddSource(badValueSource{}, PriorityFile); err != nil {
+		t.Fatal(err)
+	}
+	err := ant.WriteConfigValues()
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Source != "discovery" || fe.Path != "Port" {
+		t.Fatalf("expected FieldError from discovery source, got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package antconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStandardFlags(t *testing.T) {
+	type Cfg struct {
+		Host     string `json:"host" flag:"host"`
+		Port     int    `json:"port" env:"STD_PORT"`
+		Password string `json:"password" secret:"true" required:"true"`
+	}
+	dir := t.TempDir()
+	config := filepath.Join(dir, "app.json")
+	env := filepath.Join(dir, "app.env")
+	if err := os.WriteFile(config, []byte(`{"host": "from-file", "password": "hunter2"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(env, []byte("STD_PORT=8080\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	setup := func(args ...string) (*AntConfig, *Cfg, *bytes.Buffer) {
+		t.Helper()
+		cfg := new(Cfg)
+		ac := New().MustSetConfig(cfg)
+		ac.SetDotEnvExport(false)
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		ac.MustBindConfigFlags(fs)
+		if err := ac.EnableStandardFlags(fs); err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		ac.standard.out = &out
+		if err := fs.Parse(args); err != nil {
+			t.Fatal(err)
+		}
+		return ac, cfg, &out
+	}
+
+	ac, cfg, out := setup("--config", config, "--env-file", env)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "from-file" || cfg.Port != 8080 || out.Len() != 0 {
+		t.Fatalf("cfg = %+v, output %q", cfg, out)
+	}
+	ac.LockSources()
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("reload after LockSources: %v", err)
+	}
+
+	ac, _, out = setup("--config", config, "--print-config", "--host", "h")
+	if err := ac.WriteConfigValues(); !errors.Is(err, ErrExitRequested) {
+		t.Fatalf("err = %v", err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("output %q: %v", out, err)
+	}
+	if doc["host"] != "h" || doc["password"] != Redacted {
+		t.Fatalf("printed %v", doc)
+	}
+
+	ac, cfg, out = setup("--config", config, "--validate-config")
+	if err := ac.WriteConfigValues(); !errors.Is(err, ErrExitRequested) || !strings.Contains(out.String(), "app.json: OK") {
+		t.Fatalf("err = %v, output %q", err, out)
+	}
+	if cfg.Host != "" {
+		t.Fatalf("--validate-config must not load into the registered struct: %+v", cfg)
+	}
+
+	// A failed validation is an ordinary load error
+	empty := filepath.Join(dir, "empty.json")
+	if err := os.WriteFile(empty, []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ac, _, out = setup("--config", empty, "--validate-config")
+	if err := ac.WriteConfigValues(); err == nil || errors.Is(err, ErrExitRequested) || out.Len() != 0 {
+		t.Fatalf("err = %v, output %q", err, out)
+	}
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	fs.String("config", "", "")
+	if err := New().EnableStandardFlags(fs); err == nil {
+		t.Fatal("expected an error for an existing -config flag")
+	}
+}
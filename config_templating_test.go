@@ -0,0 +1,94 @@
+package antconfig
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestTemplating(t *testing.T) {
+	type Cfg struct {
+		Name     string `json:"name"`
+		DataDir  string `json:"data_dir"`
+		Password string `json:"password"`
+		Year     int    `json:"year"`
+		Host     string `json:"host"`
+	}
+	file := `{
+		// rendered before JSONC conversion
+		"name": "{{ env "TPL_NAME" }}",
+		"data_dir": "/srv/{{ env "TPL_MISSING" "myapp" }}",
+		"password": {{ env "TPL_PASSWORD" | json }},
+		"year": {{ now.Year }},
+		"host": "{{ hostname }}"
+	}`
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetEnvironment(map[string]string{"TPL_NAME": "api", "TPL_PASSWORD": `p"w\d`}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetFS(fstest.MapFS{"config.jsonc": {Data: []byte(file)}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.EnableTemplating(); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Name != "api" || cfg.DataDir != "/srv/myapp" || cfg.Password != `p"w\d` {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if cfg.Year < 2024 || cfg.Host == "" {
+		t.Fatalf("now or hostname not rendered: %+v", cfg)
+	}
+}
+
+func TestTemplatingDisabledByDefault(t *testing.T) {
+	type Cfg struct {
+		Greeting string `json:"greeting"`
+	}
+	var cfg Cfg
+	ac := New()
+	if err := ac.SetFS(fstest.MapFS{"config.json": {Data: []byte(`{"greeting": "Hello {{ .Name }}"}`)}}); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Greeting != "Hello {{ .Name }}" {
+		t.Fatalf("file was templated without EnableTemplating: %q", cfg.Greeting)
+	}
+}
+
+func TestTemplatingErrors(t *testing.T) {
+	type Cfg struct {
+		Host string `json:"host"`
+	}
+	cases := map[string]struct {
+		file string
+		want string
+	}{
+		"unknown function": {`{"host": "{{ readFile "/etc/passwd" }}"}`, `function "readFile" not defined`},
+		"syntax":           {"{\n\"host\": \"{{ if }}\"\n}", "config.json:2: missing value for if"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var cfg Cfg
+			ac := New()
+			if err := ac.SetFS(fstest.MapFS{"config.json": {Data: []byte(tc.file)}}); err != nil {
+				t.Fatal(err)
+			}
+			if err := ac.EnableTemplating(); err != nil {
+				t.Fatal(err)
+			}
+			ac.MustSetConfig(&cfg)
+			err := ac.WriteConfigValues()
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
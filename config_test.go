@@ -4,11 +4,14 @@ import (
 	"encoding/json"
 	"errors"
 	"flag"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 type TestConfig struct {
@@ -418,10 +421,8 @@ func TestErrorMessage_DefaultParseContext(t *testing.T) {
 	}
 	ant := New()
 	var c C
-	if err := ant.SetConfig(&c); err != nil {
-		t.Fatal(err)
-	}
-	err := ant.WriteConfigValues()
+	// Malformed defaults are reported at registration time
+	err := ant.SetConfig(&c)
 	if err == nil {
 		t.Fatal("expected default parse error")
 	}
@@ -431,6 +432,34 @@ func TestErrorMessage_DefaultParseContext(t *testing.T) {
 	}
 }
 
+func TestSetConfigReportsAllMalformedDefaults(t *testing.T) {
+	type Inner struct {
+		Ratio float64 `default:"half"`
+	}
+	type C struct {
+		I     int  `default:"x"`
+		B     bool `default:"maybe"`
+		Ok    int  `default:"7"`
+		Inner *Inner
+	}
+	var c C
+	err := New().SetConfig(&c)
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("expected *MultiError, got %v", err)
+	}
+	got := map[string]bool{}
+	for _, fe := range multi.Errors {
+		got[fe.Path] = true
+	}
+	if len(got) != 3 || !got["I"] || !got["B"] || !got["Inner.Ratio"] {
+		t.Fatalf("expected errors for I, B and Inner.Ratio, got %v", multi)
+	}
+	if c.Ok != 0 || c.Inner != nil {
+		t.Fatalf("validation must not modify the registered struct: %+v", c)
+	}
+}
+
 func TestErrorMessage_FlagParseContext(t *testing.T) {
 	type C struct {
 		I int `flag:"i"`
@@ -632,7 +661,9 @@ func TestDotEnvAutoDiscoveryWorkingDir(t *testing.T) {
 func TestListFlagsWithPrefix(t *testing.T) {
 	var cfg TestConfig
 	ant := New()
-	ant.SetFlagPrefix("config-")
+	if err := ant.SetFlagPrefix("config-"); err != nil {
+		t.Fatal(err)
+	}
 	specs, err := ant.ListFlags(&cfg)
 	if err != nil {
 		t.Fatalf("ListFlags error: %v", err)
@@ -659,7 +690,9 @@ func TestListFlagsWithPrefix(t *testing.T) {
 func TestBindFlagSetAndApply(t *testing.T) {
 	var cfg TestConfig
 	ant := New()
-	ant.SetFlagPrefix("config-")
+	if err := ant.SetFlagPrefix("config-"); err != nil {
+		t.Fatal(err)
+	}
 	fs := flag.NewFlagSet("antconfig-test", flag.ContinueOnError)
 	if err := ant.SetConfig(&cfg); err != nil {
 		t.Fatalf("SetConfig error: %v", err)
@@ -692,6 +725,87 @@ func TestBindFlagSetAndApply(t *testing.T) {
 	}
 }
 
+type testLevel int
+
+func (l *testLevel) String() string { return []string{"debug", "info", "warn"}[*l] }
+
+func (l *testLevel) Set(s string) error {
+	for i, name := range []string{"debug", "info", "warn"} {
+		if s == name {
+			*l = testLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level %q", s)
+}
+
+func TestBindFlagSetNativeValues(t *testing.T) {
+	type Cfg struct {
+		Timeout time.Duration `flag:"timeout"`
+		Level   testLevel     `flag:"level"`
+		Mode    testLevel     `flag:"mode"`
+		Name    string        `flag:"name"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	fs := flag.NewFlagSet("antconfig-test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	// A flag the user defined themselves must be reused, not re-registered
+	var userMode testLevel
+	fs.Var(&userMode, "mode", "user-defined mode")
+	if err := ant.BindConfigFlags(fs); err != nil {
+		t.Fatalf("BindConfigFlags error: %v", err)
+	}
+	if err := fs.Parse([]string{"--timeout=1m30s", "--level=warn", "--mode=info", "--name=svc"}); err != nil {
+		t.Fatalf("flag parse error: %v", err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues failed: %v", err)
+	}
+	if cfg.Timeout != 90*time.Second || cfg.Level != 2 || cfg.Mode != 1 || cfg.Name != "svc" {
+		t.Fatalf("unexpected config from native flags: %+v", cfg)
+	}
+	if err := fs.Parse([]string{"--level=loud"}); err == nil {
+		t.Fatal("expected custom flag.Value to reject invalid level at parse time")
+	}
+}
+
+func TestBindFlagSetParsesDuringParse(t *testing.T) {
+	type Cfg struct {
+		Start time.Time    `flag:"start"`
+		Day   time.Time    `flag:"day" layout:"2006-01-02"`
+		Size  testByteSize `flag:"size"`
+	}
+	newFS := func() (*AntConfig, *Cfg, *flag.FlagSet) {
+		var cfg Cfg
+		ant := New()
+		if err := ant.RegisterParser(reflect.TypeOf(testByteSize(0)), parseTestByteSize); err != nil {
+			t.Fatal(err)
+		}
+		ant.MustSetConfig(&cfg)
+		fs := flag.NewFlagSet("antconfig-test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		ant.MustBindConfigFlags(fs)
+		return ant, &cfg, fs
+	}
+	for _, bad := range []string{"--start=yesterday", "--day=31/01/2024", "--size=12"} {
+		_, _, fs := newFS()
+		if err := fs.Parse([]string{bad}); err == nil {
+			t.Errorf("%s: expected error from fs.Parse", bad)
+		}
+	}
+	ant, cfg, fs := newFS()
+	if err := fs.Parse([]string{"--start=2024-05-01T12:00:00Z", "--day=2024-01-31", "--size=2KB"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Start.Month() != time.May || cfg.Day.Day() != 31 || cfg.Size != 2<<10 {
+		t.Fatalf("unexpected values: %+v", cfg)
+	}
+}
+
 func TestNestedPointerInit(t *testing.T) {
 	type Inner struct {
 		Name string `default:"n"`
@@ -717,6 +831,34 @@ func TestNestedPointerInit(t *testing.T) {
 	}
 }
 
+func TestNestedPointerStaysNilWhenUnset(t *testing.T) {
+	type TLS struct {
+		Cert string `env:"LAZY_TLS_CERT" flag:"tls-cert"`
+		Key  string `env:"LAZY_TLS_KEY" required:"true"`
+	}
+	type Cfg struct {
+		Name string `default:"app"`
+		TLS  *TLS
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	ant.SetFlagArgs([]string{"--other=1"})
+	if err := ant.WriteConfigValues(); err == nil || !errors.Is(err, ErrRequired) {
+		t.Fatalf("expected required error for TLS.Key, got %v", err)
+	}
+	if cfg.TLS != nil {
+		t.Fatalf("expected TLS to stay nil, got %+v", cfg.TLS)
+	}
+
+	t.Setenv("LAZY_TLS_KEY", "k")
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.TLS == nil || cfg.TLS.Key != "k" {
+		t.Fatalf("expected TLS to be allocated by env, got %+v", cfg.TLS)
+	}
+}
+
 func TestJSONC_ToJSON(t *testing.T) {
 	src := []byte(`// top comment
 {
@@ -761,3 +903,322 @@ func TestJSONC_ToJSONInPlace(t *testing.T) {
 		t.Fatalf("expected x=1, got %v", m["x"])
 	}
 }
+
+func TestJSONC_ErrorPosition(t *testing.T) {
+	type Cfg struct {
+		Server struct {
+			Port int `json:"port"`
+		} `json:"server"`
+		Primary *struct{ Name string } `json:"primary"`
+	}
+	for _, tc := range []struct {
+		name, src, want string
+	}{
+		{"syntax", "// header\n{\n  /* ok */ \"server\": {\"port\": },\n}\n", "(line 3, column 31)"},
+		{"type", "{\n  // the port\n  \"server\": {\n    \"port\": \"80\", // quoted\n  },\n}\n", "(line 4, column 16)"},
+		{"unicode", "{\"primary\": {\"Name\": \"ünïcode\"}, \"server\": [}", "(line 1, column 45)"},
+	} {
+		path := filepath.Join(t.TempDir(), "config.jsonc")
+		if err := os.WriteFile(path, []byte(tc.src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var cfg Cfg
+		ac := New().MustSetConfig(&cfg)
+		ac.SetFlagArgs([]string{})
+		if err := ac.SetConfigPath(path); err != nil {
+			t.Fatal(err)
+		}
+		err := ac.WriteConfigValues()
+		if err == nil || !strings.HasSuffix(err.Error(), tc.want) {
+			t.Errorf("%s: got %v, want suffix %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestDotEnvMultilineExpansionCRLF(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, ".env")
+	content := "" +
+		"BASE=/srv/app\r\n" +
+		"DATA=${BASE}/data\r\n" +
+		"LOGS=$BASE/logs # inline comment\r\n" +
+		"FALLBACK=${DOTENV_UNSET_XYZ:-fallback}\r\n" +
+		"LITERAL='${BASE} stays'\r\n" +
+		"ESCAPED=\"cost \\$5\"\r\n" +
+		"FROM_OS=\"home is ${DOTENV_OS_HOME}\"\r\n" +
+		"CERT=\"-----BEGIN-----\r\nabc\r\n-----END-----\"\r\n" +
+		"SINGLE='line one\nline two'\n" +
+		"AFTER=ok\n"
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOTENV_OS_HOME", "/home/ant")
+
+	pairs, err := parseDotEnv([]byte(content), os.LookupEnv)
+	if err != nil {
+		t.Fatalf("parseDotEnv: %v", err)
+	}
+	got := map[string]string{}
+	for _, kv := range pairs {
+		got[kv.key] = kv.val
+	}
+	expected := map[string]string{
+		"BASE":     "/srv/app",
+		"DATA":     "/srv/app/data",
+		"LOGS":     "/srv/app/logs",
+		"FALLBACK": "fallback",
+		"LITERAL":  "${BASE} stays",
+		"ESCAPED":  "cost $5",
+		"FROM_OS":  "home is /home/ant",
+		"CERT":     "-----BEGIN-----\nabc\n-----END-----",
+		"SINGLE":   "line one\nline two",
+		"AFTER":    "ok",
+	}
+	for k, want := range expected {
+		if got[k] != want {
+			t.Errorf("%s: expected %q, got %q", k, want, got[k])
+		}
+	}
+}
+
+func TestDotEnvUnterminatedQuote(t *testing.T) {
+	_, err := parseDotEnv([]byte("A=1\nB=\"never closed\nC=3\n"), nil)
+	if err == nil {
+		t.Fatal("expected error for unterminated quoted value")
+	}
+	if !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected line number in error, got %v", err)
+	}
+}
+
+func TestDotEnvDirenvSyntax(t *testing.T) {
+	dir := t.TempDir()
+	envrc := "" +
+		"# direnv configuration\n" +
+		"PATH_add bin\n" +
+		"export ENVRC_BASE=/opt\n" +
+		"dotenv\n" +
+		"dotenv_if_exists .env.missing\n" +
+		"source_env_if_exists sub\n" +
+		"export ENVRC_QUOTED='it'\\''s'\n" +
+		"export ENVRC_MIXED=\"$ENVRC_BASE\"/lib' x'\n" +
+		"export ENVRC_OVERRIDE=envrc\n"
+	files := map[string]string{
+		".envrc":     envrc,
+		".env":       "ENVRC_FROM_DOTENV=${ENVRC_BASE}/dotenv\nENVRC_OVERRIDE=dotenv\n",
+		"sub/.envrc": "ENVRC_SUB=sub\n",
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	values := map[string]string{}
+	noEnv := func(string) (string, bool) { return "", false }
+	if err := loadDotEnv(fileSystem{}, filepath.Join(dir, ".envrc"), values, noEnv); err != nil {
+		t.Fatalf("loadDotEnv: %v", err)
+	}
+	expected := map[string]string{
+		"ENVRC_BASE":        "/opt",
+		"ENVRC_FROM_DOTENV": "/opt/dotenv",
+		"ENVRC_SUB":         "sub",
+		"ENVRC_QUOTED":      "it's",
+		"ENVRC_MIXED":       "/opt/lib x",
+		"ENVRC_OVERRIDE":    "envrc",
+	}
+	for k, want := range expected {
+		if values[k] != want {
+			t.Errorf("%s: expected %q, got %q", k, want, values[k])
+		}
+	}
+	if _, ok := values["PATH_add"]; ok {
+		t.Error("shell commands must be ignored")
+	}
+
+	// Required includes must exist, and include cycles are reported
+	if err := os.WriteFile(filepath.Join(dir, "a.env"), []byte("dotenv b.env\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.env"), []byte("dotenv a.env\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadDotEnv(fileSystem{}, filepath.Join(dir, "a.env"), map[string]string{}, noEnv); err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Fatalf("expected include cycle error, got %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "c.env"), []byte("dotenv nope.env\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := loadDotEnv(fileSystem{}, filepath.Join(dir, "c.env"), map[string]string{}, noEnv); err == nil {
+		t.Fatal("expected error for missing required include")
+	}
+}
+
+func TestDotEnvPrivateDoesNotMutateProcessEnv(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, ".env")
+	if err := os.WriteFile(p, []byte("PRIVATE_DOTENV_KEY=secret\nPRIVATE_DOTENV_OS=file\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PRIVATE_DOTENV_OS", "os")
+	type Cfg struct {
+		K  string `env:"PRIVATE_DOTENV_KEY"`
+		OS string `env:"PRIVATE_DOTENV_OS"`
+	}
+	var cfg Cfg
+	ant := New()
+	ant.SetDotEnvExport(false)
+	if err := ant.SetEnvPath(p); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.SetConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.K != "secret" {
+		t.Fatalf("expected K from private .env, got %q", cfg.K)
+	}
+	if cfg.OS != "os" {
+		t.Fatalf("expected OS env to win over .env, got %q", cfg.OS)
+	}
+	if _, ok := os.LookupEnv("PRIVATE_DOTENV_KEY"); ok {
+		t.Fatal("expected .env value not to be exported to the process environment")
+	}
+}
+
+func TestMultipleDotEnvFilesOrderedPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	profile := filepath.Join(dir, ".env.prod")
+	if err := os.WriteFile(base, []byte("ME_A=base\nME_B=base\nME_C=base\nME_ROOT=/srv\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(local, []byte("ME_B=local\nME_C=local\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(profile, []byte("ME_C=${ME_ROOT}/prod\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	type Cfg struct {
+		A string `env:"ME_A"`
+		B string `env:"ME_B"`
+		C string `env:"ME_C"`
+	}
+	var cfg Cfg
+	ant := New()
+	ant.SetDotEnvExport(false)
+	if err := ant.AddEnvPath(base); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.AddEnvPath(local); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.AddEnvPath(profile); err != nil {
+		t.Fatal(err)
+	}
+	if got := ant.EnvPaths(); len(got) != 3 || got[2] != profile || ant.EnvPath() != base {
+		t.Fatalf("unexpected EnvPaths/EnvPath: %v / %q", got, ant.EnvPath())
+	}
+	if err := ant.SetConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.A != "base" || cfg.B != "local" || cfg.C != "/srv/prod" {
+		t.Fatalf("expected later .env files to override earlier ones, got %+v", cfg)
+	}
+
+	if err := ant.AddEnvPath(filepath.Join(dir, ".env.missing")); !errors.Is(err, ErrEnvFileNotFound) {
+		t.Fatalf("expected ErrEnvFileNotFound, got %v", err)
+	}
+}
+
+func TestMultipleDotEnvFilesExpandOwnKeysFirst(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	if err := os.WriteFile(base, []byte("MX_FOO=old\nMX_HOST=db\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(local, []byte("MX_FOO=new\nMX_BAR=${MX_FOO}\nMX_URL=$MX_HOST/$MX_FOO\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	type Cfg struct {
+		Bar string `env:"MX_BAR"`
+		URL string `env:"MX_URL"`
+	}
+	var cfg Cfg
+	ant := New()
+	if err := ant.SetEnvironment(map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.AddEnvPath(base); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.AddEnvPath(local); err != nil {
+		t.Fatal(err)
+	}
+	ant.MustSetConfig(&cfg)
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	// The later file's own MX_FOO wins over the earlier file's; keys it does
+	// not define still resolve against the earlier file
+	if cfg.Bar != "new" || cfg.URL != "db/new" {
+		t.Fatalf("unexpected expansion: %+v", cfg)
+	}
+}
+
+func TestMultiErrorReportsAllFieldErrors(t *testing.T) {
+	type Cfg struct {
+		I int     `env:"ME_I"`
+		B bool    `flag:"b"`
+		F float64 `env:"ME_F" default:"1.5"`
+		S string  `env:"ME_S"`
+	}
+	t.Setenv("ME_I", "fast")
+	t.Setenv("ME_F", "nanx")
+	t.Setenv("ME_S", "fine")
+	var cfg Cfg
+	ant := New()
+	ant.SetFlagArgs([]string{"--b=maybe"})
+	if err := ant.SetConfig(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	err := ant.WriteConfigValues()
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("expected *MultiError, got %T: %v", err, err)
+	}
+	if len(multi.Errors) != 3 {
+		t.Fatalf("expected 3 field errors, got %d: %v", len(multi.Errors), err)
+	}
+	byPath := map[string]*FieldError{}
+	for _, fe := range multi.Errors {
+		byPath[fe.Path] = fe
+	}
+	if fe := byPath["I"]; fe == nil || fe.Source != LayerEnv || fe.Raw != "fast" {
+		t.Fatalf("unexpected error for I: %+v", fe)
+	}
+	if fe := byPath["B"]; fe == nil || fe.Source != LayerFlag || fe.Raw != "maybe" {
+		t.Fatalf("unexpected error for B: %+v", fe)
+	}
+	if fe := byPath["F"]; fe == nil || fe.Source != LayerEnv {
+		t.Fatalf("unexpected error for F: %+v", fe)
+	}
+	// Valid values are still applied, and bad ones leave the prior layer in place
+	if cfg.S != "fine" || cfg.F != 1.5 {
+		t.Fatalf("expected valid values applied despite errors, got %+v", cfg)
+	}
+	var fe *FieldError
+	if !errors.As(err, &fe) {
+		t.Fatal("expected errors.As to find a *FieldError")
+	}
+}
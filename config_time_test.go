@@ -0,0 +1,105 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeFields(t *testing.T) {
+	type Window struct {
+		Until *time.Time `json:"until" layout:"2006-01-02 15:04"`
+	}
+	type Cfg struct {
+		Launch  time.Time `json:"launch" default:"2024-03-01T10:00:00Z"`
+		Cutoff  time.Time `json:"cutoff" layout:"2006-01-02" default:"2024-01-31" env:"TIME_CUTOFF"`
+		Holiday time.Time `json:"holiday" layout:"02.01.2006"`
+		Window  Window    `json:"window"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	doc := `{"holiday": "24.12.2024", "window": {"until": "2024-06-30 18:00"}, "launch": "2024-04-01T08:00:00Z"}`
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TIME_CUTOFF", "2024-02-29")
+
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if !cfg.Launch.Equal(time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("Launch: %v", cfg.Launch)
+	}
+	if !cfg.Cutoff.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Cutoff: %v", cfg.Cutoff)
+	}
+	if !cfg.Holiday.Equal(time.Date(2024, 12, 24, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Holiday: %v", cfg.Holiday)
+	}
+	if cfg.Window.Until == nil || !cfg.Window.Until.Equal(time.Date(2024, 6, 30, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("Window.Until: %v", cfg.Window.Until)
+	}
+	if p := ant.Provenance(); p["Holiday"] != LayerFile || p["Cutoff"] != LayerEnv {
+		t.Errorf("unexpected provenance: %v", p)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"holiday": "2024-12-24"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := ant.WriteConfigValues()
+	if err == nil || !strings.Contains(err.Error(), `layout "02.01.2006"`) {
+		t.Fatalf("expected layout error for config value, got %v", err)
+	}
+
+	type Bad struct {
+		Day time.Time `layout:"2006-01-02" default:"01/02/2024"`
+	}
+	var bad Bad
+	if err := New().SetConfig(&bad); err == nil {
+		t.Fatal("expected malformed default to be rejected")
+	}
+}
+
+func TestTimeLayoutFromSource(t *testing.T) {
+	type Window struct {
+		Until *time.Time `json:"until" layout:"2006-01-02 15:04"`
+	}
+	type Cfg struct {
+		Cutoff time.Time `json:"cutoff" layout:"2006-01-02"`
+		Window Window    `json:"window"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.DisableAutoDiscovery(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.AddSource(optionSource{name: "remote", values: map[string]any{
+		"cutoff": "2024-05-01",
+		"window": map[string]any{"until": "2024-06-30 18:00"},
+	}}, PriorityFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if got := cfg.Cutoff.Format(time.DateOnly); got != "2024-05-01" {
+		t.Fatalf("Cutoff = %s", got)
+	}
+	if cfg.Window.Until == nil || cfg.Window.Until.Format("2006-01-02 15:04") != "2024-06-30 18:00" {
+		t.Fatalf("Until = %v", cfg.Window.Until)
+	}
+
+	if err := ant.AddValues(map[string]any{"cutoff": "01.05.2024"}, PriorityFlag); err != nil {
+		t.Fatal(err)
+	}
+	err := ant.WriteConfigValues()
+	if err == nil || !strings.Contains(err.Error(), `layout "2006-01-02"`) {
+		t.Fatalf("expected layout parse error, got %v", err)
+	}
+}
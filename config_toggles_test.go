@@ -0,0 +1,86 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDisableToggles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"Host": "discovered"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("TOGGLE_TEST_PORT=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	explicit := filepath.Join(dir, "explicit.json")
+	if err := os.WriteFile(explicit, []byte(`{"Host": "explicit"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	explicitEnv := filepath.Join(dir, "explicit.env")
+	if err := os.WriteFile(explicitEnv, []byte("TOGGLE_TEST_PORT=2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	type Cfg struct {
+		Host string `default:"localhost"`
+		Port int    `env:"TOGGLE_TEST_PORT" default:"80"`
+	}
+	cases := []struct {
+		name  string
+		setup func(*AntConfig)
+		host  string
+		port  int
+	}{
+		{"defaults", func(*AntConfig) {}, "discovered", 1},
+		{"no discovery", func(ac *AntConfig) {
+			if err := ac.DisableAutoDiscovery(); err != nil {
+				t.Fatal(err)
+			}
+		}, "localhost", 80},
+		{"no discovery, explicit paths", func(ac *AntConfig) {
+			if err := ac.DisableAutoDiscovery(); err != nil {
+				t.Fatal(err)
+			}
+			if err := ac.SetConfigPath(explicit); err != nil {
+				t.Fatal(err)
+			}
+			if err := ac.SetEnvPath(explicitEnv); err != nil {
+				t.Fatal(err)
+			}
+		}, "explicit", 2},
+		{"no config file", func(ac *AntConfig) {
+			if err := ac.DisableConfigFile(); err != nil {
+				t.Fatal(err)
+			}
+			if err := ac.SetConfigPath(explicit); err != nil {
+				t.Fatal(err)
+			}
+		}, "localhost", 1},
+		{"no dotenv", func(ac *AntConfig) {
+			if err := ac.DisableDotEnv(); err != nil {
+				t.Fatal(err)
+			}
+			if err := ac.SetEnvPath(explicitEnv); err != nil {
+				t.Fatal(err)
+			}
+		}, "discovered", 80},
+	}
+	for _, tc := range cases {
+		var cfg Cfg
+		ac := New().MustSetConfig(&cfg)
+		if err := ac.SetEnvironment(map[string]string{}); err != nil {
+			t.Fatal(err)
+		}
+		ac.SetFlagArgs([]string{})
+		tc.setup(ac)
+		if err := ac.WriteConfigValues(); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if cfg.Host != tc.host || cfg.Port != tc.port {
+			t.Errorf("%s: got %+v, want Host=%s Port=%d", tc.name, cfg, tc.host, tc.port)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package antconfig
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type recordedSpan struct {
+	name   string
+	parent string
+	attrs  map[string]string
+	err    error
+	ended  bool
+}
+
+type recordingTracer struct{ spans []*recordedSpan }
+
+type (
+	spanKey struct{}
+	reqKey  struct{}
+)
+
+func (r *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	s := &recordedSpan{name: name, attrs: map[string]string{}}
+	if p, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		s.parent = p.name
+	}
+	r.spans = append(r.spans, s)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (s *recordedSpan) SetAttribute(key, value string) { s.attrs[key] = value }
+func (s *recordedSpan) RecordError(err error)          { s.err = err }
+func (s *recordedSpan) End()                           { s.ended = true }
+
+type ctxSource struct {
+	t   *testing.T
+	err error
+}
+
+func (c ctxSource) Name() string { return "remote" }
+
+func (c ctxSource) Load(ctx context.Context) (map[string]any, error) {
+	if ctx.Value(reqKey{}) != "abc" {
+		c.t.Error("source did not receive the caller's context")
+	}
+	if s, ok := ctx.Value(spanKey{}).(*recordedSpan); !ok || s.name != "antconfig.source" {
+		c.t.Error("source context does not carry its span")
+	}
+	return map[string]any{"Host": "remote-host"}, c.err
+}
+
+func TestTracing(t *testing.T) {
+	type Cfg struct{ Host string }
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	tr := &recordingTracer{}
+	ant.SetTracer(tr)
+	if err := ant.AddSource(ctxSource{t: t}, PriorityEnv); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), reqKey{}, "abc")
+	if err := ant.WriteConfigValuesContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "remote-host" || len(tr.spans) != 2 {
+		t.Fatalf("unexpected result: %+v, %d spans", cfg, len(tr.spans))
+	}
+	load, src := tr.spans[0], tr.spans[1]
+	if load.name != "antconfig.load" || src.name != "antconfig.source" || src.parent != "antconfig.load" {
+		t.Errorf("unexpected spans: %+v %+v", load, src)
+	}
+	if src.attrs["antconfig.source.name"] != "remote" || src.attrs["antconfig.source.priority"] != "300" {
+		t.Errorf("unexpected attributes: %v", src.attrs)
+	}
+	if !load.ended || !src.ended || load.err != nil {
+		t.Errorf("spans not ended cleanly: %+v %+v", load, src)
+	}
+
+	failing := New().MustSetConfig(&cfg)
+	tr = &recordingTracer{}
+	failing.SetTracer(tr)
+	_ = failing.AddSource(ctxSource{t: t, err: errors.New("timeout")}, PriorityEnv)
+	err := failing.WriteConfigValuesContext(ctx)
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("expected source error, got %v", err)
+	}
+	if tr.spans[0].err == nil || tr.spans[1].err == nil || !tr.spans[0].ended {
+		t.Errorf("errors not recorded on spans: %+v %+v", tr.spans[0], tr.spans[1])
+	}
+}
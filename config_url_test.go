@@ -0,0 +1,90 @@
+package antconfig
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestURLAndAddrFields(t *testing.T) {
+	type Cfg struct {
+		API      url.URL        `json:"api" default:"https://api.example.com/v1"`
+		Webhook  *url.URL       `json:"webhook"`
+		Proxy    *url.URL       `json:"proxy" env:"URL_PROXY"`
+		Unset    *url.URL       `json:"unset"`
+		Bind     netip.Addr     `json:"bind" default:"127.0.0.1"`
+		Peer     netip.AddrPort `json:"peer" flag:"peer"`
+		Resolver *netip.Addr    `json:"resolver" env:"URL_RESOLVER"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"webhook": "https://hooks.example.com/in?x=1", "bind": "::1"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("URL_PROXY", "http://user:pw@proxy:3128")
+	t.Setenv("URL_RESOLVER", "1.1.1.1")
+
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	ant.MustBindConfigFlags(fs)
+	if err := fs.Parse([]string{"--peer", "[::1]:8443"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.API.Host != "api.example.com" || cfg.API.Path != "/v1" {
+		t.Errorf("API: %+v", cfg.API)
+	}
+	if cfg.Webhook == nil || cfg.Webhook.String() != "https://hooks.example.com/in?x=1" {
+		t.Errorf("Webhook: %v", cfg.Webhook)
+	}
+	if cfg.Proxy == nil || cfg.Proxy.User.Username() != "user" || cfg.Proxy.Port() != "3128" {
+		t.Errorf("Proxy: %v", cfg.Proxy)
+	}
+	if cfg.Unset != nil {
+		t.Errorf("Unset should stay nil, got %v", cfg.Unset)
+	}
+	if cfg.Bind != netip.IPv6Loopback() {
+		t.Errorf("Bind: %v", cfg.Bind)
+	}
+	if cfg.Peer != netip.MustParseAddrPort("[::1]:8443") {
+		t.Errorf("Peer: %v", cfg.Peer)
+	}
+	if cfg.Resolver == nil || *cfg.Resolver != netip.MustParseAddr("1.1.1.1") {
+		t.Errorf("Resolver: %v", cfg.Resolver)
+	}
+	if p := ant.Provenance(); p["Webhook"] != LayerFile || p["Proxy"] != LayerEnv || p["Peer"] != LayerFlag {
+		t.Errorf("unexpected provenance: %v", p)
+	}
+}
+
+func TestURLAndAddrFieldErrors(t *testing.T) {
+	type Cfg struct {
+		API  *url.URL   `json:"api" env:"URL_BAD_API"`
+		Bind netip.Addr `json:"bind" env:"URL_BAD_BIND"`
+	}
+	t.Setenv("URL_BAD_API", "http://[::1")
+	t.Setenv("URL_BAD_BIND", "300.1.1.1")
+
+	var cfg Cfg
+	err := New().MustSetConfig(&cfg).WriteConfigValues()
+	var me *MultiError
+	if !errors.As(err, &me) || len(me.Errors) != 2 {
+		t.Fatalf("expected two field errors, got %v", err)
+	}
+	for i, want := range []string{"API", "Bind"} {
+		if me.Errors[i].Path != want || me.Errors[i].Source != LayerEnv {
+			t.Errorf("error %d: got %s from %s", i, me.Errors[i].Path, me.Errors[i].Source)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package antconfig
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestWriteEnvHelpFlagLayout(t *testing.T) {
+	type Cfg struct {
+		Host    string        `env:"APP_HOST" default:"localhost" desc:"connect to this server"`
+		Port    int           "env:\"APP_PORT\" default:\"8080\" desc:\"listen on `port`\""
+		Debug   bool          `env:"X" desc:"enable debug"`
+		Timeout time.Duration `env:"APP_TIMEOUT"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	var buf bytes.Buffer
+	if err := ant.WriteEnvHelp(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := "Environment variables:\n" +
+		"  APP_HOST string\n    \tconnect to this server (default \"localhost\")\n" +
+		"  APP_PORT port\n    \tlisten on port (default 8080)\n" +
+		"  X\tenable debug\n" +
+		"  APP_TIMEOUT duration\n    \t\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("unexpected help:\n%q\nwant:\n%q", got, want)
+	}
+	if ant.EnvHelpString() != want {
+		t.Fatal("EnvHelpString must match WriteEnvHelp output")
+	}
+}
+
+func TestWriteEnvHelpWraps(t *testing.T) {
+	type Cfg struct {
+		Token string `env:"TOKEN" desc:"the access token used to authenticate against the upstream API"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	ant.SetUsageWidth(40)
+	want := "Environment variables:\n" +
+		"  TOKEN string\n" +
+		"    \tthe access token used to\n" +
+		"    \tauthenticate against the\n" +
+		"    \tupstream API\n"
+	if got := ant.EnvHelpString(); got != want {
+		t.Fatalf("unexpected help:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestHelpGroups(t *testing.T) {
+	type DB struct {
+		Host string `env:"DB_HOST" flag:"db-host" desc:"database host"`
+		Pool int    `env:"DB_POOL" flag:"db-pool" group:"Tuning"`
+	}
+	type Cfg struct {
+		Verbose bool   `env:"VERBOSE" flag:"v" group:"Logging"`
+		Name    string `env:"NAME" flag:"name"`
+		DB      DB     `group:"Database"`
+		Level   string `env:"LEVEL" flag:"level" group:"Logging" default:"info"`
+	}
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	want := "Environment variables:\n" +
+		"  NAME string\n    \t\n" +
+		"\nLogging:\n" +
+		"  VERBOSE\n    \t\n" +
+		"  LEVEL string\n    \t(default \"info\")\n" +
+		"\nDatabase:\n" +
+		"  DB_HOST string\n    \tdatabase host\n" +
+		"\nTuning:\n" +
+		"  DB_POOL int\n    \t\n"
+	if got := ant.EnvHelpString(); got != want {
+		t.Fatalf("unexpected env help:\n%q\nwant:\n%q", got, want)
+	}
+
+	if err := ant.SetFlagPrefix("app-"); err != nil {
+		t.Fatal(err)
+	}
+	want = "Flags:\n" +
+		"  -app-name string\n    \t\n" +
+		"\nLogging:\n" +
+		"  -app-v\n    \t\n" +
+		"  -app-level string\n    \t(default \"info\")\n" +
+		"\nDatabase:\n" +
+		"  -app-db-host string\n    \tdatabase host\n" +
+		"\nTuning:\n" +
+		"  -app-db-pool int\n    \t\n"
+	if got := ant.FlagHelpString(); got != want {
+		t.Fatalf("unexpected flag help:\n%q\nwant:\n%q", got, want)
+	}
+}
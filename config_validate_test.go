@@ -0,0 +1,64 @@
+package antconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	type Cfg struct {
+		Host  string `json:"host" env:"VAL_HOST" default:"localhost"`
+		Port  int    `json:"port" env:"VAL_PORT"`
+		Token string `json:"token" env:"VAL_TOKEN" required:"true"`
+	}
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFile, []byte("VAL_HOST=from-dotenv\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := Cfg{Host: "current"}
+	ac := New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--"})
+	if err := ac.SetEnvPath(envFile); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetLookupEnv(func(key string) (string, bool) {
+		if key == "VAL_PORT" {
+			return "eighty", true
+		}
+		return "", false
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := ac.Validate()
+	var me *MultiError
+	if !errors.As(err, &me) || len(me.Errors) != 2 {
+		t.Fatalf("expected the bad port and the missing token, got %v", err)
+	}
+	if cfg.Host != "current" || cfg.Port != 0 || ac.Provenance() != nil {
+		t.Fatalf("Validate changed state: cfg = %+v, provenance %v", cfg, ac.Provenance())
+	}
+	if _, ok := os.LookupEnv("VAL_HOST"); ok {
+		t.Fatal("Validate must not export .env values")
+	}
+
+	if err := ac.SetLookupEnv(func(key string) (string, bool) {
+		v, ok := map[string]string{"VAL_PORT": "80", "VAL_TOKEN": "t"}[key]
+		return v, ok
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "current" {
+		t.Fatalf("Validate changed the registered struct: %+v", cfg)
+	}
+	if err := New().Validate(); err == nil {
+		t.Fatal("expected an error without SetConfig")
+	}
+}
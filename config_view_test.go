@@ -0,0 +1,88 @@
+package antconfig
+
+import (
+	"math/big"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadOnlyView(t *testing.T) {
+	type DB struct {
+		Hosts []string
+		Opts  map[string]string
+		TLS   *struct{ Cert string }
+	}
+	type Cfg struct {
+		DB DB
+	}
+	cfg := Cfg{DB: DB{
+		Hosts: []string{"a", "b"},
+		Opts:  map[string]string{"k": "v"},
+		TLS:   &struct{ Cert string }{Cert: "c"},
+	}}
+
+	view := ReadOnly(&cfg.DB)
+	got := view.Get()
+	got.Hosts[0] = "mutated"
+	got.Opts["k"] = "mutated"
+	got.TLS.Cert = "mutated"
+	if cfg.DB.Hosts[0] != "a" || cfg.DB.Opts["k"] != "v" || cfg.DB.TLS.Cert != "c" {
+		t.Fatalf("mutating the view's value leaked into the shared config: %+v", cfg.DB)
+	}
+
+	cfg.DB.Hosts = []string{"z"}
+	if h := view.Get().Hosts; len(h) != 1 || h[0] != "z" {
+		t.Fatalf("expected view to reflect current config, got %v", h)
+	}
+}
+
+// secret keeps its state unexported and copies itself with Clone.
+type secret struct{ b []byte }
+
+func (s *secret) Clone() *secret { return &secret{b: append([]byte(nil), s.b...)} }
+
+func TestReadOnlyViewHiddenState(t *testing.T) {
+	type Cfg struct {
+		Limit *big.Int
+		Ratio big.Rat
+		Match *regexp.Regexp
+		Start time.Time
+		Key   secret
+	}
+	cfg := Cfg{
+		Limit: big.NewInt(10),
+		Ratio: *big.NewRat(1, 3),
+		Match: regexp.MustCompile("a+"),
+		Start: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Key:   secret{b: []byte("k")},
+	}
+	got := ReadOnly(&cfg).Get()
+	got.Limit.SetInt64(99)
+	got.Ratio.SetInt64(7)
+	got.Key.b[0] = 'x'
+	if cfg.Limit.Int64() != 10 || cfg.Ratio.String() != "1/3" || string(cfg.Key.b) != "k" {
+		t.Fatalf("mutating the view's value leaked into the shared config: %v %v %q", cfg.Limit, &cfg.Ratio, cfg.Key.b)
+	}
+	if !got.Match.MatchString("aa") || !got.Start.Equal(cfg.Start) {
+		t.Fatalf("immutable values not copied: %v %v", got.Match, got.Start)
+	}
+}
+
+func TestReadOnlyViewRefusesUncopyableTypes(t *testing.T) {
+	type opaque struct{ buf []byte }
+	type Cfg struct {
+		DB struct{ Pool *opaque }
+	}
+	_, err := NewReadOnly(&Cfg{})
+	if err == nil || !strings.Contains(err.Error(), "field DB.Pool") || !strings.Contains(err.Error(), "opaque") {
+		t.Fatalf("expected error naming DB.Pool, got %v", err)
+	}
+	defer func() {
+		if r, _ := recover().(error); r == nil || r.Error() != err.Error() {
+			t.Fatalf("expected ReadOnly to panic with %v, got %v", err, r)
+		}
+	}()
+	ReadOnly(&Cfg{})
+}
@@ -0,0 +1,54 @@
+package antconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOnWarning(t *testing.T) {
+	type DB struct {
+		Host string `json:"host" alias:"hostname"`
+	}
+	type Cfg struct {
+		DB   DB       `json:"db"`
+		Tags []string `json:"tags" env:"WARN_TAGS"`
+		Mode string   `json:"mode" removed_in:"v3"`
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	doc := `{"db": {"hostname": "h", "pool": 4}, "mode": "fast", "extra": true}`
+	if err := os.WriteFile(path, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("WARN_TAGS", "a,b")
+
+	var cfg Cfg
+	ant := New().MustSetConfig(&cfg)
+	if err := ant.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	ant.SetAppVersion("v2.1")
+	got := map[WarningKind][]string{}
+	ant.OnWarning(func(w Warning) { got[w.Kind] = append(got[w.Kind], w.Path+"|"+string(w.Source)) })
+	if err := ant.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if len(got[WarningUnknownKey]) != 2 {
+		t.Errorf("expected two unknown keys, got %v", got[WarningUnknownKey])
+	}
+	for _, k := range got[WarningUnknownKey] {
+		if k != "db.pool|file" && k != "extra|file" {
+			t.Errorf("unexpected unknown key %s", k)
+		}
+	}
+	if want := []string{"DB.Host|file", "Mode|file"}; !reflect.DeepEqual(got[WarningDeprecated], want) {
+		t.Errorf("deprecated: got %v, want %v", got[WarningDeprecated], want)
+	}
+	if want := []string{"Tags|env"}; !reflect.DeepEqual(got[WarningIgnoredValue], want) {
+		t.Errorf("ignored: got %v, want %v", got[WarningIgnoredValue], want)
+	}
+	if _, ok := ant.Provenance()["Tags"]; ok {
+		t.Error("ignored env value should not be recorded as provenance")
+	}
+}
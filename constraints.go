@@ -0,0 +1,141 @@
+package antconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrConstraint is reported, wrapped in a FieldError, when a field's
+// `validate:"…"` constraint is violated after every layer has been applied.
+var ErrConstraint = errors.New("configuration constraint violated")
+
+// constraint is one clause of a `validate` tag.
+type constraint struct {
+	// kind is "requires" or "conflicts".
+	kind string
+	// target is the Go field path of the other field.
+	target string
+}
+
+// parseConstraints parses the `validate` tag of the field at path against the
+// fields of the config, keyed by Go field path. Clauses are comma-separated
+// requires=Name or conflicts=Name; Name is a field of the same struct or a
+// dotted path from the config root.
+func parseConstraints(path, tag string, byPath map[string]fieldWithTagValue) ([]constraint, error) {
+	var out []constraint
+	for _, clause := range strings.Split(tag, ",") {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		kind, name, ok := strings.Cut(clause, "=")
+		kind, name = strings.TrimSpace(kind), strings.TrimSpace(name)
+		if !ok || name == "" || (kind != "requires" && kind != "conflicts") {
+			return nil, fmt.Errorf("field %s: invalid validate clause %q (want requires=Field or conflicts=Field)", path, clause)
+		}
+		target, found := resolveSibling(path, name, byPath)
+		if !found {
+			return nil, fmt.Errorf("field %s: validate clause %q names unknown field %s", path, clause, name)
+		}
+		out = append(out, constraint{kind: kind, target: target})
+	}
+	return out, nil
+}
+
+// resolveSibling resolves name relative to the struct holding the field at
+// path, falling back to a path from the config root.
+func resolveSibling(path, name string, byPath map[string]fieldWithTagValue) (string, bool) {
+	if i := strings.LastIndexByte(path, '.'); i >= 0 {
+		if p := path[:i+1] + name; byPath[p].path != "" {
+			return p, true
+		}
+	}
+	if byPath[name].path != "" {
+		return name, true
+	}
+	return "", false
+}
+
+// indexByPath maps every field to its Go field path.
+func indexByPath(fields []fieldWithTagValue) map[string]fieldWithTagValue {
+	byPath := make(map[string]fieldWithTagValue, len(fields))
+	for _, f := range fields {
+		byPath[f.path] = f
+	}
+	return byPath
+}
+
+// validateConstraints checks the `validate` tags of struct type t, so typos
+// in field names surface when the config is registered instead of at load.
+func validateConstraints(t reflect.Type) error {
+	fields, err := findFieldsWithTag("", reflect.New(t).Interface())
+	if err != nil {
+		return err
+	}
+	byPath := indexByPath(fields)
+	var errs []error
+	for _, f := range fields {
+		if tag := f.tag.Get("validate"); tag != "" {
+			if _, err := parseConstraints(f.path, tag, byPath); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkConstraints evaluates the `validate` tags of the loaded config. A
+// field's constraints apply only when the field is set (non-zero): requires
+// needs the other field set as well, conflicts needs it left unset.
+func (a *AntConfig) checkConstraints(run *loadRun) []*FieldError {
+	fields := run.plan.withTag("validate")
+	if len(fields) == 0 {
+		return nil
+	}
+	byPath := indexByPath(run.plan.fields)
+	var errs []*FieldError
+	for _, f := range fields {
+		if f.current().IsZero() {
+			continue
+		}
+		rules, err := parseConstraints(f.path, f.tagvalue, byPath)
+		if err != nil {
+			errs = append(errs, &FieldError{Path: f.path, Source: run.provenance[f.path], Err: err})
+			continue
+		}
+		for _, r := range rules {
+			other := byPath[r.target]
+			otherSet := !other.current().IsZero()
+			var msg string
+			switch {
+			case r.kind == "requires" && !otherSet:
+				msg = fmt.Sprintf("%s requires %s to be set", a.settingName(f, run), r.target)
+				if via := a.settingHints(other); len(via) > 0 {
+					msg += " (via " + strings.Join(via, ", ") + ")"
+				}
+			case r.kind == "conflicts" && otherSet:
+				msg = fmt.Sprintf("%s cannot be combined with %s", a.settingName(f, run), a.settingName(other, run))
+			default:
+				continue
+			}
+			errs = append(errs, &FieldError{Path: f.path, Source: run.provenance[f.path], Err: fmt.Errorf("%w: %s", ErrConstraint, msg)})
+		}
+	}
+	return errs
+}
+
+// settingName names a field the way it was set in this run, e.g.
+// "--tls-cert" or "env var TLS_CERT", falling back to its Go field path.
+func (a *AntConfig) settingName(f fieldWithTagValue, run *loadRun) string {
+	switch layer := run.provenance[f.path]; {
+	case layer == LayerFlag && f.tags["flag"] != "":
+		return "--" + a.flagPrefix + f.tags["flag"]
+	case (layer == LayerEnv || layer == LayerDotEnv) && f.tags["env"] != "":
+		return "env var " + f.tags["env"]
+	case (layer == LayerFile || layer == LayerEmbedded) && f.jsonPath != nil:
+		return "config key " + strings.Join(f.jsonPath, ".")
+	}
+	return f.path
+}
@@ -0,0 +1,31 @@
+package antconfig
+
+import "sync"
+
+// DebugLogger receives diagnostic messages, such as which config file
+// discovery picked. *log.Logger satisfies it.
+type DebugLogger interface {
+	Printf(format string, args ...any)
+}
+
+var (
+	debugMu     sync.RWMutex
+	debugLogger DebugLogger
+)
+
+// SetDebugLogger routes the package's diagnostic messages to l; nil (the
+// default) discards them. They are the SetSlog records of every AntConfig
+// without its own slog logger, one line each as "antconfig: msg key=value
+// ...". The package never writes to stdout or stderr on its own: failures are
+// returned as errors and soft issues go to OnWarning.
+func SetDebugLogger(l DebugLogger) {
+	debugMu.Lock()
+	debugLogger = l
+	debugMu.Unlock()
+}
+
+func currentDebugLogger() DebugLogger {
+	debugMu.RLock()
+	defer debugMu.RUnlock()
+	return debugLogger
+}
@@ -0,0 +1,74 @@
+package antconfig
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// defaultFuncs holds the named functions usable as $(name) in `default` tags.
+var defaultFuncs sync.Map // string -> func() (string, error)
+
+func init() {
+	RegisterDefaultFunc("hostname", os.Hostname)
+	RegisterDefaultFunc("numcpu", func() (string, error) { return strconv.Itoa(runtime.NumCPU()), nil })
+	RegisterDefaultFunc("tempdir", func() (string, error) { return os.TempDir(), nil })
+	RegisterDefaultFunc("homedir", os.UserHomeDir)
+	RegisterDefaultFunc("cwd", os.Getwd)
+}
+
+// RegisterDefaultFunc registers fn under name for computed defaults: every
+// $(name) in a `default` tag is replaced by fn's result before the default is
+// parsed, e.g. `default:"$(hostname)"` or `default:"$(tempdir)/cache"`. In a
+// tag containing $(, use $$ for a literal dollar sign; tags without $( are
+// taken verbatim, so `default:"pa$$word"` keeps both dollars. Built-in
+// functions are hostname, numcpu, tempdir, homedir, and cwd; registering a
+// name again replaces it. Register custom functions before SetConfig, which
+// validates defaults.
+func RegisterDefaultFunc(name string, fn func() (string, error)) {
+	defaultFuncs.Store(name, fn)
+}
+
+// expandDefault replaces the $(name) references in a `default` tag value
+// with the results of the registered functions. Tags without a $( are
+// returned unchanged, $$ included.
+func expandDefault(def string) (string, error) {
+	if !strings.Contains(def, "$(") {
+		return def, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(def); i++ {
+		c := def[i]
+		if c != '$' || i+1 == len(def) {
+			b.WriteByte(c)
+			continue
+		}
+		switch def[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '(':
+			end := strings.IndexByte(def[i+2:], ')')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated $( in %q", def)
+			}
+			name := def[i+2 : i+2+end]
+			fn, ok := defaultFuncs.Load(name)
+			if !ok {
+				return "", fmt.Errorf("unknown default function %q", name)
+			}
+			v, err := fn.(func() (string, error))()
+			if err != nil {
+				return "", fmt.Errorf("default function %s: %w", name, err)
+			}
+			b.WriteString(v)
+			i += 2 + end
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,131 @@
+package antconfig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrKeyRemoved is returned when a field tagged `removed_in:"…"` is still
+// supplied by a config file, environment variable, or flag after the running
+// application version has reached the removal version.
+var ErrKeyRemoved = errors.New("configuration key has been removed")
+
+// checkRemovedKeys reports every field tagged `removed_in` whose removal
+// version has been reached and that is still set by a non-default layer; such
+// fields used before their removal version are recorded as warnings. doc is
+// the generic form of the loaded config file (nil if none), flagValues the
+// parsed flag values keyed by name, and lookupEnv the effective environment.
+func (a *AntConfig) checkRemovedKeys(run *loadRun, doc map[string]any, flagValues map[string]*string, lookupEnv func(string) (string, Layer, bool)) error {
+	var errs []error
+	for _, f := range run.plan.withTag("removed_in") {
+		removed := a.appVersion != "" && compareVersions(a.appVersion, f.tagvalue) >= 0
+		var used []string
+		if f.jsonPath != nil && jsonHasPath(doc, f.jsonPath) {
+			used = append(used, "config key "+strings.Join(f.jsonPath, "."))
+		}
+		if name := f.tags["env"]; name != "" {
+			if v, _, _ := lookupEnv(name); v != "" {
+				used = append(used, "env var "+name)
+			}
+		}
+		if name := f.tags["flag"]; name != "" {
+			if _, ok := flagValues[name]; ok {
+				used = append(used, "flag --"+name)
+			} else if _, ok := flagValues[a.flagPrefix+name]; ok && a.flagPrefix != "" {
+				used = append(used, "flag --"+a.flagPrefix+name)
+			}
+		}
+		if len(used) == 0 {
+			continue
+		}
+		if !removed {
+			run.warn(WarningDeprecated, f.path, run.provenance[f.path], fmt.Sprintf("%s is deprecated and will be removed in %s; still set via %s",
+				f.path, f.tagvalue, strings.Join(used, ", ")))
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%w: %s was removed in %s (running %s) but is still set via %s",
+			ErrKeyRemoved, f.path, f.tagvalue, a.appVersion, strings.Join(used, ", ")))
+	}
+	return errors.Join(errs...)
+}
+
+// jsonHasPath reports whether the decoded JSON document contains the key path.
+// Keys match case-insensitively, like encoding/json does for struct fields.
+func jsonHasPath(doc map[string]any, path []string) bool {
+	cur := doc
+	for i, key := range path {
+		var next any
+		found := false
+		for k, v := range cur {
+			if strings.EqualFold(k, key) {
+				next, found = v, true
+				if k == key {
+					break
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+		if i == len(path)-1 {
+			return true
+		}
+		m, ok := next.(map[string]any)
+		if !ok {
+			return false
+		}
+		cur = m
+	}
+	return false
+}
+
+// compareVersions compares two dotted versions such as "v1.2.3" and "2".
+// Missing components count as zero and a pre-release suffix ("-rc1") sorts
+// before the corresponding release. It returns -1, 0, or +1.
+func compareVersions(a, b string) int {
+	aCore, aPre := splitVersion(a)
+	bCore, bPre := splitVersion(b)
+	for i := 0; i < len(aCore) || i < len(bCore); i++ {
+		var x, y int
+		if i < len(aCore) {
+			x = aCore[i]
+		}
+		if i < len(bCore) {
+			y = bCore[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	case aPre < bPre:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// splitVersion parses "v1.2.3-rc1+meta" into its numeric components and
+// pre-release label. Non-numeric components count as zero.
+func splitVersion(v string) ([]int, string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	v, _, _ = strings.Cut(v, "+")
+	core, pre, _ := strings.Cut(v, "-")
+	parts := strings.Split(core, ".")
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		nums[i], _ = strconv.Atoi(p)
+	}
+	return nums, pre
+}
@@ -0,0 +1,85 @@
+package antconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Descriptor is a serializable description of a config struct: every field
+// with its type and struct tags. It lets tools such as cmd/antconfig validate
+// and resolve config files without compiling the application's Go types.
+type Descriptor struct {
+	Fields []FieldDescriptor `json:"fields"`
+}
+
+// FieldDescriptor describes one config field of a Descriptor.
+type FieldDescriptor struct {
+	// Path is the dotted Go field path, e.g. "Database.Host".
+	Path string `json:"path"`
+	// Key is the dotted config file key, empty when the field cannot be set
+	// from a file (json:"-").
+	Key string `json:"key,omitempty"`
+	// Type is the Go type as printed by reflect, e.g. "time.Duration".
+	Type string `json:"type"`
+	// Kind is the underlying reflect.Kind, used for types a tool cannot name.
+	Kind string `json:"kind"`
+	// Tag is the complete struct tag of the field.
+	Tag      string `json:"tag,omitempty"`
+	Default  string `json:"default,omitempty"`
+	Env      string `json:"env,omitempty"`
+	Flag     string `json:"flag,omitempty"`
+	Desc     string `json:"desc,omitempty"`
+	Required bool   `json:"required,omitempty"`
+	Secret   bool   `json:"secret,omitempty"`
+}
+
+// Describe returns the Descriptor of the registered config struct. Nested
+// structs are flattened into their leaf fields. Requires SetConfig to have
+// been called; otherwise it returns an empty Descriptor.
+func (a *AntConfig) Describe() Descriptor {
+	if a.cfgRef == nil {
+		return Descriptor{}
+	}
+	fields, err := findFieldsWithTag("", reflect.New(reflect.TypeOf(a.cfgRef).Elem()).Interface())
+	if err != nil {
+		return Descriptor{}
+	}
+	d := Descriptor{Fields: []FieldDescriptor{}}
+	for _, f := range fields {
+		t := f.fieldValue.Type()
+		if _, nested := nestedStruct(t); nested {
+			continue
+		}
+		d.Fields = append(d.Fields, FieldDescriptor{
+			Path:     f.path,
+			Key:      strings.Join(f.jsonPath, "."),
+			Type:     t.String(),
+			Kind:     t.Kind().String(),
+			Tag:      string(f.tag),
+			Default:  f.tags["default"],
+			Env:      f.tags["env"],
+			Flag:     f.tags["flag"],
+			Desc:     f.tags["desc"],
+			Required: isRequired(f.tags["required"]),
+			Secret:   isSecret(f.tags["secret"]),
+		})
+	}
+	return d
+}
+
+// WriteDescriptor writes Describe as indented JSON, e.g. for a
+// `go generate`-d descriptor file consumed by cmd/antconfig.
+func (a *AntConfig) WriteDescriptor(w io.Writer) error {
+	if a.cfgRef == nil {
+		return fmt.Errorf("WriteDescriptor requires SetConfig to be called first")
+	}
+	data, err := json.MarshalIndent(a.Describe(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
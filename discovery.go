@@ -0,0 +1,124 @@
+package antconfig
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// Locator finds filename somewhere on disk, returning its path or an error
+// wrapping ErrConfigNotFound. LocateFromWorkingDirUp, LocateFromExeUp and
+// LocateFromModuleRoot are Locators.
+type Locator func(filename string) (string, error)
+
+// SetDiscovery sets the strategies used to auto-discover a config file when no
+// SetConfigPath is given. Each locator is tried in order with every candidate
+// file name (config.jsonc, config.json, then registered formats) and the
+// first match wins. The default is LocateFromWorkingDirUp alone; for example,
+// SetDiscovery(LocateFromWorkingDirUp, LocateFromModuleRoot) also finds the
+// project's config when running `go test` in a subpackage.
+func (a *AntConfig) SetDiscovery(locators ...Locator) error {
+	if err := a.checkUnlocked("SetDiscovery"); err != nil {
+		return err
+	}
+	for _, l := range locators {
+		if l == nil {
+			return fmt.Errorf("SetDiscovery requires non-nil locators")
+		}
+	}
+	a.locators = append([]Locator(nil), locators...)
+	return nil
+}
+
+// discover runs the configured locators over the candidate names and returns
+// the first config file found, or "" when there is none or discovery is
+// disabled (StrictEnvOnly, DisableAutoDiscovery, DisableConfigFile).
+func (a *AntConfig) discover() string {
+	if a.mode == StrictEnvOnly || a.noDiscovery || a.noConfigFile {
+		return ""
+	}
+	locators := a.locators
+	if len(locators) == 0 {
+		locators = []Locator{LocateFromWorkingDirUp}
+		if a.fsys != nil {
+			locators = []Locator{a.locateAtFSRoot}
+		}
+	}
+	for i, locate := range locators {
+		for _, name := range a.configCandidates() {
+			path, err := locate(name)
+			if err == nil && path != "" {
+				a.logDebug("config candidate", slog.Int("locator", i), slog.String("name", name), slog.String("found", path))
+				return path
+			}
+			a.logDebug("config candidate", slog.Int("locator", i), slog.String("name", name), slog.Any("error", err))
+		}
+	}
+	return ""
+}
+
+// LocateFromModuleRoot finds the project root, the nearest directory at or
+// above the working directory containing go.mod or .git, and searches for
+// filename from there upward up to 10 levels. Unlike LocateFromWorkingDirUp it
+// finds a config in the project root from any subpackage, and unlike
+// LocateFromExeUp it does not depend on where the binary was built. Returns
+// the first match or ErrConfigNotFound, also when no project root exists.
+func LocateFromModuleRoot(filename string) (string, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("locating %s: error getting working directory: %w", filename, err)
+	}
+	root, ok := moduleRoot(wd)
+	if !ok {
+		return "", fmt.Errorf("%w: %s (no go.mod or .git above %s)", ErrConfigNotFound, filename, wd)
+	}
+	return searchUpwards(root, filename)
+}
+
+// moduleRoot walks up from dir to the first directory holding go.mod or .git
+// (a directory, or a file in worktrees and submodules).
+func moduleRoot(dir string) (string, bool) {
+	for {
+		for _, marker := range []string{"go.mod", ".git"} {
+			if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+				return dir, true
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// locateAtFSRoot is the default Locator under SetFS: it finds filename at the
+// root of the file system.
+func (a *AntConfig) locateAtFSRoot(filename string) (string, error) {
+	if _, err := a.files().Stat(filename); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrConfigNotFound, filename)
+	}
+	return filename, nil
+}
+
+// discoverDotEnv returns the .env file loaded when no SetEnvPath is given:
+// .env in the working directory, or at the root of the SetFS file system.
+// StrictEnvOnly, DisableAutoDiscovery and DisableDotEnv disable it.
+func (a *AntConfig) discoverDotEnv() (string, bool) {
+	if a.mode == StrictEnvOnly || a.noDiscovery || a.noDotEnv {
+		return "", false
+	}
+	candidate := ".env"
+	if a.fsys == nil {
+		wd, err := os.Getwd()
+		if err != nil {
+			return "", false
+		}
+		candidate = filepath.Join(wd, ".env")
+	}
+	if _, err := a.files().Stat(candidate); err != nil {
+		return "", false
+	}
+	return candidate, true
+}
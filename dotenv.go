@@ -0,0 +1,395 @@
+package antconfig
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+)
+
+// dotEnvPair is a single KEY=value assignment parsed from a .env file.
+type dotEnvPair struct {
+	key string
+	val string
+}
+
+// loadDotEnv parses a .env-like file and records into values each key that is
+// not already explicitly present in the environment seen through lookupOS;
+// later calls override earlier values. This ensures precedence:
+// defaults < .env < OS env < flags. direnv include directives are resolved
+// relative to the including file. Files are read from files. References in
+// values resolve against the environment, then keys defined earlier in the
+// same file, then the values of earlier files.
+func loadDotEnv(files fileSystem, path string, values map[string]string, lookupOS func(string) (string, bool)) error {
+	earlier := func(key string) (string, bool) {
+		v, ok := values[key]
+		return v, ok
+	}
+	pairs, err := parseDotEnvFile(files, path, lookupOS, earlier, map[string]bool{})
+	if err != nil {
+		return err
+	}
+	for _, p := range pairs {
+		if _, exists := lookupOS(p.key); exists {
+			// Do not override explicit env
+			continue
+		}
+		values[p.key] = p.val
+	}
+	return nil
+}
+
+// parseDotEnvFile reads and parses path, following direnv include
+// directives. active guards against include cycles.
+func parseDotEnvFile(files fileSystem, path string, lookup, fallback func(string) (string, bool), active map[string]bool) ([]dotEnvPair, error) {
+	data, err := files.ReadFile(path)
+	if err != nil {
+		// Only return error if the path was set but unreadable; caller controls existence.
+		return nil, err
+	}
+	key := files.key(path)
+	if active[key] {
+		return nil, fmt.Errorf("%s: include cycle", path)
+	}
+	active[key] = true
+	defer delete(active, key)
+
+	include := func(target string, optional bool, fallback func(string) (string, bool)) ([]dotEnvPair, error) {
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		if fi, err := files.Stat(target); err == nil && fi.IsDir() {
+			target = filepath.Join(target, ".envrc")
+		} else if err != nil && optional && errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return parseDotEnvFile(files, target, lookup, fallback, active)
+	}
+	pairs, err := parseDotEnvWith(data, lookup, fallback, include)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return pairs, nil
+}
+
+// parseDotEnv parses .env content into ordered assignments. It supports
+// comments, an optional "export " prefix, single- and double-quoted values
+// that may span multiple lines, inline comments after unquoted values, CRLF
+// line endings, and ${VAR}, ${VAR:-default}, and $VAR expansion in unquoted
+// and double-quoted values. References resolve against lookup first (the
+// effective environment, which wins over .env) and then against keys defined
+// earlier in the same file; unknown references expand to "".
+//
+// For direnv .envrc files, a value starting with a quote follows shell word
+// rules: adjacent quoted and unquoted parts are concatenated, so the value
+// below is "it's". Other shell commands are ignored.
+//
+//	export KEY='it'\''s'
+func parseDotEnv(data []byte, lookup func(string) (string, bool)) ([]dotEnvPair, error) {
+	return parseDotEnvWith(data, lookup, nil, nil)
+}
+
+// parseDotEnvWith is parseDotEnv with support for the direnv directives
+// "dotenv [path]", "dotenv_if_exists [path]", "source_env path" and
+// "source_env_if_exists path": include is called to load the referenced file
+// (default ".env"), and its assignments are spliced in at that point. A nil
+// include ignores directives. References the keys defined so far do not
+// resolve go to fallback, if not nil; include gets the fallback for the
+// included file, which sees the keys defined before the directive.
+func parseDotEnvWith(data []byte, lookup, fallback func(string) (string, bool), include func(path string, optional bool, fallback func(string) (string, bool)) ([]dotEnvPair, error)) ([]dotEnvPair, error) {
+	src := string(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")))
+	defined := map[string]string{}
+	local := func(name string) (string, bool) {
+		if v, ok := defined[name]; ok {
+			return v, true
+		}
+		if fallback != nil {
+			return fallback(name)
+		}
+		return "", false
+	}
+	resolve := func(name string) (string, bool) {
+		if lookup != nil {
+			if v, ok := lookup(name); ok {
+				return v, true
+			}
+		}
+		return local(name)
+	}
+
+	var pairs []dotEnvPair
+	line := 1
+	for i := 0; i < len(src); {
+		// Skip blank space between assignments
+		if c := src[i]; c == ' ' || c == '\t' || c == '\n' {
+			if c == '\n' {
+				line++
+			}
+			i++
+			continue
+		}
+		eol := strings.IndexByte(src[i:], '\n')
+		if eol < 0 {
+			eol = len(src)
+		} else {
+			eol += i
+		}
+		if src[i] == '#' {
+			i = eol
+			continue
+		}
+		rest := src[i:eol]
+		if target, optional, ok := parseIncludeDirective(rest, resolve); ok {
+			if include != nil {
+				inc, err := include(target, optional, local)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", line, err)
+				}
+				for _, p := range inc {
+					defined[p.key] = p.val
+				}
+				pairs = append(pairs, inc...)
+			}
+			i = eol
+			continue
+		}
+		// Optional "export " prefix
+		if strings.HasPrefix(rest, "export ") || strings.HasPrefix(rest, "export\t") {
+			rest = strings.TrimLeft(rest[len("export"):], " \t")
+		}
+		// Split at first '='
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 { // no '=', or empty key
+			i = eol
+			continue
+		}
+		key := strings.TrimSpace(rest[:eq])
+		if key == "" {
+			i = eol
+			continue
+		}
+		valStart := eol - len(rest) + eq + 1
+		for valStart < eol && (src[valStart] == ' ' || src[valStart] == '\t') {
+			valStart++
+		}
+
+		var val string
+		if valStart < eol && (src[valStart] == '"' || src[valStart] == '\'') {
+			var b strings.Builder
+			pos := valStart
+			for pos < len(src) {
+				c := src[pos]
+				if c == '"' || c == '\'' {
+					end := findClosingQuote(src, pos+1, c)
+					if end < 0 {
+						return nil, fmt.Errorf("line %d: unterminated %c-quoted value for %s", line, c, key)
+					}
+					inner := src[pos+1 : end]
+					if c == '"' {
+						b.WriteString(expandValue(inner, resolve, true))
+					} else {
+						b.WriteString(inner)
+					}
+					line += strings.Count(inner, "\n")
+					pos = end + 1
+					continue
+				}
+				if c == ' ' || c == '\t' || c == '\n' {
+					break
+				}
+				// Unquoted part glued to a quoted one, as in 'it'\''s'
+				end := pos
+				for end < len(src) && !strings.ContainsRune(" \t\n\"'", rune(src[end])) {
+					if src[end] == '\\' && end+1 < len(src) {
+						end++
+					}
+					end++
+				}
+				b.WriteString(expandShellWord(src[pos:end], resolve))
+				pos = end
+			}
+			val = b.String()
+			// Ignore whatever follows the value on its line (e.g., a comment)
+			if next := strings.IndexByte(src[pos:], '\n'); next >= 0 {
+				i = pos + next
+			} else {
+				i = len(src)
+			}
+		} else {
+			raw := src[valStart:eol]
+			// For unquoted values, strip trailing inline comment if preceded by whitespace
+			if hash := strings.IndexByte(raw, '#'); hash >= 0 {
+				trimmed := strings.TrimRightFunc(raw[:hash], func(r rune) bool { return r == ' ' || r == '\t' })
+				if len(trimmed) < len(raw[:hash]) {
+					raw = trimmed
+				}
+			}
+			val = expandValue(strings.TrimSpace(raw), resolve, false)
+			i = eol
+		}
+		defined[key] = val
+		pairs = append(pairs, dotEnvPair{key: key, val: val})
+	}
+	return pairs, nil
+}
+
+// parseIncludeDirective recognizes a direnv include line and returns the
+// referenced path (variables expanded, quotes removed) and whether a missing
+// file is acceptable.
+func parseIncludeDirective(line string, resolve func(string) (string, bool)) (path string, optional, ok bool) {
+	cmd, arg, _ := strings.Cut(strings.TrimSpace(line), " ")
+	switch cmd {
+	case "dotenv", "dotenv_if_exists", "source_env", "source_env_if_exists":
+	default:
+		return "", false, false
+	}
+	if hash := strings.Index(arg, " #"); hash >= 0 {
+		arg = arg[:hash]
+	}
+	arg = strings.TrimSpace(arg)
+	if len(arg) >= 2 && (arg[0] == '"' || arg[0] == '\'') && arg[len(arg)-1] == arg[0] {
+		arg = arg[1 : len(arg)-1]
+	}
+	arg = expandValue(arg, resolve, false)
+	if arg == "" {
+		if strings.HasPrefix(cmd, "source_env") {
+			return "", false, false
+		}
+		arg = ".env"
+	}
+	return arg, strings.HasSuffix(cmd, "_if_exists"), true
+}
+
+// findClosingQuote returns the index of the quote closing a value that starts
+// at from, honoring backslash escapes inside double quotes, or -1.
+func findClosingQuote(s string, from int, quote byte) int {
+	for i := from; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// expandValue expands variable references in a .env value. When
+// doubleQuoted is true it also handles the escape sequences \\ \n \r \t \"
+// and \$; otherwise only \$ is treated as an escape (for a literal dollar).
+func expandValue(s string, resolve func(string) (string, bool), doubleQuoted bool) string {
+	if !strings.ContainsAny(s, "$\\") {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' && i+1 < len(s) {
+			next := s[i+1]
+			if !doubleQuoted {
+				if next == '$' {
+					b.WriteByte('$')
+					i++
+					continue
+				}
+				b.WriteByte(c)
+				continue
+			}
+			i++
+			switch next {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				// \" \\ \$ and unknown escapes keep the escaped char literally
+				b.WriteByte(next)
+			}
+			continue
+		}
+		if c == '$' {
+			if name, def, hasDef, n := parseVarRef(s[i+1:]); n > 0 {
+				v, ok := resolve(name)
+				if (!ok || v == "") && hasDef {
+					v = def
+				}
+				b.WriteString(v)
+				i += n
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// expandShellWord expands an unquoted shell word fragment: a backslash
+// escapes the next character and variable references are expanded.
+func expandShellWord(s string, resolve func(string) (string, bool)) string {
+	var b strings.Builder
+	for len(s) > 0 {
+		if s[0] == '\\' && len(s) > 1 {
+			b.WriteByte(s[1])
+			s = s[2:]
+			continue
+		}
+		run := strings.IndexByte(s[1:], '\\') + 1
+		if run == 0 {
+			run = len(s)
+		}
+		b.WriteString(expandValue(s[:run], resolve, false))
+		s = s[run:]
+	}
+	return b.String()
+}
+
+// parseVarRef parses a reference following '$': "{NAME}", "{NAME:-default}"
+// or a bare NAME. It returns the consumed length n (0 when s does not start
+// with a valid reference).
+func parseVarRef(s string) (name, def string, hasDef bool, n int) {
+	if strings.HasPrefix(s, "{") {
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return "", "", false, 0
+		}
+		body := s[1:end]
+		if k, d, ok := strings.Cut(body, ":-"); ok {
+			body, def, hasDef = k, d, true
+		}
+		if !isEnvName(body) {
+			return "", "", false, 0
+		}
+		return body, def, hasDef, end + 1
+	}
+	j := 0
+	for j < len(s) && (s[j] == '_' || isAlpha(s[j]) || (j > 0 && s[j] >= '0' && s[j] <= '9')) {
+		j++
+	}
+	return s[:j], "", false, j
+}
+
+// isEnvName reports whether s is a valid variable name ([A-Za-z_][A-Za-z0-9_]*).
+func isEnvName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '_' || isAlpha(c) || (i > 0 && c >= '0' && c <= '9') {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func isAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,63 @@
+package antconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// SetEmbeddedConfig sets a baked-in baseline config, typically a file bundled
+// with //go:embed. It is layered right after the defaults, so config files,
+// .env, env vars and flags all override it, and its values are reported as
+// LayerEmbedded. format is the extension of the data, "json" or "jsonc" (the
+// default when empty) or one added with RegisterFormat; the converter is
+// looked up at load time, so RegisterFormat may be called later. Migrations
+// and sops decryption apply as for config files; signatures are not checked.
+// data is copied; nil removes the embedded config.
+func (a *AntConfig) SetEmbeddedConfig(data []byte, format string) error {
+	if err := a.checkUnlocked("SetEmbeddedConfig"); err != nil {
+		return err
+	}
+	if data == nil {
+		a.embedded, a.embeddedFormat = nil, ""
+		return nil
+	}
+	ext := strings.ToLower(format)
+	if ext == "" {
+		ext = ".jsonc"
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	a.embedded = append([]byte{}, data...)
+	a.embeddedFormat = ext
+	return nil
+}
+
+// applyEmbeddedConfig layers the SetEmbeddedConfig data into run.target.
+func (a *AntConfig) applyEmbeddedConfig(run *loadRun) ([]*FieldError, error) {
+	var js []byte
+	var err error
+	switch ext := a.embeddedFormat; ext {
+	case ".json", ".jsonc":
+		js, err = jsoncToJSON(a.embedded, a.keepDocument)
+	default:
+		conv, ok := a.formats[ext]
+		if !ok {
+			return nil, fmt.Errorf("error decoding embedded config: no format registered for %s", ext)
+		}
+		js, err = conv(a.embedded)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding embedded config: %w", err)
+	}
+	js, err = a.decryptSOPS(js)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding embedded config: %w", err)
+	}
+	_, errs, err := a.applyConfigJSON(run, js, LayerEmbedded, "embedded config")
+	if err != nil && (a.embeddedFormat == ".json" || a.embeddedFormat == ".jsonc") {
+		err = locateJSONCError(err, a.embedded, reflect.TypeOf(run.target).Elem())
+	}
+	return errs, err
+}
@@ -0,0 +1,85 @@
+package antconfig
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// validateEncodingTags checks the `encoding` tags of struct type t: base64,
+// base64url or hex on []byte or string fields.
+func validateEncodingTags(t reflect.Type) error {
+	fields, err := findFieldsWithTag("encoding", reflect.New(t).Interface())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, f := range fields {
+		if _, err := decodeString(f.tagvalue, ""); err != nil {
+			errs = append(errs, fmt.Errorf("field %s: %w", f.path, err))
+			continue
+		}
+		if !isEncodedField(f.fieldValue.Type()) {
+			errs = append(errs, fmt.Errorf("field %s: encoding:%q needs a []byte or string field, not %s", f.path, f.tagvalue, f.fieldValue.Type()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func isEncodedField(t reflect.Type) bool {
+	return t.Kind() == reflect.String || t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// decodeString decodes s in the named encoding. Base64 is accepted with or
+// without padding; surrounding whitespace is ignored.
+func decodeString(encoding, s string) ([]byte, error) {
+	switch encoding {
+	case "base64":
+		return base64.RawStdEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(s), "="))
+	case "base64url":
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(strings.TrimSpace(s), "="))
+	case "hex":
+		return hex.DecodeString(strings.TrimSpace(s))
+	}
+	return nil, fmt.Errorf("unknown encoding %q (want base64, base64url or hex)", encoding)
+}
+
+// encodeBytes is the inverse of decodeString, with padded base64.
+func encodeBytes(encoding string, b []byte) string {
+	switch encoding {
+	case "base64url":
+		return base64.URLEncoding.EncodeToString(b)
+	case "hex":
+		return hex.EncodeToString(b)
+	}
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// setEncoded decodes s with the `encoding` tag of row and stores the bytes in
+// its []byte or string field, so binary secrets can be supplied as text in
+// every layer.
+func setEncoded(row fieldWithTagValue, encoding, s, parseCtx string) error {
+	b, err := decodeString(encoding, s)
+	if err != nil {
+		return fmt.Errorf("could not decode %s as %s: %w", parseCtx, encoding, err)
+	}
+	v := row.target()
+	if v.Kind() == reflect.String {
+		v.SetString(string(b))
+		return nil
+	}
+	v.Set(reflect.ValueOf(b).Convert(v.Type()))
+	return nil
+}
+
+// encodedValue returns the text form of the []byte or string field value v
+// in the named encoding.
+func encodedValue(encoding string, v reflect.Value) string {
+	if v.Kind() == reflect.String {
+		return encodeBytes(encoding, []byte(v.String()))
+	}
+	return encodeBytes(encoding, v.Bytes())
+}
@@ -0,0 +1,104 @@
+package antconfig
+
+import "os"
+
+// SetEnvironment replaces the process environment with vars for this
+// AntConfig: `env` tags, .env precedence checks, and Preview and
+// GenerateEnvMatrix all read from vars instead of os.LookupEnv, and .env
+// values are kept private instead of being exported with os.Setenv. This
+// makes loads in parallel tests independent of each other and of the real
+// environment. vars is copied; nil restores the process environment.
+func (a *AntConfig) SetEnvironment(vars map[string]string) error {
+	if err := a.checkUnlocked("SetEnvironment"); err != nil {
+		return err
+	}
+	if vars == nil {
+		a.lookupEnv, a.envNames = nil, nil
+		return nil
+	}
+	dup := make(map[string]string, len(vars))
+	for k, v := range vars {
+		dup[k] = v
+	}
+	a.lookupEnv = func(key string) (string, bool) {
+		v, ok := dup[key]
+		return v, ok
+	}
+	a.envNames = func() []string { return mapKeys(dup) }
+	return nil
+}
+
+// SetLookupEnv is like SetEnvironment with a lookup function, e.g. to layer
+// test values over os.LookupEnv or read from a secrets agent. Because the
+// variables cannot be listed, SetCaseInsensitive only matches names that
+// lookup itself resolves. nil restores the process environment.
+func (a *AntConfig) SetLookupEnv(lookup func(string) (string, bool)) error {
+	if err := a.checkUnlocked("SetLookupEnv"); err != nil {
+		return err
+	}
+	a.lookupEnv = lookup
+	a.envNames = nil
+	if lookup != nil {
+		a.envNames = func() []string { return nil }
+	}
+	return nil
+}
+
+// osLookup returns the lookup for the "OS" environment layer.
+func (a *AntConfig) osLookup() func(string) (string, bool) {
+	if a.lookupEnv != nil {
+		return a.lookupEnv
+	}
+	a.exportMu.Lock()
+	none := len(a.exported) == 0
+	a.exportMu.Unlock()
+	if none {
+		return os.LookupEnv
+	}
+	return a.processLookup
+}
+
+// processLookup is os.LookupEnv without the .env values an earlier load
+// exported, which are still .env values: otherwise an edited .env file would
+// never take effect on reload, shadowed by its own previous export.
+func (a *AntConfig) processLookup(key string) (string, bool) {
+	v, ok := os.LookupEnv(key)
+	if ok {
+		a.exportMu.Lock()
+		exported, mine := a.exported[key]
+		a.exportMu.Unlock()
+		if mine && exported == v {
+			return "", false
+		}
+	}
+	return v, ok
+}
+
+// exportDotEnv exports the .env values of a load to the process environment
+// and unsets the ones the previous load exported that no .env file defines
+// any more, unless something else has changed them since.
+func (a *AntConfig) exportDotEnv(dotenv map[string]string) {
+	a.exportMu.Lock()
+	defer a.exportMu.Unlock()
+	for k, v := range a.exported {
+		if _, ok := dotenv[k]; !ok {
+			if cur, set := os.LookupEnv(k); set && cur == v {
+				_ = os.Unsetenv(k)
+			}
+		}
+	}
+	exported := make(map[string]string, len(dotenv))
+	for k, v := range dotenv {
+		_ = os.Setenv(k, v)
+		exported[k] = v
+	}
+	a.exported = exported
+}
+
+// osEnvNames lists the variables of the "OS" environment layer.
+func (a *AntConfig) osEnvNames() []string {
+	if a.envNames != nil {
+		return a.envNames()
+	}
+	return environNames()
+}
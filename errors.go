@@ -0,0 +1,52 @@
+package antconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldError describes a value that could not be applied to a config field.
+type FieldError struct {
+	// Path is the dotted Go field path, e.g. "Database.Port".
+	Path string
+	// Source is the layer that supplied Raw.
+	Source Layer
+	// Raw is the unconverted value as supplied by the layer.
+	Raw string
+	// Err is the underlying conversion error.
+	Err error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// MultiError aggregates every FieldError encountered during a load so callers
+// can report all bad values at once. Use errors.As to retrieve it.
+type MultiError struct {
+	Errors []*FieldError
+}
+
+func (m *MultiError) Error() string {
+	if len(m.Errors) == 1 {
+		return m.Errors[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d configuration errors:", len(m.Errors))
+	for _, e := range m.Errors {
+		b.WriteString("\n\t")
+		b.WriteString(e.Error())
+	}
+	return b.String()
+}
+
+// Unwrap exposes the individual field errors to errors.Is and errors.As.
+func (m *MultiError) Unwrap() []error {
+	errs := make([]error, len(m.Errors))
+	for i, e := range m.Errors {
+		errs[i] = e
+	}
+	return errs
+}
@@ -0,0 +1,74 @@
+package antconfig
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SetFS makes antconfig read config files, .env files (including their
+// direnv includes) and detached signatures from fsys instead of the operating
+// system, e.g. an embed.FS with bundled defaults, a testing/fstest.MapFS, or
+// an os.DirFS rooted at a read-only mount. Paths given to SetConfigPath,
+// SetEnvPath and AddEnvPath are then names in fsys; a leading "/" or "./" is
+// ignored. Call SetFS before those setters, since they check that the file
+// exists.
+//
+// Without SetConfigPath, config.jsonc, config.json and the registered format
+// names are looked up at the root of fsys, as is .env without SetEnvPath.
+// Locators passed to SetDiscovery still run, and the path they return is
+// opened in fsys. nil restores the operating system.
+func (a *AntConfig) SetFS(fsys fs.FS) error {
+	if err := a.checkUnlocked("SetFS"); err != nil {
+		return err
+	}
+	a.fsys = fsys
+	return nil
+}
+
+// fileSystem reads files from fsys, or from the operating system when fsys
+// is nil.
+type fileSystem struct {
+	fsys fs.FS
+}
+
+// files returns the file system config and .env files are read from.
+func (a *AntConfig) files() fileSystem {
+	return fileSystem{fsys: a.fsys}
+}
+
+func (f fileSystem) ReadFile(name string) ([]byte, error) {
+	if f.fsys == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(f.fsys, fsPath(name))
+}
+
+func (f fileSystem) Stat(name string) (fs.FileInfo, error) {
+	if f.fsys == nil {
+		return os.Stat(name)
+	}
+	return fs.Stat(f.fsys, fsPath(name))
+}
+
+// key returns a name identifying the file at name, used to detect include
+// cycles between differently spelled paths.
+func (f fileSystem) key(name string) string {
+	if f.fsys == nil {
+		abs, _ := filepath.Abs(name)
+		return abs
+	}
+	return fsPath(name)
+}
+
+// fsPath converts an OS-style path to a valid fs.FS name: slash separated,
+// cleaned, and without a leading "/".
+func fsPath(name string) string {
+	name = strings.TrimLeft(filepath.ToSlash(name), "/")
+	if name == "" {
+		return "."
+	}
+	return path.Clean(name)
+}
@@ -0,0 +1,94 @@
+package antconfig
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// SeedFlagDefaults sets the default of every config flag bound to fs
+// (BindConfigFlags) to the value the field gets without command-line flags:
+// defaults, config file, .env, environment and sources are loaded into a
+// scratch value, leaving the registered struct untouched. -h then shows the
+// effective defaults instead of empty ones, for fs.PrintDefaults as well as
+// WriteFlagHelp. Fields that fail to load or validate keep their original
+// default, so a missing required value does not prevent printing the help.
+// Call it after BindConfigFlags and before fs.Parse.
+func (a *AntConfig) SeedFlagDefaults(fs *flag.FlagSet) error {
+	if a.cfgRef == nil {
+		return fmt.Errorf("SeedFlagDefaults requires SetConfig to be called first")
+	}
+	run := &loadRun{
+		target:    reflect.New(reflect.TypeOf(a.cfgRef).Elem()).Interface(),
+		lookupOS:  a.osLookup(),
+		skipFlags: true,
+	}
+	var me *MultiError
+	if err := a.load(run); err != nil && !errors.As(err, &me) {
+		return err
+	}
+	failed := map[string]bool{}
+	if me != nil {
+		for _, fe := range me.Errors {
+			failed[fe.Path] = true
+		}
+	}
+	a.flagDefaults = map[string]string{}
+	for _, f := range run.plan.withTag("flag") {
+		if _, ok := run.provenance[f.path]; !ok || failed[f.path] {
+			continue
+		}
+		cli := a.flagPrefix + f.tagvalue
+		fl := fs.Lookup(cli)
+		if fl == nil {
+			continue
+		}
+		text, ok := flagText(f, f.current())
+		if !ok {
+			continue
+		}
+		// Also set the value, so fs reads the same as the seeded default
+		// when the flag is not given
+		if err := fl.Value.Set(text); err != nil {
+			continue
+		}
+		fl.DefValue = text
+		a.flagDefaults[f.path] = text
+	}
+	return nil
+}
+
+// flagText formats the field value v as a command-line flag value, the
+// inverse of the flag layer's parsing. ok is false for a nil pointer.
+func flagText(f fieldWithTagValue, v reflect.Value) (text string, ok bool) {
+	if v.Kind() == reflect.Ptr && v.Type().Elem().Kind() != reflect.Struct {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	if enc := f.tag.Get("encoding"); enc != "" {
+		return encodedValue(enc, v), true
+	}
+	if layout := f.tags["layout"]; layout != "" && v.Type() == reflect.TypeOf(time.Time{}) {
+		return v.Interface().(time.Time).Format(layout), true
+	}
+	switch x := v.Interface().(type) {
+	case time.Duration:
+		return x.String(), true
+	case encoding.TextMarshaler:
+		b, err := x.MarshalText()
+		return string(b), err == nil
+	case fmt.Stringer:
+		return x.String(), true
+	}
+	if v.Kind() == reflect.Slice {
+		b, err := json.Marshal(v.Interface())
+		return string(b), err == nil
+	}
+	return fmt.Sprint(v.Interface()), true
+}
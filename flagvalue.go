@@ -0,0 +1,128 @@
+package antconfig
+
+import (
+	"encoding"
+	"flag"
+	"reflect"
+	"time"
+)
+
+var (
+	flagValueType       = reflect.TypeOf((*flag.Value)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// registerFlag defines the flag for a field of type t on fs, so that parsing
+// and error reporting happen during fs.Parse. Types with a registered parser
+// use flag.Func; types whose pointer implements flag.Value get a fresh
+// instance of that type; encoding.TextUnmarshaler types use flag.TextVar;
+// durations, bools and everything else map to the corresponding flag package
+// helpers, with strings converted on load. Pointers to scalars such as *int
+// or *bool are registered as their element type.
+//
+// For flag.Func flags, whose String method is empty, the returned pointer
+// receives the raw value given on the command line; it is nil otherwise.
+func registerFlag(fs *flag.FlagSet, t reflect.Type, cli, usage string, parsers typeParsers) (raw *string) {
+	ptr := reflect.PointerTo(t)
+	if p, ok := lookupParser(t, parsers); ok {
+		return funcFlag(fs, cli, usage, func(s string) error {
+			_, err := p(s)
+			return err
+		})
+	}
+	if isPointerScalar(t) && !ptr.Implements(flagValueType) && !ptr.Implements(textUnmarshalerType) {
+		return registerFlag(fs, t.Elem(), cli, usage, parsers)
+	}
+	switch {
+	case ptr.Implements(flagValueType):
+		fs.Var(reflect.New(t).Interface().(flag.Value), cli, usage)
+	case ptr.Implements(textUnmarshalerType):
+		target := reflect.New(t)
+		if def, ok := reflect.Zero(t).Interface().(encoding.TextMarshaler); ok {
+			fs.TextVar(target.Interface().(encoding.TextUnmarshaler), cli, def, usage)
+			return nil
+		}
+		u := target.Interface().(encoding.TextUnmarshaler)
+		return funcFlag(fs, cli, usage, func(s string) error { return u.UnmarshalText([]byte(s)) })
+	case t == durationType:
+		fs.Duration(cli, 0, usage)
+	case t.Kind() == reflect.Bool:
+		fs.Bool(cli, false, usage)
+	default:
+		fs.String(cli, "", usage)
+	}
+	return nil
+}
+
+// isPointerScalar reports whether t is a pointer to a non-struct type, such as
+// *int, *string or *bool.
+func isPointerScalar(t reflect.Type) bool {
+	return t.Kind() == reflect.Ptr && t.Elem().Kind() != reflect.Struct && t.Elem().Kind() != reflect.Ptr
+}
+
+// isBoolField reports whether fields of type t are boolean flags that can be
+// given without a value.
+func isBoolField(t reflect.Type) bool {
+	if isPointerScalar(t) {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Bool
+}
+
+// funcFlag registers a flag.Func that validates with check and records the
+// raw value, returning the record.
+func funcFlag(fs *flag.FlagSet, cli, usage string, check func(string) error) *string {
+	raw := new(string)
+	fs.Func(cli, usage, func(s string) error {
+		if err := check(s); err != nil {
+			return err
+		}
+		*raw = s
+		return nil
+	})
+	return raw
+}
+
+// assignFlagValue copies a parsed flag.Value into field without going through
+// its string form. It reports handled=false when the value cannot be applied
+// natively and the caller should fall back to string conversion.
+func assignFlagValue(field reflect.Value, v flag.Value) (handled bool, err error) {
+	// A flag given for a pointer scalar field allocates it, so a zero value
+	// given explicitly stays distinguishable from an unset flag
+	if isPointerScalar(field.Type()) {
+		elem := reflect.New(field.Type().Elem())
+		if handled, err = assignFlagValue(elem.Elem(), v); handled && err == nil {
+			field.Set(elem)
+		}
+		return handled, err
+	}
+	rv := reflect.ValueOf(v)
+	// Values registered via fs.Var(&x, ...) where x has the field's type
+	if rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Type().Elem() == field.Type() {
+		field.Set(rv.Elem())
+		return true, nil
+	}
+	// Standard flag types (and custom getters) expose their typed value;
+	// flag.TextVar exposes a pointer to it
+	if g, ok := v.(flag.Getter); ok {
+		if got := g.Get(); got != nil {
+			gv := reflect.ValueOf(got)
+			if gv.Type().AssignableTo(field.Type()) {
+				field.Set(gv)
+				return true, nil
+			}
+			if gv.Kind() == reflect.Ptr && !gv.IsNil() && gv.Type().Elem() == field.Type() {
+				field.Set(gv.Elem())
+				return true, nil
+			}
+		}
+	}
+	// The field type parses itself, e.g. a custom enum bound as a string flag
+	if field.CanAddr() {
+		if target, ok := field.Addr().Interface().(flag.Value); ok {
+			return true, target.Set(v.String())
+		}
+	}
+	return false, nil
+}
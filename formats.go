@@ -0,0 +1,149 @@
+package antconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// RegisterFormat adds support for config files with extension ext (e.g.
+// ".hcl") by converting their contents to JSON with toJSON, after which they
+// are layered exactly like JSON/JSONC files. Formats with third-party
+// decoders plug in here so the core stays dependency-free. Registered
+// extensions are also tried during auto-discovery as "config"+ext, after
+// config.jsonc and config.json. JSON and JSONC cannot be overridden.
+func (a *AntConfig) RegisterFormat(ext string, toJSON func([]byte) ([]byte, error)) error {
+	if err := a.checkUnlocked("RegisterFormat"); err != nil {
+		return err
+	}
+	ext = strings.ToLower(ext)
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	if toJSON == nil || ext == "." {
+		return fmt.Errorf("RegisterFormat requires an extension and a converter")
+	}
+	if ext == ".json" || ext == ".jsonc" {
+		return fmt.Errorf("RegisterFormat cannot override built-in format %s", ext)
+	}
+	if a.formats == nil {
+		a.formats = map[string]func([]byte) ([]byte, error){}
+	}
+	a.formats[ext] = toJSON
+	return nil
+}
+
+// prepareConfig turns raw config file contents into the JSON document that
+// is layered onto the struct: signature verification, template rendering,
+// format conversion, then sops decryption.
+func (a *AntConfig) prepareConfig(path string, data []byte) ([]byte, error) {
+	if err := a.verifySignature(path, data); err != nil {
+		return nil, err
+	}
+	if a.templating {
+		var err error
+		if data, err = a.renderTemplate(path, data); err != nil {
+			return nil, err
+		}
+	}
+	js, err := a.configToJSON(path, data)
+	if err != nil {
+		return nil, err
+	}
+	return a.decryptSOPS(js)
+}
+
+// configToJSON converts a config file to JSON based on its extension; unknown
+// extensions are treated as JSONC, possibly holding several documents (see
+// jsoncToJSON).
+func (a *AntConfig) configToJSON(path string, data []byte) ([]byte, error) {
+	if conv, ok := a.formats[strings.ToLower(filepath.Ext(path))]; ok {
+		return conv(data)
+	}
+	return jsoncToJSON(data, a.keepDocument)
+}
+
+// configCandidates lists the file names tried by auto-discovery, in order.
+func (a *AntConfig) configCandidates() []string {
+	names := []string{"config.jsonc", "config.json"}
+	exts := make([]string, 0, len(a.formats))
+	for ext := range a.formats {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	for _, ext := range exts {
+		names = append(names, "config"+ext)
+	}
+	return names
+}
+
+// applyConfigFile decodes a config file and merges it into run.target (see
+// applyConfigJSON), returning the generic document for key-usage checks. what
+// names the file in error messages.
+func (a *AntConfig) applyConfigFile(run *loadRun, path string, data []byte, what string) (map[string]any, []*FieldError, error) {
+	js, err := a.prepareConfig(path, data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error decoding %s %s: %w", what, path, err)
+	}
+	doc, errs, err := a.applyConfigJSON(run, js, LayerFile, what+" "+path)
+	if _, converted := a.formats[strings.ToLower(filepath.Ext(path))]; err != nil && !converted {
+		err = locateJSONCError(err, data, reflect.TypeOf(run.target).Elem())
+	}
+	return doc, errs, err
+}
+
+// applyConfigJSON migrates a config document (RegisterMigration) and merges
+// it into run.target, attributing its values to layer, and returns the
+// generic document. what names the document in error messages. Values of
+// fields that encoding/json cannot decode directly (see extractDeferred) are
+// converted separately and reported as FieldErrors.
+func (a *AntConfig) applyConfigJSON(run *loadRun, js []byte, layer Layer, what string) (map[string]any, []*FieldError, error) {
+	js, err := a.applyConditions(js)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error in %s: %w", what, err)
+	}
+	js, err = a.migrateConfig(js)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error migrating %s: %w", what, err)
+	}
+	js, uses, err := applyFileAliases(js, run.plan)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing %s: %w", what, err)
+	}
+	for _, u := range uses {
+		u.Source = layer
+		run.aliasUses = append(run.aliasUses, u)
+		run.warn(WarningDeprecated, u.Path, layer, fmt.Sprintf("config key %s is deprecated; use %s", u.Old, u.New))
+	}
+	js, refused, err := refuseFileKeys(js, run, layer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing %s: %w", what, err)
+	}
+	rest, deferred, err := extractDeferred(js, run.plan)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error parsing %s: %w", what, err)
+	}
+	if rest, err = dropIgnoredKeys(rest, reflect.TypeOf(run.target).Elem()); err != nil {
+		return nil, nil, fmt.Errorf("error parsing %s: %w", what, err)
+	}
+	if err := json.Unmarshal(rest, run.target); err != nil {
+		return nil, nil, fmt.Errorf("error parsing %s: %w", what, err)
+	}
+	var doc map[string]any
+	_ = json.Unmarshal(js, &doc)
+	t := reflect.TypeOf(run.target).Elem()
+	markFileProvenance(doc, t, "", layer, run.provenance)
+	warnUnknownKeys(run, layer, withoutSourcesKey(a.unversionedDoc(doc, t), t), t, "", nil)
+
+	errs := refused
+	for _, d := range deferred {
+		ctx := fmt.Sprintf("config key %s (%q)", strings.Join(d.row.jsonPath, "."), d.raw)
+		if err := setRowFromString(d.row, d.raw, ctx, ctx, false, run.parsers); err != nil {
+			errs = append(errs, &FieldError{Path: d.row.path, Source: layer, Raw: d.raw, Err: err})
+		}
+	}
+	return doc, errs, nil
+}
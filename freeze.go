@@ -0,0 +1,143 @@
+package antconfig
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ErrConfigMutated is returned by VerifyFrozen when the registered config was
+// modified after Freeze by anything other than a load.
+var ErrConfigMutated = errors.New("frozen config was modified")
+
+// frozenState is the checksum of the registered config taken by Freeze and
+// renewed by every successful load. Debug builds also keep a copy to name the
+// fields that changed.
+type frozenState struct {
+	sum  [sha256.Size]byte
+	copy reflect.Value
+}
+
+// Freeze marks the registered config as read-only for the rest of the
+// program: from now on only WriteConfigValues (and reloads built on it) may
+// change it. Freeze takes a checksum of every field antconfig manages, which
+// VerifyFrozen compares against, and which each successful load renews.
+//
+// In binaries built with -tags antconfig_debug, WriteConfigValues, Lookup,
+// IsSet, WasSet, Hash and RedactedConfig also verify the checksum and panic
+// with the paths of the modified fields, to catch code paths that mutate
+// shared config at runtime. Other builds only pay for the checksum when
+// VerifyFrozen is called.
+func (a *AntConfig) Freeze() error {
+	if a.cfgRef == nil {
+		return fmt.Errorf("Freeze requires SetConfig to be called first")
+	}
+	a.frozen = a.freezeState()
+	return nil
+}
+
+// Frozen reports whether Freeze is in effect.
+func (a *AntConfig) Frozen() bool { return a.frozen != nil }
+
+// VerifyFrozen reports an error wrapping ErrConfigMutated when the registered
+// config no longer matches the checksum taken by Freeze or the last load. It
+// returns nil when the config is not frozen.
+func (a *AntConfig) VerifyFrozen() error {
+	if a.frozen == nil {
+		return nil
+	}
+	if configChecksum(reflect.ValueOf(a.cfgRef).Elem()) == a.frozen.sum {
+		return nil
+	}
+	if a.frozen.copy.IsValid() {
+		var changes []Change
+		diffValues(a.frozen.copy, reflect.ValueOf(a.cfgRef).Elem(), "", nil, &changes)
+		if len(changes) > 0 {
+			paths := make([]string, len(changes))
+			for i, c := range changes {
+				paths[i] = c.Path
+			}
+			return fmt.Errorf("%w: %s", ErrConfigMutated, strings.Join(paths, ", "))
+		}
+	}
+	return ErrConfigMutated
+}
+
+// checkFrozen panics in debug builds when the frozen config was modified.
+func (a *AntConfig) checkFrozen() {
+	if !debugBuild {
+		return
+	}
+	if err := a.VerifyFrozen(); err != nil {
+		panic("antconfig: " + err.Error())
+	}
+}
+
+func (a *AntConfig) freezeState() *frozenState {
+	v := reflect.ValueOf(a.cfgRef).Elem()
+	s := &frozenState{sum: configChecksum(v)}
+	if debugBuild {
+		s.copy = deepCopy(v)
+	}
+	return s
+}
+
+// configChecksum hashes the exported fields of struct v, leaving out
+// `antconfig:"-"` fields, whose runtime state may change freely.
+func configChecksum(v reflect.Value) [sha256.Size]byte {
+	h := sha256.New()
+	writeChecksum(h, v)
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
+
+func writeChecksum(w io.Writer, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			io.WriteString(w, "nil;")
+			return
+		}
+		io.WriteString(w, "&")
+		writeChecksum(w, v.Elem())
+	case reflect.Struct:
+		t := v.Type()
+		if _, ok := nestedStruct(t); !ok {
+			// Opaque values such as time.Time hash by their printed form
+			fmt.Fprintf(w, "%v;", v)
+			return
+		}
+		io.WriteString(w, "{")
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() && !isIgnored(f) {
+				io.WriteString(w, f.Name+":")
+				writeChecksum(w, v.Field(i))
+			}
+		}
+		io.WriteString(w, "}")
+	case reflect.Slice, reflect.Array:
+		fmt.Fprintf(w, "[%d:", v.Len())
+		for i := 0; i < v.Len(); i++ {
+			writeChecksum(w, v.Index(i))
+		}
+		io.WriteString(w, "]")
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+		fmt.Fprintf(w, "map[%d:", v.Len())
+		for _, k := range keys {
+			fmt.Fprintf(w, "%v=", k)
+			writeChecksum(w, v.MapIndex(k))
+		}
+		io.WriteString(w, "]")
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+		fmt.Fprintf(w, "%x;", v.Pointer())
+	default:
+		fmt.Fprintf(w, "%q;", fmt.Sprint(v))
+	}
+}
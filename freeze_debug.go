@@ -0,0 +1,6 @@
+//go:build antconfig_debug
+
+package antconfig
+
+// debugBuild enables the Freeze checks on every config access.
+const debugBuild = true
@@ -0,0 +1,7 @@
+//go:build !antconfig_debug
+
+package antconfig
+
+// debugBuild enables the Freeze checks on every config access; build with
+// -tags antconfig_debug to turn it on.
+const debugBuild = false
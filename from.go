@@ -0,0 +1,157 @@
+package antconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrSourceNotAllowed is reported when a layer supplies a value for a field
+// whose `from` tag excludes that layer.
+var ErrSourceNotAllowed = errors.New("value from a disallowed source")
+
+// layerRule is a parsed `from` tag. The tag either lists the layers a field
+// may be set from (`from:"env,flag"`) or excludes built-in layers with a "no"
+// prefix (`from:"nofile,nodotenv"`). Names are Layer values, so registered
+// sources are listed by name; defaults from the `default` tag are always
+// allowed.
+type layerRule struct {
+	tag    string
+	only   map[Layer]bool
+	except map[Layer]bool
+}
+
+// parseFromTag parses the `from` tag of the field at path.
+func parseFromTag(path, tag string) (layerRule, error) {
+	r := layerRule{tag: tag}
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.TrimSpace(name)
+		if denied, neg := strings.CutPrefix(name, "no"); neg && isBuiltinLayer(Layer(denied)) {
+			if r.except == nil {
+				r.except = map[Layer]bool{}
+			}
+			r.except[Layer(denied)] = true
+			continue
+		}
+		if name == "" {
+			return layerRule{}, fmt.Errorf("field %s: empty layer name in from:%q", path, tag)
+		}
+		if r.only == nil {
+			r.only = map[Layer]bool{}
+		}
+		r.only[Layer(name)] = true
+	}
+	if r.only != nil && r.except != nil {
+		return layerRule{}, fmt.Errorf("field %s: from:%q mixes allowed and excluded layers", path, tag)
+	}
+	return r, nil
+}
+
+// isBuiltinLayer reports whether l names one of the built-in layers.
+func isBuiltinLayer(l Layer) bool {
+	switch l {
+	case LayerDefault, LayerEmbedded, LayerFile, LayerDotEnv, LayerEnv, LayerFlag, LayerMemory:
+		return true
+	}
+	return false
+}
+
+func (r layerRule) allows(l Layer) bool {
+	if l == LayerDefault {
+		return true
+	}
+	if r.only != nil {
+		return r.only[l]
+	}
+	return !r.except[l]
+}
+
+// validateFromTags checks the `from` tags of struct type t when the config is
+// registered, including contradictions such as an `env` tag on a field that
+// refuses both env and .env values.
+func validateFromTags(t reflect.Type) error {
+	fields, err := findFieldsWithTag("from", reflect.New(t).Interface())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, f := range fields {
+		r, err := parseFromTag(f.path, f.tagvalue)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if f.tags["env"] != "" && !r.allows(LayerEnv) && !r.allows(LayerDotEnv) {
+			errs = append(errs, fmt.Errorf("field %s: env tag %q can never apply with from:%q", f.path, f.tags["env"], f.tagvalue))
+		}
+		if f.tags["flag"] != "" && !r.allows(LayerFlag) {
+			errs = append(errs, fmt.Errorf("field %s: flag tag %q can never apply with from:%q", f.path, f.tags["flag"], f.tagvalue))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// fromRules collects the parsed `from` tags of plan by field path, or nil
+// when no field is restricted.
+func fromRules(plan *fieldPlan) (map[string]layerRule, error) {
+	var rules map[string]layerRule
+	for _, f := range plan.withTag("from") {
+		r, err := parseFromTag(f.path, f.tagvalue)
+		if err != nil {
+			return nil, err
+		}
+		if rules == nil {
+			rules = map[string]layerRule{}
+		}
+		rules[f.path] = r
+	}
+	return rules, nil
+}
+
+// refuse returns the error for layer setting the field at path when the
+// field's `from` tag excludes layer, or nil. The refused value itself is not
+// included, as restricted fields are typically secrets.
+func (run *loadRun) refuse(path string, layer Layer) *FieldError {
+	r, ok := run.from[path]
+	if !ok || r.allows(layer) {
+		return nil
+	}
+	return &FieldError{Path: path, Source: layer, Err: fmt.Errorf("%w: %s (from:%q)", ErrSourceNotAllowed, layer, r.tag)}
+}
+
+// refuseFileKeys removes from the JSON document of layer the values of fields
+// that may not be set from it, returning the remaining document and an error
+// for each removed value.
+func refuseFileKeys(js []byte, run *loadRun, layer Layer) ([]byte, []*FieldError, error) {
+	if run.from == nil {
+		return js, nil, nil
+	}
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		// Let the regular decoding report malformed documents
+		return js, nil, nil
+	}
+	var errs []*FieldError
+	for _, f := range run.plan.fields {
+		if f.jsonPath == nil {
+			continue
+		}
+		fe := run.refuse(f.path, layer)
+		if fe == nil {
+			continue
+		}
+		if _, ok := popJSONValue(doc, f.jsonPath); ok {
+			errs = append(errs, fe)
+		}
+	}
+	if len(errs) == 0 {
+		return js, nil, nil
+	}
+	rest, err := json.Marshal(doc)
+	return rest, errs, err
+}
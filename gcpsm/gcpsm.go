@@ -0,0 +1,150 @@
+// Package gcpsm resolves gcpsm:// value URIs from Google Cloud Secret
+// Manager, so any config layer can reference a secret instead of holding it:
+//
+//	ac.RegisterResolver("gcpsm", gcpsm.New())
+//	// DB_PASSWORD=gcpsm://projects/my-project/secrets/db-password
+//	// or pin a version: gcpsm://projects/my-project/secrets/db-password/versions/3
+//
+// Without a version the latest one is read. Requests use the Secret Manager
+// REST API with the standard library only; by default the access token comes
+// from the metadata server available on GCE, GKE, Cloud Run and Cloud
+// Functions.
+package gcpsm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Scheme is the URI scheme handled by Resolver.
+const Scheme = "gcpsm"
+
+const (
+	defaultEndpoint = "https://secretmanager.googleapis.com"
+	metadataToken   = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// Resolver reads secret versions from Secret Manager. It implements
+// antconfig.Resolver.
+type Resolver struct {
+	// Client sends the requests; nil means http.DefaultClient.
+	Client *http.Client
+	// Token returns an OAuth2 access token with the cloud-platform scope;
+	// nil fetches one from the metadata server.
+	Token func(ctx context.Context) (string, error)
+	// Endpoint replaces https://secretmanager.googleapis.com, e.g. for a
+	// regional or private endpoint.
+	Endpoint string
+}
+
+// New returns a Resolver using the metadata server's credentials.
+func New() *Resolver { return &Resolver{} }
+
+// Resolve returns the payload of the secret version uri refers to.
+func (r *Resolver) Resolve(ctx context.Context, uri string) (string, error) {
+	name, err := versionName(uri)
+	if err != nil {
+		return "", err
+	}
+	token, err := r.token(ctx)
+	if err != nil {
+		return "", fmt.Errorf("gcpsm: access token: %w", err)
+	}
+	endpoint := r.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/v1/"+name+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var body struct {
+		Payload struct {
+			Data       string `json:"data"`
+			DataCrc32c string `json:"dataCrc32c"`
+		} `json:"payload"`
+	}
+	if err := r.getJSON(req, &body); err != nil {
+		return "", fmt.Errorf("gcpsm: %s: %w", name, err)
+	}
+	data, err := base64.StdEncoding.DecodeString(body.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("gcpsm: %s: payload: %w", name, err)
+	}
+	if sum := body.Payload.DataCrc32c; sum != "" {
+		want, err := strconv.ParseUint(sum, 10, 32)
+		if err != nil || crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)) != uint32(want) {
+			return "", fmt.Errorf("gcpsm: %s: payload checksum mismatch", name)
+		}
+	}
+	return string(data), nil
+}
+
+// versionName turns gcpsm://projects/p/secrets/s[/versions/v] into the
+// resource name of the secret version.
+func versionName(uri string) (string, error) {
+	rest, ok := strings.CutPrefix(uri, Scheme+"://")
+	if !ok {
+		return "", fmt.Errorf("gcpsm: %q is not a %s:// URI", uri, Scheme)
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	switch {
+	case len(parts) == 4 && parts[0] == "projects" && parts[2] == "secrets":
+		parts = append(parts, "versions", "latest")
+	case len(parts) == 6 && parts[0] == "projects" && parts[2] == "secrets" && parts[4] == "versions":
+	default:
+		return "", fmt.Errorf("gcpsm: %q is not of the form %s://projects/PROJECT/secrets/NAME[/versions/VERSION]", uri, Scheme)
+	}
+	for _, p := range parts {
+		if p == "" {
+			return "", fmt.Errorf("gcpsm: %q has an empty path segment", uri)
+		}
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+func (r *Resolver) token(ctx context.Context) (string, error) {
+	if r.Token != nil {
+		return r.Token(ctx)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataToken, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var body struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := r.getJSON(req, &body); err != nil {
+		return "", err
+	}
+	return body.AccessToken, nil
+}
+
+func (r *Resolver) getJSON(req *http.Request, v any) error {
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,61 @@
+package gcpsm
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/robfordww/antconfig"
+)
+
+func TestResolver(t *testing.T) {
+	payload := []byte("hunter2")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, "unauthenticated", http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/projects/p/secrets/db/versions/latest:access":
+			sum := crc32.Checksum(payload, crc32.MakeTable(crc32.Castagnoli))
+			fmt.Fprintf(w, `{"payload": {"data": %q, "dataCrc32c": "%d"}}`, base64.StdEncoding.EncodeToString(payload), sum)
+		case "/v1/projects/p/secrets/db/versions/2:access":
+			fmt.Fprintf(w, `{"payload": {"data": %q, "dataCrc32c": "1"}}`, base64.StdEncoding.EncodeToString(payload))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	type Config struct {
+		Password string `json:"password" env:"GCPSM_PASSWORD"`
+	}
+	var cfg Config
+	ac := antconfig.New().MustSetConfig(&cfg)
+	ac.SetFlagArgs([]string{"--"})
+	r := &Resolver{Endpoint: srv.URL, Token: func(context.Context) (string, error) { return "tok", nil }}
+	if err := ac.RegisterResolver(Scheme, r); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GCPSM_PASSWORD", "gcpsm://projects/p/secrets/db")
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "hunter2" {
+		t.Fatalf("Password = %q", cfg.Password)
+	}
+
+	for _, uri := range []string{"gcpsm://projects/p/secrets/db/versions/2", "gcpsm://projects/p/secrets/gone", "gcpsm://p/db"} {
+		t.Setenv("GCPSM_PASSWORD", uri)
+		err := ac.WriteConfigValues()
+		var me *antconfig.MultiError
+		if !errors.As(err, &me) || me.Errors[0].Path != "Password" || me.Errors[0].Source != antconfig.LayerEnv {
+			t.Errorf("%s: %v", uri, err)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+module github.com/robfordww/antconfig/hclformat
+
+go 1.24.3
+
+require (
+	github.com/hashicorp/hcl/v2 v2.24.0
+	github.com/robfordww/antconfig v0.0.0-00010101000000-000000000000
+	github.com/zclconf/go-cty v1.17.0
+)
+
+require (
+	github.com/agext/levenshtein v1.2.1 // indirect
+	github.com/apparentlymart/go-textseg/v15 v15.0.0 // indirect
+	github.com/mitchellh/go-wordwrap v1.0.1 // indirect
+	golang.org/x/mod v0.17.0 // indirect
+	golang.org/x/sync v0.14.0 // indirect
+	golang.org/x/text v0.25.0 // indirect
+	golang.org/x/tools v0.21.1-0.20240508182429-e35e4ccd0d2d // indirect
+)
+
+replace github.com/robfordww/antconfig => ../
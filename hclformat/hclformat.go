@@ -0,0 +1,122 @@
+// Package hclformat reads HCL v2 native syntax config files, so tools that
+// sit next to Terraform can describe their config in HCL while antconfig
+// still layers .env, env and flags on top:
+//
+//	ac := antconfig.New()
+//	if err := hclformat.Register(ac); err != nil { ... }
+//	_ = ac.SetConfigPath("infra.hcl")
+//
+// It is a separate module so that the core antconfig module keeps no
+// dependencies. Attributes become object keys and blocks become nested
+// objects, one level per label, the way HCL's JSON syntax maps them:
+//
+//	listen = ":8080"
+//	database "primary" {
+//	  host = "db.internal"
+//	}
+//
+// converts to {"listen": ":8080", "database": {"primary": {"host": "db.internal"}}}.
+// Unlabeled blocks of a type that appears more than once become an array of
+// objects. Expressions are evaluated without variables or functions, so
+// literals, arithmetic, templates without interpolation and heredocs work.
+package hclformat
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+
+	"github.com/robfordww/antconfig"
+)
+
+// Ext is the config file extension Register handles; auto-discovery then
+// also tries config.hcl.
+const Ext = ".hcl"
+
+// Register makes ac read config files with the .hcl extension.
+func Register(ac *antconfig.AntConfig) error {
+	return ac.RegisterFormat(Ext, ToJSON)
+}
+
+// ToJSON converts an HCL config document to JSON; it has the signature of the
+// converter taken by antconfig.AntConfig.RegisterFormat. Errors carry the
+// line and column of the problem.
+func ToJSON(src []byte) ([]byte, error) {
+	file, diags := hclsyntax.ParseConfig(src, "config"+Ext, hcl.InitialPos)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	doc, err := bodyToMap(file.Body.(*hclsyntax.Body))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// bodyToMap converts the attributes and blocks of body to a JSON object.
+func bodyToMap(body *hclsyntax.Body) (map[string]any, error) {
+	doc := make(map[string]any, len(body.Attributes)+len(body.Blocks))
+	for name, attr := range body.Attributes {
+		val, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		data, err := ctyjson.SimpleJSONValue{Value: val}.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", attr.SrcRange, name, err)
+		}
+		doc[name] = json.RawMessage(data)
+	}
+	// Unlabeled block types seen more than once become arrays
+	count, labeled := map[string]int{}, map[string]bool{}
+	for _, b := range body.Blocks {
+		if len(b.Labels) == 0 {
+			count[b.Type]++
+		} else {
+			labeled[b.Type] = true
+		}
+	}
+	for _, b := range body.Blocks {
+		content, err := bodyToMap(b.Body)
+		if err != nil {
+			return nil, err
+		}
+		if _, isAttr := body.Attributes[b.Type]; isAttr {
+			return nil, fmt.Errorf("%s: %s is both an attribute and a block", b.DefRange(), b.Type)
+		}
+		if count[b.Type] > 0 && labeled[b.Type] {
+			return nil, fmt.Errorf("%s: %s mixes labeled and unlabeled blocks", b.DefRange(), b.Type)
+		}
+		if len(b.Labels) == 0 {
+			if count[b.Type] > 1 {
+				list, _ := doc[b.Type].([]any)
+				doc[b.Type] = append(list, content)
+			} else {
+				doc[b.Type] = content
+			}
+			continue
+		}
+		// One nested object per label: type "a" "b" {} -> {"type": {"a": {"b": {}}}}
+		parent := doc
+		for _, key := range append([]string{b.Type}, b.Labels[:len(b.Labels)-1]...) {
+			next, ok := parent[key].(map[string]any)
+			if !ok {
+				if _, taken := parent[key]; taken {
+					return nil, fmt.Errorf("%s: %s mixes labeled and unlabeled blocks", b.DefRange(), b.Type)
+				}
+				next = map[string]any{}
+				parent[key] = next
+			}
+			parent = next
+		}
+		last := b.Labels[len(b.Labels)-1]
+		if _, dup := parent[last]; dup {
+			return nil, fmt.Errorf("%s: duplicate %s block %q", b.DefRange(), b.Type, last)
+		}
+		parent[last] = content
+	}
+	return doc, nil
+}
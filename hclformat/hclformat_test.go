@@ -0,0 +1,138 @@
+package hclformat
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robfordww/antconfig"
+)
+
+func TestLoad(t *testing.T) {
+	type Database struct {
+		Host string `json:"host"`
+		Port int    `json:"port" env:"HCL_DB_PORT"`
+	}
+	type Rule struct {
+		Path  string `json:"path"`
+		Allow bool   `json:"allow"`
+	}
+	type Config struct {
+		Listen    string              `json:"listen"`
+		Tags      []string            `json:"tags"`
+		Timeout   int                 `json:"timeout"`
+		Banner    string              `json:"banner"`
+		Databases map[string]Database `json:"database"`
+		Rules     []Rule              `json:"rule"`
+	}
+	path := filepath.Join(t.TempDir(), "infra.hcl")
+	src := `
+listen  = ":8080"
+tags    = ["a", "b"]
+timeout = 60 * 2 # seconds
+banner  = <<EOT
+hello
+EOT
+
+database "primary" {
+  host = "db.internal"
+  port = 5432
+}
+
+database "replica" {
+  host = "db-ro.internal"
+}
+
+rule {
+  path  = "/admin"
+  allow = false
+}
+
+rule {
+  path  = "/"
+  allow = true
+}
+`
+	if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Config
+	ac := antconfig.New()
+	if err := Register(ac); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetEnvironment(map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{})
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Listen != ":8080" || len(cfg.Tags) != 2 || cfg.Timeout != 120 || cfg.Banner != "hello\n" {
+		t.Fatalf("unexpected attributes: %+v", cfg)
+	}
+	if db := cfg.Databases["primary"]; db.Host != "db.internal" || db.Port != 5432 || cfg.Databases["replica"].Host != "db-ro.internal" {
+		t.Fatalf("unexpected labeled blocks: %+v", cfg.Databases)
+	}
+	if len(cfg.Rules) != 2 || cfg.Rules[0].Path != "/admin" || !cfg.Rules[1].Allow {
+		t.Fatalf("unexpected repeated blocks: %+v", cfg.Rules)
+	}
+	if prov := ac.Provenance()["Listen"]; prov != antconfig.LayerFile {
+		t.Fatalf("provenance = %v", prov)
+	}
+}
+
+func TestEnvOverridesHCL(t *testing.T) {
+	type Config struct {
+		Port int `json:"port" env:"HCL_PORT"`
+	}
+	path := filepath.Join(t.TempDir(), "config.hcl")
+	if err := os.WriteFile(path, []byte("port = 8080\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var cfg Config
+	ac := antconfig.New()
+	if err := Register(ac); err != nil {
+		t.Fatal(err)
+	}
+	if err := ac.SetEnvironment(map[string]string{"HCL_PORT": "9090"}); err != nil {
+		t.Fatal(err)
+	}
+	ac.SetFlagArgs([]string{})
+	if err := ac.SetConfigPath(path); err != nil {
+		t.Fatal(err)
+	}
+	ac.MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatalf("WriteConfigValues: %v", err)
+	}
+	if cfg.Port != 9090 {
+		t.Fatalf("env did not override the HCL file: %+v", cfg)
+	}
+}
+
+func TestToJSONErrors(t *testing.T) {
+	cases := map[string]struct {
+		src  string
+		want string
+	}{
+		"syntax":           {"listen = \n", "config.hcl:1"},
+		"variable":         {"listen = var.port\n", "Variables not allowed"},
+		"duplicate label":  {"db \"a\" {}\ndb \"a\" {}\n", `duplicate db block "a"`},
+		"attribute clash":  {"db = 1\ndb {}\n", "both an attribute and a block"},
+		"mixed block kind": {"db {}\ndb \"a\" {}\n", "mixes labeled and unlabeled blocks"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ToJSON([]byte(tc.src))
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected error containing %q, got %v", tc.want, err)
+			}
+		})
+	}
+}
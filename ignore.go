@@ -0,0 +1,81 @@
+package antconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// isIgnored reports whether struct field f is tagged `antconfig:"-"`. Such a
+// field and everything below it are left alone by every layer (no defaults,
+// env, flags, config file keys or sources) and are omitted from help,
+// samples, descriptors and diffs, so runtime-only state can live in the
+// config struct.
+func isIgnored(f reflect.StructField) bool {
+	name, _, _ := strings.Cut(f.Tag.Get("antconfig"), ",")
+	return name == "-"
+}
+
+// hasIgnoredFields reports whether struct type t has an `antconfig:"-"`
+// field at any depth.
+func hasIgnoredFields(t reflect.Type) bool {
+	return hasIgnoredFieldsSeen(t, map[reflect.Type]bool{})
+}
+
+func hasIgnoredFieldsSeen(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if isIgnored(f) {
+			return true
+		}
+		if st, ok := nestedStruct(f.Type); ok && hasIgnoredFieldsSeen(st, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropIgnoredKeys removes from the JSON document js the keys that would
+// decode into `antconfig:"-"` fields of struct type t.
+func dropIgnoredKeys(js []byte, t reflect.Type) ([]byte, error) {
+	if !hasIgnoredFields(t) {
+		return js, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		// Let the regular decoding report malformed documents
+		return js, nil
+	}
+	dropUnmatchedKeys(doc, t)
+	return json.Marshal(doc)
+}
+
+// dropUnmatchedKeys deletes the keys of doc that match no field of struct
+// type t (jsonField skips ignored fields), recursing into nested structs.
+// encoding/json would skip unknown keys anyway, unless t decodes itself.
+func dropUnmatchedKeys(doc map[string]any, t reflect.Type) {
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		return
+	}
+	for k, v := range doc {
+		f, _, ok := jsonField(t, k, "")
+		if !ok {
+			delete(doc, k)
+			continue
+		}
+		if st, nested := nestedStruct(f.Type); nested {
+			if sub, ok := v.(map[string]any); ok {
+				dropUnmatchedKeys(sub, st)
+			}
+		}
+	}
+}
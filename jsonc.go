@@ -1,5 +1,15 @@
 package antconfig
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/robfordww/antconfig/jsonc"
+)
+
 // The MIT License (MIT)
 // Copyright (c) 2021 Josh Baker
 
@@ -84,3 +94,32 @@ func toJSON(src, dst []byte) []byte {
 	}
 	return dst
 }
+
+// locateJSONCError adds the line and column in the JSONC source src to err,
+// the error of decoding the config document built from src into a t, when
+// it is a JSON syntax or type error that src itself reproduces. ToJSON keeps
+// byte offsets, so decoding ToJSON(src) again yields an offset into src.
+func locateJSONCError(err error, src []byte, t reflect.Type) error {
+	var syntax *json.SyntaxError
+	var typ *json.UnmarshalTypeError
+	if !errors.As(err, &syntax) && !errors.As(err, &typ) {
+		return err
+	}
+	again := json.Unmarshal(ToJSON(src), reflect.New(t).Interface())
+	if again == nil || !strings.HasSuffix(err.Error(), again.Error()) {
+		// A different problem, e.g. introduced by a migration
+		return err
+	}
+	var offset int64
+	switch e := again.(type) {
+	case *json.SyntaxError:
+		offset = e.Offset
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	default:
+		return err
+	}
+	// The offset is just past the offending byte
+	line, col := jsonc.Position(src, offset-1)
+	return fmt.Errorf("%w (line %d, column %d)", err, line, col)
+}
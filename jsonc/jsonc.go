@@ -0,0 +1,542 @@
+// Package jsonc parses JSONC (JSON with // and /* */ comments and trailing
+// commas) into a tree that keeps every comment, blank and the original
+// spelling of each value, so programs can edit a user's config file and
+// write it back without disturbing the rest of it:
+//
+//	doc, err := jsonc.Parse(data)
+//	if err != nil { /* handle */ }
+//	port, _ := jsonc.NewValue(9090)
+//	doc.Root.Get("server").Set("port", port)
+//	err = os.WriteFile(path, doc.Serialize(), 0o644)
+//
+// Serialize returns the parsed bytes unchanged when the tree was not
+// modified. New members and elements follow the layout of their siblings.
+package jsonc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind is the JSON type of a Node.
+type Kind int
+
+const (
+	Null Kind = iota
+	Bool
+	Number
+	String
+	Array
+	Object
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Null:
+		return "null"
+	case Bool:
+		return "bool"
+	case Number:
+		return "number"
+	case String:
+		return "string"
+	case Array:
+		return "array"
+	case Object:
+		return "object"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Document is a parsed JSONC document.
+type Document struct {
+	// Before and After hold the blanks and comments around Root.
+	Before, After string
+	Root          *Node
+}
+
+// Node is a JSONC value.
+type Node struct {
+	Kind Kind
+	// Raw is the source text of a scalar: a quoted string, a number, true,
+	// false or null.
+	Raw string
+	// Members are the members of an Object, in source order.
+	Members []*Member
+	// Elements are the elements of an Array.
+	Elements []*Element
+	// TrailingComma records a comma after the last member or element.
+	TrailingComma bool
+	// Close holds the blanks and comments before the closing brace or
+	// bracket.
+	Close string
+	// indent is the indentation of the line the node starts on.
+	indent string
+}
+
+// Member is a key and value of an Object with the text around them.
+type Member struct {
+	// Before holds the blanks and comments ahead of the key.
+	Before string
+	Key    string
+	Value  *Node
+	// After holds the blanks and comments between the value and its comma.
+	After string
+	// Comment holds the blanks and comments that follow the member on its
+	// line, after the comma if there is one.
+	Comment string
+	// sep is the text between key and value, colon included; rawKey and
+	// origKey are the key as written and decoded, kept while Key is
+	// unchanged.
+	sep, rawKey, origKey string
+}
+
+// Element is a value of an Array with the text around it.
+type Element struct {
+	// Before, After and Comment are laid out as for a Member.
+	Before  string
+	Value   *Node
+	After   string
+	Comment string
+}
+
+// Parse parses a JSONC document.
+func Parse(data []byte) (*Document, error) {
+	p := &parser{src: data}
+	doc := &Document{Before: p.trivia()}
+	root, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	doc.Root = root
+	doc.After = p.trivia()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q after the top-level value", p.src[p.pos])
+	}
+	return doc, nil
+}
+
+// NewValue returns the node for v as encoded by encoding/json.
+func NewValue(v any) (*Node, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return doc.Root, nil
+}
+
+// Serialize returns the document as JSONC text.
+func (d *Document) Serialize() []byte {
+	var b bytes.Buffer
+	b.WriteString(d.Before)
+	d.Root.write(&b, false)
+	b.WriteString(d.After)
+	return b.Bytes()
+}
+
+// Get returns the value of the last member of an Object named key, or nil.
+func (n *Node) Get(key string) *Node {
+	if m := n.member(key); m != nil {
+		return m.Value
+	}
+	return nil
+}
+
+func (n *Node) member(key string) *Member {
+	for i := len(n.Members) - 1; i >= 0; i-- {
+		if n.Members[i].Key == key {
+			return n.Members[i]
+		}
+	}
+	return nil
+}
+
+// Set sets the member key of an Object to v, replacing the value of an
+// existing member in place, or adding a member after the last one.
+func (n *Node) Set(key string, v *Node) {
+	if m := n.member(key); m != nil {
+		m.Value = v
+		return
+	}
+	m := &Member{Key: key, Value: v}
+	if len(n.Members) == 0 {
+		m.Before = n.openLine()
+	} else {
+		m.Before = nextLine(n.Members[len(n.Members)-1].Before)
+	}
+	v.indent = lastLine(m.Before)
+	n.Members = append(n.Members, m)
+}
+
+// Delete removes the members of an Object named key, reporting whether
+// there were any.
+func (n *Node) Delete(key string) bool {
+	kept := n.Members[:0]
+	for _, m := range n.Members {
+		if m.Key != key {
+			kept = append(kept, m)
+		}
+	}
+	deleted := len(kept) < len(n.Members)
+	n.Members = kept
+	return deleted
+}
+
+// Append adds v as the last element of an Array.
+func (n *Node) Append(v *Node) {
+	e := &Element{Value: v}
+	if len(n.Elements) == 0 {
+		e.Before = n.openLine()
+	} else {
+		e.Before = nextLine(n.Elements[len(n.Elements)-1].Before)
+	}
+	v.indent = lastLine(e.Before)
+	n.Elements = append(n.Elements, e)
+}
+
+// openLine starts the first child of an empty Object or Array on its own
+// line, moving the closing bracket to the next one.
+func (n *Node) openLine() string {
+	if !strings.Contains(n.Close, "\n") {
+		n.Close = strings.TrimRight(n.Close, " \t") + "\n" + n.indent
+	}
+	return "\n" + n.indent + "  "
+}
+
+// nextLine returns the Before of a child following one whose Before is
+// prev: on a new line with the same indentation, or after a space for
+// children sharing a line.
+func nextLine(prev string) string {
+	i := strings.LastIndexByte(prev, '\n')
+	if i < 0 {
+		return " "
+	}
+	nl := "\n"
+	if i > 0 && prev[i-1] == '\r' {
+		nl = "\r\n"
+	}
+	return nl + lastLine(prev)
+}
+
+// lastLine returns the blanks ending s after its last newline.
+func lastLine(s string) string {
+	s = s[strings.LastIndexByte(s, '\n')+1:]
+	return s[:len(s)-len(strings.TrimLeft(s, " \t"))]
+}
+
+// JSON returns the node as compact JSON without comments.
+func (n *Node) JSON() []byte {
+	var b bytes.Buffer
+	n.write(&b, true)
+	return b.Bytes()
+}
+
+// Decode stores the node's value in v like json.Unmarshal.
+func (n *Node) Decode(v any) error {
+	return json.Unmarshal(n.JSON(), v)
+}
+
+// write writes n as JSONC text, or as compact JSON when strict is set.
+func (n *Node) write(b *bytes.Buffer, strict bool) {
+	text := func(s string) {
+		if !strict {
+			b.WriteString(s)
+		}
+	}
+	switch n.Kind {
+	case Object:
+		b.WriteByte('{')
+		for i, m := range n.Members {
+			text(m.Before)
+			if m.rawKey != "" && m.Key == m.origKey {
+				b.WriteString(m.rawKey)
+			} else {
+				k, _ := json.Marshal(m.Key)
+				b.Write(k)
+			}
+			switch {
+			case strict:
+				b.WriteByte(':')
+			case m.sep == "":
+				b.WriteString(": ")
+			default:
+				b.WriteString(m.sep)
+			}
+			m.Value.write(b, strict)
+			text(m.After)
+			if i < len(n.Members)-1 || (n.TrailingComma && !strict) {
+				b.WriteByte(',')
+			}
+			text(m.Comment)
+		}
+		text(n.Close)
+		b.WriteByte('}')
+	case Array:
+		b.WriteByte('[')
+		for i, e := range n.Elements {
+			text(e.Before)
+			e.Value.write(b, strict)
+			text(e.After)
+			if i < len(n.Elements)-1 || (n.TrailingComma && !strict) {
+				b.WriteByte(',')
+			}
+			text(e.Comment)
+		}
+		text(n.Close)
+		b.WriteByte(']')
+	default:
+		b.WriteString(n.Raw)
+	}
+}
+
+// Position returns the 1-based line and column, counted in characters, of
+// byte offset in src. Since NewReader and antconfig.ToJSON keep offsets, it
+// maps the Offset of a json.SyntaxError or json.UnmarshalTypeError from the
+// converted JSON back to the JSONC text.
+func Position(src []byte, offset int64) (line, col int) {
+	offset = max(0, min(offset, int64(len(src))))
+	head := src[:offset]
+	start := bytes.LastIndexByte(head, '\n') + 1
+	return 1 + bytes.Count(head, []byte("\n")), 1 + utf8.RuneCount(head[start:])
+}
+
+// parser reads JSONC text.
+type parser struct {
+	src []byte
+	pos int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	line, col := Position(p.src, int64(p.pos))
+	return fmt.Errorf("jsonc: line %d, column %d: %s", line, col, fmt.Sprintf(format, args...))
+}
+
+// trivia consumes blanks and comments.
+func (p *parser) trivia() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			p.pos++
+		case p.comment(true):
+		default:
+			return string(p.src[start:p.pos])
+		}
+	}
+	return string(p.src[start:p.pos])
+}
+
+// sameLine consumes the blanks and comments left on the current line.
+func (p *parser) sameLine() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t':
+			p.pos++
+		case p.comment(false):
+		default:
+			return string(p.src[start:p.pos])
+		}
+	}
+	return string(p.src[start:p.pos])
+}
+
+// comment consumes a comment at p.pos, up to but excluding the newline
+// ending a line comment. Block comments spanning lines are only consumed
+// when multiline is set.
+func (p *parser) comment(multiline bool) bool {
+	rest := p.src[p.pos:]
+	switch {
+	case bytes.HasPrefix(rest, []byte("//")):
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			p.pos += i
+		} else {
+			p.pos = len(p.src)
+		}
+		return true
+	case bytes.HasPrefix(rest, []byte("/*")):
+		end := bytes.Index(rest[2:], []byte("*/"))
+		if end < 0 {
+			if !multiline {
+				return false
+			}
+			p.pos = len(p.src)
+			return true
+		}
+		if !multiline && bytes.IndexByte(rest[:end+2], '\n') >= 0 {
+			return false
+		}
+		p.pos += end + 4
+		return true
+	}
+	return false
+}
+
+// value parses the value at p.pos.
+func (p *parser) value() (*Node, error) {
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of input")
+	}
+	switch c := p.src[p.pos]; {
+	case c == '{':
+		return p.object()
+	case c == '[':
+		return p.array()
+	case c == '"':
+		raw, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Kind: String, Raw: raw}, nil
+	}
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(",:{}[]\" \t\r\n/", rune(p.src[p.pos])) {
+		p.pos++
+	}
+	raw := string(p.src[start:p.pos])
+	switch raw {
+	case "null":
+		return &Node{Kind: Null, Raw: raw}, nil
+	case "true", "false":
+		return &Node{Kind: Bool, Raw: raw}, nil
+	case "":
+		return nil, p.errorf("unexpected %q", p.src[p.pos])
+	}
+	if raw[0] != '-' && (raw[0] < '0' || raw[0] > '9') || !json.Valid([]byte(raw)) {
+		p.pos = start
+		return nil, p.errorf("invalid value %q", raw)
+	}
+	return &Node{Kind: Number, Raw: raw}, nil
+}
+
+// str parses the string at p.pos, returning it as written.
+func (p *parser) str() (string, error) {
+	start := p.pos
+	for i := p.pos + 1; i < len(p.src); i++ {
+		switch p.src[i] {
+		case '\\':
+			i++
+		case '"':
+			raw := p.src[start : i+1]
+			if !json.Valid(raw) {
+				return "", p.errorf("invalid string %s", raw)
+			}
+			p.pos = i + 1
+			return string(raw), nil
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// object parses the object at p.pos.
+func (p *parser) object() (*Node, error) {
+	n := &Node{Kind: Object, indent: p.indent()}
+	p.pos++
+	for {
+		before := p.trivia()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("unterminated object")
+		}
+		if p.src[p.pos] == '}' {
+			// Empty object, or a comma after the last member
+			n.TrailingComma = len(n.Members) > 0
+			n.Close = before
+			p.pos++
+			return n, nil
+		}
+		if p.src[p.pos] != '"' {
+			return nil, p.errorf("expected an object key")
+		}
+		raw, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		m := &Member{Before: before, rawKey: raw}
+		_ = json.Unmarshal([]byte(raw), &m.Key)
+		m.origKey = m.Key
+		sepStart := p.pos
+		p.trivia()
+		if p.pos >= len(p.src) || p.src[p.pos] != ':' {
+			return nil, p.errorf("expected ':' after key %s", raw)
+		}
+		p.pos++
+		p.trivia()
+		m.sep = string(p.src[sepStart:p.pos])
+		if m.Value, err = p.value(); err != nil {
+			return nil, err
+		}
+		n.Members = append(n.Members, m)
+		if done, err := p.next(&m.After, &m.Comment, &n.Close, '}'); err != nil {
+			return nil, err
+		} else if done {
+			return n, nil
+		}
+	}
+}
+
+// array parses the array at p.pos.
+func (p *parser) array() (*Node, error) {
+	n := &Node{Kind: Array, indent: p.indent()}
+	p.pos++
+	for {
+		before := p.trivia()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.src[p.pos] == ']' {
+			n.TrailingComma = len(n.Elements) > 0
+			n.Close = before
+			p.pos++
+			return n, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		e := &Element{Before: before, Value: v}
+		n.Elements = append(n.Elements, e)
+		if done, err := p.next(&e.After, &e.Comment, &n.Close, ']'); err != nil {
+			return nil, err
+		} else if done {
+			return n, nil
+		}
+	}
+}
+
+// next consumes what follows a member or element: a comma and the rest of
+// its line, or the closing bracket, which sets done.
+func (p *parser) next(after, comment, closing *string, bracket byte) (done bool, err error) {
+	start := p.pos
+	t := p.trivia()
+	switch {
+	case p.pos < len(p.src) && p.src[p.pos] == ',':
+		*after = t
+		p.pos++
+		*comment = p.sameLine()
+		return false, nil
+	case p.pos < len(p.src) && p.src[p.pos] == bracket:
+		p.pos = start
+		*comment = p.sameLine()
+		*closing = p.trivia()
+		p.pos++
+		return true, nil
+	}
+	return false, p.errorf("expected ',' or '%c'", bracket)
+}
+
+// indent returns the indentation of the line holding p.pos.
+func (p *parser) indent() string {
+	start := bytes.LastIndexByte(p.src[:p.pos], '\n') + 1
+	end := start
+	for end < len(p.src) && (p.src[end] == ' ' || p.src[end] == '\t') {
+		end++
+	}
+	return string(p.src[start:end])
+}
@@ -0,0 +1,122 @@
+package jsonc
+
+import (
+	"strings"
+	"testing"
+)
+
+const sample = `// Service config
+{
+  "server": {
+    "host": "localhost", // where to listen
+    "port": 8080,
+    /* ports to probe */
+    "probes": [1, 2,],
+  },
+  "debug": false,
+  "name": "café"
+}
+`
+
+func TestRoundTrip(t *testing.T) {
+	for _, src := range []string{sample, `{}`, `[]`, `"x"`, `-1.5e3`, "{\r\n  \"a\": 1 /* c */\r\n}\r\n", "  null // end"} {
+		doc, err := Parse([]byte(src))
+		if err != nil {
+			t.Fatalf("%q: %v", src, err)
+		}
+		if got := string(doc.Serialize()); got != src {
+			t.Errorf("round trip changed %q to %q", src, got)
+		}
+	}
+}
+
+func TestEdit(t *testing.T) {
+	doc, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := doc.Root.Get("server")
+	port, _ := NewValue(9090)
+	server.Set("port", port)
+	timeout, _ := NewValue("5s")
+	server.Set("timeout", timeout)
+	three, _ := NewValue(3)
+	server.Get("probes").Append(three)
+	if !doc.Root.Delete("debug") {
+		t.Fatal("debug not deleted")
+	}
+	tags, _ := NewValue(map[string]any{"env": "prod"})
+	doc.Root.Set("tags", tags)
+	want := `// Service config
+{
+  "server": {
+    "host": "localhost", // where to listen
+    "port": 9090,
+    /* ports to probe */
+    "probes": [1, 2, 3,],
+    "timeout": "5s",
+  },
+  "name": "café",
+  "tags": {"env":"prod"}
+}
+`
+	if got := string(doc.Serialize()); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	var name string
+	if err := doc.Root.Get("name").Decode(&name); err != nil || name != "café" {
+		t.Fatalf("name=%q err=%v", name, err)
+	}
+	if got := string(doc.Root.JSON()); got != `{"server":{"host":"localhost","port":9090,"probes":[1,2,3],"timeout":"5s"},"name":"café","tags":{"env":"prod"}}` {
+		t.Fatalf("JSON() = %s", got)
+	}
+}
+
+func TestInsertLayout(t *testing.T) {
+	for _, tc := range []struct {
+		name, src, want string
+	}{
+		{"inline", `{"a": 0}`, `{"a": 0, "b": 1}`},
+		{"empty root", "{}\n", "{\n  \"b\": 1\n}\n"},
+		{"empty nested", "{\n  \"a\": {}\n}", "{\n  \"a\": {\n    \"b\": 1\n  }\n}"},
+		{"line comment", "{\n  \"a\": 0 // zero\n}", "{\n  \"a\": 0, // zero\n  \"b\": 1\n}"},
+		{"crlf", "{\r\n  \"a\": 0\r\n}", "{\r\n  \"a\": 0,\r\n  \"b\": 1\r\n}"},
+	} {
+		doc, err := Parse([]byte(tc.src))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		target := doc.Root
+		if a := target.Get("a"); a != nil && a.Kind == Object {
+			target = a
+		}
+		one, _ := NewValue(1)
+		target.Set("b", one)
+		if got := string(doc.Serialize()); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{``, `{"a": }`, `{"a" 1}`, `{"a": 1 "b": 2}`, `[1, 2`, `"\x"`, `nope`, `{} {}`, `{a: 1}`} {
+		if _, err := Parse([]byte(src)); err == nil {
+			t.Errorf("Parse(%q): expected error", src)
+		} else if !strings.HasPrefix(err.Error(), "jsonc: line ") {
+			t.Errorf("Parse(%q): unexpected error format %v", src, err)
+		}
+	}
+}
+
+func TestPosition(t *testing.T) {
+	src := []byte("{\n  \"é\": x\n}")
+	for _, tc := range []struct {
+		offset    int64
+		line, col int
+	}{{0, 1, 1}, {2, 2, 1}, {10, 2, 8}, {100, 3, 2}, {-1, 1, 1}} {
+		if line, col := Position(src, tc.offset); line != tc.line || col != tc.col {
+			t.Errorf("Position(%d) = %d:%d, want %d:%d", tc.offset, line, col, tc.line, tc.col)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package jsonc
+
+import (
+	"io"
+)
+
+// NewReader returns a reader of the strict JSON equivalent of the JSONC read
+// from r, for files too large to hold twice in memory. Like
+// antconfig.ToJSON, it replaces comments and trailing commas with blanks and
+// keeps newlines, so byte offsets, lines and columns in the output match the
+// input:
+//
+//	dec := json.NewDecoder(jsonc.NewReader(f))
+//
+// Only a comma and the blanks and comments after it are held back, until
+// the next value or closing bracket shows whether the comma is a trailing
+// one.
+func NewReader(r io.Reader) io.Reader {
+	return &reader{src: r, buf: make([]byte, 32*1024)}
+}
+
+// reader states between bytes
+const (
+	stNormal = iota
+	stSlash
+	stLineComment
+	stBlockComment
+	stBlockStar
+	stString
+	stEscape
+)
+
+type reader struct {
+	src   io.Reader
+	buf   []byte
+	out   []byte
+	state int
+	// pending holds a comma and what followed it while it may still turn
+	// out to be a trailing one.
+	pending []byte
+	err     error
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.out) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		n, err := r.src.Read(r.buf)
+		for _, c := range r.buf[:n] {
+			r.convert(c)
+		}
+		if err != nil {
+			if err == io.EOF {
+				r.finish()
+			}
+			r.err = err
+		}
+	}
+	n := copy(p, r.out)
+	r.out = r.out[n:]
+	if len(r.out) == 0 {
+		r.out = r.out[:0:0]
+	}
+	return n, nil
+}
+
+// emit writes c to the output, behind any pending comma.
+func (r *reader) emit(c ...byte) {
+	if r.pending != nil {
+		r.pending = append(r.pending, c...)
+		return
+	}
+	r.out = append(r.out, c...)
+}
+
+// settle releases the pending comma, blanking it when the next significant
+// byte c closes an object or array.
+func (r *reader) settle(c byte) {
+	if r.pending == nil {
+		return
+	}
+	if c == '}' || c == ']' {
+		r.pending[0] = ' '
+	}
+	r.out = append(r.out, r.pending...)
+	r.pending = nil
+}
+
+// blank returns what a comment byte becomes: newlines, tabs and carriage
+// returns are kept and everything else turns into a space.
+func blank(c byte) byte {
+	if c == '\n' || c == '\t' || c == '\r' {
+		return c
+	}
+	return ' '
+}
+
+func (r *reader) convert(c byte) {
+	switch r.state {
+	case stSlash:
+		switch c {
+		case '/':
+			r.emit(' ', ' ')
+			r.state = stLineComment
+			return
+		case '*':
+			r.emit(' ', ' ')
+			r.state = stBlockComment
+			return
+		}
+		// A lone slash is not a comment: keep it as a value byte
+		r.settle('/')
+		r.emit('/')
+		r.state = stNormal
+	case stLineComment:
+		if c == '\n' {
+			r.emit('\n')
+			r.state = stNormal
+		} else {
+			r.emit(blank(c))
+		}
+		return
+	case stBlockComment, stBlockStar:
+		if r.state == stBlockStar && c == '/' {
+			r.emit(' ')
+			r.state = stNormal
+			return
+		}
+		r.state = stBlockComment
+		if c == '*' {
+			r.state = stBlockStar
+		}
+		r.emit(blank(c))
+		return
+	case stString:
+		r.emit(c)
+		switch c {
+		case '\\':
+			r.state = stEscape
+		case '"':
+			r.state = stNormal
+		}
+		return
+	case stEscape:
+		r.emit(c)
+		r.state = stString
+		return
+	}
+
+	switch c {
+	case '/':
+		r.state = stSlash
+	case ' ', '\t', '\r', '\n':
+		r.emit(c)
+	case ',':
+		r.settle(c)
+		r.pending = append(make([]byte, 0, 64), c)
+	default:
+		r.settle(c)
+		r.emit(c)
+		if c == '"' {
+			r.state = stString
+		}
+	}
+}
+
+// finish flushes what is held back at the end of the input.
+func (r *reader) finish() {
+	if r.state == stSlash {
+		r.settle('/')
+		r.emit('/')
+	}
+	r.state = stNormal
+	r.settle(0)
+}
@@ -0,0 +1,59 @@
+package jsonc_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/robfordww/antconfig"
+	"github.com/robfordww/antconfig/jsonc"
+)
+
+func TestNewReaderMatchesToJSON(t *testing.T) {
+	inputs := []string{
+		`{"a": 1, /* c */ "b": [1, 2,], // x
+"c": "a/b // not a comment \" /* nor this */",}`,
+		"{\r\n\t// tab\t\r\n\t\"k\": \"v\\\\\",\r\n}\r\n",
+		`[1,
+  // trailing
+]`,
+		`{"a": 4/2}`,
+		`{"a": [ , ]}`,
+		`/`,
+		``,
+	}
+	for _, in := range inputs {
+		want := antconfig.ToJSON([]byte(in))
+		for _, r := range []io.Reader{jsonc.NewReader(strings.NewReader(in)), jsonc.NewReader(iotest.OneByteReader(strings.NewReader(in)))} {
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("NewReader(%q) = %q, want %q", in, got, want)
+			}
+		}
+	}
+}
+
+func TestNewReaderDecode(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("[\n")
+	for i := 0; i < 10000; i++ {
+		b.WriteString("  {\"id\": 1, /* row */ \"tags\": [\"a\", \"b\",],}, // seed\n")
+	}
+	b.WriteString("]\n")
+	var rows []struct {
+		ID   int
+		Tags []string
+	}
+	if err := json.NewDecoder(jsonc.NewReader(strings.NewReader(b.String()))).Decode(&rows); err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 10000 || rows[9999].ID != 1 || len(rows[0].Tags) != 2 {
+		t.Fatalf("decoded %d rows", len(rows))
+	}
+}
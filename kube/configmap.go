@@ -0,0 +1,287 @@
+package kube
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serviceAccountDir holds the credentials Kubernetes mounts into every pod.
+const serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// ConfigMap reads a named ConfigMap from the API server, for ConfigMaps that
+// are not mounted or that must reach the process faster than the kubelet
+// syncs volumes. Its data keys are looked up by env name (see EnvName). The
+// pod's service account needs get, list and watch on configmaps.
+type ConfigMap struct {
+	// Name of the ConfigMap.
+	Name string
+	// Namespace of the ConfigMap; empty means the pod's own namespace.
+	Namespace string
+	// Host is the API server URL; empty means the in-cluster address from
+	// KUBERNETES_SERVICE_HOST and KUBERNETES_SERVICE_PORT.
+	Host string
+	// Client sends the requests; nil means a client trusting the
+	// service account's CA certificate.
+	Client *http.Client
+	// Token returns the bearer token; nil reads the service account token,
+	// on every request as the kubelet rotates it.
+	Token func(ctx context.Context) (string, error)
+
+	mu      sync.RWMutex
+	data    map[string]string
+	vars    map[string]string
+	version string
+}
+
+// Fetch reads the ConfigMap, replacing the data Lookup serves.
+func (c *ConfigMap) Fetch(ctx context.Context) error {
+	_, err := c.fetch(ctx)
+	return err
+}
+
+// Lookup returns the data entry whose env name is key, as of the last Fetch
+// or watch event; it has the signature of antconfig.AntConfig.SetLookupEnv.
+func (c *ConfigMap) Lookup(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	v, ok := c.vars[key]
+	return v, ok
+}
+
+// Data returns a copy of the ConfigMap's data, keyed as in the ConfigMap.
+func (c *ConfigMap) Data() map[string]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return maps.Clone(c.data)
+}
+
+// Watch keeps the data current until ctx is done and calls onChange after
+// each change, typically to reload the configuration:
+//
+//	go cm.Watch(ctx, func() {
+//		if err := ac.WriteConfigValues(); err != nil { log.Print(err) }
+//	})
+//
+// Watch reconnects when the server ends the stream and refetches the
+// ConfigMap when its watch position expires; failures are retried with
+// backoff, up to 30s apart. A deleted ConfigMap holds no data. Watch returns
+// ctx's error.
+func (c *ConfigMap) Watch(ctx context.Context, onChange func()) error {
+	backoff := time.Second
+	for {
+		err := c.watchOnce(ctx, onChange)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil {
+			backoff = time.Second
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, 30*time.Second)
+	}
+}
+
+// watchOnce brings the data up to date if no watch position is known, then
+// follows one watch stream until the server ends it.
+func (c *ConfigMap) watchOnce(ctx context.Context, onChange func()) error {
+	c.mu.RLock()
+	version := c.version
+	c.mu.RUnlock()
+	if version == "" {
+		changed, err := c.fetch(ctx)
+		if err != nil {
+			return err
+		}
+		if changed {
+			onChange()
+		}
+	}
+	ns, err := c.namespace()
+	if err != nil {
+		return err
+	}
+	c.mu.RLock()
+	q := url.Values{
+		"watch":               {"true"},
+		"fieldSelector":       {"metadata.name=" + c.Name},
+		"resourceVersion":     {c.version},
+		"allowWatchBookmarks": {"true"},
+	}
+	c.mu.RUnlock()
+	resp, err := c.get(ctx, "/api/v1/namespaces/"+url.PathEscape(ns)+"/configmaps?"+q.Encode())
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var ev struct {
+			Type   string          `json:"type"`
+			Object json.RawMessage `json:"object"`
+		}
+		if err := dec.Decode(&ev); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		switch ev.Type {
+		case "ADDED", "MODIFIED", "DELETED", "BOOKMARK":
+			var obj configMapObject
+			if err := json.Unmarshal(ev.Object, &obj); err != nil {
+				return err
+			}
+			if ev.Type == "DELETED" {
+				obj.Data = nil
+			}
+			if c.set(obj, ev.Type != "BOOKMARK") {
+				onChange()
+			}
+		case "ERROR":
+			// Mostly 410 Gone: the resourceVersion is too old to resume from
+			c.mu.Lock()
+			c.version = ""
+			c.mu.Unlock()
+			return fmt.Errorf("kube: watch %s/%s: %s", ns, c.Name, ev.Object)
+		}
+	}
+}
+
+type configMapObject struct {
+	Metadata struct {
+		ResourceVersion string `json:"resourceVersion"`
+	} `json:"metadata"`
+	Data map[string]string `json:"data"`
+}
+
+// fetch reads the ConfigMap and reports whether its data changed.
+func (c *ConfigMap) fetch(ctx context.Context) (bool, error) {
+	ns, err := c.namespace()
+	if err != nil {
+		return false, err
+	}
+	resp, err := c.get(ctx, "/api/v1/namespaces/"+url.PathEscape(ns)+"/configmaps/"+url.PathEscape(c.Name))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	var obj configMapObject
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&obj); err != nil {
+		return false, fmt.Errorf("kube: configmap %s/%s: %w", ns, c.Name, err)
+	}
+	return c.set(obj, true), nil
+}
+
+// set records obj's resource version and, if withData, its data, reporting
+// whether the data changed.
+func (c *ConfigMap) set(obj configMapObject, withData bool) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version = obj.Metadata.ResourceVersion
+	if !withData || (c.vars != nil && maps.Equal(c.data, obj.Data)) {
+		return false
+	}
+	c.data = maps.Clone(obj.Data)
+	c.vars = make(map[string]string, len(obj.Data))
+	for k, v := range obj.Data {
+		c.vars[EnvName(k)] = v
+	}
+	return true
+}
+
+func (c *ConfigMap) namespace() (string, error) {
+	if c.Namespace != "" {
+		return c.Namespace, nil
+	}
+	ns, err := os.ReadFile(serviceAccountDir + "/namespace")
+	if err != nil {
+		return "", fmt.Errorf("kube: pod namespace: %w", err)
+	}
+	return strings.TrimSpace(string(ns)), nil
+}
+
+// get sends an authenticated GET for path to the API server and returns the
+// response if its status is 200.
+func (c *ConfigMap) get(ctx context.Context, path string) (*http.Response, error) {
+	host := c.Host
+	if host == "" {
+		h, p := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if h == "" || p == "" {
+			return nil, errors.New("kube: not running in a cluster (KUBERNETES_SERVICE_HOST is unset) and no Host given")
+		}
+		host = "https://" + net.JoinHostPort(h, p)
+	}
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("kube: service account token: %w", err)
+	}
+	client, err := c.client()
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(host, "/")+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+		return nil, fmt.Errorf("kube: configmap %s: %s: %s", c.Name, resp.Status, strings.TrimSpace(string(data)))
+	}
+	return resp, nil
+}
+
+func (c *ConfigMap) token(ctx context.Context) (string, error) {
+	if c.Token != nil {
+		return c.Token(ctx)
+	}
+	data, err := os.ReadFile(serviceAccountDir + "/token")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func (c *ConfigMap) client() (*http.Client, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Client != nil {
+		return c.Client, nil
+	}
+	pem, err := os.ReadFile(serviceAccountDir + "/ca.crt")
+	if err != nil {
+		return nil, fmt.Errorf("kube: cluster CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("kube: cluster CA: no certificates in ca.crt")
+	}
+	c.Client = &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	return c.Client, nil
+}
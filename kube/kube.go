@@ -0,0 +1,136 @@
+// Package kube feeds Kubernetes configuration into the antconfig env layer:
+// mounted ConfigMap and Downward API volumes, and ConfigMaps read from the
+// API server with an optional watch for hot reload.
+//
+//	cm := &kube.ConfigMap{Name: "app-config"}
+//	if err := cm.Fetch(ctx); err != nil { ... }
+//	err := ac.SetLookupEnv(kube.Chain(
+//		os.LookupEnv,                                           // the pod's env wins
+//		kube.Dir{Path: "/etc/podinfo", Prefix: "POD_"}.Lookup, // Downward API
+//		kube.Dir{Path: "/etc/config"}.Lookup,                  // mounted ConfigMap
+//		cm.Lookup,
+//	))
+//	if err != nil { ... }
+//	go cm.Watch(ctx, func() { reload() })
+//
+// Keys become env names the way `env` tags usually spell them: upper case,
+// with every character other than letters and digits replaced by '_'
+// (log-level is LOG_LEVEL). The package uses the standard library only.
+package kube
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Dir is a mounted ConfigMap, Secret or Downward API volume: each file is a
+// variable named after the file, holding its contents. The Downward API
+// files labels and annotations, whose lines read key="value", also yield one
+// variable per entry, e.g. LABELS_APP for the label app.
+type Dir struct {
+	// Path is the mount point.
+	Path string
+	// Prefix is prepended to each name, e.g. "POD_" so the Downward API
+	// file namespace is POD_NAMESPACE.
+	Prefix string
+}
+
+// Vars reads the directory. The kubelet updates a mounted volume in place
+// (ConfigMap changes reach the pod after its sync period), and Vars always
+// returns the current contents. Files in subdirectories, and the kubelet's
+// own ..data entries, are skipped.
+func (d Dir) Vars() (map[string]string, error) {
+	entries, err := os.ReadDir(d.Path)
+	if err != nil {
+		return nil, err
+	}
+	vars := map[string]string{}
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "..") {
+			continue
+		}
+		name := filepath.Join(d.Path, e.Name())
+		// Keys are symlinks into ..data, so stat the target
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		key := d.Prefix + EnvName(e.Name())
+		vars[key] = string(data)
+		if e.Name() == "labels" || e.Name() == "annotations" {
+			for k, v := range parseMetadata(string(data)) {
+				vars[key+"_"+EnvName(k)] = v
+			}
+		}
+	}
+	return vars, nil
+}
+
+// Lookup returns the variable key of d, reading the directory on every call
+// so values follow the mounted volume; it has the signature of
+// antconfig.AntConfig.SetLookupEnv. A missing or unreadable directory holds
+// no variables.
+func (d Dir) Lookup(key string) (string, bool) {
+	vars, err := d.Vars()
+	if err != nil {
+		return "", false
+	}
+	v, ok := vars[key]
+	return v, ok
+}
+
+// Chain returns a lookup asking each of lookups in turn; the first one that
+// has the variable wins.
+func Chain(lookups ...func(string) (string, bool)) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		for _, lookup := range lookups {
+			if v, ok := lookup(key); ok {
+				return v, true
+			}
+		}
+		return "", false
+	}
+}
+
+// EnvName turns a ConfigMap key or file name into an env name: upper case,
+// with every character other than an ASCII letter or digit replaced by '_'.
+func EnvName(key string) string {
+	b := []byte(key)
+	for i, c := range b {
+		switch {
+		case c >= 'a' && c <= 'z':
+			b[i] = c - 'a' + 'A'
+		case c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		default:
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// parseMetadata parses the key="value" lines of the Downward API labels and
+// annotations files; lines of another form are skipped.
+func parseMetadata(s string) map[string]string {
+	out := map[string]string{}
+	sc := bufio.NewScanner(strings.NewReader(s))
+	for sc.Scan() {
+		k, v, ok := strings.Cut(sc.Text(), "=")
+		if !ok {
+			continue
+		}
+		if uq, err := strconv.Unquote(v); err == nil {
+			out[k] = uq
+		}
+	}
+	return out
+}
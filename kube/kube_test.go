@@ -0,0 +1,152 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mountVolume lays files out the way the kubelet does: the data lives in a
+// timestamped directory behind ..data, and each key is a symlink into it.
+func mountVolume(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	data := filepath.Join(dir, "..2026_10_14_12_00_00.000000001")
+	if err := os.Mkdir(data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(data, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Base(data), filepath.Join(dir, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestDir(t *testing.T) {
+	cfgDir := mountVolume(t, map[string]string{"log-level": "debug", "DB_HOST": "db.internal"})
+	podDir := mountVolume(t, map[string]string{
+		"namespace": "prod",
+		"labels":    "app=\"web\"\npod-template-hash=\"5d8f\"\n",
+	})
+
+	vars, err := Dir{Path: cfgDir}.Vars()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vars) != 2 || vars["LOG_LEVEL"] != "debug" || vars["DB_HOST"] != "db.internal" {
+		t.Fatalf("vars = %v", vars)
+	}
+
+	pod := Dir{Path: podDir, Prefix: "POD_"}
+	for key, want := range map[string]string{
+		"POD_NAMESPACE":                "prod",
+		"POD_LABELS_APP":               "web",
+		"POD_LABELS_POD_TEMPLATE_HASH": "5d8f",
+	} {
+		if got, ok := pod.Lookup(key); !ok || got != want {
+			t.Errorf("Lookup(%s) = %q, %v; want %q", key, got, ok, want)
+		}
+	}
+
+	lookup := Chain(func(key string) (string, bool) {
+		if key == "DB_HOST" {
+			return "override", true
+		}
+		return "", false
+	}, Dir{Path: cfgDir}.Lookup, Dir{Path: filepath.Join(cfgDir, "missing")}.Lookup)
+	if v, _ := lookup("DB_HOST"); v != "override" {
+		t.Errorf("DB_HOST = %q, the first lookup should win", v)
+	}
+	if v, _ := lookup("LOG_LEVEL"); v != "debug" {
+		t.Errorf("LOG_LEVEL = %q", v)
+	}
+	if _, ok := lookup("NOPE"); ok {
+		t.Error("NOPE should not be found")
+	}
+}
+
+func TestConfigMapWatch(t *testing.T) {
+	var watches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/api/v1/namespaces/prod/configmaps/app-config":
+			w.Write([]byte(`{"metadata": {"resourceVersion": "1"}, "data": {"log-level": "info"}}`))
+		case r.URL.Path == "/api/v1/namespaces/prod/configmaps" && r.URL.Query().Get("watch") == "true":
+			if r.URL.Query().Get("fieldSelector") != "metadata.name=app-config" || r.URL.Query().Get("resourceVersion") != "1" || watches.Add(1) > 1 {
+				http.Error(w, "unexpected watch: "+r.URL.RawQuery, http.StatusBadRequest)
+				return
+			}
+			enc := json.NewEncoder(w)
+			enc.Encode(map[string]any{"type": "BOOKMARK", "object": map[string]any{"metadata": map[string]any{"resourceVersion": "1"}}})
+			enc.Encode(map[string]any{"type": "MODIFIED", "object": map[string]any{
+				"metadata": map[string]any{"resourceVersion": "2"},
+				"data":     map[string]any{"log-level": "debug"},
+			}})
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	cm := &ConfigMap{
+		Name:      "app-config",
+		Namespace: "prod",
+		Host:      srv.URL,
+		Client:    srv.Client(),
+		Token:     func(context.Context) (string, error) { return "tok", nil },
+	}
+	if err := cm.Fetch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := cm.Lookup("LOG_LEVEL"); !ok || v != "info" {
+		t.Fatalf("LOG_LEVEL = %q, %v", v, ok)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := make(chan struct{}, 4)
+	done := make(chan error, 1)
+	go func() { done <- cm.Watch(ctx, func() { changed <- struct{}{} }) }()
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+	if v, _ := cm.Lookup("LOG_LEVEL"); v != "debug" {
+		t.Fatalf("LOG_LEVEL = %q after the watch event", v)
+	}
+	if d := cm.Data(); d["log-level"] != "debug" {
+		t.Fatalf("Data = %v", d)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("Watch returned %v", err)
+	}
+	if len(changed) != 0 {
+		t.Fatal("the bookmark should not count as a change")
+	}
+
+	cm.Name = "other"
+	if err := cm.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("err = %v", err)
+	}
+}
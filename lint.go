@@ -0,0 +1,104 @@
+package antconfig
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrUnexportedTag is wrapped by the *FieldError of each tag antconfig cannot
+// act on because its field is unexported, under SetTagLint(LintError).
+var ErrUnexportedTag = errors.New("tag on unexported field")
+
+// LintLevel selects how WriteConfigValues treats config tags on fields it
+// cannot set (SetTagLint).
+type LintLevel int
+
+const (
+	// LintOff silently skips unexported fields, like encoding/json.
+	LintOff LintLevel = iota
+	// LintWarn reports each such tag as a WarningUnexportedTag.
+	LintWarn
+	// LintError fails the load with a *MultiError before any layer is
+	// applied.
+	LintError
+)
+
+// lintedTags are the tags that have no effect on an unexported field.
+var lintedTags = []string{"default", "env", "flag", "envalias", "alias", "required", "secret", "desc", "layout", "validate", "from", "group", "removed_in", "resolve", "loadfile", "encoding", "feature"}
+
+// SetTagLint checks the registered struct on each load for config tags on
+// unexported fields and on fields nested under an unexported struct field.
+// Such tags, as in an unexported host field tagged `env:"HOST"`, are
+// otherwise skipped without notice.
+func (a *AntConfig) SetTagLint(l LintLevel) error {
+	if l < LintOff || l > LintError {
+		return fmt.Errorf("SetTagLint: unknown level %d", l)
+	}
+	a.tagLint = l
+	return nil
+}
+
+// lintTags applies the SetTagLint level to struct type t.
+func (a *AntConfig) lintTags(run *loadRun, t reflect.Type) error {
+	if a.tagLint == LintOff {
+		return nil
+	}
+	var errs []*FieldError
+	for _, u := range unexportedTags(t, "", "", map[reflect.Type]bool{}) {
+		if a.tagLint == LintWarn {
+			run.warn(WarningUnexportedTag, u.path, "", fmt.Sprintf("field %s: tag %s is ignored because %s is unexported", u.path, u.tag, u.via))
+			continue
+		}
+		errs = append(errs, &FieldError{Path: u.path, Err: fmt.Errorf("%w: %s is ignored because %s is unexported", ErrUnexportedTag, u.tag, u.via)})
+	}
+	if len(errs) > 0 {
+		return &MultiError{Errors: errs}
+	}
+	return nil
+}
+
+// unexportedTag is a config tag that is skipped: tag is the first linted tag
+// of the field at path, and via the unexported field on that path.
+type unexportedTag struct {
+	path, tag, via string
+}
+
+// unexportedTags walks struct type t under the Go field path prefix. via is
+// the unexported field already on the path, if any; active guards against
+// recursive types.
+func unexportedTags(t reflect.Type, prefix, via string, active map[reflect.Type]bool) []unexportedTag {
+	var found []unexportedTag
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if isIgnored(f) {
+			continue
+		}
+		path := f.Name
+		if prefix != "" {
+			path = prefix + "." + f.Name
+		}
+		fieldVia := via
+		if fieldVia == "" && !f.IsExported() {
+			fieldVia = path
+		}
+		if fieldVia != "" {
+			for _, name := range lintedTags {
+				if v, ok := f.Tag.Lookup(name); ok {
+					found = append(found, unexportedTag{path: path, tag: fmt.Sprintf("%s:%q", name, v), via: fieldVia})
+					break
+				}
+			}
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct && !isOpaqueStruct(ft) && !active[ft] {
+			active[ft] = true
+			found = append(found, unexportedTags(ft, path, fieldVia, active)...)
+			delete(active, ft)
+		}
+	}
+	return found
+}
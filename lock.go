@@ -0,0 +1,68 @@
+package antconfig
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrSourcesLocked is returned by methods that change where configuration is
+// read from, or how it is read, after LockSources: SetEnvPath, AddEnvPath,
+// SetConfigPath, SetConfigPathOptional, SetDiscovery, DisableAutoDiscovery,
+// DisableConfigFile, DisableDotEnv, SetFS, SetEmbeddedConfig, SetEnvironment,
+// SetLookupEnv, SetSOPSKeyProvider, RequireSignature, EnableTemplating,
+// SetCondition, SetMode, SetArgFiles, SetFlagPrefix, SetCaseInsensitive,
+// AddSource, AddValues, RegisterFormat, RegisterMigration, RegisterParser,
+// RegisterResolver and EnableBuiltinResolvers. A locked AntConfig also fails
+// to load a "$sources" entry whose provider was registered with
+// RegisterProvider after the lock.
+//
+// SetFlagArgs and SetDotEnvExport stay callable: the first supplies the
+// values of the flag layer, like os.Args, and the second only decides where
+// .env values are written, not which are read.
+var ErrSourcesLocked = errors.New("configuration sources are locked")
+
+// LockSources freezes the set of files and sources, typically right after the
+// initial WriteConfigValues, so the configurator itself cannot drift at
+// runtime. Loading keeps working; only adding or changing paths and sources
+// fails with ErrSourcesLocked until UnlockForReload.
+func (a *AntConfig) LockSources() {
+	a.lockedProviders.Store(providerSeq.Load())
+	a.sourcesLocked.Store(true)
+}
+
+// UnlockForReload lifts LockSources for a deliberate change, e.g. by a file
+// watcher switching to a new config path, and returns a function that ends
+// the change:
+//
+//	relock := ac.UnlockForReload()
+//	defer relock()
+//
+// Unlocks nest: the sources stay unlocked until every relock function has
+// been called, and calling one again has no effect. An AntConfig that was
+// not locked stays unlocked.
+func (a *AntConfig) UnlockForReload() (relock func()) {
+	a.unlocks.Add(1)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			if a.unlocks.Add(-1) == 0 {
+				// Providers registered during the change are accepted
+				a.lockedProviders.Store(providerSeq.Load())
+			}
+		})
+	}
+}
+
+// SourcesLocked reports whether LockSources is in effect and not lifted by
+// UnlockForReload.
+func (a *AntConfig) SourcesLocked() bool {
+	return a.sourcesLocked.Load() && a.unlocks.Load() == 0
+}
+
+func (a *AntConfig) checkUnlocked(op string) error {
+	if a.SourcesLocked() {
+		return fmt.Errorf("%w: %s", ErrSourcesLocked, op)
+	}
+	return nil
+}
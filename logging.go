@@ -0,0 +1,140 @@
+package antconfig
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// LogEffective logs the effective configuration to logger, one Info record
+// "config" per leaf field with the attributes path (the dotted Go path),
+// value and source (the provenance Layer, "unset" for fields no layer set).
+// Non-empty `secret:"true"` values are replaced by Redacted. Fields under a
+// nil section pointer are skipped. Call it after loading, for the same
+// startup banner in every service:
+//
+//	ac.LogEffective(slog.Default())
+//	// level=INFO msg=config path=Database.Host value=db.internal source=env
+func (a *AntConfig) LogEffective(logger *slog.Logger) error {
+	if a.cfgRef == nil {
+		return fmt.Errorf("LogEffective requires SetConfig to be called first")
+	}
+	ctx := context.Background()
+	st := a.state()
+	for _, f := range a.Describe().Fields {
+		v, _, ok := a.lookupField(st.config, f.Path)
+		if !ok {
+			continue
+		}
+		var value any = Redacted
+		if !f.Secret || v.IsZero() {
+			value = logValue(v)
+		}
+		source := "unset"
+		if layer, ok := st.provenance[f.Path]; ok {
+			source = string(layer)
+		}
+		logger.LogAttrs(ctx, slog.LevelInfo, "config",
+			slog.String("path", f.Path), slog.Any("value", value), slog.String("source", source))
+	}
+	return nil
+}
+
+// logValue returns the value of the field v for a log record, dereferencing
+// pointers.
+func logValue(v reflect.Value) any {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// SetSlog sends debug-level diagnostics of every load to l, to troubleshoot
+// where settings come from:
+//
+//	"config candidate"   a discovery locator tried a file name (locator,
+//	                     name, found or error)
+//	"config file"        the config file that is read (path, discovered)
+//	".env file"          a .env file that is read (path, discovered)
+//	"source"             a registered or declared source was applied (name,
+//	                     priority)
+//	"value overridden"   a layer replaced the value of a field set by a lower
+//	                     one (path, layer, previous)
+//	"config migrated"    a migration ran (from, to)
+//	"persisted"          Persist saved a field (path, file)
+//	"source cache ..."   the source cache could not be read or saved, or
+//	                     held values older than the stale TTL
+//	"poll failed"        a StartPolling reload failed (error)
+//	"signalled reload failed"
+//	                     a ReloadOnSignal reload failed (signal, error)
+//
+// Values are not logged, so secrets stay out of the log. Unlike
+// SetDebugLogger it applies to this AntConfig only, which then no longer
+// writes to the SetDebugLogger logger; nil turns it off.
+func (a *AntConfig) SetSlog(l *slog.Logger) {
+	a.slog = l
+}
+
+// debugEnabled reports whether diagnostics are wanted, by SetSlog or else by
+// SetDebugLogger.
+func (a *AntConfig) debugEnabled() bool {
+	if a.slog != nil {
+		return a.slog.Enabled(context.Background(), slog.LevelDebug)
+	}
+	return currentDebugLogger() != nil
+}
+
+// logDebug emits a diagnostic to the SetSlog logger, or to the package's
+// SetDebugLogger when this AntConfig has none.
+func (a *AntConfig) logDebug(msg string, attrs ...slog.Attr) {
+	if a.slog != nil {
+		if a.debugEnabled() {
+			a.slog.LogAttrs(context.Background(), slog.LevelDebug, msg, attrs...)
+		}
+		return
+	}
+	if l := currentDebugLogger(); l != nil {
+		var b strings.Builder
+		for _, attr := range attrs {
+			b.WriteString(" " + attr.String())
+		}
+		l.Printf("antconfig: %s%s", msg, b.String())
+	}
+}
+
+// logSource logs the application of the source ps.
+func (a *AntConfig) logSource(ps prioritizedSource) {
+	a.logDebug("source", slog.String("name", ps.source.Name()), slog.Int("priority", int(ps.priority)))
+}
+
+// overrideLogger returns a function that logs, on each call, the fields
+// whose provenance changed to another layer since the previous call. It is a
+// no-op without SetSlog diagnostics.
+func (a *AntConfig) overrideLogger(run *loadRun) func() {
+	if !a.debugEnabled() {
+		return func() {}
+	}
+	seen := map[string]Layer{}
+	return func() {
+		var changed []string
+		for path, layer := range run.provenance {
+			if prev, ok := seen[path]; ok && prev != layer {
+				changed = append(changed, path)
+			}
+		}
+		sort.Strings(changed)
+		for _, path := range changed {
+			a.logDebug("value overridden", slog.String("path", path),
+				slog.String("layer", string(run.provenance[path])), slog.String("previous", string(seen[path])))
+		}
+		for path, layer := range run.provenance {
+			seen[path] = layer
+		}
+	}
+}
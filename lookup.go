@@ -0,0 +1,83 @@
+package antconfig
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Lookup returns the current value of the loaded config at key, a dotted
+// path of Go field or json names matched case-insensitively
+// ("database.host"). Struct-valued keys return the struct itself. It reports
+// false if no field matches or a nil pointer lies on the path. With
+// EnableAccessAudit, the read is recorded.
+func (a *AntConfig) Lookup(key string) (any, bool) {
+	v, path, ok := a.lookupField(a.current(), key)
+	if !ok {
+		return nil, false
+	}
+	a.recordRead(path)
+	return v.Interface(), true
+}
+
+// IsSet reports whether any layer set key, or, for struct-valued keys, any
+// field beneath it, during the most recent WriteConfigValues.
+func (a *AntConfig) IsSet(key string) bool {
+	st := a.state()
+	_, path, ok := a.lookupField(st.config, key)
+	if !ok {
+		return false
+	}
+	for p := range st.provenance {
+		if p == path || strings.HasPrefix(p, path+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// WasSet is like IsSet but ignores the `default` tags: it reports whether key
+// was explicitly provided by the embedded config, config file, .env, env,
+// flags, a source or the secret prompt during the most recent
+// WriteConfigValues. Frameworks use it to apply their own fallbacks only to
+// settings the user left alone.
+func (a *AntConfig) WasSet(key string) bool {
+	st := a.state()
+	_, path, ok := a.lookupField(st.config, key)
+	return ok && setAbove(st.provenance, path, LayerDefault)
+}
+
+// lookupField resolves key against the loaded config cfg without allocating
+// nil pointers, returning the field and its dotted Go path.
+func (a *AntConfig) lookupField(cfg any, key string) (reflect.Value, string, bool) {
+	if cfg == nil || key == "" {
+		return reflect.Value{}, "", false
+	}
+	a.checkFrozen()
+	v := reflect.ValueOf(cfg).Elem()
+	var path string
+	for _, seg := range strings.Split(key, ".") {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, "", false
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}, "", false
+		}
+		idx, ok := fieldIndexByName(v.Type(), seg)
+		if !ok {
+			return reflect.Value{}, "", false
+		}
+		fv, err := v.FieldByIndexErr(idx)
+		if err != nil {
+			return reflect.Value{}, "", false
+		}
+		if path != "" {
+			path += "."
+		}
+		path += v.Type().FieldByIndex(idx).Name
+		v = fv
+	}
+	return v, path, true
+}
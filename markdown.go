@@ -0,0 +1,87 @@
+package antconfig
+
+import (
+	"io"
+	"reflect"
+	"strings"
+)
+
+// MarkdownDoc returns WriteMarkdownDoc's output as a string.
+func (a *AntConfig) MarkdownDoc() string {
+	var b strings.Builder
+	_ = a.WriteMarkdownDoc(&b)
+	return b.String()
+}
+
+// WriteMarkdownDoc writes a Markdown table documenting every config field:
+// config file key, Go type, default, env var, flag, description and whether
+// it is required. It is generated from the same struct tags the loader uses,
+// so it can be committed to docs or printed for a --help-markdown flag.
+// Requires SetConfig to have been called; otherwise nothing is written.
+func (a *AntConfig) WriteMarkdownDoc(w io.Writer) error {
+	if a.cfgRef == nil {
+		return nil
+	}
+	fields, err := findFieldsWithTag("", reflect.New(reflect.TypeOf(a.cfgRef).Elem()).Interface())
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	b.WriteString("| Key | Type | Default | Env | Flag | Description | Required |\n")
+	b.WriteString("|-----|------|---------|-----|------|-------------|----------|\n")
+	for _, f := range fields {
+		t := f.fieldValue.Type()
+		if _, nested := nestedStruct(t); nested {
+			continue
+		}
+		_, desc := unquoteUsage(f.tags["desc"], t)
+		if v := f.tags["removed_in"]; v != "" {
+			desc = strings.TrimSpace(desc + " Deprecated; removed in " + v + ".")
+		}
+		env := codeCell(f.tags["env"])
+		for _, old := range aliasNames(f.tags["envalias"]) {
+			env += " (was " + codeCell(old) + ")"
+		}
+		flagName := ""
+		if name := f.tags["flag"]; name != "" {
+			flagName = "--" + a.flagPrefix + name
+		}
+		required := ""
+		if isRequired(f.tags["required"]) {
+			required = "yes"
+		}
+		cells := []string{
+			codeCell(strings.Join(f.jsonPath, ".")),
+			codeCell(t.String()),
+			codeCell(f.tags["default"]),
+			env,
+			codeCell(flagName),
+			markdownEscape(desc),
+			required,
+		}
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// codeCell formats s as inline code for a table cell; empty stays empty.
+func codeCell(s string) string {
+	if s == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(s, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return fence + strings.ReplaceAll(s, "|", `\|`) + fence
+}
+
+// markdownEscape makes s safe inside a table cell.
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,55 @@
+package antconfig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// MatrixResult is one fully resolved configuration produced by GenerateEnvMatrix.
+type MatrixResult struct {
+	// Overrides are the environment overrides this configuration was built from.
+	Overrides map[string]string
+	// Config is a pointer to a fresh value of the registered config type.
+	Config any
+	// Provenance maps each set field path to the layer that supplied it.
+	Provenance map[string]Layer
+}
+
+// GenerateEnvMatrix resolves one configuration per entry in overrides, using
+// the same defaults, config file, .env, env, and flag layering as
+// WriteConfigValues. Each entry's variables take precedence over the real OS
+// environment for that configuration only; the process environment and the
+// struct registered via SetConfig are left untouched. This lets table-driven
+// integration tests sweep configurations through the library's own merge logic.
+func (a *AntConfig) GenerateEnvMatrix(overrides []map[string]string) ([]MatrixResult, error) {
+	if a.cfgRef == nil {
+		return nil, fmt.Errorf("GenerateEnvMatrix requires SetConfig to be called first")
+	}
+	typ := reflect.TypeOf(a.cfgRef).Elem()
+	results := make([]MatrixResult, 0, len(overrides))
+	for i, env := range overrides {
+		run := &loadRun{
+			target:   reflect.New(typ).Interface(),
+			lookupOS: overlayLookup(env, a.osLookup()),
+		}
+		if err := a.load(run); err != nil {
+			return nil, fmt.Errorf("matrix entry %d: %w", i, err)
+		}
+		results = append(results, MatrixResult{
+			Overrides:  env,
+			Config:     run.target,
+			Provenance: run.provenance,
+		})
+	}
+	return results, nil
+}
+
+// overlayLookup returns an environment lookup that consults vars before base.
+func overlayLookup(vars map[string]string, base func(string) (string, bool)) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		if v, ok := vars[key]; ok {
+			return v, true
+		}
+		return base(key)
+	}
+}
@@ -0,0 +1,109 @@
+package antconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strconv"
+)
+
+// versionKey is the top-level config file key holding the schema version.
+const versionKey = "version"
+
+// RegisterMigration registers fn to upgrade config files from schema version
+// from to version to. Before a file is decoded, its top-level "version" key
+// selects the first migration; migrations are chained (1→2, 2→3, …) until no
+// migration starts at the current version, and "version" is updated after
+// each step. fn edits the generic JSON document in place; numbers appear as
+// json.Number. Files without a "version" key are not migrated. Only one
+// migration may start at a given version, and to must be greater than from.
+func (a *AntConfig) RegisterMigration(from, to int, fn func(doc map[string]any) error) error {
+	if err := a.checkUnlocked("RegisterMigration"); err != nil {
+		return err
+	}
+	if fn == nil {
+		return fmt.Errorf("RegisterMigration requires a migration function")
+	}
+	if to <= from {
+		return fmt.Errorf("RegisterMigration: target version %d must be greater than %d", to, from)
+	}
+	if _, dup := a.migrations[from]; dup {
+		return fmt.Errorf("RegisterMigration: a migration from version %d is already registered", from)
+	}
+	if a.migrations == nil {
+		a.migrations = map[int]migration{}
+	}
+	a.migrations[from] = migration{to: to, fn: fn}
+	return nil
+}
+
+// migration is a registered RegisterMigration step.
+type migration struct {
+	to int
+	fn func(doc map[string]any) error
+}
+
+// migrateConfig applies the registered migrations to a JSON config document,
+// returning it unchanged when none apply.
+func (a *AntConfig) migrateConfig(js []byte) ([]byte, error) {
+	if len(a.migrations) == 0 {
+		return js, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		// Let the regular decoding report malformed documents
+		return js, nil
+	}
+	raw, ok := doc[versionKey]
+	if !ok {
+		return js, nil
+	}
+	version, err := strconv.Atoi(fmt.Sprint(raw))
+	if err != nil {
+		return nil, fmt.Errorf("config %s %v is not an integer", versionKey, raw)
+	}
+	migrated := false
+	for {
+		m, ok := a.migrations[version]
+		if !ok {
+			break
+		}
+		if err := m.fn(doc); err != nil {
+			return nil, fmt.Errorf("migrating config from version %d to %d: %w", version, m.to, err)
+		}
+		a.logDebug("config migrated", slog.Int("from", version), slog.Int("to", m.to))
+		version = m.to
+		doc[versionKey] = json.Number(strconv.Itoa(version))
+		migrated = true
+	}
+	if !migrated {
+		return js, nil
+	}
+	return json.Marshal(doc)
+}
+
+// unversionedDoc returns doc without its top-level "version" key when
+// migrations are registered and struct type t has no field for it, so the
+// key is not reported as unknown.
+func (a *AntConfig) unversionedDoc(doc map[string]any, t reflect.Type) map[string]any {
+	if len(a.migrations) == 0 {
+		return doc
+	}
+	if _, ok := doc[versionKey]; !ok {
+		return doc
+	}
+	if _, _, ok := jsonField(t, versionKey, ""); ok {
+		return doc
+	}
+	rest := make(map[string]any, len(doc))
+	for k, v := range doc {
+		if k != versionKey {
+			rest[k] = v
+		}
+	}
+	return rest
+}
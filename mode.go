@@ -0,0 +1,51 @@
+package antconfig
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrStrictMode is returned by WriteConfigValues when StrictEnvOnly is in
+// effect and a config or .env file path was set.
+var ErrStrictMode = errors.New("config files are disabled in StrictEnvOnly mode")
+
+// Mode selects which built-in layers WriteConfigValues reads.
+type Mode int
+
+const (
+	// ModeDefault reads every layer: defaults, config file, .env, env and
+	// flags.
+	ModeDefault Mode = iota
+	// StrictEnvOnly enforces 12-factor deployments: configuration comes only
+	// from defaults, env vars and flags. Config file and .env discovery are
+	// disabled, and a path set with SetConfigPath, SetEnvPath or AddEnvPath
+	// makes WriteConfigValues fail with ErrStrictMode. SetEmbeddedConfig and
+	// registered sources still apply, as they are part of the binary.
+	StrictEnvOnly
+)
+
+// SetMode selects the layers WriteConfigValues reads; see Mode.
+func (a *AntConfig) SetMode(m Mode) error {
+	if err := a.checkUnlocked("SetMode"); err != nil {
+		return err
+	}
+	if m != ModeDefault && m != StrictEnvOnly {
+		return fmt.Errorf("SetMode: unknown mode %d", m)
+	}
+	a.mode = m
+	return nil
+}
+
+// checkMode reports file paths that the current mode forbids.
+func (a *AntConfig) checkMode() error {
+	if a.mode != StrictEnvOnly {
+		return nil
+	}
+	if a.configPath != "" {
+		return fmt.Errorf("%w: config file %s was set", ErrStrictMode, a.configPath)
+	}
+	if len(a.envPaths) > 0 {
+		return fmt.Errorf("%w: .env file %s was set", ErrStrictMode, a.envPaths[0])
+	}
+	return nil
+}
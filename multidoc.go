@@ -0,0 +1,105 @@
+package antconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/robfordww/antconfig/jsonc"
+)
+
+// jsoncToJSON converts a JSON or JSONC config file to a single JSON
+// document. A file may hold several documents, each an object, one after the
+// other or separated by lines consisting of "---"; they are deep-merged in
+// order, so later documents override keys of earlier ones and nested objects
+// are merged key by key. This lets one delivered artifact carry a base
+// config and its overrides. keep, if not nil, selects the documents to merge
+// (see SetCondition). A single document is returned as converted by ToJSON,
+// keeping its byte offsets.
+func jsoncToJSON(data []byte, keep func(doc map[string]any) (bool, error)) ([]byte, error) {
+	js := blankSeparators(ToJSON(data))
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	var merged map[string]any
+	for n := 1; ; n++ {
+		var doc any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) && n > 1 {
+			break
+		}
+		if err != nil && n == 1 {
+			// Let decoding report an empty or malformed config as usual
+			return js, nil
+		}
+		if err != nil {
+			var syntax *json.SyntaxError
+			if errors.As(err, &syntax) {
+				line, col := jsonc.Position(data, syntax.Offset-1)
+				return nil, fmt.Errorf("%w (line %d, column %d)", err, line, col)
+			}
+			return nil, err
+		}
+		if n == 1 && !dec.More() {
+			return js, nil
+		}
+		obj, ok := doc.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("document %d is not an object", n)
+		}
+		if keep != nil {
+			ok, err := keep(obj)
+			if err != nil {
+				return nil, fmt.Errorf("document %d: %w", n, err)
+			}
+			if !ok {
+				continue
+			}
+		}
+		merged = mergeDocument(merged, obj)
+	}
+	return json.Marshal(merged)
+}
+
+// blankSeparators replaces "---" separator lines with spaces. JSON strings
+// cannot span lines, so such a line is never part of a value.
+func blankSeparators(js []byte) []byte {
+	if !bytes.Contains(js, []byte("---")) {
+		return js
+	}
+	for start := 0; start < len(js); {
+		end := bytes.IndexByte(js[start:], '\n')
+		if end < 0 {
+			end = len(js)
+		} else {
+			end += start
+		}
+		if string(bytes.TrimSpace(js[start:end])) == "---" {
+			for i := start; i < end; i++ {
+				if js[i] != '\r' {
+					js[i] = ' '
+				}
+			}
+		}
+		start = end + 1
+	}
+	return js
+}
+
+// mergeDocument deep-merges src into dst and returns dst.
+func mergeDocument(dst, src map[string]any) map[string]any {
+	if dst == nil {
+		dst = map[string]any{}
+	}
+	for k, v := range src {
+		if sub, ok := v.(map[string]any); ok {
+			if cur, ok := dst[k].(map[string]any); ok {
+				dst[k] = mergeDocument(cur, sub)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+	return dst
+}
@@ -0,0 +1,57 @@
+package antconfig
+
+import (
+	"flag"
+	"strconv"
+	"strings"
+)
+
+// negatedBoolFlag reports whether arg (a flag argument without its leading
+// dashes, e.g. "no-encrypt" or "no-encrypt=true") negates one of boolFlags,
+// returning the flag name and the value to assign. A flag actually named
+// "no-…" is never treated as a negation.
+func negatedBoolFlag(arg string, boolFlags map[string]bool) (name, value string, ok bool) {
+	key, raw, hasValue := strings.Cut(arg, "=")
+	name, isNeg := strings.CutPrefix(key, "no-")
+	if !isNeg || boolFlags[key] || !boolFlags[name] {
+		return "", "", false
+	}
+	if !hasValue {
+		return name, "false", true
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		// Keep the raw text so the conversion error names the bad input
+		return name, raw, true
+	}
+	return name, strconv.FormatBool(!b), true
+}
+
+// negatedFlag is the flag.Value behind the --no-name form BindConfigFlags
+// registers for boolean fields; setting it sets the positive flag to the
+// opposite value, so the field sees an ordinary parsed flag.
+type negatedFlag struct {
+	fs   *flag.FlagSet
+	name string
+}
+
+func (n *negatedFlag) String() string   { return "" }
+func (n *negatedFlag) IsBoolFlag() bool { return true }
+
+func (n *negatedFlag) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	return n.fs.Set(n.name, strconv.FormatBool(!b))
+}
+
+// registerNegatedFlag defines --no-cli on fs for the boolean flag cli, unless
+// a flag of that name already exists.
+func registerNegatedFlag(fs *flag.FlagSet, cli string) {
+	neg := "no-" + cli
+	if fs.Lookup(neg) != nil {
+		return
+	}
+	fs.Var(&negatedFlag{fs: fs, name: cli}, neg, "sets -"+cli+"=false")
+}
@@ -0,0 +1,51 @@
+package antconfig
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ParseAndLoad is the one-call setup for programs whose only flags are the
+// config flags: it creates a FlagSet named after the program, binds the
+// config flags (BindConfigFlags), parses args, and runs WriteConfigValues,
+// returning the positional arguments left after the flags. A nil args means
+// os.Args[1:]; with SetArgFiles, "@path" arguments are expanded first.
+//
+// -h, -help and --help print the flag and environment variable help
+// (WriteFlagHelp, WriteEnvHelp) to stderr and return flag.ErrHelp, so callers
+// can exit with status 0. Parse errors are printed with the help as well and
+// returned.
+func (a *AntConfig) ParseAndLoad(args []string) ([]string, error) {
+	if args == nil && len(os.Args) > 0 {
+		args = os.Args[1:]
+	}
+	name := "program"
+	if len(os.Args) > 0 {
+		name = filepath.Base(os.Args[0])
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	if err := a.BindConfigFlags(fs); err != nil {
+		return nil, err
+	}
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintf(out, "Usage of %s:\n", name)
+		_ = a.WriteFlagHelp(out)
+		if env := a.EnvHelpString(); env != "" {
+			fmt.Fprintf(out, "\n%s", env)
+		}
+	}
+	args, err := a.expandArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := a.WriteConfigValues(); err != nil {
+		return nil, err
+	}
+	return fs.Args(), nil
+}
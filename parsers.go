@@ -0,0 +1,122 @@
+package antconfig
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// typeParser converts a string into a value of the type it is registered for.
+type typeParser func(string) (reflect.Value, error)
+
+// typeParsers maps exact field types to their parsers.
+type typeParsers map[reflect.Type]typeParser
+
+// globalParsers holds the process-wide parsers registered via the
+// RegisterParser function.
+var globalParsers sync.Map // reflect.Type -> typeParser
+
+// RegisterParser registers parse as the process-wide conversion from strings
+// (defaults, env values, flags, string values from sources) to fields of type
+// T. It takes precedence over encoding.TextUnmarshaler, flag.Value and the
+// built-in kind conversions, so it can also override how e.g. a named int enum
+// is parsed. Registering the same type again replaces the previous parser.
+// Parsers registered on an AntConfig via its RegisterParser method win over
+// process-wide ones.
+func RegisterParser[T any](parse func(string) (T, error)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	globalParsers.Store(t, typeParser(func(s string) (reflect.Value, error) {
+		v, err := parse(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return reflect.ValueOf(&v).Elem(), nil
+	}))
+}
+
+// RegisterParser teaches this AntConfig how to parse strings into fields of
+// type t, uniformly across defaults, config-file strings from sources, .env,
+// env, and flags, e.g. a ByteSize from "512MB". parse must return a value
+// assignable to t. Register parsers before SetConfig so default validation
+// uses them; if a config is already registered its defaults are re-validated
+// and any malformed ones are returned.
+func (a *AntConfig) RegisterParser(t reflect.Type, parse func(string) (any, error)) error {
+	if t == nil || parse == nil {
+		return fmt.Errorf("RegisterParser requires a non-nil type and parse function")
+	}
+	if err := a.checkUnlocked("RegisterParser"); err != nil {
+		return err
+	}
+	if a.parsers == nil {
+		a.parsers = typeParsers{}
+	}
+	a.parsers[t] = func(s string) (reflect.Value, error) {
+		v, err := parse(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if v == nil {
+			return reflect.Zero(t), nil
+		}
+		rv := reflect.ValueOf(v)
+		if !rv.Type().AssignableTo(t) {
+			return reflect.Value{}, fmt.Errorf("parser for %s returned %s", t, rv.Type())
+		}
+		return rv, nil
+	}
+	if a.cfgRef != nil {
+		return validateDefaults(reflect.TypeOf(a.cfgRef).Elem(), a.parsers)
+	}
+	return nil
+}
+
+// lookupParser returns the parser for t, preferring per-instance parsers over the
+// process-wide registry.
+func lookupParser(t reflect.Type, parsers typeParsers) (typeParser, bool) {
+	if p, ok := parsers[t]; ok {
+		return p, true
+	}
+	if p, ok := globalParsers.Load(t); ok {
+		return p.(typeParser), true
+	}
+	return nil, false
+}
+
+// setFromTextParser converts s for fields whose type knows how to parse
+// itself: a registered parser, encoding.TextUnmarshaler (e.g. time.Time,
+// net.IP), flag.Value (custom enums), or time.Duration. It reports
+// handled=false for types left to the kind-based conversion.
+func setFromTextParser(fieldVal reflect.Value, s string, parsers typeParsers) (handled bool, err error) {
+	t := fieldVal.Type()
+	if p, ok := lookupParser(t, parsers); ok {
+		v, err := p(s)
+		if err != nil {
+			return true, err
+		}
+		fieldVal.Set(v)
+		return true, nil
+	}
+	if fieldVal.CanAddr() {
+		switch target := fieldVal.Addr().Interface().(type) {
+		case encoding.TextUnmarshaler:
+			return true, target.UnmarshalText([]byte(s))
+		case flag.Value:
+			return true, target.Set(s)
+		}
+	}
+	if t == urlType {
+		return true, parseURL(fieldVal, s)
+	}
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return true, err
+		}
+		fieldVal.SetInt(int64(d))
+		return true, nil
+	}
+	return false, nil
+}
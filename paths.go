@@ -0,0 +1,167 @@
+package antconfig
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// validatePathTags checks the `resolve` tags of struct type t, whose only
+// mode is "path" on string, *string or []string fields, and its `loadfile`
+// tags, "true" or "trim" on string or *string fields.
+func validatePathTags(t reflect.Type) error {
+	fields, err := findFieldsWithTag("resolve", reflect.New(t).Interface())
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, f := range fields {
+		if f.tagvalue != "path" {
+			errs = append(errs, fmt.Errorf("field %s: unknown resolve mode %q (want \"path\")", f.path, f.tagvalue))
+			continue
+		}
+		if !isStringsField(f.fieldValue.Type()) {
+			errs = append(errs, fmt.Errorf("field %s: resolve:%q needs a string, *string or []string field, not %s", f.path, f.tagvalue, f.fieldValue.Type()))
+		}
+	}
+	loadFields, err := findFieldsWithTag("loadfile", reflect.New(t).Interface())
+	if err != nil {
+		return err
+	}
+	for _, f := range loadFields {
+		if f.tagvalue != "true" && f.tagvalue != "trim" {
+			errs = append(errs, fmt.Errorf("field %s: unknown loadfile mode %q (want \"true\" or \"trim\")", f.path, f.tagvalue))
+			continue
+		}
+		if k := f.fieldValue.Type(); k.Kind() != reflect.String && !(k.Kind() == reflect.Ptr && k.Elem().Kind() == reflect.String) {
+			errs = append(errs, fmt.Errorf("field %s: loadfile:%q needs a string or *string field, not %s", f.path, f.tagvalue, k))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isStringsField reports whether t is a string kind, a pointer to one, or a
+// slice of them.
+func isStringsField(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.String
+}
+
+// resolvePaths makes the relative values of the `resolve:"path"` fields set
+// during run absolute; see pathResolver.
+func (a *AntConfig) resolvePaths(run *loadRun) []*FieldError {
+	var errs []*FieldError
+	for _, f := range run.plan.withTag("resolve") {
+		layer, ok := run.provenance[f.path]
+		if !ok {
+			continue
+		}
+		if err := mapStrings(f, a.pathResolver(run, layer)); err != nil {
+			errs = append(errs, &FieldError{Path: f.path, Source: layer, Err: fmt.Errorf("could not resolve path: %w", err)})
+		}
+	}
+	return errs
+}
+
+// pathResolver returns the function resolving relative paths supplied by
+// layer. Values from the config file and the defaults are relative to the
+// config file's directory, as users expect of entries such as a certificate
+// stored next to the config; values from the other layers, and defaults when
+// no config file was read, are relative to the working directory. With
+// SetFS, paths stay names in the file system, joined to the config file's
+// directory.
+func (a *AntConfig) pathResolver(run *loadRun, layer Layer) func(string) (string, error) {
+	dir := ""
+	if (layer == LayerFile || layer == LayerDefault) && run.report.ConfigFile != "" {
+		dir = filepath.Dir(run.report.ConfigFile)
+		if a.fsys != nil {
+			dir = path.Dir(run.report.ConfigFile)
+		}
+	}
+	return func(p string) (string, error) {
+		switch {
+		case p == "" || filepath.IsAbs(p):
+			return p, nil
+		case a.fsys != nil:
+			return path.Join(dir, p), nil
+		case dir != "":
+			p = filepath.Join(dir, p)
+		}
+		return filepath.Abs(p)
+	}
+}
+
+// loadFiles replaces the value of each `loadfile` field set during run, a
+// path resolved like `resolve:"path"` values, with the contents of that
+// file; `loadfile:"trim"` also strips surrounding whitespace such as the
+// trailing newline of a token file. Files are read through SetFS when set.
+func (a *AntConfig) loadFiles(run *loadRun) []*FieldError {
+	var errs []*FieldError
+	for _, f := range run.plan.withTag("loadfile") {
+		layer, ok := run.provenance[f.path]
+		if !ok {
+			continue
+		}
+		resolve := a.pathResolver(run, layer)
+		err := mapStrings(f, func(p string) (string, error) {
+			if p == "" {
+				return "", nil
+			}
+			name, err := resolve(p)
+			if err != nil {
+				return "", err
+			}
+			data, err := a.files().ReadFile(name)
+			if err != nil {
+				return "", err
+			}
+			if f.tagvalue == "trim" {
+				return strings.TrimSpace(string(data)), nil
+			}
+			return string(data), nil
+		})
+		if err != nil {
+			errs = append(errs, &FieldError{Path: f.path, Source: layer, Err: fmt.Errorf("could not load file: %w", err)})
+		}
+	}
+	return errs
+}
+
+// mapStrings replaces each string held by the string, *string or []string
+// field f with fn's result.
+func mapStrings(f fieldWithTagValue, fn func(string) (string, error)) error {
+	v := f.current()
+	switch {
+	case v.Kind() == reflect.String:
+		s, err := fn(v.String())
+		if err != nil {
+			return err
+		}
+		f.target().SetString(s)
+	case v.Kind() == reflect.Ptr && !v.IsNil():
+		s, err := fn(v.Elem().String())
+		if err != nil {
+			return err
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().SetString(s)
+		f.target().Set(p)
+	case v.Kind() == reflect.Slice && !v.IsNil():
+		// A new slice, as the old one may be shared with a source's values
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s, err := fn(v.Index(i).String())
+			if err != nil {
+				return err
+			}
+			out.Index(i).SetString(s)
+		}
+		f.target().Set(out)
+	}
+	return nil
+}
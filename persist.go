@@ -0,0 +1,185 @@
+package antconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/robfordww/antconfig/jsonc"
+)
+
+// Persist sets the config file key of the field at fieldPath (a Go field
+// path such as "Server.Port", or its dotted config key) to value and writes
+// the config file back atomically, so apps can save settings changed at
+// runtime. The file is the SetConfigPath file, or the one discovered by the
+// last successful load. Only the edited value is rewritten: comments,
+// formatting and other keys are kept, and missing keys or objects are added.
+// value is converted like a Source value (strings are parsed as in env vars)
+// and must fit the field. Persist supports JSON and JSONC files that are
+// neither SOPS-encrypted nor signed (RequireSignature), and does not change
+// the loaded config; call WriteConfigValues to apply the new value, which
+// higher layers such as env vars may still override.
+func (a *AntConfig) Persist(fieldPath string, value any) error {
+	if a.cfgRef == nil {
+		return fmt.Errorf("Persist requires SetConfig to be called first")
+	}
+	path := a.configPath
+	if path == "" {
+		path = a.state().configFile
+	}
+	switch {
+	case path == "":
+		return fmt.Errorf("Persist requires a config file (SetConfigPath)")
+	case a.fsys != nil:
+		return fmt.Errorf("Persist cannot write to a SetFS file system")
+	case a.signingKey != nil:
+		return fmt.Errorf("Persist cannot rewrite the signed config file %s", path)
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".json" && ext != ".jsonc" {
+		return fmt.Errorf("Persist supports only JSON and JSONC config files, not %s", path)
+	}
+	fields, err := findFieldsWithTag("", reflect.New(reflect.TypeOf(a.cfgRef).Elem()).Interface())
+	if err != nil {
+		return fmt.Errorf("error collecting config fields: %v", err)
+	}
+	var field fieldWithTagValue
+	for _, f := range fields {
+		if f.path == fieldPath || (f.jsonPath != nil && strings.Join(f.jsonPath, ".") == fieldPath) {
+			field = f
+			break
+		}
+	}
+	if field.path == "" {
+		return fmt.Errorf("Persist: no config field matches %q", fieldPath)
+	}
+	if field.jsonPath == nil {
+		return fmt.Errorf("Persist: field %s cannot be set from a config file", field.path)
+	}
+	raw, err := a.persistedJSON(field, value)
+	if err != nil {
+		return err
+	}
+
+	a.persistMu.Lock()
+	defer a.persistMu.Unlock()
+	perm := fs.FileMode(0o644)
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		data = nil
+	case err != nil:
+		return fmt.Errorf("error reading config file %s: %w", path, err)
+	default:
+		if fi, err := os.Stat(path); err == nil {
+			perm = fi.Mode().Perm()
+		}
+	}
+	out, err := setJSONCValue(data, field.jsonPath, raw)
+	if err != nil {
+		return fmt.Errorf("Persist: config file %s: %w", path, err)
+	}
+	if err := writeFileAtomic(path, out, perm); err != nil {
+		return fmt.Errorf("error writing config file %s: %w", path, err)
+	}
+	a.logDebug("persisted", slog.String("path", field.path), slog.String("file", path))
+	return nil
+}
+
+// persistedJSON converts value to the type of field f and encodes it the way
+// the config file layer reads it back.
+func (a *AntConfig) persistedJSON(f fieldWithTagValue, value any) ([]byte, error) {
+	row := f
+	row.root, row.index = reflect.Value{}, nil
+	row.fieldValue = reflect.New(f.fieldValue.Type()).Elem()
+	ctx := "persisted value for " + f.path
+	if err := assignValue(row, value, ctx, a.parsers); err != nil {
+		return nil, err
+	}
+	v := row.fieldValue
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return []byte("null"), nil
+		}
+		v = v.Elem()
+	}
+	switch {
+	case f.tags["layout"] != "" && v.Type() == timeType:
+		return json.Marshal(v.Interface().(time.Time).Format(f.tags["layout"]))
+	case f.tag.Get("encoding") != "":
+		return json.Marshal(encodedValue(f.tag.Get("encoding"), v))
+	case v.Type() == urlType:
+		return json.Marshal(v.Addr().Interface().(fmt.Stringer).String())
+	}
+	return json.Marshal(v.Interface())
+}
+
+// setJSONCValue returns the JSONC document src with the value at the key
+// path keys set to raw, keeping comments and layout (see package jsonc).
+// Missing keys are inserted into the innermost existing object. An empty src
+// yields a new document.
+func setJSONCValue(src []byte, keys []string, raw []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(src)) == 0 {
+		src = []byte("{}\n")
+	}
+	doc, err := jsonc.Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	obj := doc.Root
+	if obj.Kind != jsonc.Object {
+		return nil, fmt.Errorf("top-level value is not an object")
+	}
+	if sops := obj.Get("sops"); sops != nil && sops.Kind == jsonc.Object {
+		return nil, fmt.Errorf("cannot edit a SOPS-encrypted file")
+	}
+	for i, key := range keys {
+		m := findJSONCMember(obj, key)
+		if m == nil || i == len(keys)-1 || m.Value.Kind != jsonc.Object {
+			v, err := jsonc.Parse(nestJSONValue(keys[i+1:], raw))
+			if err != nil {
+				return nil, err
+			}
+			if m == nil {
+				obj.Set(key, v.Root)
+			} else {
+				m.Value = v.Root
+			}
+			return doc.Serialize(), nil
+		}
+		obj = m.Value
+	}
+	return nil, fmt.Errorf("empty key path")
+}
+
+// findJSONCMember returns the member encoding/json would decode key from:
+// the last exact match, else the last case-insensitive one.
+func findJSONCMember(obj *jsonc.Node, key string) *jsonc.Member {
+	var fold *jsonc.Member
+	for i := len(obj.Members) - 1; i >= 0; i-- {
+		m := obj.Members[i]
+		if m.Key == key {
+			return m
+		}
+		if fold == nil && strings.EqualFold(m.Key, key) {
+			fold = m
+		}
+	}
+	return fold
+}
+
+// nestJSONValue wraps raw in one object per key, innermost last.
+func nestJSONValue(keys []string, raw []byte) []byte {
+	for i := len(keys) - 1; i >= 0; i-- {
+		k, _ := json.Marshal(keys[i])
+		raw = []byte("{" + string(k) + ": " + string(raw) + "}")
+	}
+	return raw
+}
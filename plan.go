@@ -0,0 +1,106 @@
+package antconfig
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// fieldPlan holds the tag metadata of every settable field of a config
+// struct, collected in a single traversal. A load builds one plan and every
+// layer (defaults, file, env, flags, checks) selects its fields from it.
+type fieldPlan struct {
+	fields []fieldWithTagValue
+	// shape is the cached metadata of the struct type.
+	shape *planShape
+}
+
+// planShape is what a plan learns from the struct type alone: its fields,
+// bound to no value, and the fields carrying each tag asked for so far.
+type planShape struct {
+	fields []fieldWithTagValue
+	// tagged maps a tag name to the []taggedField carrying it.
+	tagged sync.Map
+}
+
+// taggedField is the index of a plan field and its value for one tag.
+type taggedField struct {
+	i     int
+	value string
+}
+
+// planShapes caches a *planShape per config struct type. Which fields a
+// traversal finds depends on the type alone, so repeated loads reuse them and
+// only bind them to the value being loaded.
+var planShapes sync.Map
+
+// newFieldPlan records all fields of the struct pointed to by s, including
+// those of nested structs. The struct type is walked on first use only.
+func newFieldPlan(s any) (*fieldPlan, error) {
+	v := reflect.ValueOf(s)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		// Let findFieldsWithTag describe the problem
+		_, err := findFieldsWithTag("", s)
+		return nil, err
+	}
+	root := v.Elem()
+	cached, ok := planShapes.Load(root.Type())
+	if !ok {
+		fields, err := findFieldsWithTag("", reflect.New(root.Type()).Interface())
+		if err != nil {
+			return nil, err
+		}
+		for i := range fields {
+			fields[i].jsonPath = slices.Clip(fields[i].jsonPath)
+		}
+		cached, _ = planShapes.LoadOrStore(root.Type(), &planShape{fields: fields})
+	}
+	shape := cached.(*planShape)
+	fields := slices.Clone(shape.fields)
+	for i := range fields {
+		fields[i].root = root
+		fields[i].fieldValue = bindField(root, fields[i].index)
+	}
+	return &fieldPlan{fields: fields, shape: shape}, nil
+}
+
+// bindField returns the field of root at index, as findFieldsWithTag would:
+// under a nil struct pointer, the field of a detached instance.
+func bindField(root reflect.Value, index []int) reflect.Value {
+	v := root
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v = reflect.New(v.Type().Elem())
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// withTag returns the fields carrying a non-empty tagname tag, in traversal
+// order, with tagvalue set to that tag's value.
+func (p *fieldPlan) withTag(tagname string) []fieldWithTagValue {
+	var tagged []taggedField
+	if cached, ok := p.shape.tagged.Load(tagname); ok {
+		tagged = cached.([]taggedField)
+	} else {
+		for i, f := range p.fields {
+			if v := f.tag.Get(tagname); v != "" {
+				tagged = append(tagged, taggedField{i: i, value: v})
+			}
+		}
+		p.shape.tagged.Store(tagname, tagged)
+	}
+	if len(tagged) == 0 {
+		return nil
+	}
+	out := make([]fieldWithTagValue, len(tagged))
+	for j, t := range tagged {
+		out[j] = p.fields[t.i]
+		out[j].tagvalue = t.value
+	}
+	return out
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -19,13 +20,9 @@ type Config struct {
 }
 
 func main() {
-
-	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
-	// regular application flags
 	var cfg Config
 	ac := antconfig.New().MustSetConfig(&cfg)
-	ac.SetFlagPrefix("config-")
-	ac.MustBindConfigFlags(fs)
+	_ = ac.SetFlagPrefix("config-")
 	loc, err := antconfig.LocateFromExeUp("config_test.jsonc")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -33,35 +30,16 @@ func main() {
 	}
 	ac.SetConfigPath(loc)
 
-	fs.Bool("verbose", false, "Enable verbose output")
-	fs.Bool("v", false, "(alias for --verbose)")
-	fs.Bool("help", false, "Show help message")
-	fs.Bool("h", false, "(alias for --help)")
-
-	fs.Usage = func() { Usage(*fs, ac) }
-
-	fs.Parse(os.Args[1:])
-
-	if err := ac.WriteConfigValues(); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
-	}
-
-	// if help or h flag is set, print usage and exit
-	helpset := fs.Lookup("help").Value.String() == "true" ||
-		fs.Lookup("h").Value.String() == "true"
-	if helpset {
-		fs.Usage()
+	// Binds the config flags, handles -h/--help, parses and applies everything
+	args, err := ac.ParseAndLoad(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
 		os.Exit(0)
 	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(2)
+	}
 
 	fmt.Printf("Config: %#v\n %#v\n", cfg, cfg.SC)
-}
-
-func Usage(fs flag.FlagSet, ac *antconfig.AntConfig) {
-	fmt.Println("Usage: antapp [options]")
-	fmt.Println("Options:")
-	fs.PrintDefaults()
-	fmt.Println()
-	fmt.Print(ac.EnvHelpString())
+	fmt.Printf("Args: %q\n", args)
 }
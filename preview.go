@@ -0,0 +1,109 @@
+package antconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Change is one field whose effective value would differ.
+type Change struct {
+	// Path is the dotted Go field path, e.g. "Database.Host".
+	Path string
+	// Old and New are the current and the previewed value.
+	Old, New any
+	// Source is the layer that would supply New.
+	Source Layer
+}
+
+// Diff is the effect of a set of hypothetical overrides, as computed by
+// Preview. Changes are ordered by field declaration.
+type Diff struct {
+	Changes []Change
+}
+
+// Empty reports whether the overrides would change nothing.
+func (d Diff) Empty() bool { return len(d.Changes) == 0 }
+
+// Preview computes what would change if overrides were applied, without
+// applying them. Keys are environment variable names, or flag names prefixed
+// with "--" ("--port"). The full pipeline runs against a fresh value with the
+// overrides layered over the real environment and flags, and the result is
+// compared with the current contents of the loaded config (the struct
+// registered via SetConfig, or the latest LoadInto or Reload value), which,
+// like the process environment, is left untouched.
+func (a *AntConfig) Preview(overrides map[string]string) (Diff, error) {
+	if a.cfgRef == nil {
+		return Diff{}, fmt.Errorf("Preview requires SetConfig to be called first")
+	}
+	env := map[string]string{}
+	flags := map[string]string{}
+	for k, v := range overrides {
+		if name, ok := strings.CutPrefix(k, "-"); ok {
+			flags[strings.TrimPrefix(name, "-")] = v
+			continue
+		}
+		env[k] = v
+	}
+	cur := reflect.ValueOf(a.current()).Elem()
+	run := &loadRun{
+		target:        reflect.New(cur.Type()).Interface(),
+		lookupOS:      overlayLookup(env, a.osLookup()),
+		flagOverrides: flags,
+	}
+	if err := a.load(run); err != nil {
+		return Diff{}, err
+	}
+	var d Diff
+	diffValues(cur, reflect.ValueOf(run.target).Elem(), "", run.provenance, &d.Changes)
+	return d, nil
+}
+
+// diffValues appends a Change for every leaf field that differs between the
+// structs cur and next. Structs without exported fields (time.Time, big.Int)
+// are compared as leaves.
+func diffValues(cur, next reflect.Value, prefix string, prov map[string]Layer, out *[]Change) {
+	t := cur.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() || isIgnored(f) {
+			continue
+		}
+		path, _ := childPaths(f, prefix, nil)
+		ov, nv := cur.Field(i), next.Field(i)
+		if st, ok := nestedStruct(f.Type); ok {
+			if ov.Kind() == reflect.Ptr {
+				ov, nv = derefOrZero(ov, st), derefOrZero(nv, st)
+			}
+			diffValues(ov, nv, path, prov, out)
+			continue
+		}
+		if !reflect.DeepEqual(ov.Interface(), nv.Interface()) {
+			*out = append(*out, Change{Path: path, Old: ov.Interface(), New: nv.Interface(), Source: prov[path]})
+		}
+	}
+}
+
+// nestedStruct reports whether t (or *t) is a struct with exported fields,
+// returning the struct type.
+func nestedStruct(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || isOpaqueStruct(t) {
+		return nil, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+func derefOrZero(v reflect.Value, t reflect.Type) reflect.Value {
+	if v.IsNil() {
+		return reflect.New(t).Elem()
+	}
+	return v.Elem()
+}
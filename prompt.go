@@ -0,0 +1,103 @@
+package antconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrNoTerminal is returned by PromptTerminal when standard input is not a
+// terminal, or when echo cannot be disabled on it.
+var ErrNoTerminal = errors.New("standard input is not a terminal")
+
+// LayerPrompt is the provenance layer of values entered at a SetSecretPrompt
+// prompt.
+const LayerPrompt Layer = "prompt"
+
+// SetSecretPrompt enables an interactive last layer for CLI tools: a field
+// tagged both `required:"true"` and `secret:"true"` that is still empty after
+// every other layer is read with prompt, which receives a label naming the
+// field. Pass PromptTerminal to ask on the terminal with echo off. An empty
+// answer, or ErrNoTerminal (no TTY, e.g. in CI), leaves the field to the
+// usual required check. Only WriteConfigValues prompts; Preview and
+// GenerateEnvMatrix never do. nil disables prompting.
+func (a *AntConfig) SetSecretPrompt(prompt func(label string) (string, error)) {
+	a.secretPrompt = prompt
+}
+
+// PromptTerminal writes label to standard error and reads one line from
+// standard input with echo disabled. It returns ErrNoTerminal when standard
+// input is not a terminal.
+func PromptTerminal(label string) (string, error) {
+	if fi, err := os.Stdin.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return "", ErrNoTerminal
+	}
+	restore, err := disableEcho(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNoTerminal, err)
+	}
+	defer restore()
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	// The typed newline is not echoed, so end the prompt line ourselves
+	defer fmt.Fprintln(os.Stderr)
+	// Read byte by byte so no input past the line is consumed
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n == 1 {
+			if buf[0] == '\n' {
+				break
+			}
+			line = append(line, buf[0])
+		}
+		if err != nil {
+			if len(line) > 0 {
+				break
+			}
+			return "", err
+		}
+	}
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+	return string(line), nil
+}
+
+// promptSecrets asks run.prompt for every required secret still left zero.
+func (a *AntConfig) promptSecrets(run *loadRun) []*FieldError {
+	var errs []*FieldError
+	for _, f := range withoutSections(run.plan.withTag("required"), run.cleared) {
+		if !isRequired(f.tagvalue) || !isSecret(f.tags["secret"]) || !f.current().IsZero() {
+			continue
+		}
+		label := f.path
+		if desc := f.tags["desc"]; desc != "" {
+			label += " (" + desc + ")"
+		}
+		val, err := run.prompt(label)
+		if errors.Is(err, ErrNoTerminal) {
+			return errs
+		}
+		if err != nil {
+			errs = append(errs, &FieldError{Path: f.path, Source: LayerPrompt, Err: err})
+			continue
+		}
+		if val == "" {
+			continue
+		}
+		if fe := run.refuse(f.path, LayerPrompt); fe != nil {
+			errs = append(errs, fe)
+			continue
+		}
+		ctx := fmt.Sprintf("prompt for %s", f.path)
+		if err := setRowFromString(f, val, ctx, ctx, false, run.parsers); err != nil {
+			// Conversion errors quote their input; keep the secret out of them
+			errs = append(errs, &FieldError{Path: f.path, Source: LayerPrompt, Err: fmt.Errorf("the entered value is not a valid %s", f.fieldValue.Type())})
+			continue
+		}
+		run.provenance[f.path] = LayerPrompt
+		run.report.Prompted++
+	}
+	return errs
+}
@@ -0,0 +1,26 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package antconfig
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// disableEcho turns off echo on the terminal fd and returns a function that
+// restores the previous state.
+func disableEcho(fd int) (restore func(), err error) {
+	var old syscall.Termios
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TIOCGETA, uintptr(unsafe.Pointer(&old))); e != 0 {
+		return nil, e
+	}
+	t := old
+	t.Lflag &^= syscall.ECHO
+	t.Lflag |= syscall.ICANON | syscall.ISIG
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TIOCSETA, uintptr(unsafe.Pointer(&t))); e != 0 {
+		return nil, e
+	}
+	return func() {
+		syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TIOCSETA, uintptr(unsafe.Pointer(&old)))
+	}, nil
+}
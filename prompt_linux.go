@@ -0,0 +1,24 @@
+package antconfig
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// disableEcho turns off echo on the terminal fd and returns a function that
+// restores the previous state.
+func disableEcho(fd int) (restore func(), err error) {
+	var old syscall.Termios
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TCGETS, uintptr(unsafe.Pointer(&old))); e != 0 {
+		return nil, e
+	}
+	t := old
+	t.Lflag &^= syscall.ECHO
+	t.Lflag |= syscall.ICANON | syscall.ISIG
+	if _, _, e := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TCSETS, uintptr(unsafe.Pointer(&t))); e != 0 {
+		return nil, e
+	}
+	return func() {
+		syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.TCSETS, uintptr(unsafe.Pointer(&old)))
+	}, nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package antconfig
+
+import "errors"
+
+// disableEcho is not supported on this platform, so PromptTerminal reports
+// ErrNoTerminal rather than reading a secret with echo on.
+func disableEcho(fd int) (restore func(), err error) {
+	return nil, errors.New("disabling terminal echo is not supported on this platform")
+}
@@ -0,0 +1,150 @@
+package antconfig
+
+import (
+	"context"
+	"reflect"
+	"strings"
+)
+
+// Layer identifies the configuration layer that supplied a field's value.
+type Layer string
+
+// Built-in configuration layers, from lowest to highest precedence.
+const (
+	LayerDefault  Layer = "default"
+	LayerEmbedded Layer = "embedded"
+	LayerFile     Layer = "file"
+	LayerDotEnv   Layer = "dotenv"
+	LayerEnv      Layer = "env"
+	LayerFlag     Layer = "flag"
+)
+
+// loadRun carries the per-invocation state of one pass through the layered
+// pipeline, so the same AntConfig can load into different targets or against
+// a synthetic environment without touching its own configuration.
+type loadRun struct {
+	// ctx is passed to sources and parents trace spans; nil means Background.
+	ctx context.Context
+	// target is the pointer to the struct being populated.
+	target any
+	// lookupOS resolves "OS" environment variables for this run.
+	lookupOS func(string) (string, bool)
+	// exportDotEnv exports loaded .env values via os.Setenv.
+	exportDotEnv bool
+	// provenance records the layer that last set each field path.
+	provenance map[string]Layer
+	// plan is the field metadata of target, collected once per run.
+	plan *fieldPlan
+	// from holds the `from` restrictions by field path; nil when none.
+	from map[string]layerRule
+	// parsers are the AntConfig's per-instance string parsers.
+	parsers typeParsers
+	// prompt asks for required secrets left empty (SetSecretPrompt); nil
+	// for non-interactive runs.
+	prompt func(label string) (string, error)
+	// flagOverrides are extra flag values by name, on top of the parsed ones.
+	flagOverrides map[string]string
+	// skipFlags leaves out the command-line layer (SeedFlagDefaults).
+	skipFlags bool
+	// remainingArgs are the positional arguments left after flag parsing.
+	remainingArgs []string
+	// aliasUses lists the settings read through an alias name.
+	aliasUses []AliasUse
+	// report summarizes the inputs used by the run (Load).
+	report LoadReport
+	// fresh are the values loaded from each source by name, for
+	// SetSourceCache.
+	fresh map[string]map[string]any
+	// cleared are the Go paths of the optional sections left nil
+	// (resolveSections).
+	cleared []string
+	// warnings collects soft issues found during the run (OnWarning).
+	warnings []Warning
+}
+
+// Provenance returns, for every field set during the most recent
+// WriteConfigValues, the layer that supplied its final value. Keys are dotted
+// Go field paths such as "Database.Host". Fields left at their zero value by
+// every layer are absent.
+func (a *AntConfig) Provenance() map[string]Layer {
+	return copyProvenance(a.state().provenance)
+}
+
+func copyProvenance(p map[string]Layer) map[string]Layer {
+	if p == nil {
+		return nil
+	}
+	dup := make(map[string]Layer, len(p))
+	for k, v := range p {
+		dup[k] = v
+	}
+	return dup
+}
+
+// markFileProvenance records layer for every field of struct type t that the
+// decoded config document sets, recursing into nested objects.
+func markFileProvenance(doc map[string]any, t reflect.Type, prefix string, layer Layer, prov map[string]Layer) {
+	for key, val := range doc {
+		f, path, ok := jsonField(t, key, prefix)
+		if !ok {
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if nested, isObj := val.(map[string]any); isObj && ft.Kind() == reflect.Struct {
+			markFileProvenance(nested, ft, path, layer, prov)
+			continue
+		}
+		prov[path] = layer
+	}
+}
+
+// jsonField finds the field of struct type t that encoding/json would decode
+// key into (case-insensitively, exact matches preferred), searching embedded
+// structs that have no json name. It returns the field and its dotted Go path.
+func jsonField(t reflect.Type, key, prefix string) (reflect.StructField, string, bool) {
+	var fold reflect.StructField
+	var foldPath string
+	found := false
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if (!f.IsExported() && !f.Anonymous) || isIgnored(f) {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if f.Anonymous && name == "" {
+			et := f.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			if et.Kind() == reflect.Struct {
+				if ef, p, ok := jsonField(et, key, prefix); ok && !found {
+					fold, foldPath, found = ef, p, true
+				}
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		path := f.Name
+		if prefix != "" {
+			path = prefix + "." + f.Name
+		}
+		if name == key {
+			return f, path, true
+		}
+		if !found && strings.EqualFold(name, key) {
+			fold, foldPath, found = f, path, true
+		}
+	}
+	return fold, foldPath, found
+}
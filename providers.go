@@ -0,0 +1,176 @@
+package antconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// sourcesKey is the config file key listing the sources the file declares.
+const sourcesKey = "$sources"
+
+// ProviderFactory creates a Source from the options of a "$sources" entry in
+// a config file: every key of the entry except "type" and "priority", with
+// JSON values (strings, float64 numbers, bools, maps and slices).
+type ProviderFactory func(options map[string]any) (Source, error)
+
+// providers holds the factories registered with RegisterProvider.
+var providers sync.Map // string -> registeredProvider
+
+// providerSeq numbers RegisterProvider calls, so that a locked AntConfig can
+// tell providers registered after LockSources.
+var providerSeq atomic.Uint64
+
+type registeredProvider struct {
+	factory ProviderFactory
+	seq     uint64
+}
+
+// RegisterProvider makes a kind of Source available by name to config files,
+// typically from the init function of a package imported for its side
+// effects:
+//
+//	import _ "example.com/antconfig-vault"
+//
+// A config file then lists the sources it wants under "$sources", each
+// entry naming its provider in "type":
+//
+//	{
+//	  "$sources": [{"type": "vault", "path": "secret/app", "priority": 300}],
+//	  "port": 8080
+//	}
+//
+// The sources are created on every load that reads the file and applied like
+// AddSource ones. "priority" defaults to PriorityFile, so the source
+// overrides the file itself but yields to .env, env and flags; a priority
+// below PriorityFile is raised to it, as the file is only read at that
+// point. Registering a name again replaces it, built-in ones ("file" and
+// "http") included. An AntConfig locked with LockSources refuses providers
+// registered after the lock.
+func RegisterProvider(name string, factory ProviderFactory) {
+	providers.Store(name, registeredProvider{factory: factory, seq: providerSeq.Add(1)})
+}
+
+// providerNames lists the registered and built-in provider names, sorted.
+func providerNames() []string {
+	var names []string
+	for name := range builtinProviders {
+		names = append(names, name)
+	}
+	providers.Range(func(k, _ any) bool {
+		if _, ok := builtinProviders[k.(string)]; !ok {
+			names = append(names, k.(string))
+		}
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// fileSources creates the sources declared under "$sources" in the document
+// doc of the config file at path, for struct type t, ordered by priority. A
+// struct whose own field uses the key keeps it as data.
+func (a *AntConfig) fileSources(doc map[string]any, t reflect.Type, path string) ([]prioritizedSource, error) {
+	raw, ok := doc[sourcesKey]
+	if !ok {
+		return nil, nil
+	}
+	if _, _, ok := jsonField(t, sourcesKey, ""); ok {
+		return nil, nil
+	}
+	entries, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("%s must be an array of objects, not %s", sourcesKey, jsonKind(raw))
+	}
+	var out []prioritizedSource
+	for i, e := range entries {
+		entry, ok := e.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s[%d] must be an object, not %s", sourcesKey, i, jsonKind(e))
+		}
+		name, _ := entry["type"].(string)
+		if name == "" {
+			return nil, fmt.Errorf("%s[%d] needs a \"type\"", sourcesKey, i)
+		}
+		var factory ProviderFactory
+		if f, ok := providers.Load(name); ok {
+			rp := f.(registeredProvider)
+			if a.SourcesLocked() && rp.seq > a.lockedProviders.Load() {
+				return nil, fmt.Errorf("%s[%d]: source type %q was registered after LockSources: %w", sourcesKey, i, name, ErrSourcesLocked)
+			}
+			factory = rp.factory
+		} else if builtin, ok := builtinProviders[name]; ok {
+			factory = func(options map[string]any) (Source, error) {
+				return builtin(a, filepath.Dir(path), options)
+			}
+		} else {
+			return nil, fmt.Errorf("%s[%d]: unknown source type %q (registered: %s)", sourcesKey, i, name, strings.Join(providerNames(), ", "))
+		}
+		priority := PriorityFile
+		if p, ok := entry["priority"]; ok {
+			n, isNum := p.(float64)
+			if !isNum || n != float64(int(n)) {
+				return nil, fmt.Errorf("%s[%d]: priority must be an integer, not %v", sourcesKey, i, p)
+			}
+			priority = max(Priority(n), PriorityFile)
+		}
+		options := make(map[string]any, len(entry))
+		for k, v := range entry {
+			if k != "type" && k != "priority" {
+				options[k] = v
+			}
+		}
+		src, err := factory(options)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d] (%s): %w", sourcesKey, i, name, err)
+		}
+		if src == nil {
+			return nil, fmt.Errorf("%s[%d] (%s): provider returned no Source", sourcesKey, i, name)
+		}
+		out = append(out, prioritizedSource{source: src, priority: priority, last: &lastGood{}})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].priority < out[j].priority })
+	return out, nil
+}
+
+// jsonKind names the JSON type of a decoded value for error messages.
+func jsonKind(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "an object"
+	case []any:
+		return "an array"
+	case string:
+		return "a string"
+	case float64, json.Number:
+		return "a number"
+	case bool:
+		return "a boolean"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// withoutSourcesKey returns doc without its "$sources" entry, unless a field
+// of struct type t decodes it.
+func withoutSourcesKey(doc map[string]any, t reflect.Type) map[string]any {
+	if _, ok := doc[sourcesKey]; !ok {
+		return doc
+	}
+	if _, _, ok := jsonField(t, sourcesKey, ""); ok {
+		return doc
+	}
+	rest := make(map[string]any, len(doc))
+	for k, v := range doc {
+		if k != sourcesKey {
+			rest[k] = v
+		}
+	}
+	return rest
+}
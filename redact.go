@@ -0,0 +1,53 @@
+package antconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the values of `secret:"true"` fields in RedactedConfig.
+const Redacted = "REDACTED"
+
+// RedactedConfig returns the loaded config (the registered one, or the latest
+// LoadInto or Reload value) in its JSON form, decoded into
+// generic maps, with every non-empty `secret:"true"` field replaced by
+// Redacted. It is meant for showing the effective configuration to operators
+// without leaking credentials. Requires SetConfig to have been called.
+func (a *AntConfig) RedactedConfig() (map[string]any, error) {
+	if a.cfgRef == nil {
+		return nil, fmt.Errorf("RedactedConfig requires SetConfig to be called first")
+	}
+	a.checkFrozen()
+	data, err := json.Marshal(a.current())
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	dropUnmatchedKeys(doc, reflect.TypeOf(a.cfgRef).Elem())
+	for _, f := range a.Describe().Fields {
+		if f.Secret && f.Key != "" {
+			redactPath(doc, strings.Split(f.Key, "."))
+		}
+	}
+	return doc, nil
+}
+
+// redactPath replaces a non-empty value at path in doc.
+func redactPath(doc map[string]any, path []string) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := doc[key].(map[string]any)
+		if !ok {
+			return
+		}
+		doc = next
+	}
+	last := path[len(path)-1]
+	if v, ok := doc[last]; ok && v != nil && v != "" {
+		doc[last] = Redacted
+	}
+}
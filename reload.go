@@ -0,0 +1,255 @@
+package antconfig
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// reloadState tracks the effective config across Reload calls.
+type reloadState struct {
+	// mu serializes reloads from polling, signals and callers.
+	mu sync.Mutex
+	// hash is the Hash of hashOf, the value of the latest reload, which is
+	// never written again; both are guarded by mu.
+	hashOf any
+	hash   string
+
+	statusMu sync.Mutex
+	// status describes the latest reload.
+	status ReloadStatus
+
+	subMu     sync.Mutex
+	subs      map[int]func(cfg any)
+	errorSubs map[int]func(err error)
+	nextID    int
+}
+
+// ReloadStatus describes the outcome of the latest Reload, including those
+// run by StartPolling and ReloadOnSignal.
+type ReloadStatus struct {
+	// LastAttempt is when the latest reload finished and LastSuccess when
+	// the latest successful one did; both are zero before the first.
+	LastAttempt time.Time `json:"last_attempt,omitzero"`
+	LastSuccess time.Time `json:"last_success,omitzero"`
+	// Error is the error of the latest reload, empty if it succeeded.
+	Error string `json:"error,omitempty"`
+	// Failures counts the reloads that failed since the latest success.
+	Failures int `json:"failures"`
+}
+
+// SetPostLoad registers fn to check every loaded config after the built-in
+// validation, with cfg the struct that was loaded (the registered one for
+// WriteConfigValues, the scratch or fresh value of Validate, LoadInto and
+// Reload). An error from fn fails the load, wrapped, so a Reload that yields
+// a config the application rejects keeps the previous one in effect. fn
+// should only inspect cfg: it runs for check-only loads too. A nil fn
+// removes the hook.
+func (a *AntConfig) SetPostLoad(fn func(cfg any) error) {
+	a.postLoad = fn
+}
+
+// OnChange subscribes fn to configuration changes found by Reload and
+// StartPolling: fn is called with the new config, a fresh pointer of the
+// registered type (a *Config for SetConfig(&cfg)), whenever a reload yields
+// an effective config whose Hash differs from the previous load. Publish it
+// with a Snapshot's Store or an atomic swap; the registered struct is not
+// changed by reloads, so goroutines reading it never observe a half-applied
+// load. The returned function cancels the subscription.
+func (a *AntConfig) OnChange(fn func(cfg any)) (cancel func()) {
+	r := &a.reload
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+	if r.subs == nil {
+		r.subs = map[int]func(any){}
+	}
+	id := r.nextID
+	r.nextID++
+	r.subs[id] = fn
+	return func() {
+		r.subMu.Lock()
+		delete(r.subs, id)
+		r.subMu.Unlock()
+	}
+}
+
+// OnReloadError subscribes fn to failed reloads: fn is called with the error
+// of each Reload, polled or signalled reload that failed, such as a config
+// file that no longer parses or a config rejected by validation or the
+// SetPostLoad hook. The previous config stays in effect and no OnChange
+// subscriber is called. The returned function cancels the subscription.
+func (a *AntConfig) OnReloadError(fn func(err error)) (cancel func()) {
+	r := &a.reload
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+	if r.errorSubs == nil {
+		r.errorSubs = map[int]func(error){}
+	}
+	id := r.nextID
+	r.nextID++
+	r.errorSubs[id] = fn
+	return func() {
+		r.subMu.Lock()
+		delete(r.errorSubs, id)
+		r.subMu.Unlock()
+	}
+}
+
+// ReloadStatus returns the outcome of the latest reload.
+func (a *AntConfig) ReloadStatus() ReloadStatus {
+	a.reload.statusMu.Lock()
+	defer a.reload.statusMu.Unlock()
+	return a.reload.status
+}
+
+// Reload re-runs the whole pipeline into a fresh value of the registered
+// type and notifies the OnChange subscribers if the effective config changed
+// since the previous load, which it reports. A failed load, including one
+// rejected by validation or the SetPostLoad hook, returns its error and is
+// reported to the OnReloadError subscribers and by ReloadStatus instead, so
+// the previous config stays in effect. The registered struct is left alone;
+// after a successful reload the readers of the loaded config (Lookup, IsSet,
+// Hash, RedactedConfig, LogEffective, Preview, Read, Provenance and
+// RemainingArgs) report the new value, as after LoadInto, and switch back to
+// the registered struct with the next WriteConfigValues.
+func (a *AntConfig) Reload() (changed bool, err error) {
+	return a.ReloadContext(context.Background())
+}
+
+// ReloadContext is Reload with a context; see WriteConfigValuesContext.
+func (a *AntConfig) ReloadContext(ctx context.Context) (changed bool, err error) {
+	if a.cfgRef == nil {
+		return false, fmt.Errorf("Reload requires SetConfig to be called first")
+	}
+	r := &a.reload
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fresh, changed, err := a.reloadOnce(ctx)
+	r.statusMu.Lock()
+	r.status.LastAttempt = time.Now()
+	if err != nil {
+		r.status.Error = err.Error()
+		r.status.Failures++
+	} else {
+		r.status.LastSuccess, r.status.Error, r.status.Failures = r.status.LastAttempt, "", 0
+	}
+	r.statusMu.Unlock()
+
+	r.subMu.Lock()
+	var subs []func(any)
+	var errorSubs []func(error)
+	for id := 0; id < r.nextID; id++ {
+		if fn, ok := r.subs[id]; ok && changed {
+			subs = append(subs, fn)
+		}
+		if fn, ok := r.errorSubs[id]; ok && err != nil {
+			errorSubs = append(errorSubs, fn)
+		}
+	}
+	r.subMu.Unlock()
+	for _, fn := range subs {
+		fn(fresh)
+	}
+	for _, fn := range errorSubs {
+		fn(err)
+	}
+	return changed, err
+}
+
+// reloadOnce loads a fresh config and reports whether its Hash differs from
+// the previous load's.
+func (a *AntConfig) reloadOnce(ctx context.Context) (fresh any, changed bool, err error) {
+	r := &a.reload
+	// The registered struct may have been rewritten in place since it was
+	// hashed, so only a reloaded value's hash is kept
+	prev := r.hash
+	if cur := a.current(); cur != r.hashOf {
+		if prev, err = configHash(cur); err != nil {
+			return nil, false, err
+		}
+	}
+	fresh = reflect.New(reflect.TypeOf(a.cfgRef).Elem()).Interface()
+	if _, err := a.loadInto(ctx, fresh); err != nil {
+		return nil, false, err
+	}
+	hash, err := configHash(fresh)
+	if err != nil {
+		return nil, false, err
+	}
+	r.hashOf, r.hash = fresh, hash
+	return fresh, hash != prev, nil
+}
+
+// StartPolling calls Reload every interval until ctx is done, for sources
+// that cannot push changes, such as HTTP endpoints or parameter stores;
+// OnChange subscribers hear only of polls that changed the effective config.
+// A failed poll keeps the previous config, is reported to OnReloadError and
+// is retried at the next tick.
+func (a *AntConfig) StartPolling(ctx context.Context, interval time.Duration) error {
+	if a.cfgRef == nil {
+		return fmt.Errorf("StartPolling requires SetConfig to be called first")
+	}
+	if interval <= 0 {
+		return fmt.Errorf("StartPolling: interval must be positive, got %s", interval)
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := a.ReloadContext(ctx); err != nil {
+					a.logDebug("poll failed", slog.Any("error", err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// ReloadOnSignal calls Reload whenever the process receives one of sigs, the
+// classic daemon pattern:
+//
+//	stop, err := ac.ReloadOnSignal(syscall.SIGHUP)
+//
+// Changes reach the OnChange subscribers and failures the OnReloadError
+// ones, as with StartPolling. Signals arriving during a reload are coalesced
+// into one more reload. stop removes the handler, restoring the signals'
+// default behavior unless another signal.Notify still relays them.
+func (a *AntConfig) ReloadOnSignal(sigs ...os.Signal) (stop func(), err error) {
+	if a.cfgRef == nil {
+		return nil, fmt.Errorf("ReloadOnSignal requires SetConfig to be called first")
+	}
+	if len(sigs) == 0 {
+		return nil, fmt.Errorf("ReloadOnSignal: no signals given")
+	}
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sigs...)
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-ch:
+				if _, err := a.Reload(); err != nil {
+					a.logDebug("signalled reload failed", slog.String("signal", sig.String()), slog.Any("error", err))
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}, nil
+}
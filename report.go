@@ -0,0 +1,47 @@
+package antconfig
+
+import "context"
+
+// LoadReport summarizes which inputs contributed to a load, making
+// auto-discovery visible to the caller. Counts are of fields set by each
+// layer, including values later overridden by a higher layer.
+type LoadReport struct {
+	// ConfigFile is the config file that was read, or "" when none was.
+	ConfigFile string
+	// ConfigDiscovered reports whether ConfigFile was found by auto-discovery
+	// rather than set with SetConfigPath.
+	ConfigDiscovered bool
+	// EmbeddedConfig reports whether a SetEmbeddedConfig baseline was applied.
+	EmbeddedConfig bool
+	// EnvFiles lists the .env files that were loaded, in order.
+	EnvFiles []string
+	// EnvFileDiscovered reports whether EnvFiles holds an auto-discovered .env
+	// rather than paths from SetEnvPath/AddEnvPath.
+	EnvFileDiscovered bool
+	// DotEnvVars is the number of fields set from .env values.
+	DotEnvVars int
+	// EnvVars is the number of fields set from environment variables.
+	EnvVars int
+	// Flags is the number of fields set from command-line flags.
+	Flags int
+	// Sources lists the names of the registered sources that were applied.
+	Sources []string
+	// Prompted is the number of secrets read with SetSecretPrompt.
+	Prompted int
+}
+
+// Load is WriteConfigValues returning a report of the inputs that were used.
+// The report is returned for failed loads too, covering the layers applied
+// before the failure.
+func (a *AntConfig) Load() (LoadReport, error) {
+	return a.LoadContext(context.Background())
+}
+
+// LoadContext is Load with a context; see WriteConfigValuesContext.
+func (a *AntConfig) LoadContext(ctx context.Context) (LoadReport, error) {
+	run, err := a.writeConfigValues(ctx)
+	if run == nil {
+		return LoadReport{}, err
+	}
+	return run.report, err
+}
@@ -0,0 +1,52 @@
+package antconfig
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrRequired is reported, wrapped in a FieldError, for a field tagged
+// `required:"true"` that is still zero after every layer has been applied.
+var ErrRequired = errors.New("required setting is missing")
+
+// isRequired reports whether a `required` tag value is set to true.
+func isRequired(tag string) bool {
+	on, _ := strconv.ParseBool(tag)
+	return on
+}
+
+// checkRequired returns a FieldError for every required field left zero,
+// naming the ways the setting can be supplied.
+func (a *AntConfig) checkRequired(fields []fieldWithTagValue) []*FieldError {
+	var errs []*FieldError
+	for _, f := range fields {
+		if !isRequired(f.tagvalue) || !f.current().IsZero() {
+			continue
+		}
+		via := a.settingHints(f)
+		err := ErrRequired
+		if len(via) > 0 {
+			err = fmt.Errorf("%w; set it via %s", ErrRequired, strings.Join(via, ", "))
+		}
+		errs = append(errs, &FieldError{Path: f.path, Err: err})
+	}
+	return errs
+}
+
+// settingHints lists the ways a field can be supplied: its config key, env
+// var, and flag.
+func (a *AntConfig) settingHints(f fieldWithTagValue) []string {
+	var via []string
+	if f.jsonPath != nil {
+		via = append(via, "config key "+strings.Join(f.jsonPath, "."))
+	}
+	if name := f.tags["env"]; name != "" {
+		via = append(via, "env var "+name)
+	}
+	if name := f.tags["flag"]; name != "" {
+		via = append(via, "flag --"+a.flagPrefix+name)
+	}
+	return via
+}
@@ -0,0 +1,185 @@
+package antconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DefaultResolveDepth is the number of times a value may resolve to another
+// value URI before the resolution pass gives up (SetResolveDepth).
+const DefaultResolveDepth = 8
+
+// Resolver dereferences value URIs of one scheme, such as references into a
+// secret manager. Cloud providers plug in here so the core stays
+// dependency-free; see the gcpsm and azurekv packages.
+type Resolver interface {
+	// Resolve returns the value uri refers to. uri is the complete field
+	// value, scheme included.
+	Resolve(ctx context.Context, uri string) (string, error)
+}
+
+// ResolverFunc adapts a function to the Resolver interface.
+type ResolverFunc func(ctx context.Context, uri string) (string, error)
+
+// Resolve calls f(ctx, uri).
+func (f ResolverFunc) Resolve(ctx context.Context, uri string) (string, error) { return f(ctx, uri) }
+
+// RegisterResolver makes WriteConfigValues dereference string values of the
+// form scheme://… with r. The resolution pass runs once every layer has been
+// merged, so a reference may come from any layer (defaults, config file,
+// .env, env, flags or sources) and only the winning value is fetched. It
+// covers string, *string and []string fields; a failed resolution is
+// reported as a FieldError naming the field and the layer of the reference.
+// A resolved value that is itself a value URI is resolved again, up to
+// SetResolveDepth levels.
+func (a *AntConfig) RegisterResolver(scheme string, r Resolver) error {
+	if err := a.checkUnlocked("RegisterResolver"); err != nil {
+		return err
+	}
+	scheme = strings.ToLower(strings.TrimSuffix(scheme, "://"))
+	if scheme == "" || r == nil {
+		return fmt.Errorf("RegisterResolver requires a scheme and a Resolver")
+	}
+	if a.resolvers == nil {
+		a.resolvers = map[string]Resolver{}
+	}
+	a.resolvers[scheme] = r
+	return nil
+}
+
+// EnableBuiltinResolvers registers the built-in resolvers for the given
+// schemes, or for file and env when none are given:
+//
+//	file://PATH   contents of the file (read through SetFS when set), without
+//	              a trailing newline; file:///etc/app/token is absolute
+//	env://NAME    value of another environment variable, which must be set
+//	exec://CMD    standard output of the command, without a trailing newline;
+//	              CMD is split on spaces and run without a shell
+//
+// exec is never enabled implicitly: anyone who can set a config value could
+// then run commands, so only name it when every layer is trusted.
+func (a *AntConfig) EnableBuiltinResolvers(schemes ...string) error {
+	if len(schemes) == 0 {
+		schemes = []string{"file", "env"}
+	}
+	for _, scheme := range schemes {
+		var r ResolverFunc
+		switch scheme {
+		case "file":
+			r = a.resolveFile
+		case "env":
+			r = a.resolveEnv
+		case "exec":
+			r = resolveExec
+		default:
+			return fmt.Errorf("EnableBuiltinResolvers: unknown scheme %q (want file, env or exec)", scheme)
+		}
+		if err := a.RegisterResolver(scheme, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetResolveDepth limits how many times a value may resolve to another value
+// URI, which also stops reference cycles such as env://A naming env://B
+// naming env://A. n <= 0 restores DefaultResolveDepth.
+func (a *AntConfig) SetResolveDepth(n int) {
+	a.resolveDepth = n
+}
+
+func (a *AntConfig) resolveFile(_ context.Context, uri string) (string, error) {
+	data, err := a.files().ReadFile(strings.TrimPrefix(uri, "file://"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+func (a *AntConfig) resolveEnv(_ context.Context, uri string) (string, error) {
+	name := strings.TrimPrefix(uri, "env://")
+	v, ok := a.osLookup()(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return v, nil
+}
+
+func resolveExec(ctx context.Context, uri string) (string, error) {
+	args := strings.Fields(strings.TrimPrefix(uri, "exec://"))
+	if len(args) == 0 {
+		return "", errors.New("no command given")
+	}
+	out, err := exec.CommandContext(ctx, args[0], args[1:]...).Output()
+	if err != nil {
+		var exit *exec.ExitError
+		if errors.As(err, &exit) && len(exit.Stderr) > 0 {
+			return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exit.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
+
+// resolverFor returns the registered resolver for the scheme of s, if s is a
+// value URI.
+func (a *AntConfig) resolverFor(s string) (Resolver, bool) {
+	scheme, _, ok := strings.Cut(s, "://")
+	if !ok {
+		return nil, false
+	}
+	r, ok := a.resolvers[strings.ToLower(scheme)]
+	return r, ok
+}
+
+// resolveURI resolves s until it is no longer a registered value URI.
+func (a *AntConfig) resolveURI(ctx context.Context, s string) (string, error) {
+	depth := a.resolveDepth
+	if depth <= 0 {
+		depth = DefaultResolveDepth
+	}
+	for n := 0; ; n++ {
+		r, ok := a.resolverFor(s)
+		if !ok {
+			return s, nil
+		}
+		if n == depth {
+			return "", fmt.Errorf("still a value URI (%s) after %d resolutions", s, depth)
+		}
+		v, err := r.Resolve(ctx, s)
+		if err != nil {
+			return "", err
+		}
+		s = v
+	}
+}
+
+// resolveValues runs the resolution pass over the string fields set during
+// run.
+func (a *AntConfig) resolveValues(run *loadRun) []*FieldError {
+	if len(a.resolvers) == 0 {
+		return nil
+	}
+	var errs []*FieldError
+	for _, f := range run.plan.fields {
+		layer, ok := run.provenance[f.path]
+		if !ok || !isStringsField(f.fieldValue.Type()) {
+			continue
+		}
+		var raw string
+		err := mapStrings(f, func(s string) (string, error) {
+			if _, ok := a.resolverFor(s); !ok {
+				return s, nil
+			}
+			raw = s
+			return a.resolveURI(run.context(), s)
+		})
+		if err != nil {
+			errs = append(errs, &FieldError{Path: f.path, Source: layer, Raw: raw, Err: fmt.Errorf("could not resolve %s: %w", raw, err)})
+		}
+	}
+	return errs
+}
@@ -0,0 +1,128 @@
+package antconfig
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how a failing Source.Load is retried, so transient
+// network failures at boot do not fail the load. The zero value makes a
+// single attempt.
+type RetryPolicy struct {
+	// Attempts is the total number of Load calls per source and load; values
+	// below 1 mean 1.
+	Attempts int
+	// Backoff is the delay before the second attempt; it doubles for each
+	// further attempt.
+	Backoff time.Duration
+	// MaxBackoff caps the delay between attempts; 0 means no cap.
+	MaxBackoff time.Duration
+	// Jitter randomizes each delay by up to this fraction in either
+	// direction (0.2 gives 80%–120%), so restarting replicas do not retry in
+	// lockstep. It must be within [0, 1].
+	Jitter float64
+	// FallbackToCache reuses the values of a source's last successful load
+	// when every attempt fails, reporting a WarningStaleSource instead of an
+	// error. Without an earlier success the error is returned.
+	FallbackToCache bool
+}
+
+// SetRetryPolicy sets the retry policy applied to every registered Source.
+// Retries stop early when the load's context is done (SetSourceTimeout).
+func (a *AntConfig) SetRetryPolicy(p RetryPolicy) error {
+	if p.Backoff < 0 || p.MaxBackoff < 0 {
+		return fmt.Errorf("SetRetryPolicy: negative backoff")
+	}
+	if p.Jitter < 0 || p.Jitter > 1 {
+		return fmt.Errorf("SetRetryPolicy: jitter %v is outside [0, 1]", p.Jitter)
+	}
+	a.retry = p
+	return nil
+}
+
+// delay returns the wait before attempt n (n >= 2).
+func (p RetryPolicy) delay(n int) time.Duration {
+	d := p.Backoff
+	// Without a cap, doubling stops before it would overflow
+	for i := 2; i < n && (p.MaxBackoff == 0 || d < p.MaxBackoff) && d <= math.MaxInt64/2; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		j := time.Duration(float64(d) * p.Jitter * (2*rand.Float64() - 1))
+		if j > 0 && d > math.MaxInt64-j {
+			return math.MaxInt64
+		}
+		d += j
+	}
+	return d
+}
+
+// lastGood holds the values of a source's most recent successful load.
+type lastGood struct {
+	mu     sync.Mutex
+	values map[string]any
+}
+
+func (l *lastGood) get() (map[string]any, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.values, l.values != nil
+}
+
+func (l *lastGood) set(values map[string]any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.values = values
+}
+
+// sourceValues are the values loaded from a source.
+type sourceValues struct {
+	values map[string]any
+	// stale is the load error when values are the cached ones of an earlier
+	// load (RetryPolicy.FallbackToCache, AllowStaleOnError).
+	stale error
+}
+
+// loadWithRetry calls ps.source.Load according to the retry policy.
+func (a *AntConfig) loadWithRetry(ctx context.Context, ps prioritizedSource) (sourceValues, error) {
+	attempts := max(a.retry.Attempts, 1)
+	var err error
+	for n := 1; ; n++ {
+		var values map[string]any
+		if values, err = ps.source.Load(ctx); err == nil {
+			ps.last.set(values)
+			return sourceValues{values: values}, nil
+		}
+		if n >= attempts || !sleepContext(ctx, a.retry.delay(n+1)) {
+			break
+		}
+	}
+	if a.retry.FallbackToCache {
+		if values, ok := ps.last.get(); ok {
+			return sourceValues{values: values, stale: err}, nil
+		}
+	}
+	if values, ok := a.cachedSource(ps.source.Name()); ok {
+		return sourceValues{values: values, stale: err}, nil
+	}
+	return sourceValues{}, err
+}
+
+// sleepContext waits for d, reporting false when ctx is done first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
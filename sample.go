@@ -0,0 +1,212 @@
+package antconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// isSecret reports whether a `secret` tag value marks a field as sensitive.
+func isSecret(tag string) bool {
+	on, _ := strconv.ParseBool(tag)
+	return on
+}
+
+// sampleEntry is one key of a generated sample config object.
+type sampleEntry struct {
+	comments []string
+	key      string
+	value    string // rendered JSON, possibly a multi-line object
+}
+
+// GenerateSample renders a starter config file for the registered config in
+// the given format: "jsonc" (every key preceded by // comments taken from its
+// `desc` tag and listing its env var, flag and constraints) or "json" (the
+// same document without comments). Keys hold their defaults, or zero values
+// when there is none; fields tagged `secret:"true"` are always left blank.
+func (a *AntConfig) GenerateSample(format string) ([]byte, error) {
+	if a.cfgRef == nil {
+		return nil, fmt.Errorf("GenerateSample requires SetConfig to be called first")
+	}
+	var comments bool
+	switch strings.TrimPrefix(strings.ToLower(format), ".") {
+	case "jsonc":
+		comments = true
+	case "json":
+	default:
+		return nil, fmt.Errorf("GenerateSample: unsupported format %q (want jsonc or json)", format)
+	}
+	scratch := reflect.New(reflect.TypeOf(a.cfgRef).Elem())
+	defaults, err := findFieldsWithTag("default", scratch.Interface())
+	if err != nil {
+		return nil, err
+	}
+	// Malformed defaults are reported by SetConfig; render what parses
+	_ = setDefaultValues(defaults, a.parsers, map[string]Layer{})
+
+	entries, err := a.sampleEntries(scratch.Elem(), comments)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(renderSampleObject(entries) + "\n"), nil
+}
+
+// sampleEntries collects the keys of struct v, flattening embedded structs
+// without a json name like encoding/json does.
+func (a *AntConfig) sampleEntries(v reflect.Value, comments bool) ([]sampleEntry, error) {
+	var entries []sampleEntry
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if (!f.IsExported() && !f.Anonymous) || isIgnored(f) {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if nt, nested := nestedStruct(f.Type); nested {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv = reflect.New(nt)
+				}
+				fv = fv.Elem()
+			}
+			sub, err := a.sampleEntries(fv, comments)
+			if err != nil {
+				return nil, err
+			}
+			if f.Anonymous && name == "" {
+				entries = append(entries, sub...)
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			e := sampleEntry{key: name, value: renderSampleObject(sub)}
+			if comments {
+				e.comments = a.sampleComments(f)
+			}
+			entries = append(entries, e)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if isSecret(f.Tag.Get("secret")) {
+			fv = reflect.Zero(f.Type)
+		}
+		val, err := sampleValue(fv, f.Tag.Get("layout"))
+		if err != nil {
+			return nil, fmt.Errorf("GenerateSample: %s: %w", f.Name, err)
+		}
+		e := sampleEntry{key: name, value: val}
+		if comments {
+			e.comments = a.sampleComments(f)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// sampleComments describes field f for a jsonc sample.
+func (a *AntConfig) sampleComments(f reflect.StructField) []string {
+	var lines []string
+	if desc := f.Tag.Get("desc"); desc != "" {
+		_, usage := unquoteUsage(desc, f.Type)
+		lines = append(lines, strings.Split(usage, "\n")...)
+	}
+	var meta []string
+	if env := f.Tag.Get("env"); env != "" {
+		meta = append(meta, "env "+env)
+	}
+	if name := f.Tag.Get("flag"); name != "" {
+		meta = append(meta, "flag --"+a.flagPrefix+name)
+	}
+	if layout := f.Tag.Get("layout"); layout != "" {
+		meta = append(meta, "layout "+layout)
+	}
+	if isRequired(f.Tag.Get("required")) {
+		meta = append(meta, "required")
+	}
+	if isSecret(f.Tag.Get("secret")) {
+		meta = append(meta, "secret, prefer env")
+	}
+	if v := f.Tag.Get("removed_in"); v != "" {
+		meta = append(meta, "deprecated, removed in "+v)
+	}
+	if len(meta) > 0 {
+		lines = append(lines, strings.Join(meta, "; "))
+	}
+	return lines
+}
+
+// sampleValue renders the JSON form of fv as the loader would read it back.
+func sampleValue(fv reflect.Value, layout string) (string, error) {
+	v := fv.Interface()
+	switch x := v.(type) {
+	case time.Time:
+		if layout != "" {
+			v = x.Format(layout)
+		}
+	case *time.Time:
+		if x != nil && layout != "" {
+			v = x.Format(layout)
+		}
+	case url.URL:
+		v = x.String()
+	case *url.URL:
+		if x != nil {
+			v = x.String()
+		}
+	}
+	switch {
+	case fv.Kind() == reflect.Slice && fv.IsNil():
+		return "[]", nil
+	case fv.Kind() == reflect.Map && fv.IsNil():
+		return "{}", nil
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// renderSampleObject renders entries as a JSON object indented by two spaces
+// per level.
+func renderSampleObject(entries []sampleEntry) string {
+	if len(entries) == 0 {
+		return "{}"
+	}
+	const inner = "  "
+	var b strings.Builder
+	b.WriteString("{\n")
+	for i, e := range entries {
+		if i > 0 && len(e.comments) > 0 {
+			b.WriteString("\n")
+		}
+		for _, c := range e.comments {
+			b.WriteString(strings.TrimRight(inner+"// "+c, " ") + "\n")
+		}
+		key, _ := json.Marshal(e.key)
+		lines := strings.Split(e.value, "\n")
+		for j := 1; j < len(lines); j++ {
+			if lines[j] != "" {
+				lines[j] = inner + lines[j]
+			}
+		}
+		b.WriteString(inner + string(key) + ": " + strings.Join(lines, "\n"))
+		if i < len(entries)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}")
+	return b.String()
+}
@@ -0,0 +1,113 @@
+package antconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// isOptionalSection reports whether f is a pointer to a nested struct tagged
+// `default:"nil"` or `enablekey:"ENV"`. Such a section stays nil unless it is
+// enabled: its enablekey env var is true, the config file has its key, or a
+// layer above the defaults sets one of its fields. The defaults of its fields
+// apply only when it is enabled.
+func isOptionalSection(f fieldWithTagValue) bool {
+	t := f.fieldValue.Type()
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct || isOpaqueStruct(t.Elem()) {
+		return false
+	}
+	return f.tags["default"] == "nil" || f.tag.Get("enablekey") != ""
+}
+
+// resolveSections enables or clears the optional sections of run.plan once
+// every layer has been applied, recording the cleared ones in run.cleared.
+// doc is the decoded config file, if any; lookupEnv reads the enablekey vars
+// from .env and the environment. Invalid enablekey values are reported as
+// FieldErrors and leave the section as the other layers made it.
+func resolveSections(run *loadRun, doc map[string]any, lookupEnv func(string) (string, Layer, bool)) []*FieldError {
+	var errs []*FieldError
+	for _, f := range run.plan.fields {
+		if !isOptionalSection(f) || underSection(f.path, run.cleared) {
+			continue
+		}
+		enabled := f.jsonPath != nil && jsonHasPath(doc, f.jsonPath) || setAbove(run.provenance, f.path, LayerDefault)
+		if name := f.tag.Get("enablekey"); name != "" {
+			if raw, layer, ok := lookupEnv(name); ok && raw != "" {
+				on, err := strconv.ParseBool(raw)
+				if err != nil {
+					errs = append(errs, &FieldError{Path: f.path, Source: layer, Raw: raw, Err: fmt.Errorf("enablekey %s: %w", name, err)})
+					continue
+				}
+				enabled = on
+			}
+		}
+		if enabled {
+			if v := f.target(); v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			continue
+		}
+		clearSection(f)
+		run.cleared = append(run.cleared, f.path)
+		for path := range run.provenance {
+			if path == f.path || strings.HasPrefix(path, f.path+".") {
+				delete(run.provenance, path)
+			}
+		}
+	}
+	return errs
+}
+
+// setAbove reports whether a layer other than skip set the field at path or
+// one below it.
+func setAbove(prov map[string]Layer, path string, skip Layer) bool {
+	for p, layer := range prov {
+		if layer != skip && (p == path || strings.HasPrefix(p, path+".")) {
+			return true
+		}
+	}
+	return false
+}
+
+// underSection reports whether path lies under one of the section paths.
+func underSection(path string, sections []string) bool {
+	for _, s := range sections {
+		if strings.HasPrefix(path, s+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutSections drops the fields under the cleared sections.
+func withoutSections(fields []fieldWithTagValue, cleared []string) []fieldWithTagValue {
+	if len(cleared) == 0 {
+		return fields
+	}
+	var out []fieldWithTagValue
+	for _, f := range fields {
+		if !underSection(f.path, cleared) {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// clearSection sets the section pointer f to nil without allocating the
+// path to it.
+func clearSection(f fieldWithTagValue) {
+	v := f.root
+	for n, i := range f.index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+		if n == len(f.index)-1 {
+			v.Set(reflect.Zero(v.Type()))
+		}
+	}
+}
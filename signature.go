@@ -0,0 +1,59 @@
+package antconfig
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrSignatureInvalid is returned when RequireSignature is active and a
+// config file's detached signature is missing or does not verify.
+var ErrSignatureInvalid = errors.New("config file signature verification failed")
+
+// RequireSignature makes WriteConfigValues refuse config files that are not
+// signed by pubkey. The Ed25519 signature over the exact file bytes is read
+// from a detached file next to the config, path+".sig" (config.jsonc.sig),
+// either raw (64 bytes) or base64-encoded. Verification happens before the
+// file is parsed, protecting services from tampered config on shared hosts.
+func (a *AntConfig) RequireSignature(pubkey ed25519.PublicKey) error {
+	if err := a.checkUnlocked("RequireSignature"); err != nil {
+		return err
+	}
+	if len(pubkey) != ed25519.PublicKeySize {
+		return fmt.Errorf("RequireSignature expects a %d-byte Ed25519 public key, got %d bytes", ed25519.PublicKeySize, len(pubkey))
+	}
+	a.signingKey = append(ed25519.PublicKey(nil), pubkey...)
+	return nil
+}
+
+// verifySignature checks data against its detached signature when a signing
+// key is configured.
+func (a *AntConfig) verifySignature(path string, data []byte) error {
+	if a.signingKey == nil {
+		return nil
+	}
+	raw, err := a.files().ReadFile(path + ".sig")
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrSignatureInvalid, path, err)
+	}
+	return a.checkSignature(path, data, raw)
+}
+
+// checkSignature verifies data against raw, the contents of name's detached
+// signature, either raw or base64-encoded.
+func (a *AntConfig) checkSignature(name string, data, raw []byte) error {
+	sig := raw
+	if len(sig) != ed25519.SignatureSize {
+		decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(raw)))
+		if err != nil {
+			return fmt.Errorf("%w: %s.sig is neither raw nor base64", ErrSignatureInvalid, name)
+		}
+		sig = decoded
+	}
+	if !ed25519.Verify(a.signingKey, data, sig) {
+		return fmt.Errorf("%w: %s", ErrSignatureInvalid, name)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package antconfig
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Snapshot shares a config between goroutines as an immutable value that is
+// replaced atomically instead of mutated in place. Readers call Load and must
+// treat the returned struct as read-only.
+type Snapshot[T any] struct {
+	cur atomic.Pointer[T]
+
+	mu        sync.Mutex
+	base      *T
+	overrides []scopedOverride
+}
+
+type scopedOverride struct {
+	path  string
+	value any
+}
+
+// TB is the subset of testing.TB used by ScopedOverride, so the package does
+// not import "testing". ScopedOverride needs no cleanup: its result is a
+// separate Snapshot that the test simply stops using.
+type TB interface {
+	Helper()
+	Fatalf(format string, args ...any)
+}
+
+// NewSnapshot returns a Snapshot holding a deep copy of *cfg.
+func NewSnapshot[T any](cfg *T) *Snapshot[T] {
+	s := &Snapshot[T]{}
+	s.Store(cfg)
+	return s
+}
+
+// Load returns the current snapshot.
+func (s *Snapshot[T]) Load() *T { return s.cur.Load() }
+
+// Store replaces the snapshot with a deep copy of *cfg. The overrides of a
+// snapshot returned by ScopedOverride are re-applied on top of it.
+func (s *Snapshot[T]) Store(cfg *T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.base = copyOf(cfg)
+	// Overrides were validated against the same type when they were added
+	_ = s.publish()
+}
+
+// ScopedOverride returns a new Snapshot for the calling test holding the
+// current value of s with the field at path (e.g. "Database.Host", Go or json
+// names) set to value; hand it to the code under test. s is not modified, so
+// parallel tests sharing s may override the same path with different values
+// without seeing each other's. Calling ScopedOverride on the result adds
+// another override, and Store on it keeps its overrides on top of the new
+// value. An unknown path or a value that does not fit the field fails t.
+func (s *Snapshot[T]) ScopedOverride(t TB, path string, value any) *Snapshot[T] {
+	t.Helper()
+	s.mu.Lock()
+	derived := &Snapshot[T]{base: copyOf(s.base)}
+	for _, o := range s.overrides {
+		if !strings.EqualFold(o.path, path) {
+			derived.overrides = append(derived.overrides, o)
+		}
+	}
+	s.mu.Unlock()
+	derived.overrides = append(derived.overrides, scopedOverride{path: path, value: value})
+	if err := derived.publish(); err != nil {
+		t.Fatalf("antconfig: ScopedOverride: %v", err)
+		return nil
+	}
+	return derived
+}
+
+// publish builds a fresh value from base plus the overrides and swaps it in.
+// The caller holds s.mu or has not shared s yet.
+func (s *Snapshot[T]) publish() error {
+	next := copyOf(s.base)
+	root := reflect.ValueOf(next).Elem()
+	for _, o := range s.overrides {
+		row, ok := fieldByPath(root, "", o.path)
+		if !ok {
+			return fmt.Errorf("no config field matches %q", o.path)
+		}
+		if err := assignValue(row, o.value, "override for "+row.path, nil); err != nil {
+			return err
+		}
+	}
+	s.cur.Store(next)
+	return nil
+}
+
+// copyOf deep-copies *cfg; a nil cfg yields a zero value.
+func copyOf[T any](cfg *T) *T {
+	out := new(T)
+	if cfg != nil {
+		reflect.ValueOf(out).Elem().Set(deepCopy(reflect.ValueOf(cfg).Elem()))
+	}
+	return out
+}
@@ -0,0 +1,275 @@
+package antconfig
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrSOPSNoKey is returned when a config file carries sops metadata but no
+// SOPSKeyProvider was configured or it could not supply a data key.
+var ErrSOPSNoKey = errors.New("no key available for sops-encrypted config")
+
+// ErrSOPSMACMismatch is returned when the MAC of a sops-encrypted config file
+// does not match its values: values were added, removed, reordered or
+// swapped, or the mac is missing.
+var ErrSOPSMACMismatch = errors.New("sops MAC mismatch: config file was modified")
+
+// SOPSKeyProvider returns the 32-byte sops data key of a document, given its
+// "sops" metadata object (which holds the data key encrypted for KMS, age,
+// PGP, ... recipients). KMS or age integrations implement it outside the
+// core; SOPSDataKeyFromEnv covers keys injected by the environment.
+type SOPSKeyProvider func(metadata map[string]any) ([]byte, error)
+
+// SetSOPSKeyProvider enables loading of Mozilla SOPS encrypted JSON config
+// files. A file with a top-level "sops" object has its ENC[AES256_GCM,...]
+// values decrypted with the data key from p before it is applied; the
+// metadata itself is dropped. The file is refused with ErrSOPSMACMismatch
+// unless its sops MAC matches the decrypted values.
+func (a *AntConfig) SetSOPSKeyProvider(p SOPSKeyProvider) error {
+	if err := a.checkUnlocked("SetSOPSKeyProvider"); err != nil {
+		return err
+	}
+	a.sopsKeys = p
+	return nil
+}
+
+// SOPSDataKeyFromEnv returns a provider reading the plaintext data key,
+// hex- or base64-encoded, from the environment variable name.
+func SOPSDataKeyFromEnv(name string) SOPSKeyProvider {
+	return func(map[string]any) ([]byte, error) {
+		raw := strings.TrimSpace(os.Getenv(name))
+		if raw == "" {
+			return nil, fmt.Errorf("%w: %s is not set", ErrSOPSNoKey, name)
+		}
+		if key, err := hex.DecodeString(raw); err == nil {
+			return key, nil
+		}
+		key, err := base64.StdEncoding.DecodeString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must hold a hex or base64 data key", name)
+		}
+		return key, nil
+	}
+}
+
+// decryptSOPS returns js unchanged unless it is a sops document, in which
+// case the decrypted document without metadata is returned.
+func (a *AntConfig) decryptSOPS(js []byte) ([]byte, error) {
+	if !bytes.Contains(js, []byte(`"sops"`)) {
+		return js, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		// Leave reporting of malformed JSON to the regular decoding
+		return js, nil
+	}
+	meta, ok := doc["sops"].(map[string]any)
+	if !ok {
+		return js, nil
+	}
+	if a.sopsKeys == nil {
+		return nil, fmt.Errorf("%w: call SetSOPSKeyProvider", ErrSOPSNoKey)
+	}
+	key, err := a.sopsKeys(meta)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("sops data key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	d := &sopsDecoder{dec: json.NewDecoder(bytes.NewReader(js)), block: block, mac: sha512.New()}
+	d.dec.UseNumber()
+	d.onlyEncrypted, _ = meta["mac_only_encrypted"].(bool)
+	out, err := d.value(nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySOPSMAC(block, meta, d.mac.Sum(nil)); err != nil {
+		return nil, err
+	}
+	return json.Marshal(out)
+}
+
+// sopsDecoder decrypts a sops document token by token, so that leaf values
+// reach the MAC in document order, as sops hashes them.
+type sopsDecoder struct {
+	dec   *json.Decoder
+	block cipher.Block
+	mac   hash.Hash
+	// onlyEncrypted mirrors the mac_only_encrypted metadata flag
+	onlyEncrypted bool
+}
+
+// value decodes the next value of the stream. path holds the map keys leading
+// to it; sops authenticates each value with them (array indices are not part
+// of the path). The top-level "sops" metadata is skipped.
+func (d *sopsDecoder) value(path []string) (any, error) {
+	tok, err := d.dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '[' {
+			list := []any{}
+			for d.dec.More() {
+				v, err := d.value(path)
+				if err != nil {
+					return nil, err
+				}
+				list = append(list, v)
+			}
+			_, err := d.dec.Token()
+			return list, err
+		}
+		obj := map[string]any{}
+		for d.dec.More() {
+			kt, err := d.dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			k := kt.(string)
+			if k == "sops" && path == nil {
+				var skip json.RawMessage
+				if err := d.dec.Decode(&skip); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			v, err := d.value(append(path[:len(path):len(path)], k))
+			if err != nil {
+				return nil, err
+			}
+			obj[k] = v
+		}
+		_, err := d.dec.Token()
+		return obj, err
+	case string:
+		if !strings.HasPrefix(t, "ENC[") {
+			if !d.onlyEncrypted {
+				d.mac.Write(sopsMACBytes(t))
+			}
+			return t, nil
+		}
+		dv, err := decryptSOPSValue(d.block, t, strings.Join(path, ":")+":")
+		if err != nil {
+			return nil, fmt.Errorf("decrypting %s: %w", strings.Join(path, "."), err)
+		}
+		d.mac.Write(sopsMACBytes(dv))
+		return dv, nil
+	}
+	if !d.onlyEncrypted {
+		d.mac.Write(sopsMACBytes(tok))
+	}
+	return tok, nil
+}
+
+// sopsMACBytes is the representation of a leaf value that sops hashes.
+func sopsMACBytes(v any) []byte {
+	switch t := v.(type) {
+	case string:
+		return []byte(t)
+	case bool:
+		if t {
+			return []byte("True")
+		}
+		return []byte("False")
+	case json.Number:
+		if i, err := strconv.ParseInt(string(t), 10, 64); err == nil {
+			return []byte(strconv.FormatInt(i, 10))
+		}
+		if f, err := strconv.ParseFloat(string(t), 64); err == nil {
+			return []byte(strconv.FormatFloat(f, 'f', -1, 64))
+		}
+		return []byte(t)
+	}
+	return nil
+}
+
+// verifySOPSMAC checks the MAC of the document against the encrypted "mac"
+// recorded in its metadata, which is authenticated with lastmodified.
+func verifySOPSMAC(block cipher.Block, meta map[string]any, sum []byte) error {
+	enc, _ := meta["mac"].(string)
+	if enc == "" {
+		return fmt.Errorf("%w: sops metadata has no mac", ErrSOPSMACMismatch)
+	}
+	modified, _ := meta["lastmodified"].(string)
+	ts, err := time.Parse(time.RFC3339, modified)
+	if err != nil {
+		return fmt.Errorf("sops metadata: invalid lastmodified %q", modified)
+	}
+	want, err := decryptSOPSValue(block, enc, ts.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("%w: decrypting mac: %v", ErrSOPSMACMismatch, err)
+	}
+	got := fmt.Sprintf("%X", sum)
+	if w, _ := want.(string); subtle.ConstantTimeCompare([]byte(w), []byte(got)) != 1 {
+		return ErrSOPSMACMismatch
+	}
+	return nil
+}
+
+// decryptSOPSValue decrypts one ENC[AES256_GCM,data:…,iv:…,tag:…,type:…]
+// value and converts it back to its recorded type.
+func decryptSOPSValue(block cipher.Block, enc, aad string) (any, error) {
+	if !strings.HasPrefix(enc, "ENC[") || !strings.HasSuffix(enc, "]") || len(enc) < len("ENC[]") {
+		return nil, fmt.Errorf("malformed sops value")
+	}
+	parts := strings.Split(enc[len("ENC["):len(enc)-1], ",")
+	if len(parts) == 0 || parts[0] != "AES256_GCM" {
+		return nil, fmt.Errorf("unsupported sops cipher %q", parts[0])
+	}
+	fields := map[string]string{}
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, ":")
+		fields[k] = v
+	}
+	var raw [3][]byte
+	for i, name := range []string{"data", "iv", "tag"} {
+		b, err := base64.StdEncoding.DecodeString(fields[name])
+		if err != nil {
+			return nil, fmt.Errorf("malformed sops %s: %w", name, err)
+		}
+		raw[i] = b
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(raw[1]))
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, raw[1], append(raw[0], raw[2]...), []byte(aad))
+	if err != nil {
+		return nil, fmt.Errorf("authentication failed (wrong key or tampered value)")
+	}
+	s := string(plain)
+	switch fields["type"] {
+	case "int", "float":
+		return json.Number(s), nil
+	case "bool":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, err
+		}
+		return b, nil
+	default: // str, bytes
+		return s, nil
+	}
+}
@@ -0,0 +1,308 @@
+package antconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LayerMemory is the provenance layer of values injected via AddValues.
+const LayerMemory Layer = "memory"
+
+// Priority positions a Source relative to the built-in layers. A source with
+// priority p is applied after every built-in layer whose priority is <= p and
+// before the others; sources with equal priority apply in registration order.
+type Priority int
+
+// Priorities of the built-in layers.
+const (
+	PriorityDefault Priority = 0
+	PriorityFile    Priority = 100
+	PriorityDotEnv  Priority = 200
+	PriorityEnv     Priority = 300
+	PriorityFlag    Priority = 400
+)
+
+// Source supplies configuration values from outside the built-in layers,
+// e.g. computed values or service discovery results.
+type Source interface {
+	// Name identifies the source; it is recorded as the provenance Layer of
+	// the fields it sets.
+	Name() string
+	// Load returns the values to apply. Keys are field paths using Go field
+	// names or json names, matched case-insensitively ("Database.Host"), and
+	// values may also be nested maps mirroring the struct. String values are
+	// parsed like env values; other values must be assignable or
+	// JSON-compatible with the field type.
+	Load(ctx context.Context) (map[string]any, error)
+}
+
+type prioritizedSource struct {
+	source   Source
+	priority Priority
+	// last caches the source's last successful load (RetryPolicy).
+	last *lastGood
+}
+
+// AddSource registers src to be applied at the given priority on every
+// WriteConfigValues.
+func (a *AntConfig) AddSource(src Source, priority Priority) error {
+	if err := a.checkUnlocked("AddSource"); err != nil {
+		return err
+	}
+	if src == nil {
+		return fmt.Errorf("AddSource requires a non-nil Source")
+	}
+	a.sources = append(a.sources, prioritizedSource{source: src, priority: priority, last: &lastGood{}})
+	return nil
+}
+
+// AddValues registers an in-memory source holding values, applied at the
+// given priority with provenance LayerMemory. For example,
+// AddValues(map[string]any{"Database.Host": host}, PriorityEnv) overrides env
+// values but still yields to flags. When a config is already registered via
+// SetConfig, keys that match no field are rejected.
+func (a *AntConfig) AddValues(values map[string]any, priority Priority) error {
+	if err := a.checkUnlocked("AddValues"); err != nil {
+		return err
+	}
+	dup := make(map[string]any, len(values))
+	for k, v := range values {
+		dup[k] = v
+	}
+	if a.cfgRef != nil {
+		typ := reflect.TypeOf(a.cfgRef).Elem()
+		for k := range dup {
+			if !hasFieldPath(typ, k) {
+				return fmt.Errorf("AddValues: no config field matches %q", k)
+			}
+		}
+	}
+	return a.AddSource(memorySource(dup), priority)
+}
+
+// memorySource is the Source behind AddValues.
+type memorySource map[string]any
+
+func (m memorySource) Name() string { return string(LayerMemory) }
+
+func (m memorySource) Load(context.Context) (map[string]any, error) { return m, nil }
+
+// sortedSources returns the registered sources ordered by priority, keeping
+// registration order among equal priorities.
+func (a *AntConfig) sortedSources() []prioritizedSource {
+	out := make([]prioritizedSource, len(a.sources))
+	copy(out, a.sources)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].priority < out[j].priority })
+	return out
+}
+
+// applySource loads ps.source and writes its values into run.target. Load
+// failures are returned as err; values that cannot be applied are returned as
+// FieldErrors.
+func (a *AntConfig) applySource(run *loadRun, ps prioritizedSource) ([]*FieldError, error) {
+	values, err := a.loadSource(run.context(), ps)
+	if err != nil {
+		return nil, err
+	}
+	return a.applySourceValues(run, ps, values), nil
+}
+
+// loadSource loads ps.source in an "antconfig.source" span, retrying as set
+// by SetRetryPolicy.
+func (a *AntConfig) loadSource(ctx context.Context, ps prioritizedSource) (_ sourceValues, err error) {
+	src := ps.source
+	ctx, end := a.span(ctx, "antconfig.source", "antconfig.source.name", src.Name(), "antconfig.source.priority", strconv.Itoa(int(ps.priority)))
+	defer end(&err)
+	sv, err := a.loadWithRetry(ctx, ps)
+	if err != nil {
+		return sourceValues{}, fmt.Errorf("error loading source %s: %w", src.Name(), err)
+	}
+	return sv, nil
+}
+
+// applySourceValues writes the loaded values of ps into run.target.
+func (a *AntConfig) applySourceValues(run *loadRun, ps prioritizedSource, sv sourceValues) []*FieldError {
+	name := ps.source.Name()
+	if sv.stale != nil {
+		run.warn(WarningStaleSource, name, Layer(name), fmt.Sprintf("source %s failed (%v); using the values of its last successful load", name, sv.stale))
+	}
+	if _, mem := ps.source.(memorySource); sv.stale == nil && !mem {
+		if run.fresh == nil {
+			run.fresh = map[string]map[string]any{}
+		}
+		run.fresh[name] = sv.values
+	}
+	root := reflect.ValueOf(run.target).Elem()
+	run.report.Sources = append(run.report.Sources, name)
+	return applyValues(run, root, "", sv.values, Layer(name))
+}
+
+// applyValues writes values into the struct v (located at Go path prefix),
+// recording provenance into run for every field set.
+func applyValues(run *loadRun, v reflect.Value, prefix string, values map[string]any, layer Layer) []*FieldError {
+	var errs []*FieldError
+	// Sort keys so that errors and overlapping keys behave deterministically
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		val := values[key]
+		row, ok := fieldByPath(v, prefix, key)
+		field, path := row.fieldValue, row.path
+		if !ok {
+			p := key
+			if prefix != "" {
+				p = prefix + "." + key
+			}
+			errs = append(errs, &FieldError{Path: p, Source: layer, Raw: fmt.Sprint(val),
+				Err: fmt.Errorf("no config field matches %q", key)})
+			continue
+		}
+		if nested, isMap := val.(map[string]any); isMap {
+			target := field
+			if target.Kind() == reflect.Ptr && target.Type().Elem().Kind() == reflect.Struct {
+				if target.IsNil() {
+					target.Set(reflect.New(target.Type().Elem()))
+				}
+				target = target.Elem()
+			}
+			if target.Kind() == reflect.Struct {
+				errs = append(errs, applyValues(run, target, path, nested, layer)...)
+				continue
+			}
+		}
+		if fe := run.refuse(path, layer); fe != nil {
+			errs = append(errs, fe)
+			continue
+		}
+		ctx := fmt.Sprintf("%s value for %s", layer, path)
+		if err := assignValue(row, val, ctx, run.parsers); err != nil {
+			errs = append(errs, &FieldError{Path: path, Source: layer, Raw: fmt.Sprint(val), Err: err})
+			continue
+		}
+		run.provenance[path] = layer
+	}
+	return errs
+}
+
+// fieldByPath resolves a dotted key under struct v, allocating nil struct
+// pointers along the way. Each segment matches a Go field name or json name,
+// case-insensitively. It returns the field as a row with its dotted Go path
+// and the tags that affect parsing.
+func fieldByPath(v reflect.Value, prefix, key string) (fieldWithTagValue, bool) {
+	path := prefix
+	segs := strings.Split(key, ".")
+	for i, seg := range segs {
+		if v.Kind() != reflect.Struct {
+			return fieldWithTagValue{}, false
+		}
+		idx, ok := fieldIndexByName(v.Type(), seg)
+		if !ok {
+			return fieldWithTagValue{}, false
+		}
+		f := v.Type().FieldByIndex(idx)
+		fv, err := v.FieldByIndexErr(idx)
+		if err != nil { // promoted through a nil embedded pointer
+			return fieldWithTagValue{}, false
+		}
+		if path != "" {
+			path += "."
+		}
+		path += f.Name
+		if i == len(segs)-1 {
+			return fieldWithTagValue{fieldValue: fv, path: path, tag: f.Tag,
+				tags: map[string]string{"layout": f.Tag.Get("layout")}}, true
+		}
+		if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+		v = fv
+	}
+	return fieldWithTagValue{}, false
+}
+
+// hasFieldPath reports whether a dotted key resolves to a field of struct type t.
+func hasFieldPath(t reflect.Type, key string) bool {
+	for _, seg := range strings.Split(key, ".") {
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			return false
+		}
+		idx, ok := fieldIndexByName(t, seg)
+		if !ok {
+			return false
+		}
+		t = t.FieldByIndex(idx).Type
+	}
+	return true
+}
+
+// fieldIndexByName finds an exported field of struct type t by Go name or
+// json name (case-insensitive, exact matches preferred), including fields
+// promoted from embedded structs.
+func fieldIndexByName(t reflect.Type, name string) ([]int, bool) {
+	var fold []int
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous || ignoredAlong(t, f.Index) {
+			continue
+		}
+		jsonName, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.Name == name || (jsonName != "" && jsonName != "-" && jsonName == name) {
+			return f.Index, true
+		}
+		if fold == nil && (strings.EqualFold(f.Name, name) || (jsonName != "" && jsonName != "-" && strings.EqualFold(jsonName, name))) {
+			fold = f.Index
+		}
+	}
+	return fold, fold != nil
+}
+
+// ignoredAlong reports whether the field of struct type t at index, or an
+// embedded struct promoting it, is tagged `antconfig:"-"`.
+func ignoredAlong(t reflect.Type, index []int) bool {
+	for i := range index {
+		if isIgnored(t.FieldByIndex(index[:i+1])) {
+			return true
+		}
+	}
+	return false
+}
+
+// assignValue stores val into the field of row. Strings are parsed like env
+// values, honoring the `layout` and `encoding` tags, assignable values are
+// set directly, and anything else is converted through its JSON encoding.
+func assignValue(row fieldWithTagValue, val any, ctx string, parsers typeParsers) error {
+	if s, ok := val.(string); ok {
+		return setRowFromString(row, s, ctx, ctx, false, parsers)
+	}
+	field := row.target()
+	if val == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Type().AssignableTo(field.Type()) {
+		field.Set(rv)
+		return nil
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("could not encode %s: %w", ctx, err)
+	}
+	if err := json.Unmarshal(data, field.Addr().Interface()); err != nil {
+		return fmt.Errorf("could not convert %s to %s: %w", ctx, field.Type(), err)
+	}
+	return nil
+}
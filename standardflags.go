@@ -0,0 +1,134 @@
+package antconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+)
+
+// ErrExitRequested is returned by WriteConfigValues after it handled
+// --print-config or --validate-config (EnableStandardFlags); the program
+// should exit with status 0, like after flag.ErrHelp.
+var ErrExitRequested = errors.New("exit requested by --print-config or --validate-config")
+
+// standardFlags holds the values of the flags registered by
+// EnableStandardFlags.
+type standardFlags struct {
+	config   string
+	envFiles []string
+	print    *bool
+	validate *bool
+	// out receives --print-config and --validate-config output.
+	out io.Writer
+}
+
+// EnableStandardFlags registers the conventional flags of a configurable
+// program on fs, with the SetFlagPrefix prefix:
+//
+//	--config PATH       config file to load, as SetConfigPath
+//	--env-file PATH     .env file to load, as SetEnvPath; repeat for more
+//	--print-config      print the effective config as JSON, secrets redacted
+//	--validate-config   load and validate the config, report the outcome
+//
+// WriteConfigValues applies --config and --env-file before loading. With
+// --validate-config it runs Validate instead of loading; with --print-config
+// it prints after loading. Both then make it return ErrExitRequested once
+// they wrote to standard output, so the program exits without starting; a
+// failed load or validation returns its error as usual, for a non-zero exit.
+// Call it before fs.Parse; a flag fs already defines is an error.
+func (a *AntConfig) EnableStandardFlags(fs *flag.FlagSet) error {
+	names := []string{ConfigFlag, EnvFileFlag, "print-config", "validate-config"}
+	for _, name := range names {
+		if fs.Lookup(a.flagPrefix+name) != nil {
+			return fmt.Errorf("EnableStandardFlags: flag -%s is already defined", a.flagPrefix+name)
+		}
+	}
+	std := &standardFlags{out: os.Stdout}
+	fs.Func(a.flagPrefix+ConfigFlag, "config file to load", func(s string) error {
+		std.config = s
+		return nil
+	})
+	fs.Func(a.flagPrefix+EnvFileFlag, ".env file to load; may be repeated", func(s string) error {
+		std.envFiles = append(std.envFiles, s)
+		return nil
+	})
+	std.print = fs.Bool(a.flagPrefix+"print-config", false, "print the effective configuration, secrets redacted, and exit")
+	std.validate = fs.Bool(a.flagPrefix+"validate-config", false, "check the configuration and exit")
+	a.standard = std
+	return nil
+}
+
+// applyStandardFlags applies --config and --env-file before a load, unless
+// they are already in effect, so reloads work after LockSources.
+func (a *AntConfig) applyStandardFlags() error {
+	std := a.standard
+	if std == nil {
+		return nil
+	}
+	if std.config != "" && std.config != a.configPath {
+		if err := a.SetConfigPath(std.config); err != nil {
+			return err
+		}
+	}
+	if len(std.envFiles) > 0 && !slices.Equal(std.envFiles, a.envPaths) {
+		for i, p := range std.envFiles {
+			var err error
+			if i == 0 {
+				err = a.SetEnvPath(p)
+			} else {
+				err = a.AddEnvPath(p)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateFlagSet reports whether --validate-config was given.
+func (a *AntConfig) validateFlagSet() bool {
+	return a.standard != nil && *a.standard.validate
+}
+
+// validateStandard handles --validate-config: it runs Validate, leaving the
+// registered struct alone, and reports success on the standard output.
+func (a *AntConfig) validateStandard(ctx context.Context) error {
+	run, err := a.validate(ctx)
+	if err != nil {
+		return err
+	}
+	name := run.report.ConfigFile
+	if name == "" {
+		name = "configuration"
+	}
+	if _, err := fmt.Fprintf(a.standard.out, "%s: OK\n", name); err != nil {
+		return err
+	}
+	return ErrExitRequested
+}
+
+// finishStandardFlags handles --print-config after a successful load.
+func (a *AntConfig) finishStandardFlags() error {
+	std := a.standard
+	if std == nil || !*std.print {
+		return nil
+	}
+	doc, err := a.RedactedConfig()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(std.out, "%s\n", data); err != nil {
+		return err
+	}
+	return ErrExitRequested
+}
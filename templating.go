@@ -0,0 +1,73 @@
+package antconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"text/template"
+	"time"
+)
+
+// EnableTemplating runs config files through text/template before they are
+// converted to JSON, for light templating without external tooling:
+//
+//	{
+//	  "host": "{{ hostname }}",
+//	  "data_dir": "/srv/{{ env "APP_NAME" "myapp" }}",
+//	  "password": {{ env "DB_PASSWORD" | json }},
+//	  "started": "{{ now.Format "2006-01-02" }}"
+//	}
+//
+// Only a restricted set of functions is available besides the text/template
+// built-ins, none of which reads files or runs commands:
+//
+//	env NAME [DEFAULT]  the environment variable NAME (see SetEnvironment),
+//	                    DEFAULT or "" when unset; .env files load later and
+//	                    are not seen
+//	hostname            os.Hostname
+//	now                 the time of the load, a time.Time
+//	json VALUE          VALUE as a JSON literal, quoting and escaping strings
+//
+// The signature of a signed file (RequireSignature) covers the template, not its
+// output. The embedded config and other layers are not templated; write
+// {{"{{"}} for a literal "{{".
+func (a *AntConfig) EnableTemplating() error {
+	if err := a.checkUnlocked("EnableTemplating"); err != nil {
+		return err
+	}
+	a.templating = true
+	return nil
+}
+
+// renderTemplate executes the config file data at path as a template.
+func (a *AntConfig) renderTemplate(path string, data []byte) ([]byte, error) {
+	lookup := a.osLookup()
+	start := time.Now()
+	funcs := template.FuncMap{
+		"env": func(name string, def ...string) string {
+			if v, ok := lookup(name); ok {
+				return v
+			}
+			if len(def) > 0 {
+				return def[0]
+			}
+			return ""
+		},
+		"hostname": os.Hostname,
+		"now":      func() time.Time { return start },
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(funcs).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, nil); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
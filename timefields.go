@@ -0,0 +1,138 @@
+package antconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// setRowFromString is setFieldFromString for a tagged field, honoring
+// per-field tags that change how strings are parsed: `layout:"…"` on
+// time.Time (or *time.Time) fields selects a time.Parse layout instead of
+// RFC 3339.
+func setRowFromString(row fieldWithTagValue, s, parseCtx, unsupportedCtx string, ignoreNonIntSlice bool, parsers typeParsers) error {
+	if enc := row.tag.Get("encoding"); enc != "" && isEncodedField(row.fieldValue.Type()) {
+		return setEncoded(row, enc, s, parseCtx)
+	}
+	if layout := row.tags["layout"]; layout != "" && isTimeField(row.fieldValue.Type()) {
+		t, err := time.Parse(layout, s)
+		if err != nil {
+			return fmt.Errorf("could not parse %s with layout %q: %w", parseCtx, layout, err)
+		}
+		if row.fieldValue.Kind() == reflect.Ptr {
+			row.target().Set(reflect.ValueOf(&t))
+		} else {
+			row.target().Set(reflect.ValueOf(t))
+		}
+		return nil
+	}
+	return setFieldFromString(row.target(), s, parseCtx, unsupportedCtx, ignoreNonIntSlice, parsers)
+}
+
+func isTimeField(t reflect.Type) bool {
+	return t == timeType || (t.Kind() == reflect.Ptr && t.Elem() == timeType)
+}
+
+// deferredValue is a config file string held back from encoding/json because
+// its field needs tag-aware parsing.
+type deferredValue struct {
+	row fieldWithTagValue
+	raw string
+}
+
+// extractDeferred removes from the JSON document the string values of fields
+// that encoding/json would decode incorrectly (time.Time with a `layout`
+// tag, url.URL, fields with an `encoding` tag), returning the remaining document and the removed values.
+func extractDeferred(js []byte, plan *fieldPlan) ([]byte, []deferredValue, error) {
+	var wanted []fieldWithTagValue
+	for _, f := range plan.fields {
+		t := f.fieldValue.Type()
+		if f.jsonPath != nil && (f.tags["layout"] != "" && isTimeField(t) || isURLField(t) || f.tag.Get("encoding") != "") {
+			wanted = append(wanted, f)
+		}
+	}
+	if len(wanted) == 0 {
+		return js, nil, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(js))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		// Let the regular decoding report malformed documents
+		return js, nil, nil
+	}
+	var out []deferredValue
+	for _, f := range wanted {
+		if raw, ok := popJSONString(doc, f.jsonPath); ok {
+			out = append(out, deferredValue{row: f, raw: raw})
+		}
+	}
+	if len(out) == 0 {
+		return js, nil, nil
+	}
+	rest, err := json.Marshal(doc)
+	return rest, out, err
+}
+
+// popJSONString deletes and returns the string at path in doc, matching keys
+// case-insensitively like encoding/json (exact matches preferred). Values of
+// other types are left in place.
+func popJSONString(doc map[string]any, path []string) (string, bool) {
+	parent, key, ok := jsonParent(doc, path)
+	if !ok {
+		return "", false
+	}
+	s, ok := parent[key].(string)
+	if ok {
+		delete(parent, key)
+	}
+	return s, ok
+}
+
+// popJSONValue deletes and returns the value at path in doc, matching keys
+// like popJSONString.
+func popJSONValue(doc map[string]any, path []string) (any, bool) {
+	parent, key, ok := jsonParent(doc, path)
+	if !ok {
+		return nil, false
+	}
+	v := parent[key]
+	delete(parent, key)
+	return v, true
+}
+
+// jsonParent resolves path in doc to the object holding its last element and
+// that element's actual key.
+func jsonParent(doc map[string]any, path []string) (map[string]any, string, bool) {
+	cur := doc
+	for i, key := range path {
+		match := ""
+		found := false
+		for k := range cur {
+			if k == key {
+				match, found = k, true
+				break
+			}
+			if !found && strings.EqualFold(k, key) {
+				match, found = k, true
+			}
+		}
+		if !found {
+			return nil, "", false
+		}
+		if i == len(path)-1 {
+			return cur, match, true
+		}
+		next, ok := cur[match].(map[string]any)
+		if !ok {
+			return nil, "", false
+		}
+		cur = next
+	}
+	return nil, "", false
+}
@@ -0,0 +1,34 @@
+package antconfig
+
+// DisableAutoDiscovery turns off the search for config.jsonc, config.json and
+// .env when no path is set, so only files named with SetConfigPath,
+// SetEnvPath or AddEnvPath are read. Use it in production to make sure an
+// unrelated file in the working directory or one of its parents is never
+// picked up.
+func (a *AntConfig) DisableAutoDiscovery() error {
+	if err := a.checkUnlocked("DisableAutoDiscovery"); err != nil {
+		return err
+	}
+	a.noDiscovery = true
+	return nil
+}
+
+// DisableConfigFile skips the config file layer entirely: neither a
+// SetConfigPath file nor a discovered one is read.
+func (a *AntConfig) DisableConfigFile() error {
+	if err := a.checkUnlocked("DisableConfigFile"); err != nil {
+		return err
+	}
+	a.noConfigFile = true
+	return nil
+}
+
+// DisableDotEnv skips the .env layer entirely: neither SetEnvPath/AddEnvPath
+// files nor a discovered .env are read.
+func (a *AntConfig) DisableDotEnv() error {
+	if err := a.checkUnlocked("DisableDotEnv"); err != nil {
+		return err
+	}
+	a.noDotEnv = true
+	return nil
+}
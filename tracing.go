@@ -0,0 +1,77 @@
+package antconfig
+
+import "context"
+
+// Tracer starts tracing spans around configuration loading. It is shaped
+// after OpenTelemetry's trace.Tracer so an adapter is a few lines, without
+// antconfig depending on the OpenTelemetry module:
+//
+//	type otelTracer struct{ t trace.Tracer }
+//
+//	func (o otelTracer) Start(ctx context.Context, name string) (context.Context, antconfig.Span) {
+//		ctx, span := o.t.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+type Tracer interface {
+	// Start begins a span named name as a child of any span in ctx and
+	// returns a context carrying it.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced operation started by a Tracer.
+type Span interface {
+	// SetAttribute annotates the span, e.g. with the source name.
+	SetAttribute(key, value string)
+	// RecordError marks the span as failed with err.
+	RecordError(err error)
+	// End finishes the span.
+	End()
+}
+
+// SetTracer enables tracing: every load (WriteConfigValues, Preview, ...)
+// runs in an "antconfig.load" span, and each registered Source.Load in an
+// "antconfig.source" span under it, so slow remote fetches show up in traces.
+// A nil tracer disables tracing.
+func (a *AntConfig) SetTracer(t Tracer) {
+	a.tracer = t
+}
+
+// startSpan starts a span for run when a tracer is set, making it the
+// parent of spans started later in the run. The returned function ends the
+// span, recording *errp if it is non-nil.
+func (a *AntConfig) startSpan(run *loadRun, name string, attrs ...string) (end func(errp *error)) {
+	parent := run.context()
+	ctx, endSpan := a.span(parent, name, attrs...)
+	run.ctx = ctx
+	return func(errp *error) {
+		endSpan(errp)
+		run.ctx = parent
+	}
+}
+
+// span is startSpan for a plain context, safe to use from several
+// goroutines: it returns the context carrying the new span instead of
+// storing it in a run.
+func (a *AntConfig) span(parent context.Context, name string, attrs ...string) (context.Context, func(errp *error)) {
+	if a.tracer == nil {
+		return parent, func(*error) {}
+	}
+	ctx, span := a.tracer.Start(parent, name)
+	for i := 0; i+1 < len(attrs); i += 2 {
+		span.SetAttribute(attrs[i], attrs[i+1])
+	}
+	return ctx, func(errp *error) {
+		if errp != nil && *errp != nil {
+			span.RecordError(*errp)
+		}
+		span.End()
+	}
+}
+
+// context returns the context of the run, Background when unset.
+func (run *loadRun) context() context.Context {
+	if run.ctx == nil {
+		return context.Background()
+	}
+	return run.ctx
+}
@@ -0,0 +1,32 @@
+package antconfig
+
+import (
+	"net/url"
+	"reflect"
+)
+
+var urlType = reflect.TypeOf(url.URL{})
+
+// isURLField reports whether t is url.URL or *url.URL. Such fields are parsed
+// with url.Parse, including plain JSON strings in config files.
+func isURLField(t reflect.Type) bool {
+	return t == urlType || (t.Kind() == reflect.Ptr && t.Elem() == urlType)
+}
+
+// isOpaqueStruct reports whether struct type t is a single value rather than
+// a nested config section (url.URL, or a type parsed via UnmarshalText such as
+// time.Time or regexp.Regexp); its fields are not walked, nor nil pointers to
+// it allocated, when collecting tagged fields.
+func isOpaqueStruct(t reflect.Type) bool {
+	return t == urlType || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// parseURL is the built-in converter for url.URL fields.
+func parseURL(fieldVal reflect.Value, s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	fieldVal.Set(reflect.ValueOf(*u))
+	return nil
+}
@@ -0,0 +1,211 @@
+package antconfig
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// usageIndent prefixes description lines, as in flag.PrintDefaults.
+const usageIndent = "    \t"
+
+// usageTabWidth is the column width assumed for the tab in usageIndent when
+// wrapping descriptions.
+const usageTabWidth = 8
+
+// SetUsageWidth sets the column at which help descriptions are wrapped.
+// Zero (the default) disables wrapping, matching flag.PrintDefaults.
+func (a *AntConfig) SetUsageWidth(width int) {
+	if width < 0 {
+		width = 0
+	}
+	a.usageWidth = width
+}
+
+// UsageWidth returns the width set by SetUsageWidth.
+func (a *AntConfig) UsageWidth() int { return a.usageWidth }
+
+// WriteEnvHelp writes the environment variable help section to w. Entries
+// follow flag.PrintDefaults: two-space indented name and type hint, then the
+// description on a tab-indented line, with the default appended. As with
+// flags, a back-quoted word in the `desc` tag is used as the type hint
+// (desc:"connect to `host`" yields "DB_HOST host"). Fields with a
+// `group:"…"` tag (set on the field or on an enclosing struct field) are
+// listed under that heading, after the ungrouped ones, in declaration order
+// (see groupFields). Requires SetConfig to have been called; otherwise
+// nothing is written.
+func (a *AntConfig) WriteEnvHelp(w io.Writer) error {
+	if a.cfgRef == nil {
+		return nil
+	}
+	fields, err := findFieldsWithTag("env", reflect.New(reflect.TypeOf(a.cfgRef).Elem()).Interface())
+	if err != nil || len(fields) == 0 {
+		return err
+	}
+	return a.writeHelp(w, "Environment variables:\n", fields, func(f fieldWithTagValue) string { return f.tagvalue }, nil)
+}
+
+// WriteFlagHelp writes a help section for the fields tagged `flag:"name"`,
+// in the layout of flag.PrintDefaults but in declaration order and grouped by
+// `group` tags like WriteEnvHelp, instead of sorted by name. Names include
+// the SetFlagPrefix prefix. Use it from a FlagSet's Usage function in place
+// of PrintDefaults. Requires SetConfig to have been called; otherwise nothing
+// is written.
+func (a *AntConfig) WriteFlagHelp(w io.Writer) error {
+	if a.cfgRef == nil {
+		return nil
+	}
+	fields, err := findFieldsWithTag("flag", reflect.New(reflect.TypeOf(a.cfgRef).Elem()).Interface())
+	if err != nil || len(fields) == 0 {
+		return err
+	}
+	return a.writeHelp(w, "Flags:\n", fields, func(f fieldWithTagValue) string { return "-" + a.flagPrefix + f.tagvalue }, a.flagDefaults)
+}
+
+// FlagHelpString is WriteFlagHelp into a string.
+func (a *AntConfig) FlagHelpString() string {
+	var b strings.Builder
+	_ = a.WriteFlagHelp(&b)
+	return b.String()
+}
+
+// writeHelp writes title and one entry per field, named by name, with a
+// blank line and heading before each group. defaults, by field path, replace
+// the `default` tags shown (SeedFlagDefaults).
+func (a *AntConfig) writeHelp(w io.Writer, title string, fields []fieldWithTagValue, name func(fieldWithTagValue) string, defaults map[string]string) error {
+	var b strings.Builder
+	b.WriteString(title)
+	for _, g := range groupFields(fields) {
+		if g.name != "" {
+			fmt.Fprintf(&b, "\n%s:\n", g.name)
+		}
+		for _, f := range g.fields {
+			typeName, usage := unquoteUsage(f.tags["desc"], f.fieldValue.Type())
+			def, ok := defaults[f.path]
+			if !ok {
+				def = f.tags["default"]
+			}
+			writeUsageEntry(&b, name(f), typeName, usage, def, f.fieldValue.Type(), a.usageWidth)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fieldGroup is a heading and the fields listed under it.
+type fieldGroup struct {
+	name   string
+	fields []fieldWithTagValue
+}
+
+// groupFields partitions fields by their `group` tag. Ungrouped fields come
+// first, then each group in the order its first field was declared; fields
+// keep their declaration order within a group.
+func groupFields(fields []fieldWithTagValue) []fieldGroup {
+	groups := []fieldGroup{{}}
+	index := map[string]int{"": 0}
+	for _, f := range fields {
+		name := f.tags["group"]
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, fieldGroup{name: name})
+		}
+		groups[i].fields = append(groups[i].fields, f)
+	}
+	if len(groups[0].fields) == 0 {
+		groups = groups[1:]
+	}
+	return groups
+}
+
+// writeUsageEntry writes one help entry in flag.PrintDefaults layout,
+// wrapping the description at width columns when width > 0.
+func writeUsageEntry(b *strings.Builder, name, typeName, usage, def string, t reflect.Type, width int) {
+	start := b.Len()
+	fmt.Fprintf(b, "  %s", name)
+	if typeName != "" {
+		b.WriteString(" ")
+		b.WriteString(typeName)
+	}
+	// Short names keep the description on the same line, like flag does
+	if b.Len()-start <= 4 {
+		b.WriteString("\t")
+	} else {
+		b.WriteString("\n" + usageIndent)
+	}
+	if def != "" {
+		if t.Kind() == reflect.String {
+			usage += fmt.Sprintf(" (default %q)", def)
+		} else {
+			usage += fmt.Sprintf(" (default %v)", def)
+		}
+		usage = strings.TrimPrefix(usage, " ")
+	}
+	lines := strings.Split(usage, "\n")
+	if width > 0 {
+		var wrapped []string
+		for _, l := range lines {
+			wrapped = append(wrapped, wrapText(l, width-usageTabWidth)...)
+		}
+		lines = wrapped
+	}
+	b.WriteString(strings.Join(lines, "\n"+usageIndent))
+	b.WriteString("\n")
+}
+
+// unquoteUsage extracts a back-quoted type hint from desc, like
+// flag.UnquoteUsage, and otherwise derives one from the field type. Booleans
+// get no hint since they can be given without a value.
+func unquoteUsage(desc string, t reflect.Type) (typeName, usage string) {
+	if i := strings.IndexByte(desc, '`'); i >= 0 {
+		if j := strings.IndexByte(desc[i+1:], '`'); j >= 0 {
+			j += i + 1
+			return desc[i+1 : j], desc[:i] + desc[i+1:j] + desc[j+1:]
+		}
+	}
+	if isPointerScalar(t) {
+		t = t.Elem()
+	}
+	switch {
+	case t == durationType:
+		return "duration", desc
+	case reflect.PointerTo(t).Implements(flagValueType):
+		return "value", desc
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return "", desc
+	case reflect.String:
+		return "string", desc
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int", desc
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return "uint", desc
+	case reflect.Float32, reflect.Float64:
+		return "float", desc
+	}
+	return "value", desc
+}
+
+// wrapText splits s into lines of at most width columns at spaces. Words
+// longer than width are kept whole.
+func wrapText(s string, width int) []string {
+	words := strings.Fields(s)
+	if width <= 0 || len(words) == 0 {
+		return []string{s}
+	}
+	var lines []string
+	line := words[0]
+	for _, w := range words[1:] {
+		if len(line)+1+len(w) > width {
+			lines = append(lines, line)
+			line = w
+			continue
+		}
+		line += " " + w
+	}
+	return append(lines, line)
+}
@@ -0,0 +1,44 @@
+package antconfig
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Validate runs the whole WriteConfigValues pipeline in check-only mode, for
+// CI gates such as `app --validate-config`: every layer is read, converted
+// and validated into a scratch value, and all problems are returned, field
+// errors together as a *MultiError. Nothing is changed: the registered
+// struct, Provenance, RemainingArgs and the source cache stay as they were,
+// .env values are not exported and SetSecretPrompt is not asked, so a
+// required secret the prompt would supply is reported as missing. Warnings
+// are still delivered to OnWarning.
+func (a *AntConfig) Validate() error {
+	return a.ValidateContext(context.Background())
+}
+
+// ValidateContext is Validate with a context; see WriteConfigValuesContext.
+func (a *AntConfig) ValidateContext(ctx context.Context) error {
+	_, err := a.validate(ctx)
+	return err
+}
+
+// validate runs a check-only load and returns the run.
+func (a *AntConfig) validate(ctx context.Context) (*loadRun, error) {
+	if a.cfgRef == nil {
+		return nil, fmt.Errorf("Validate requires SetConfig to be called first")
+	}
+	run := &loadRun{
+		ctx:      ctx,
+		target:   reflect.New(reflect.TypeOf(a.cfgRef).Elem()).Interface(),
+		lookupOS: a.osLookup(),
+	}
+	err := a.load(run)
+	if a.onWarning != nil {
+		for _, w := range run.warnings {
+			a.onWarning(w)
+		}
+	}
+	return run, err
+}
@@ -0,0 +1,211 @@
+package antconfig
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+)
+
+// View is a read-only projection of a config struct (or sub-struct) suitable
+// for handing to third-party libraries. Get returns an independent deep copy,
+// so callers can neither mutate the shared config nor observe later changes
+// through previously returned values.
+type View[T any] interface {
+	Get() T
+}
+
+// NewReadOnly returns a View over *src. Each call to Get deep-copies the
+// current value of *src; src must stay valid for the lifetime of the view.
+// Reads are not synchronized with writers of *src.
+//
+// Types that keep their state in unexported fields are copied with their
+// Clone() or Set(*T) *T method (big.Int, big.Float, big.Rat, ...);
+// time.Time, regexp.Regexp and url.Userinfo are immutable and copied as
+// values. NewReadOnly returns an error if T reaches any other such type,
+// since a copy would share its internals with the config. Values held in
+// interface fields are copied but not checked.
+func NewReadOnly[T any](src *T) (View[T], error) {
+	if err := checkCopyable(reflect.TypeFor[T](), "", map[reflect.Type]bool{}); err != nil {
+		return nil, err
+	}
+	return readOnlyView[T]{src: src}, nil
+}
+
+// ReadOnly is like NewReadOnly but panics on error.
+func ReadOnly[T any](src *T) View[T] {
+	v, err := NewReadOnly(src)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+type readOnlyView[T any] struct {
+	src *T
+}
+
+func (v readOnlyView[T]) Get() T {
+	return deepCopy(reflect.ValueOf(v.src).Elem()).Interface().(T)
+}
+
+// immutableTypes hold only unexported state that is never modified after
+// construction, so copying the value is a deep enough copy.
+var immutableTypes = map[reflect.Type]bool{
+	timeType:                         true,
+	reflect.TypeFor[regexp.Regexp](): true,
+	reflect.TypeFor[url.Userinfo]():  true,
+}
+
+// hiddenState reports whether struct type t has fields but none exported, so
+// walking its exported fields would copy nothing but the shared internals.
+func hiddenState(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || t.NumField() == 0 || immutableTypes[t] {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
+
+// cloneMethod returns a function copying values of t with a Clone() T,
+// Clone() *T or Set(*T) *T method, or nil if t has none of them.
+func cloneMethod(t reflect.Type) func(v reflect.Value) reflect.Value {
+	ptr := reflect.PointerTo(t)
+	// addr yields a pointer to v without modifying the original
+	addr := func(v reflect.Value) reflect.Value {
+		p := reflect.New(t)
+		p.Elem().Set(v)
+		return p
+	}
+	if m, ok := ptr.MethodByName("Clone"); ok && m.Type.NumIn() == 1 && m.Type.NumOut() == 1 {
+		switch m.Type.Out(0) {
+		case t:
+			return func(v reflect.Value) reflect.Value { return m.Func.Call([]reflect.Value{addr(v)})[0] }
+		case ptr:
+			return func(v reflect.Value) reflect.Value {
+				out := m.Func.Call([]reflect.Value{addr(v)})[0]
+				if out.IsNil() {
+					return reflect.New(t).Elem()
+				}
+				return out.Elem()
+			}
+		}
+	}
+	if m, ok := ptr.MethodByName("Set"); ok && m.Type.NumIn() == 2 && m.Type.In(1) == ptr && m.Type.NumOut() == 1 && m.Type.Out(0) == ptr {
+		return func(v reflect.Value) reflect.Value {
+			out := reflect.New(t)
+			m.Func.Call([]reflect.Value{out, addr(v)})
+			return out.Elem()
+		}
+	}
+	return nil
+}
+
+// checkCopyable reports the first type reachable from t that deepCopy can only
+// copy shallowly: a type with hidden state and no clone method.
+func checkCopyable(t reflect.Type, path string, seen map[reflect.Type]bool) error {
+	if seen[t] {
+		return nil
+	}
+	seen[t] = true
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return checkCopyable(t.Elem(), path, seen)
+	case reflect.Map:
+		if err := checkCopyable(t.Key(), path, seen); err != nil {
+			return err
+		}
+		return checkCopyable(t.Elem(), path, seen)
+	case reflect.Struct:
+		if hiddenState(t) {
+			if cloneMethod(t) != nil {
+				return nil
+			}
+			where := ""
+			if path != "" {
+				where = "field " + path + ": "
+			}
+			return fmt.Errorf("%stype %s keeps its state in unexported fields and has no Clone or Set method, so it cannot be copied", where, t)
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				if err := checkCopyable(f.Type, joinPath(path, f.Name), seen); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func joinPath(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// deepCopy returns a copy of v in which pointers, slices, maps, and interfaces
+// reachable through exported fields are duplicated rather than shared. Types
+// with hidden state are copied with their clone method when they have one.
+// Values held in unexported struct fields are copied shallowly.
+func deepCopy(v reflect.Value) reflect.Value {
+	out := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return out
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(deepCopy(v.Elem()))
+		out.Set(p)
+	case reflect.Struct:
+		if hiddenState(v.Type()) {
+			if clone := cloneMethod(v.Type()); clone != nil {
+				out.Set(clone(v))
+				return out
+			}
+		}
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if f := out.Field(i); f.CanSet() {
+				f.Set(deepCopy(v.Field(i)))
+			}
+		}
+	case reflect.Slice:
+		if v.IsNil() {
+			return out
+		}
+		s := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			s.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		out.Set(s)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return out
+		}
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		out.Set(m)
+	case reflect.Interface:
+		if v.IsNil() {
+			return out
+		}
+		out.Set(deepCopy(v.Elem()))
+	default:
+		out.Set(v)
+	}
+	return out
+}
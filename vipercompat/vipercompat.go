@@ -0,0 +1,208 @@
+// Package vipercompat offers a small, read-only subset of the Viper API backed
+// by an antconfig.AntConfig, so code written against viper.GetString and
+// friends can move to antconfig one call site at a time.
+//
+// Keys are dotted paths of json or Go field names and match
+// case-insensitively, like Viper keys. Getters return the zero value for
+// unknown keys or values that cannot be converted.
+package vipercompat
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfordww/antconfig"
+)
+
+// Viper mirrors the read side of *viper.Viper over a loaded AntConfig.
+type Viper struct {
+	ac     *antconfig.AntConfig
+	prefix string
+}
+
+// New returns a Viper view of ac. Values reflect the struct registered with
+// SetConfig, so call it after WriteConfigValues (or use it across reloads).
+func New(ac *antconfig.AntConfig) *Viper {
+	return &Viper{ac: ac}
+}
+
+func (v *Viper) key(k string) string {
+	if v.prefix == "" {
+		return k
+	}
+	if k == "" {
+		return v.prefix
+	}
+	return v.prefix + "." + k
+}
+
+// Get returns the value at key, or nil.
+func (v *Viper) Get(key string) any {
+	val, ok := v.ac.Lookup(v.key(key))
+	if !ok {
+		return nil
+	}
+	return val
+}
+
+// IsSet reports whether any configuration layer (including defaults) set key.
+func (v *Viper) IsSet(key string) bool {
+	return v.ac.IsSet(v.key(key))
+}
+
+// Sub returns a Viper scoped to the nested struct at key, or nil if key does
+// not name a struct, matching viper's behavior for missing subtrees.
+func (v *Viper) Sub(key string) *Viper {
+	val, ok := v.ac.Lookup(v.key(key))
+	if !ok {
+		return nil
+	}
+	rv := reflect.ValueOf(val)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	return &Viper{ac: v.ac, prefix: v.key(key)}
+}
+
+// GetString returns the value at key formatted as a string.
+func (v *Viper) GetString(key string) string {
+	val := v.Get(key)
+	switch s := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return s
+	case fmt.Stringer:
+		return s.String()
+	}
+	return fmt.Sprint(val)
+}
+
+// GetBool returns the value at key as a bool; strings are parsed.
+func (v *Viper) GetBool(key string) bool {
+	rv := indirect(v.Get(key))
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool()
+	case reflect.String:
+		b, _ := strconv.ParseBool(rv.String())
+		return b
+	}
+	return v.GetInt64(key) != 0
+}
+
+// GetInt returns the value at key as an int.
+func (v *Viper) GetInt(key string) int { return int(v.GetInt64(key)) }
+
+// GetInt64 returns the value at key as an int64; floats are truncated and
+// strings are parsed.
+func (v *Viper) GetInt64(key string) int64 {
+	rv := indirect(v.Get(key))
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return int64(rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return int64(rv.Float())
+	case reflect.Bool:
+		if rv.Bool() {
+			return 1
+		}
+	case reflect.String:
+		n, _ := strconv.ParseInt(strings.TrimSpace(rv.String()), 0, 64)
+		return n
+	}
+	return 0
+}
+
+// GetFloat64 returns the value at key as a float64.
+func (v *Viper) GetFloat64(key string) float64 {
+	rv := indirect(v.Get(key))
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(rv.String()), 64)
+		return f
+	}
+	return float64(v.GetInt64(key))
+}
+
+// GetDuration returns the value at key as a time.Duration; strings such as
+// "1m30s" are parsed and plain numbers are nanoseconds.
+func (v *Viper) GetDuration(key string) time.Duration {
+	rv := indirect(v.Get(key))
+	if rv.Kind() == reflect.String {
+		d, _ := time.ParseDuration(rv.String())
+		return d
+	}
+	return time.Duration(v.GetInt64(key))
+}
+
+// GetStringSlice returns the slice at key with each element formatted as a
+// string; a string value yields its comma-separated parts.
+func (v *Viper) GetStringSlice(key string) []string {
+	rv := indirect(v.Get(key))
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		out := make([]string, rv.Len())
+		for i := range out {
+			out[i] = fmt.Sprint(rv.Index(i).Interface())
+		}
+		return out
+	case reflect.String:
+		if rv.String() == "" {
+			return nil
+		}
+		parts := strings.Split(rv.String(), ",")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		return parts
+	}
+	return nil
+}
+
+// GetIntSlice returns the integer slice at key.
+func (v *Viper) GetIntSlice(key string) []int {
+	rv := indirect(v.Get(key))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil
+	}
+	out := make([]int, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		e := indirect(rv.Index(i).Interface())
+		switch e.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			out = append(out, int(e.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			out = append(out, int(e.Uint()))
+		default:
+			return nil
+		}
+	}
+	return out
+}
+
+// indirect returns the reflect.Value of val with pointers dereferenced; nil
+// and nil pointers yield the invalid Value.
+func indirect(val any) reflect.Value {
+	rv := reflect.ValueOf(val)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
@@ -0,0 +1,52 @@
+package vipercompat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robfordww/antconfig"
+)
+
+func TestViperSubset(t *testing.T) {
+	type DB struct {
+		Host    string        `json:"host" default:"localhost"`
+		Port    int           `json:"port" env:"VIPERCOMPAT_PORT"`
+		Timeout time.Duration `json:"timeout" default:"2s"`
+		Tags    []int         `json:"tags" default:"[1,2]"`
+	}
+	type Cfg struct {
+		Name     string `json:"name"`
+		Debug    bool   `json:"debug" default:"true"`
+		Database DB     `json:"database"`
+		Cache    *DB    `json:"cache"`
+	}
+	t.Setenv("VIPERCOMPAT_PORT", "5432")
+	var cfg Cfg
+	ac := antconfig.New().MustSetConfig(&cfg)
+	if err := ac.WriteConfigValues(); err != nil {
+		t.Fatal(err)
+	}
+	v := New(ac)
+	if v.GetString("database.host") != "localhost" || v.GetInt("Database.Port") != 5432 {
+		t.Fatalf("unexpected values: %q %d", v.GetString("database.host"), v.GetInt("Database.Port"))
+	}
+	if v.GetString("database.port") != "5432" || !v.GetBool("debug") {
+		t.Fatal("expected conversions between types")
+	}
+	if v.GetDuration("database.timeout") != 2*time.Second || len(v.GetIntSlice("database.tags")) != 2 {
+		t.Fatal("unexpected duration or slice")
+	}
+	if !v.IsSet("database.port") || v.IsSet("name") || !v.IsSet("database") {
+		t.Fatal("unexpected IsSet results")
+	}
+	if v.Get("missing") != nil || v.GetString("missing") != "" {
+		t.Fatal("missing keys must yield zero values")
+	}
+	sub := v.Sub("database")
+	if sub == nil || sub.GetInt("port") != 5432 || !sub.IsSet("host") {
+		t.Fatal("Sub must scope keys to the nested struct")
+	}
+	if v.Sub("name") != nil {
+		t.Fatal("Sub of a non-struct key must be nil")
+	}
+}
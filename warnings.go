@@ -0,0 +1,85 @@
+package antconfig
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+)
+
+// WarningKind classifies a Warning.
+type WarningKind int
+
+const (
+	// WarningDeprecated reports a setting supplied under an alias or a key
+	// tagged `removed_in` whose removal version has not been reached yet.
+	WarningDeprecated WarningKind = iota + 1
+	// WarningUnknownKey reports a config file key that matches no field.
+	WarningUnknownKey
+	// WarningIgnoredValue reports a value that was skipped, such as an env
+	// var for a field type that cannot be set from env.
+	WarningIgnoredValue
+	// WarningMissingFile reports that an optional config file
+	// (SetConfigPathOptional) that existed when it was set is gone at load
+	// time.
+	WarningMissingFile
+	// WarningStaleSource reports a Source whose load failed and whose values
+	// from an earlier successful load were used instead
+	// (RetryPolicy.FallbackToCache).
+	WarningStaleSource
+	// WarningUnexportedTag reports a config tag on an unexported field, or on
+	// a field nested under one, which no layer can set (SetTagLint).
+	WarningUnexportedTag
+)
+
+// Warning is a soft issue found while loading: the load still succeeds, but
+// the application may want to tell its users.
+type Warning struct {
+	Kind WarningKind
+	// Path is the dotted Go field path, the dotted config key for
+	// WarningUnknownKey, the file path for WarningMissingFile, or the source
+	// name for WarningStaleSource. For WarningUnexportedTag it includes the
+	// unexported field names.
+	Path string
+	// Source is the layer the offending value came from.
+	Source Layer
+	// Message describes the issue in a form suitable for logging.
+	Message string
+}
+
+func (w Warning) String() string { return w.Message }
+
+// errValueIgnored marks values that are skipped rather than rejected.
+var errValueIgnored = errors.New("value ignored")
+
+// OnWarning registers fn to receive the warnings of each WriteConfigValues,
+// in the order they were found, after the load finishes (successfully or
+// not). The library never prints warnings itself; without a callback they are
+// dropped. A nil fn disables the callback.
+func (a *AntConfig) OnWarning(fn func(Warning)) {
+	a.onWarning = fn
+}
+
+func (run *loadRun) warn(kind WarningKind, path string, source Layer, msg string) {
+	run.warnings = append(run.warnings, Warning{Kind: kind, Path: path, Source: source, Message: msg})
+}
+
+// warnUnknownKeys records a WarningUnknownKey for every key of the decoded
+// config document from layer that no field of struct type t would decode.
+func warnUnknownKeys(run *loadRun, layer Layer, doc map[string]any, t reflect.Type, prefix string, keyPrefix []string) {
+	for key, val := range doc {
+		keyPath := append(append([]string(nil), keyPrefix...), key)
+		f, path, ok := jsonField(t, key, prefix)
+		if !ok {
+			dotted := strings.Join(keyPath, ".")
+			run.warn(WarningUnknownKey, dotted, layer, "config key "+dotted+" matches no field and was ignored")
+			continue
+		}
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if nested, isObj := val.(map[string]any); isObj && ft.Kind() == reflect.Struct && !isOpaqueStruct(ft) {
+			warnUnknownKeys(run, layer, nested, ft, path, keyPath)
+		}
+	}
+}